@@ -1,6 +1,11 @@
 package decredplugin
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	dcrtime "github.com/decred/dcrtime/api/v1"
+	"github.com/decred/dcrtime/merkle"
+)
 
 // Plugin settings, kinda doesn;t go here but for now it is fine
 const (
@@ -16,6 +21,9 @@ const (
 	CmdGetComments           = "getcomments"
 	CmdProposalVotes         = "proposalvotes"
 	CmdProposalCommentsLikes = "proposalcommentslikes"
+	CmdCensoredComments      = "censoredcomments"
+	CmdVoteInclusionProof    = "voteinclusionproof"
+	CmdBlockTimes            = "blocktimes"
 	MDStreamAuthorizeVote    = 13 // Vote authorization by proposal author
 	MDStreamVoteBits         = 14 // Vote bits and mask
 	MDStreamVoteSnapshot     = 15 // Vote tickets and start/end parameters
@@ -24,6 +32,23 @@ const (
 	VoteDurationMax = 4032 // Maximum vote duration (in blocks)
 )
 
+// ErrorStatusT is a decred plugin error code.  Error codes are returned by
+// politeiad when the decred plugin vetoes a record operation.
+type ErrorStatusT int
+
+const (
+	ErrorStatusInvalid     ErrorStatusT = 0 // Invalid status
+	ErrorStatusVoteStarted ErrorStatusT = 1 // Proposal vote has started
+)
+
+var (
+	// ErrorStatus converts error status codes to human readable text.
+	ErrorStatus = map[ErrorStatusT]string{
+		ErrorStatusInvalid:     "invalid status",
+		ErrorStatusVoteStarted: "proposal vote has started",
+	}
+)
+
 // CastVote is a signed vote.
 type CastVote struct {
 	Token     string `json:"token"`     // Proposal ID
@@ -575,3 +600,159 @@ func DecodeGetProposalCommentsLikesReply(payload []byte) (*GetProposalCommentsLi
 
 	return &gpclr, nil
 }
+
+// GetCensoredComments is a command to fetch the censor records of all
+// comments that have been censored on a given proposal.  The censor records
+// of all proposals are returned if the token is empty.
+type GetCensoredComments struct {
+	Token string `json:"token"` // Censorship token, empty for all proposals
+}
+
+// EncodeGetCensoredComments encodes GetCensoredComments into a JSON byte
+// slice.
+func EncodeGetCensoredComments(gcc GetCensoredComments) ([]byte, error) {
+	return json.Marshal(gcc)
+}
+
+// DecodeGetCensoredComments decodes a JSON byte slice into a
+// GetCensoredComments.
+func DecodeGetCensoredComments(payload []byte) (*GetCensoredComments, error) {
+	var gcc GetCensoredComments
+
+	err := json.Unmarshal(payload, &gcc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gcc, nil
+}
+
+// GetCensoredCommentsReply returns the censor records, including the server
+// receipts, for all censored comments of the requested proposals.
+type GetCensoredCommentsReply struct {
+	CensoredComments []CensorComment `json:"censoredcomments"`
+}
+
+// EncodeGetCensoredCommentsReply encodes GetCensoredCommentsReply into a JSON
+// byte slice.
+func EncodeGetCensoredCommentsReply(gccr GetCensoredCommentsReply) ([]byte, error) {
+	return json.Marshal(gccr)
+}
+
+// DecodeGetCensoredCommentsReply decodes a JSON byte slice into a
+// GetCensoredCommentsReply.
+func DecodeGetCensoredCommentsReply(payload []byte) (*GetCensoredCommentsReply, error) {
+	var gccr GetCensoredCommentsReply
+
+	err := json.Unmarshal(payload, &gccr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gccr, nil
+}
+
+// VoteInclusionProof is a command to fetch the proof that a ticket vote was
+// included in the vetted repository and anchored in dcrtime.
+type VoteInclusionProof struct {
+	Token  string `json:"token"`  // Censorship token
+	Ticket string `json:"ticket"` // Ticket hash
+}
+
+// EncodeVoteInclusionProof encodes VoteInclusionProof into a JSON byte slice.
+func EncodeVoteInclusionProof(vip VoteInclusionProof) ([]byte, error) {
+	return json.Marshal(vip)
+}
+
+// DecodeVoteInclusionProof decodes a JSON byte slice into a
+// VoteInclusionProof.
+func DecodeVoteInclusionProof(payload []byte) (*VoteInclusionProof, error) {
+	var vip VoteInclusionProof
+
+	err := json.Unmarshal(payload, &vip)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vip, nil
+}
+
+// VoteInclusionProofReply links a cast vote to the git commit that flushed
+// it into the vetted repository and to the dcrtime anchor that contains that
+// commit.  Fields are left empty for the steps that have not happened yet,
+// e.g. Commit is empty while the vote only exists in the ballot journal and
+// AnchorMerkle is empty while the commit has not been anchored.  CastVote is
+// empty if the vote does not exist.
+type VoteInclusionProofReply struct {
+	CastVote     CastVote             `json:"castvote"`     // Vote as stored in the ballot journal
+	Receipt      string               `json:"receipt"`      // Server signature of the vote signature
+	Commit       string               `json:"commit"`       // Git commit that added the vote
+	AnchorMerkle string               `json:"anchormerkle"` // Merkle root of the anchored commits
+	MerklePath   merkle.Branch        `json:"merklepath"`   // Path from commit digest to AnchorMerkle
+	VerifyDigest dcrtime.VerifyDigest `json:"verifydigest"` // dcrtime verification of AnchorMerkle
+}
+
+// EncodeVoteInclusionProofReply encodes VoteInclusionProofReply into a JSON
+// byte slice.
+func EncodeVoteInclusionProofReply(vipr VoteInclusionProofReply) ([]byte, error) {
+	return json.Marshal(vipr)
+}
+
+// DecodeVoteInclusionProofReply decodes a JSON byte slice into a
+// VoteInclusionProofReply.
+func DecodeVoteInclusionProofReply(payload []byte) (*VoteInclusionProofReply, error) {
+	var vipr VoteInclusionProofReply
+
+	err := json.Unmarshal(payload, &vipr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vipr, nil
+}
+
+// BlockTimes is a command to fetch the timestamps of the blocks at the
+// provided heights.
+type BlockTimes struct {
+	Heights []uint32 `json:"heights"` // Block heights
+}
+
+// EncodeBlockTimes encodes BlockTimes into a JSON byte slice.
+func EncodeBlockTimes(bt BlockTimes) ([]byte, error) {
+	return json.Marshal(bt)
+}
+
+// DecodeBlockTimes decodes a JSON byte slice into a BlockTimes.
+func DecodeBlockTimes(payload []byte) (*BlockTimes, error) {
+	var bt BlockTimes
+
+	err := json.Unmarshal(payload, &bt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bt, nil
+}
+
+// BlockTimesReply returns the unix timestamps of the requested blocks in the
+// order the heights were requested.
+type BlockTimesReply struct {
+	Timestamps []int64 `json:"timestamps"` // Block timestamps
+}
+
+// EncodeBlockTimesReply encodes BlockTimesReply into a JSON byte slice.
+func EncodeBlockTimesReply(btr BlockTimesReply) ([]byte, error) {
+	return json.Marshal(btr)
+}
+
+// DecodeBlockTimesReply decodes a JSON byte slice into a BlockTimesReply.
+func DecodeBlockTimesReply(payload []byte) (*BlockTimesReply, error) {
+	var btr BlockTimesReply
+
+	err := json.Unmarshal(payload, &btr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &btr, nil
+}
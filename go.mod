@@ -5,7 +5,6 @@ require (
 	github.com/badoux/checkmail v0.0.0-20180430153108-0755fe2dc241
 	github.com/btcsuite/go-flags v0.0.0-20150116065318-6c288d648c1c
 	github.com/btcsuite/go-socks v0.0.0-20170105172521-4720035b7bfd
-	github.com/dajohi/goemail v0.0.0-20180918191546-bf17cdf4557b
 	github.com/davecgh/go-spew v1.1.1
 	github.com/decred/dcrd/certgen v1.0.1
 	github.com/decred/dcrd/chaincfg v1.1.1
@@ -24,6 +23,7 @@ require (
 	github.com/gorilla/csrf v1.5.1
 	github.com/gorilla/mux v1.6.2
 	github.com/gorilla/schema v1.0.2
+	github.com/gorilla/securecookie v1.1.1
 	github.com/gorilla/sessions v1.1.3
 	github.com/gorilla/websocket v1.2.0
 	github.com/h2non/go-is-svg v0.0.0-20160927212452-35e8c4b0612c
@@ -8,6 +8,7 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"regexp"
 
@@ -35,6 +36,8 @@ const (
 	SetVettedStatusRoute   = "/v1/setvettedstatus/"            // Set vetted status
 	PluginCommandRoute     = "/v1/plugin/"                     // Send a command to a plugin
 	PluginInventoryRoute   = PluginCommandRoute + "inventory/" // Inventory all plugins
+	AnchorStatusRoute      = "/v1/anchorstatus/"               // Anchor status
+	AnchorRoute            = "/v1/anchor/"                     // Drop anchor now
 
 	ChallengeSize      = 32         // Size of challenge token in bytes
 	TokenSize          = 32         // Size of token
@@ -58,6 +61,7 @@ const (
 	ErrorStatusNoChanges                     ErrorStatusT = 14
 	ErrorStatusRecordFound                   ErrorStatusT = 15
 	ErrorStatusInvalidRPCCredentials         ErrorStatusT = 16
+	ErrorStatusPluginVeto                    ErrorStatusT = 17
 
 	// Record status codes (set and get)
 	RecordStatusInvalid           RecordStatusT = 0 // Invalid status
@@ -97,6 +101,7 @@ var (
 		ErrorStatusNoChanges:                     "no changes in record",
 		ErrorStatusRecordFound:                   "record found",
 		ErrorStatusInvalidRPCCredentials:         "invalid RPC client credentials",
+		ErrorStatusPluginVeto:                    "operation vetoed by plugin",
 	}
 
 	// RecordStatus converts record status codes to human readable text.
@@ -359,8 +364,10 @@ type InventoryReply struct {
 // UserErrorReply returns details about an error that occurred while trying to
 // execute a command due to bad input from the client.
 type UserErrorReply struct {
-	ErrorCode    ErrorStatusT `json:"errorcode"`              // Numeric error code
-	ErrorContext []string     `json:"errorcontext,omitempty"` // Additional error information
+	ErrorCode       ErrorStatusT `json:"errorcode"`                 // Numeric error code
+	ErrorContext    []string     `json:"errorcontext,omitempty"`    // Additional error information
+	PluginID        string       `json:"pluginid,omitempty"`        // Plugin that vetoed the operation
+	PluginErrorCode int          `json:"pluginerrorcode,omitempty"` // Plugin specific error code
 }
 
 // ServerErrorReply returns an error code that can be correlated with
@@ -375,11 +382,92 @@ type PluginSetting struct {
 	Value string `json:"value"` // Value of setting
 }
 
-// Plugin describes a plugin and its settings.
+// Following are the well-known plugin command permissions.  They describe who
+// a frontend may issue a plugin command on behalf of.  politeiad itself
+// requires RPC credentials for all plugin commands.
+const (
+	PluginPermissionPublic = "public" // Anyone
+	PluginPermissionUser   = "user"   // Logged in users
+	PluginPermissionAdmin  = "admin"  // Administrators
+)
+
+// PluginCommandDescriptor describes a plugin command.  The schemas describe
+// the JSON encoding of the command payload and of the reply payload.  A
+// missing request schema means that the command does not take a payload.
+type PluginCommandDescriptor struct {
+	Name          string          `json:"name"`                    // Command identifier
+	RequestSchema json.RawMessage `json:"requestschema,omitempty"` // JSON schema of the payload
+	ReplySchema   json.RawMessage `json:"replyschema,omitempty"`   // JSON schema of the reply payload
+	Permissions   []string        `json:"permissions"`             // Required permissions
+}
+
+// Plugin describes a plugin, its settings and its commands.
 type Plugin struct {
-	ID       string          `json:"id"`       // Identifier
-	Version  string          `json:"version"`  // Version
-	Settings []PluginSetting `json:"settings"` // Settings
+	ID       string                    `json:"id"`       // Identifier
+	Version  string                    `json:"version"`  // Version
+	Settings []PluginSetting           `json:"settings"` // Settings
+	Commands []PluginCommandDescriptor `json:"commands"` // Commands
+}
+
+// AnchorRecord describes an anchor that was dropped in dcrtime.
+type AnchorRecord struct {
+	Merkle         string   `json:"merkle"`         // Merkle root of anchored digests
+	Time           int64    `json:"time"`           // Time anchor was dropped
+	Confirmed      bool     `json:"confirmed"`      // Confirmed by dcrtime
+	Digests        []string `json:"digests"`        // Anchored git digests
+	Messages       []string `json:"messages"`       // Commit messages of digests
+	Transaction    string   `json:"transaction"`    // Anchor transaction
+	ChainTimestamp int64    `json:"chaintimestamp"` // Anchor block timestamp
+}
+
+// LastAnchor describes the last anchor that was dropped for a repo.
+type LastAnchor struct {
+	Repo   string `json:"repo"`   // Repository name
+	Last   string `json:"last"`   // Last anchored git digest
+	Merkle string `json:"merkle"` // Merkle root of anchor
+	Time   int64  `json:"time"`   // Time anchor was dropped
+}
+
+// AnchorVerification is the dcrtime verification status of an unconfirmed
+// anchor.  ChainTimestamp is 0 when the anchor transaction does not have
+// enough confirmations yet.
+type AnchorVerification struct {
+	Merkle         string `json:"merkle"`          // Merkle root of anchor
+	Transaction    string `json:"transaction"`     // Anchor transaction
+	ChainTimestamp int64  `json:"chaintimestamp"`  // Anchor block timestamp
+	Error          string `json:"error,omitempty"` // Verification error
+}
+
+// AnchorStatus requests the anchoring state of politeiad.  Count limits the
+// number of anchors that are returned from the anchor history, 0 returns all
+// anchors.  When Verify is set dcrtime is asked for the status of all
+// unconfirmed anchors.
+type AnchorStatus struct {
+	Challenge string `json:"challenge"` // Random challenge
+	Count     uint   `json:"count"`     // Anchor history count
+	Verify    bool   `json:"verify"`    // Verify unconfirmed anchors
+}
+
+// AnchorStatusReply returns the anchoring state of politeiad.
+type AnchorStatusReply struct {
+	Response      string               `json:"response"`                // Challenge response
+	LastAnchors   []LastAnchor         `json:"lastanchors"`             // Last anchor per repo
+	Unconfirmed   []string             `json:"unconfirmed"`             // Unconfirmed merkle roots
+	Anchors       []AnchorRecord       `json:"anchors"`                 // Anchor history
+	Verifications []AnchorVerification `json:"verifications,omitempty"` // dcrtime status
+}
+
+// Anchor drops an anchor immediately instead of waiting for the anchor cron
+// job.
+type Anchor struct {
+	Challenge string `json:"challenge"` // Random challenge
+}
+
+// AnchorReply returns the merkle root of the anchor that was dropped.  Merkle
+// is empty when there was nothing to anchor.
+type AnchorReply struct {
+	Response string `json:"response"` // Challenge response
+	Merkle   string `json:"merkle"`   // Merkle root of anchor
 }
 
 // PluginInventory retrieves all active plugins and their settings.
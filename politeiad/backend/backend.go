@@ -5,6 +5,7 @@
 package backend
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"regexp"
@@ -57,6 +58,20 @@ func (c ContentVerificationError) Error() string {
 	return fmt.Sprintf("%v: %v", v1.ErrorStatus[c.ErrorCode], c.ErrorContext)
 }
 
+// PluginVetoError is returned when a plugin hook vetoes a record operation.
+// The error code and context are defined by the plugin.
+type PluginVetoError struct {
+	PluginID     string   // Plugin that vetoed the operation
+	Hook         string   // Hook that vetoed the operation
+	ErrorCode    int      // Plugin specific error code
+	ErrorContext []string // Additional error information
+}
+
+func (p PluginVetoError) Error() string {
+	return fmt.Sprintf("%v %v veto %v: %v", p.PluginID, p.Hook,
+		p.ErrorCode, p.ErrorContext)
+}
+
 type File struct {
 	Name    string // Basename of the file
 	MIME    string // MIME type
@@ -133,11 +148,56 @@ type PluginSetting struct {
 	Value string // Value of setting
 }
 
-// Plugin describes a plugin and its settings.
+// PluginCommandDescriptor describes a plugin command.
+type PluginCommandDescriptor struct {
+	Name          string          // Command identifier
+	RequestSchema json.RawMessage // JSON schema of the payload, nil if none
+	ReplySchema   json.RawMessage // JSON schema of the reply payload
+	Permissions   []string        // Required permissions
+}
+
+// Plugin describes a plugin, its settings and its commands.
 type Plugin struct {
-	ID       string          // Identifier
-	Version  string          // Version
-	Settings []PluginSetting // Settings
+	ID       string                    // Identifier
+	Version  string                    // Version
+	Settings []PluginSetting           // Settings
+	Commands []PluginCommandDescriptor // Commands
+}
+
+// AnchorRecord describes an anchor that was dropped in dcrtime.
+type AnchorRecord struct {
+	Merkle         string   // Merkle root of the anchored digests
+	Time           int64    // OS time when the anchor was dropped
+	Confirmed      bool     // Anchor has been confirmed by dcrtime
+	Digests        []string // Anchored git digests
+	Messages       []string // One-line commit messages of anchored digests
+	Transaction    string   // Anchor transaction, if confirmed
+	ChainTimestamp int64    // Anchor block timestamp, if confirmed
+}
+
+// LastAnchorRecord describes the last anchor that was dropped for a repo.
+type LastAnchorRecord struct {
+	Repo   string // Repository name
+	Last   string // Last git digest that was anchored
+	Merkle string // Merkle root of the last anchor
+	Time   int64  // OS time when the anchor was dropped
+}
+
+// AnchorVerification is the dcrtime verification status of an unconfirmed
+// anchor.
+type AnchorVerification struct {
+	Merkle         string // Merkle root of the anchor
+	Transaction    string // Anchor transaction, if any
+	ChainTimestamp int64  // Anchor block timestamp, 0 if not confirmed
+	Error          string // Verification error, if any
+}
+
+// AnchorStatus describes the anchoring state of the backend.
+type AnchorStatus struct {
+	LastAnchors   []LastAnchorRecord   // Last anchor per repo
+	Unconfirmed   []string             // Merkle roots of unconfirmed anchors
+	Anchors       []AnchorRecord       // Anchor history, newest first
+	Verifications []AnchorVerification // dcrtime status of unconfirmed anchors
 }
 
 type Backend interface {
@@ -176,8 +236,15 @@ type Backend interface {
 	// Obtain plugin settings
 	GetPlugins() ([]Plugin, error)
 
+	// Anchor status (anchor history count, verify unconfirmed anchors)
+	AnchorStatus(uint, bool) (*AnchorStatus, error)
+
+	// Drop an anchor immediately, returns the merkle root of the anchor
+	// or an empty string when there was nothing to anchor
+	Anchor() (string, error)
+
 	// Plugin pass-through command
-	Plugin(string, string) (string, string, error) // command type, payload, errror
+	Plugin(string, string, string) (string, string, error) // plugin id, command type, payload, errror
 
 	// Close performs cleanup of the backend.
 	Close()
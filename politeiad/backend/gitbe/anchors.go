@@ -5,6 +5,7 @@
 package gitbe
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
@@ -13,6 +14,7 @@ import (
 	"time"
 
 	"github.com/decred/dcrtime/merkle"
+	"github.com/decred/politeia/politeiad/backend"
 )
 
 // An anchor corresponds to a set of git commit hashes, along with their
@@ -95,6 +97,7 @@ var (
 	regexAnchorConfirmation   = regexp.MustCompile(anchorConfirmationPattern)
 	anchorPattern             = fmt.Sprintf(`\s*%s\s*(\S*)`, markerAnchor)
 	regexAnchor               = regexp.MustCompile(anchorPattern)
+	regexAnchorTransaction    = regexp.MustCompile(`anchored in TX\s+(\S+)`)
 )
 
 const (
@@ -151,6 +154,18 @@ func anchorConfirmationMerkle(commit *GitCommit) string {
 	return anchorConfirmations[1]
 }
 
+// anchorConfirmationTransaction extracts the dcrtime transaction from an
+// anchor confirmation commit.
+func anchorConfirmationTransaction(commit *GitCommit) string {
+	for _, line := range commit.Message[1:] {
+		tx := regexAnchorTransaction.FindStringSubmatch(line)
+		if len(tx) == 2 {
+			return tx[1]
+		}
+	}
+	return ""
+}
+
 // anchorConfirmationMerkle extracts the Merkle Root from an anchor commit.
 func anchorCommitMerkle(commit *GitCommit) string {
 	return regexAnchor.FindStringSubmatch(commit.Message[0])[1]
@@ -172,7 +187,8 @@ func parseAnchorCommit(commit *GitCommit) ([][]byte, []string, error) {
 	var messages []string
 	for _, line := range commit.Message[2 : len(commit.Message)-1] {
 		// The first word is the commit hash. The rest is the one-line commit message.
-		lineParts := strings.SplitN(line, " ", 2)
+		// git log indents commit messages so strip the indentation first.
+		lineParts := strings.SplitN(strings.TrimSpace(line), " ", 2)
 		digest, err := hex.DecodeString(lineParts[0])
 		if err != nil {
 			return nil, nil, err
@@ -318,3 +334,122 @@ func (g *gitBackEnd) readUnconfirmedAnchorRecord() (*UnconfirmedAnchor, error) {
 
 	return &ua, nil
 }
+
+// readAnchorRecordByDigest finds the anchor that contains the provided commit
+// digest and returns it along with its Merkle root.  A nil anchor is returned
+// if the digest has not been anchored yet.
+func (g *gitBackEnd) readAnchorRecordByDigest(digest []byte) (*Anchor, []byte, error) {
+	// Get the git log
+	gitLog, err := g.gitLog(g.vetted)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The git log is ordered from newest to oldest so anchor
+	// confirmations are encountered before the anchor they confirm.
+	confirmed := make(map[string]struct{})
+	currLine := 0
+	for currLine < len(gitLog) {
+		commit, linesUsed, err := extractCommit(gitLog[currLine:])
+		if err != nil {
+			return nil, nil, err
+		}
+		currLine = currLine + linesUsed
+
+		firstLine := commit.Message[0]
+		if regexAnchorConfirmation.MatchString(firstLine) {
+			confirmed[anchorConfirmationMerkle(commit)] = struct{}{}
+			continue
+		}
+		if !regexAnchor.MatchString(firstLine) {
+			continue
+		}
+
+		digests, messages, err := parseAnchorCommit(commit)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, d := range digests {
+			if !bytes.Equal(d, digest) {
+				continue
+			}
+
+			// Found the anchor
+			merkleStr := anchorCommitMerkle(commit)
+			key, err := hex.DecodeString(merkleStr)
+			if err != nil {
+				return nil, nil, err
+			}
+			t := AnchorUnverified
+			if _, ok := confirmed[merkleStr]; ok {
+				t = AnchorVerified
+			}
+			return &Anchor{
+				Type:     t,
+				Time:     commit.Time,
+				Digests:  digests,
+				Messages: messages,
+			}, key, nil
+		}
+	}
+
+	return nil, nil, nil
+}
+
+// readAnchorHistory retrieves up to count anchor records from the git log,
+// newest first.  All anchors are returned when count is 0.
+func (g *gitBackEnd) readAnchorHistory(count uint) ([]backend.AnchorRecord, error) {
+	// Get the git log
+	gitLog, err := g.gitLog(g.vetted)
+	if err != nil {
+		return nil, err
+	}
+
+	// The git log is ordered from newest to oldest so anchor
+	// confirmations are encountered before the anchor they confirm.
+	confirmed := make(map[string]string) // [merkle]transaction
+	anchors := make([]backend.AnchorRecord, 0, count)
+	currLine := 0
+	for currLine < len(gitLog) {
+		if count != 0 && uint(len(anchors)) >= count {
+			break
+		}
+
+		commit, linesUsed, err := extractCommit(gitLog[currLine:])
+		if err != nil {
+			return nil, err
+		}
+		currLine = currLine + linesUsed
+
+		firstLine := commit.Message[0]
+		if regexAnchorConfirmation.MatchString(firstLine) {
+			confirmed[anchorConfirmationMerkle(commit)] =
+				anchorConfirmationTransaction(commit)
+			continue
+		}
+		if !regexAnchor.MatchString(firstLine) {
+			continue
+		}
+
+		digests, messages, err := parseAnchorCommit(commit)
+		if err != nil {
+			return nil, err
+		}
+		merkleStr := anchorCommitMerkle(commit)
+		tx, ok := confirmed[merkleStr]
+		ar := backend.AnchorRecord{
+			Merkle:      merkleStr,
+			Time:        commit.Time,
+			Confirmed:   ok,
+			Digests:     make([]string, 0, len(digests)),
+			Messages:    messages,
+			Transaction: tx,
+		}
+		for _, d := range digests {
+			ar.Digests = append(ar.Digests, hex.EncodeToString(d))
+		}
+		anchors = append(anchors, ar)
+	}
+
+	return anchors, nil
+}
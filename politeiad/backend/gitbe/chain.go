@@ -0,0 +1,545 @@
+// Copyright (c) 2018 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package gitbe
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	dcrdataapi "github.com/decred/dcrdata/api/types"
+	"github.com/decred/politeia/util"
+)
+
+const (
+	// Chain providers that can be listed in the chainproviders plugin
+	// setting.
+	chainProviderDcrdata = "dcrdata" // dcrdata HTTP API
+	chainProviderDcrd    = "dcrd"    // dcrd JSON-RPC
+	chainProviderFixture = "fixture" // JSON fixture file
+
+	defaultChainTimeout    = 30 * time.Second
+	defaultChainRetries    = 2
+	defaultChainRetryDelay = time.Second
+)
+
+var (
+	// errChainUnsupported is returned by chain providers that can't
+	// provide the requested data.  It is not retried.
+	errChainUnsupported = errors.New("not supported by chain provider")
+)
+
+// chainProvider provides the chain data that is required by the decred
+// plugin.
+type chainProvider interface {
+	// Name returns the name of the provider.
+	Name() string
+
+	// BestBlock returns the best block.
+	BestBlock() (*dcrdataapi.BlockDataBasic, error)
+
+	// Block returns the block at the provided height.
+	Block(uint32) (*dcrdataapi.BlockDataBasic, error)
+
+	// Snapshot returns the sorted live tickets at the provided block
+	// hash.
+	Snapshot(string) ([]string, error)
+
+	// Transactions returns the provided transactions in the same order.
+	Transactions([]string) ([]dcrdataapi.TrimmedTx, error)
+}
+
+// isChainSetting returns whether the provided plugin setting configures the
+// chain provider.
+func isChainSetting(key string) bool {
+	switch key {
+	case decredPluginChainProviders, decredPluginDcrdata,
+		decredPluginDcrdHost, decredPluginDcrdUser,
+		decredPluginDcrdPass, decredPluginDcrdCert,
+		decredPluginChainFixture, decredPluginChainTimeout,
+		decredPluginChainRetries:
+		return true
+	}
+	return false
+}
+
+// validateChainSetting validates the value of a chain setting.
+func validateChainSetting(key, value string) error {
+	if value == "" {
+		return nil
+	}
+	switch key {
+	case decredPluginChainProviders:
+		return validateChainProviders(strings.Split(value, ","))
+	case decredPluginChainTimeout:
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid chain timeout: %v", value)
+		}
+	case decredPluginChainRetries:
+		r, err := strconv.Atoi(value)
+		if err != nil || r < 0 {
+			return fmt.Errorf("invalid chain retries: %v", value)
+		}
+	}
+	return nil
+}
+
+// validateChainProviders verifies that the provided chain providers are known
+// and that at least one of them supports ticket pool snapshots.  Votes can't
+// be started without a snapshot.
+func validateChainProviders(names []string) error {
+	snapshots := false
+	for _, v := range names {
+		switch strings.TrimSpace(v) {
+		case chainProviderDcrdata, chainProviderFixture:
+			snapshots = true
+		case chainProviderDcrd:
+		default:
+			return fmt.Errorf("unknown chain provider: %v", v)
+		}
+	}
+	if !snapshots {
+		return fmt.Errorf("chain providers do not support ticket "+
+			"snapshots: %v", strings.Join(names, ","))
+	}
+	return nil
+}
+
+// newChainProvider creates the chain provider that is described by the
+// provided plugin settings.  Providers are tried in the order of the
+// chainproviders setting.
+func newChainProvider(settings map[string]string) (chainProvider, error) {
+	timeout := defaultChainTimeout
+	if v, ok := settings[decredPluginChainTimeout]; ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chain timeout: %v", err)
+		}
+		timeout = d
+	}
+	retries := defaultChainRetries
+	if v, ok := settings[decredPluginChainRetries]; ok {
+		r, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chain retries: %v", err)
+		}
+		retries = r
+	}
+
+	names := []string{chainProviderDcrdata}
+	if v, ok := settings[decredPluginChainProviders]; ok {
+		names = strings.Split(v, ",")
+	}
+	err := validateChainProviders(names)
+	if err != nil {
+		return nil, err
+	}
+	providers := make([]chainProvider, 0, len(names))
+	for _, name := range names {
+		var (
+			cp  chainProvider
+			err error
+		)
+		switch strings.TrimSpace(name) {
+		case chainProviderDcrdata:
+			host, ok := settings[decredPluginDcrdata]
+			if !ok {
+				return nil, fmt.Errorf("dcrdata host not set")
+			}
+			cp = newDcrdataProvider(host, timeout)
+		case chainProviderDcrd:
+			cp, err = newDcrdProvider(settings[decredPluginDcrdHost],
+				settings[decredPluginDcrdUser],
+				settings[decredPluginDcrdPass],
+				settings[decredPluginDcrdCert], timeout)
+		case chainProviderFixture:
+			cp, err = loadFixtureProvider(
+				settings[decredPluginChainFixture])
+		default:
+			err = fmt.Errorf("unknown chain provider: %v", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, cp)
+	}
+
+	if len(providers) == 1 && retries == 0 {
+		return providers[0], nil
+	}
+	return &failoverProvider{
+		providers:  providers,
+		retries:    retries,
+		retryDelay: defaultChainRetryDelay,
+	}, nil
+}
+
+// chain returns the chain provider of the decred plugin.  It is created from
+// the plugin settings on first use and whenever a chain setting changed.
+func (d *decredPlugin) chain() (chainProvider, error) {
+	d.Lock()
+	defer d.Unlock()
+
+	if d.cp != nil {
+		return d.cp, nil
+	}
+	cp, err := newChainProvider(d.settings)
+	if err != nil {
+		return nil, err
+	}
+	d.cp = cp
+	return cp, nil
+}
+
+// failoverProvider tries its providers in order and moves on to the next
+// provider once a provider failed retries+1 times.
+type failoverProvider struct {
+	providers  []chainProvider
+	retries    int
+	retryDelay time.Duration
+}
+
+// do calls f with every provider until it succeeds.
+func (f *failoverProvider) do(call string, fn func(chainProvider) error) error {
+	var errs []string
+	for _, cp := range f.providers {
+		for i := 0; i <= f.retries; i++ {
+			if i != 0 {
+				time.Sleep(f.retryDelay)
+			}
+			err := fn(cp)
+			if err == nil {
+				return nil
+			}
+			log.Debugf("%v %v attempt %v: %v", cp.Name(), call, i+1,
+				err)
+			errs = append(errs, fmt.Sprintf("%v: %v", cp.Name(), err))
+			if err == errChainUnsupported {
+				break
+			}
+		}
+	}
+	return fmt.Errorf("%v failed: %v", call, strings.Join(errs, "; "))
+}
+
+func (f *failoverProvider) Name() string {
+	names := make([]string, 0, len(f.providers))
+	for _, cp := range f.providers {
+		names = append(names, cp.Name())
+	}
+	return strings.Join(names, ",")
+}
+
+func (f *failoverProvider) BestBlock() (*dcrdataapi.BlockDataBasic, error) {
+	var bdb *dcrdataapi.BlockDataBasic
+	err := f.do("BestBlock", func(cp chainProvider) error {
+		var err error
+		bdb, err = cp.BestBlock()
+		return err
+	})
+	return bdb, err
+}
+
+func (f *failoverProvider) Block(height uint32) (*dcrdataapi.BlockDataBasic, error) {
+	var bdb *dcrdataapi.BlockDataBasic
+	err := f.do("Block", func(cp chainProvider) error {
+		var err error
+		bdb, err = cp.Block(height)
+		return err
+	})
+	return bdb, err
+}
+
+func (f *failoverProvider) Snapshot(hash string) ([]string, error) {
+	var tickets []string
+	err := f.do("Snapshot", func(cp chainProvider) error {
+		var err error
+		tickets, err = cp.Snapshot(hash)
+		return err
+	})
+	return tickets, err
+}
+
+func (f *failoverProvider) Transactions(hashes []string) ([]dcrdataapi.TrimmedTx, error) {
+	var ttx []dcrdataapi.TrimmedTx
+	err := f.do("Transactions", func(cp chainProvider) error {
+		var err error
+		ttx, err = cp.Transactions(hashes)
+		return err
+	})
+	return ttx, err
+}
+
+// dcrdataProvider retrieves chain data from the dcrdata HTTP API.
+type dcrdataProvider struct {
+	host   string
+	client *http.Client
+}
+
+func newDcrdataProvider(host string, timeout time.Duration) *dcrdataProvider {
+	if !strings.HasSuffix(host, "/") {
+		host += "/"
+	}
+	return &dcrdataProvider{
+		host: host,
+		client: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+// decode decodes the reply of a dcrdata request.
+func (d *dcrdataProvider) decode(r *http.Response, v interface{}) error {
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		return fmt.Errorf("%v: %v", r.Request.URL, r.Status)
+	}
+	decoder := json.NewDecoder(r.Body)
+	return decoder.Decode(v)
+}
+
+func (d *dcrdataProvider) get(path string, v interface{}) error {
+	url := d.host + path
+	log.Debugf("connecting to %v", url)
+	r, err := d.client.Get(url)
+	if err != nil {
+		return err
+	}
+	return d.decode(r, v)
+}
+
+func (d *dcrdataProvider) Name() string {
+	return chainProviderDcrdata
+}
+
+func (d *dcrdataProvider) BestBlock() (*dcrdataapi.BlockDataBasic, error) {
+	var bdb dcrdataapi.BlockDataBasic
+	err := d.get("api/block/best", &bdb)
+	if err != nil {
+		return nil, err
+	}
+	return &bdb, nil
+}
+
+func (d *dcrdataProvider) Block(height uint32) (*dcrdataapi.BlockDataBasic, error) {
+	var bdb dcrdataapi.BlockDataBasic
+	h := strconv.FormatUint(uint64(height), 10)
+	err := d.get("api/block/"+h, &bdb)
+	if err != nil {
+		return nil, err
+	}
+	return &bdb, nil
+}
+
+func (d *dcrdataProvider) Snapshot(hash string) ([]string, error) {
+	var tickets []string
+	err := d.get("api/stake/pool/b/"+hash+"/full?sort=true", &tickets)
+	if err != nil {
+		return nil, err
+	}
+	return tickets, nil
+}
+
+func (d *dcrdataProvider) Transactions(hashes []string) ([]dcrdataapi.TrimmedTx, error) {
+	// Request body is dcrdataapi.Txns marshalled to JSON
+	reqBody, err := json.Marshal(dcrdataapi.Txns{
+		Transactions: hashes,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	url := d.host + "api/txs/trimmed"
+	log.Debugf("connecting to %v", url)
+	r, err := d.client.Post(url, "application/json; charset=utf-8",
+		bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	var ttx []dcrdataapi.TrimmedTx
+	err = d.decode(r, &ttx)
+	if err != nil {
+		return nil, err
+	}
+	if len(ttx) != len(hashes) {
+		return nil, fmt.Errorf("unexpected number of transactions: "+
+			"got %v wanted %v", len(ttx), len(hashes))
+	}
+	return ttx, nil
+}
+
+// dcrdProvider retrieves chain data from a dcrd node using JSON-RPC.  dcrd
+// can't provide ticket pool snapshots of past blocks so Snapshot is not
+// supported and dcrd can't be the only chain provider.
+type dcrdProvider struct {
+	client *util.DcrdClient
+}
+
+func newDcrdProvider(host, user, pass, cert string, timeout time.Duration) (*dcrdProvider, error) {
+	client, err := util.NewDcrdClient(host, user, pass, cert, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &dcrdProvider{
+		client: client,
+	}, nil
+}
+
+// dcrdTx contains the fields of a verbose dcrd getrawtransaction reply that
+// are used by the decred plugin.
+type dcrdTx struct {
+	TxID     string            `json:"txid"`
+	Version  int32             `json:"version"`
+	LockTime uint32            `json:"locktime"`
+	Expiry   uint32            `json:"expiry"`
+	Vout     []dcrdataapi.Vout `json:"vout"`
+}
+
+func (d *dcrdProvider) Name() string {
+	return chainProviderDcrd
+}
+
+func (d *dcrdProvider) BestBlock() (*dcrdataapi.BlockDataBasic, error) {
+	var bb struct {
+		Hash   string `json:"hash"`
+		Height uint32 `json:"height"`
+	}
+	err := d.client.Call("getbestblock", &bb)
+	if err != nil {
+		return nil, err
+	}
+	return &dcrdataapi.BlockDataBasic{
+		Height: bb.Height,
+		Hash:   bb.Hash,
+	}, nil
+}
+
+func (d *dcrdProvider) Block(height uint32) (*dcrdataapi.BlockDataBasic, error) {
+	var hash string
+	err := d.client.Call("getblockhash", &hash, height)
+	if err != nil {
+		return nil, err
+	}
+	var bh struct {
+		Time int64 `json:"time"`
+	}
+	err = d.client.Call("getblockheader", &bh, hash, true)
+	if err != nil {
+		return nil, err
+	}
+	return &dcrdataapi.BlockDataBasic{
+		Height: height,
+		Hash:   hash,
+		Time:   bh.Time,
+	}, nil
+}
+
+func (d *dcrdProvider) Snapshot(hash string) ([]string, error) {
+	return nil, errChainUnsupported
+}
+
+func (d *dcrdProvider) Transactions(hashes []string) ([]dcrdataapi.TrimmedTx, error) {
+	ttx := make([]dcrdataapi.TrimmedTx, 0, len(hashes))
+	for _, h := range hashes {
+		var tx dcrdTx
+		err := d.client.Call("getrawtransaction", &tx, h, 1)
+		if err != nil {
+			return nil, err
+		}
+		ttx = append(ttx, dcrdataapi.TrimmedTx{
+			TxID:     tx.TxID,
+			Version:  tx.Version,
+			Locktime: tx.LockTime,
+			Expiry:   tx.Expiry,
+			Vout:     tx.Vout,
+		})
+	}
+	return ttx, nil
+}
+
+// chainFixture is the on-disk format of the fixture chain provider.
+type chainFixture struct {
+	Blocks       []dcrdataapi.BlockDataBasic `json:"blocks"`       // Blocks, the last one is the best block
+	Snapshots    map[string][]string         `json:"snapshots"`    // [blockhash]tickets
+	Transactions []dcrdataapi.TrimmedTx      `json:"transactions"` // Transactions
+}
+
+// fixtureProvider is a deterministic chain provider that serves the data of
+// a chainFixture.  It is intended for testing.
+type fixtureProvider struct {
+	fixture chainFixture
+}
+
+func loadFixtureProvider(filename string) (*fixtureProvider, error) {
+	if filename == "" {
+		return nil, fmt.Errorf("chain fixture not set")
+	}
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("chain fixture: %v", err)
+	}
+	var f chainFixture
+	err = json.Unmarshal(b, &f)
+	if err != nil {
+		return nil, fmt.Errorf("chain fixture: %v", err)
+	}
+	return &fixtureProvider{fixture: f}, nil
+}
+
+func (f *fixtureProvider) Name() string {
+	return chainProviderFixture
+}
+
+func (f *fixtureProvider) BestBlock() (*dcrdataapi.BlockDataBasic, error) {
+	if len(f.fixture.Blocks) == 0 {
+		return nil, fmt.Errorf("no blocks")
+	}
+	bdb := f.fixture.Blocks[len(f.fixture.Blocks)-1]
+	return &bdb, nil
+}
+
+func (f *fixtureProvider) Block(height uint32) (*dcrdataapi.BlockDataBasic, error) {
+	for _, v := range f.fixture.Blocks {
+		if v.Height == height {
+			bdb := v
+			return &bdb, nil
+		}
+	}
+	return nil, fmt.Errorf("block not found: %v", height)
+}
+
+func (f *fixtureProvider) Snapshot(hash string) ([]string, error) {
+	tickets, ok := f.fixture.Snapshots[hash]
+	if !ok {
+		return nil, fmt.Errorf("snapshot not found: %v", hash)
+	}
+	return tickets, nil
+}
+
+func (f *fixtureProvider) Transactions(hashes []string) ([]dcrdataapi.TrimmedTx, error) {
+	ttx := make([]dcrdataapi.TrimmedTx, 0, len(hashes))
+	for _, h := range hashes {
+		found := false
+		for _, v := range f.fixture.Transactions {
+			if v.TxID == h {
+				ttx = append(ttx, v)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("transaction not found: %v", h)
+		}
+	}
+	return ttx, nil
+}
@@ -7,25 +7,28 @@ package gitbe
 import (
 	"bufio"
 	"bytes"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/decred/dcrd/chaincfg/chainec"
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/dcrutil"
 	"github.com/decred/dcrd/wire"
-	dcrdataapi "github.com/decred/dcrdata/api/types"
+	"github.com/decred/dcrtime/merkle"
 	"github.com/decred/politeia/decredplugin"
+	pd "github.com/decred/politeia/politeiad/api/v1"
 	"github.com/decred/politeia/politeiad/api/v1/identity"
 	"github.com/decred/politeia/politeiad/backend"
 	"github.com/decred/politeia/util"
@@ -37,6 +40,17 @@ const (
 	decredPluginIdentity = "fullidentity"
 	decredPluginJournals = "journals"
 
+	// Chain provider settings
+	decredPluginChainProviders = "chainproviders" // Providers in failover order
+	decredPluginDcrdata        = "dcrdata"        // dcrdata URL
+	decredPluginDcrdHost       = "dcrdhost"       // dcrd JSON-RPC host
+	decredPluginDcrdUser       = "dcrduser"       // dcrd JSON-RPC user
+	decredPluginDcrdPass       = "dcrdpass"       // dcrd JSON-RPC password
+	decredPluginDcrdCert       = "dcrdcert"       // dcrd JSON-RPC certificate
+	decredPluginChainFixture   = "chainfixture"   // Fixture filename
+	decredPluginChainTimeout   = "chaintimeout"   // Request timeout
+	decredPluginChainRetries   = "chainretries"   // Retries per provider
+
 	defaultCommentIDFilename = "commentid.txt"
 	defaultCommentFilename   = "comments.journal"
 	defaultCommentsFlushed   = "comments.flushed"
@@ -51,9 +65,6 @@ const (
 
 	flushRecordVersion = "1" // Version 1 of the flush journal
 
-	// Following are what should be well-known interface hooks
-	PluginPostHookEdit = "postedit" // Hook Post Edit
-
 	// Authorize vote actions
 	AuthVoteActionAuthorize = "authorize" // Authorize a proposal vote
 	AuthVoteActionRevoke    = "revoke"    // Revoke a proposal vote authorization
@@ -104,9 +115,6 @@ func decodeCastVoteJournal(payload []byte) (*CastVoteJournal, error) {
 }
 
 var (
-	decredPluginSettings map[string]string             // [key]setting
-	decredPluginHooks    map[string]func(string) error // [key]func(token) error
-
 	// cached values, requires lock
 	// XXX why is this a pointer? Convert if possible after investigating
 	decredPluginVoteCache = make(map[string]*decredplugin.StartVote) // [token]startvote
@@ -126,6 +134,8 @@ var (
 	decredPluginCommentsCache      = make(map[string]map[string]decredplugin.Comment) // [token][commentid]comment
 	decredPluginCommentsLikesCache = make(map[string][]decredplugin.LikeComment)      // [token]LikeComment
 
+	decredPluginCommentsCensoredCache = make(map[string][]decredplugin.CensorComment) // [token]CensorComment
+
 	journalsReplayed bool = false
 )
 
@@ -156,37 +166,183 @@ func init() {
 	}
 }
 
-func getDecredPlugin(testnet bool) backend.Plugin {
-	decredPlugin := backend.Plugin{
-		ID:       decredplugin.ID,
-		Version:  decredplugin.Version,
-		Settings: []backend.PluginSetting{},
-	}
+// decredPlugin is the decred plugin.  It implements the Plugin interface on
+// top of the git backend.  Its settings are protected by its own mutex since
+// they are read and written without the backend lock held.
+type decredPlugin struct {
+	sync.RWMutex
+	g        *gitBackEnd
+	settings map[string]string // [key]setting
+	cp       chainProvider     // Built from the settings on first use
+}
 
+// newDecredPlugin returns the decred plugin and initializes its settings.
+func newDecredPlugin(g *gitBackEnd, testnet bool) *decredPlugin {
+	d := &decredPlugin{
+		g:        g,
+		settings: make(map[string]string),
+	}
 	if testnet {
-		decredPlugin.Settings = append(decredPlugin.Settings,
-			backend.PluginSetting{
-				Key:   "dcrdata",
-				Value: "https://testnet.dcrdata.org:443/",
-			},
-		)
+		d.settings[decredPluginDcrdata] = "https://testnet.dcrdata.org:443/"
 	} else {
-		decredPlugin.Settings = append(decredPlugin.Settings,
-			backend.PluginSetting{
-				Key:   "dcrdata",
-				Value: "https://explorer.dcrdata.org:443/",
-			})
+		d.settings[decredPluginDcrdata] = "https://explorer.dcrdata.org:443/"
+	}
+	return d
+}
+
+// ID returns the plugin identifier.
+//
+// ID satisfies the Plugin interface.
+func (d *decredPlugin) ID() string {
+	return decredplugin.ID
+}
+
+// Version returns the plugin version.
+//
+// Version satisfies the Plugin interface.
+func (d *decredPlugin) Version() string {
+	return decredplugin.Version
+}
+
+// Settings returns the public decred plugin settings sorted by key.  The
+// identity, journals directory and dcrd password are not reported.
+//
+// Settings satisfies the Plugin interface.
+func (d *decredPlugin) Settings() []backend.PluginSetting {
+	d.RLock()
+	defer d.RUnlock()
+
+	settings := make([]backend.PluginSetting, 0, len(d.settings))
+	for k, v := range d.settings {
+		switch k {
+		case decredPluginIdentity, decredPluginJournals,
+			decredPluginDcrdPass:
+			continue
+		}
+		settings = append(settings, backend.PluginSetting{
+			Key:   k,
+			Value: v,
+		})
+	}
+	sort.Slice(settings, func(i, j int) bool {
+		return settings[i].Key < settings[j].Key
+	})
+	return settings
+}
+
+// SetSetting validates and applies a decred plugin setting.
+//
+// SetSetting satisfies the Plugin interface.
+func (d *decredPlugin) SetSetting(key, value string) error {
+	err := validateChainSetting(key, value)
+	if err != nil {
+		return err
 	}
+	d.setSetting(key, value)
+	return nil
+}
 
-	// Initialize hooks
-	decredPluginHooks = make(map[string]func(string) error)
+// Commands returns the decred plugin commands.
+//
+// Commands satisfies the Plugin interface.
+func (d *decredPlugin) Commands() map[string]PluginCommand {
+	g := d.g
+	public := []string{pd.PluginPermissionPublic}
+	user := []string{pd.PluginPermissionUser}
+	admin := []string{pd.PluginPermissionAdmin}
+	return map[string]PluginCommand{
+		decredplugin.CmdAuthorizeVote: {
+			Handler:     g.pluginAuthorizeVote,
+			Request:     decredplugin.AuthorizeVote{},
+			Reply:       decredplugin.AuthorizeVoteReply{},
+			Permissions: user,
+		},
+		decredplugin.CmdStartVote: {
+			Handler:     g.pluginStartVote,
+			Request:     decredplugin.StartVote{},
+			Reply:       decredplugin.StartVoteReply{},
+			Permissions: admin,
+		},
+		decredplugin.CmdBallot: {
+			Handler:     g.pluginBallot,
+			Request:     decredplugin.Ballot{},
+			Reply:       decredplugin.BallotReply{},
+			Permissions: public,
+		},
+		decredplugin.CmdProposalVotes: {
+			Handler:     g.pluginProposalVotes,
+			Request:     decredplugin.VoteResults{},
+			Reply:       decredplugin.VoteResultsReply{},
+			Permissions: public,
+		},
+		decredplugin.CmdBestBlock: {
+			Handler: func(string) (string, error) {
+				return g.pluginBestBlock()
+			},
+			Reply:       uint32(0),
+			Permissions: public,
+		},
+		decredplugin.CmdNewComment: {
+			Handler:     g.pluginNewComment,
+			Request:     decredplugin.NewComment{},
+			Reply:       decredplugin.NewCommentReply{},
+			Permissions: user,
+		},
+		decredplugin.CmdLikeComment: {
+			Handler:     g.pluginLikeComment,
+			Request:     decredplugin.LikeComment{},
+			Reply:       decredplugin.LikeCommentReply{},
+			Permissions: user,
+		},
+		decredplugin.CmdCensorComment: {
+			Handler:     g.pluginCensorComment,
+			Request:     decredplugin.CensorComment{},
+			Reply:       decredplugin.CensorCommentReply{},
+			Permissions: admin,
+		},
+		decredplugin.CmdGetComments: {
+			Handler:     g.pluginGetComments,
+			Request:     decredplugin.GetComments{},
+			Reply:       decredplugin.GetCommentsReply{},
+			Permissions: public,
+		},
+		decredplugin.CmdProposalCommentsLikes: {
+			Handler:     g.pluginGetProposalCommentsLikes,
+			Request:     decredplugin.GetProposalCommentsLikes{},
+			Reply:       decredplugin.GetProposalCommentsLikesReply{},
+			Permissions: public,
+		},
+		decredplugin.CmdCensoredComments: {
+			Handler:     g.pluginGetCensoredComments,
+			Request:     decredplugin.GetCensoredComments{},
+			Reply:       decredplugin.GetCensoredCommentsReply{},
+			Permissions: public,
+		},
+		decredplugin.CmdVoteInclusionProof: {
+			Handler:     g.pluginVoteInclusionProof,
+			Request:     decredplugin.VoteInclusionProof{},
+			Reply:       decredplugin.VoteInclusionProofReply{},
+			Permissions: public,
+		},
+		decredplugin.CmdBlockTimes: {
+			Handler:     g.pluginBlockTimes,
+			Request:     decredplugin.BlockTimes{},
+			Reply:       decredplugin.BlockTimesReply{},
+			Permissions: public,
+		},
+	}
+}
 
-	// Initialize settings map
-	decredPluginSettings = make(map[string]string)
-	for _, v := range decredPlugin.Settings {
-		decredPluginSettings[v.Key] = v.Value
+// Hooks returns the backend hooks of the decred plugin.
+//
+// Hooks satisfies the Plugin interface.
+func (d *decredPlugin) Hooks() map[string]PluginHookFunc {
+	return map[string]PluginHookFunc{
+		PluginPreHookEditVetted: d.g.decredPluginPreEditVetted,
+		PluginPostHookEdit: func(args PluginHookArgs) error {
+			return d.g.decredPluginPostEdit(args.Token)
+		},
 	}
-	return decredPlugin
 }
 
 // initDecredPlugin is called externally to run initial procedures
@@ -231,18 +387,32 @@ func (g *gitBackEnd) replayAllJournals() error {
 	return nil
 }
 
-//SetDecredPluginSetting removes a setting if the value is "" and adds a setting otherwise.
-func setDecredPluginSetting(key, value string) {
+// setSetting removes a setting if the value is "" and adds a setting
+// otherwise.  The chain provider is recreated on next use when a chain
+// setting changes.
+func (d *decredPlugin) setSetting(key, value string) {
+	d.Lock()
+	defer d.Unlock()
+
+	if isChainSetting(key) {
+		d.cp = nil
+	}
+
 	if value == "" {
-		delete(decredPluginSettings, key)
+		delete(d.settings, key)
 		return
 	}
 
-	decredPluginSettings[key] = value
+	d.settings[key] = value
 }
 
-func setDecredPluginHook(name string, f func(string) error) {
-	decredPluginHooks[name] = f
+// setting returns the value of the provided setting and whether it is set.
+func (d *decredPlugin) setting(key string) (string, bool) {
+	d.RLock()
+	defer d.RUnlock()
+
+	v, ok := d.settings[key]
+	return v, ok
 }
 
 func (g *gitBackEnd) propExists(repo, token string) bool {
@@ -371,105 +541,19 @@ func (g *gitBackEnd) verifyMessage(address, message, signature string) (bool, er
 	return a.EncodeAddress() == address, nil
 }
 
-func bestBlock() (*dcrdataapi.BlockDataBasic, error) {
-	url := decredPluginSettings["dcrdata"] + "api/block/best"
-	log.Debugf("connecting to %v", url)
-	// XXX this http command needs a reasonable timeout.
-	r, err := http.Get(url)
-	log.Debugf("http connecting to %v", url)
-	if err != nil {
-		return nil, err
-	}
-	defer r.Body.Close()
-
-	var bdb dcrdataapi.BlockDataBasic
-	decoder := json.NewDecoder(r.Body)
-	if err := decoder.Decode(&bdb); err != nil {
-		return nil, err
-	}
-
-	return &bdb, nil
-}
-
-func block(block uint32) (*dcrdataapi.BlockDataBasic, error) {
-	h := strconv.FormatUint(uint64(block), 10)
-	url := decredPluginSettings["dcrdata"] + "api/block/" + h
-	log.Debugf("connecting to %v", url)
-	r, err := http.Get(url)
-	if err != nil {
-		return nil, err
-	}
-	defer r.Body.Close()
-
-	var bdb dcrdataapi.BlockDataBasic
-	decoder := json.NewDecoder(r.Body)
-	if err := decoder.Decode(&bdb); err != nil {
-		return nil, err
-	}
-
-	return &bdb, nil
-}
-
-func snapshot(hash string) ([]string, error) {
-	url := decredPluginSettings["dcrdata"] + "api/stake/pool/b/" + hash +
-		"/full?sort=true"
-	log.Debugf("connecting to %v", url)
-	r, err := http.Get(url)
-	if err != nil {
-		return nil, err
-	}
-	defer r.Body.Close()
-
-	var tickets []string
-	decoder := json.NewDecoder(r.Body)
-	if err := decoder.Decode(&tickets); err != nil {
-		return nil, err
-	}
-
-	return tickets, nil
-}
-
-func batchTransactions(hashes []string) ([]dcrdataapi.TrimmedTx, error) {
-	// Request body is dcrdataapi.Txns marshalled to JSON
-	reqBody, err := json.Marshal(dcrdataapi.Txns{
-		Transactions: hashes,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	// Make the POST request
-	url := decredPluginSettings["dcrdata"] + "api/txs/trimmed"
-	log.Debugf("connecting to %v", url)
-	r, err := http.Post(url, "application/json; charset=utf-8",
-		bytes.NewReader(reqBody))
-	if err != nil {
-		return nil, err
-	}
-	defer r.Body.Close()
-
-	if r.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("POST request failed: %d", r.StatusCode)
-	}
-
-	// Unmarshal the resonse
-	var ttx []dcrdataapi.TrimmedTx
-	decoder := json.NewDecoder(r.Body)
-	if err := decoder.Decode(&ttx); err != nil {
-		return nil, err
-	}
-	return ttx, nil
-}
-
 // largestCommitmentResult returns the largest commitment address or an error.
 type largestCommitmentResult struct {
 	bestAddr string
 	err      error
 }
 
-func largestCommitmentAddresses(hashes []string) ([]largestCommitmentResult, error) {
-	// Batch request all of the transaction info from dcrdata.
-	ttxs, err := batchTransactions(hashes)
+func (g *gitBackEnd) largestCommitmentAddresses(hashes []string) ([]largestCommitmentResult, error) {
+	// Batch request all of the transaction info from the chain.
+	cp, err := g.decred.chain()
+	if err != nil {
+		return nil, err
+	}
+	ttxs, err := cp.Transactions(hashes)
 	if err != nil {
 		return nil, err
 	}
@@ -509,13 +593,69 @@ func largestCommitmentAddresses(hashes []string) ([]largestCommitmentResult, err
 
 // pluginBestBlock returns current best block height from wallet.
 func (g *gitBackEnd) pluginBestBlock() (string, error) {
-	bb, err := bestBlock()
+	cp, err := g.decred.chain()
+	if err != nil {
+		return "", err
+	}
+	bb, err := cp.BestBlock()
 	if err != nil {
 		return "", err
 	}
 	return strconv.FormatUint(uint64(bb.Height), 10), nil
 }
 
+// pluginBlockTimes returns the timestamps of the requested blocks as recorded
+// by the chain provider.
+func (g *gitBackEnd) pluginBlockTimes(payload string) (string, error) {
+	log.Tracef("pluginBlockTimes: %v", payload)
+
+	bt, err := decredplugin.DecodeBlockTimes([]byte(payload))
+	if err != nil {
+		return "", fmt.Errorf("DecodeBlockTimes %v", err)
+	}
+
+	cp, err := g.decred.chain()
+	if err != nil {
+		return "", err
+	}
+	reply := decredplugin.BlockTimesReply{
+		Timestamps: make([]int64, 0, len(bt.Heights)),
+	}
+	for _, height := range bt.Heights {
+		bdb, err := cp.Block(height)
+		if err != nil {
+			return "", fmt.Errorf("block %v: %v", height, err)
+		}
+		reply.Timestamps = append(reply.Timestamps, bdb.Time)
+	}
+
+	btr, err := decredplugin.EncodeBlockTimesReply(reply)
+	if err != nil {
+		return "", fmt.Errorf("EncodeBlockTimesReply %v", err)
+	}
+
+	return string(btr), nil
+}
+
+// decredPluginPreEditVetted is called before a vetted record is updated.  It
+// vetoes the update when the proposal vote has started.
+//
+// Must be called WITH the mutex held.
+func (g *gitBackEnd) decredPluginPreEditVetted(args PluginHookArgs) error {
+	log.Tracef("decredPluginPreEditVetted: %v", args.Token)
+
+	_, err := os.Stat(mdFilename(g.vetted, args.Token,
+		decredplugin.MDStreamVoteBits))
+	if err == nil {
+		return backend.PluginVetoError{
+			ErrorCode:    int(decredplugin.ErrorStatusVoteStarted),
+			ErrorContext: []string{args.Token},
+		}
+	}
+
+	return nil
+}
+
 // decredPluginPostEdit called after and edit is complete but before commit.
 func (g *gitBackEnd) decredPluginPostEdit(token string) error {
 	log.Tracef("decredPluginPostEdit: %v", token)
@@ -958,7 +1098,7 @@ func (g *gitBackEnd) decredPluginJournalFlusher() {
 
 func (g *gitBackEnd) pluginNewComment(payload string) (string, error) {
 	// XXX this should become part of some sort of context
-	fiJSON, ok := decredPluginSettings[decredPluginIdentity]
+	fiJSON, ok := g.decred.setting(decredPluginIdentity)
 	if !ok {
 		return "", fmt.Errorf("full identity not set")
 	}
@@ -1076,7 +1216,7 @@ func (g *gitBackEnd) pluginLikeComment(payload string) (string, error) {
 	}
 
 	// XXX this should become part of some sort of context
-	fiJSON, ok := decredPluginSettings[decredPluginIdentity]
+	fiJSON, ok := g.decred.setting(decredPluginIdentity)
 	if !ok {
 		return "", fmt.Errorf("full identity not set")
 	}
@@ -1128,7 +1268,16 @@ func (g *gitBackEnd) pluginLikeComment(payload string) (string, error) {
 	cc := decredPluginCommentsLikesCache[like.Token]
 
 	// Update cache
-	decredPluginCommentsLikesCache[like.Token] = append(cc, *like)
+	lc := decredplugin.LikeComment{
+		Token:     like.Token,
+		CommentID: like.CommentID,
+		Action:    like.Action,
+		Signature: like.Signature,
+		PublicKey: like.PublicKey,
+		Receipt:   receipt,
+		Timestamp: time.Now().Unix(),
+	}
+	decredPluginCommentsLikesCache[like.Token] = append(cc, lc)
 	g.Unlock()
 
 	// We create an unwind function that MUST be called from all error
@@ -1140,15 +1289,6 @@ func (g *gitBackEnd) pluginLikeComment(payload string) (string, error) {
 	}
 
 	// Create Journal entry
-	lc := decredplugin.LikeComment{
-		Token:     like.Token,
-		CommentID: like.CommentID,
-		Action:    like.Action,
-		Signature: like.Signature,
-		PublicKey: like.PublicKey,
-		Receipt:   receipt,
-		Timestamp: time.Now().Unix(),
-	}
 	blob, err := decredplugin.EncodeLikeComment(lc)
 	if err != nil {
 		unwind()
@@ -1190,7 +1330,7 @@ func (g *gitBackEnd) pluginCensorComment(payload string) (string, error) {
 	}
 
 	// XXX this should become part of some sort of context
-	fiJSON, ok := decredPluginSettings[decredPluginIdentity]
+	fiJSON, ok := g.decred.setting(decredPluginIdentity)
 	if !ok {
 		return "", fmt.Errorf("full identity not set")
 	}
@@ -1251,6 +1391,19 @@ func (g *gitBackEnd) pluginCensorComment(payload string) (string, error) {
 	c.Censored = true
 	decredPluginCommentsCache[censor.Token][censor.CommentID] = c
 
+	// Update censored comments cache
+	cc := decredplugin.CensorComment{
+		Token:     censor.Token,
+		CommentID: censor.CommentID,
+		Reason:    censor.Reason,
+		Signature: censor.Signature,
+		PublicKey: censor.PublicKey,
+		Receipt:   receipt,
+		Timestamp: time.Now().Unix(),
+	}
+	occ := decredPluginCommentsCensoredCache[censor.Token]
+	decredPluginCommentsCensoredCache[censor.Token] = append(occ, cc)
+
 	g.Unlock()
 
 	// We create an unwind function that MUST be called from all error
@@ -1258,19 +1411,11 @@ func (g *gitBackEnd) pluginCensorComment(payload string) (string, error) {
 	unwind := func() {
 		g.Lock()
 		decredPluginCommentsCache[censor.Token][censor.CommentID] = oc
+		decredPluginCommentsCensoredCache[censor.Token] = occ
 		g.Unlock()
 	}
 
 	// Create Journal entry
-	cc := decredplugin.CensorComment{
-		Token:     censor.Token,
-		CommentID: censor.CommentID,
-		Reason:    censor.Reason,
-		Signature: censor.Signature,
-		PublicKey: censor.PublicKey,
-		Receipt:   receipt,
-		Timestamp: time.Now().Unix(),
-	}
 	blob, err := decredplugin.EncodeCensorComment(cc)
 	if err != nil {
 		unwind()
@@ -1354,6 +1499,7 @@ func (g *gitBackEnd) replayComments(token string) (map[string]decredplugin.Comme
 
 	comments := make(map[string]decredplugin.Comment)
 	commentsLikes := make([]decredplugin.LikeComment, 0, 1024)
+	commentsCensored := make([]decredplugin.CensorComment, 0)
 
 	for {
 		err = g.journal.Replay(cfilename, func(s string) error {
@@ -1406,6 +1552,7 @@ func (g *gitBackEnd) replayComments(token string) (map[string]decredplugin.Comme
 				c.Comment = ""
 				c.Censored = true
 				comments[cc.CommentID] = c
+				commentsCensored = append(commentsCensored, cc)
 
 			case journalActionAddLike:
 				var lc decredplugin.LikeComment
@@ -1433,6 +1580,7 @@ func (g *gitBackEnd) replayComments(token string) (map[string]decredplugin.Comme
 	g.Lock()
 	decredPluginCommentsCache[token] = comments
 	decredPluginCommentsLikesCache[token] = commentsLikes
+	decredPluginCommentsCensoredCache[token] = commentsCensored
 	g.Unlock()
 
 	return comments, nil
@@ -1458,6 +1606,44 @@ func (g *gitBackEnd) pluginGetProposalCommentsLikes(payload string) (string, err
 	return string(egpclr), nil
 }
 
+// pluginGetCensoredComments returns the censor records of all censored
+// comments for a given proposal, or for all proposals if no token is
+// provided.
+func (g *gitBackEnd) pluginGetCensoredComments(payload string) (string, error) {
+	log.Tracef("pluginGetCensoredComments")
+
+	// Check if journals were replayed
+	if !journalsReplayed {
+		return "", backend.ErrJournalsNotReplayed
+	}
+
+	gcc, err := decredplugin.DecodeGetCensoredComments([]byte(payload))
+	if err != nil {
+		return "", fmt.Errorf("DecodeGetCensoredComments: %v", err)
+	}
+
+	var gccr decredplugin.GetCensoredCommentsReply
+	g.Lock()
+	if gcc.Token == "" {
+		for _, v := range decredPluginCommentsCensoredCache {
+			gccr.CensoredComments = append(gccr.CensoredComments,
+				v...)
+		}
+	} else {
+		gccr.CensoredComments = decredPluginCommentsCensoredCache[gcc.Token]
+	}
+	g.Unlock()
+	if gccr.CensoredComments == nil {
+		gccr.CensoredComments = []decredplugin.CensorComment{}
+	}
+
+	egccr, err := decredplugin.EncodeGetCensoredCommentsReply(gccr)
+	if err != nil {
+		return "", fmt.Errorf("EncodeGetCensoredCommentsReply: %v", err)
+	}
+	return string(egccr), nil
+}
+
 func (g *gitBackEnd) pluginGetComments(payload string) (string, error) {
 	log.Tracef("pluginGetComments")
 
@@ -1497,7 +1683,7 @@ func (g *gitBackEnd) pluginAuthorizeVote(payload string) (string, error) {
 
 	// Get identity
 	// XXX this should become part of some sort of context
-	fiJSON, ok := decredPluginSettings[decredPluginIdentity]
+	fiJSON, ok := g.decred.setting(decredPluginIdentity)
 	if !ok {
 		return "", fmt.Errorf("full identity not set")
 	}
@@ -1587,7 +1773,11 @@ func (g *gitBackEnd) pluginStartVote(payload string) (string, error) {
 	}
 
 	// 1. Get best block
-	bb, err := bestBlock()
+	cp, err := g.decred.chain()
+	if err != nil {
+		return "", fmt.Errorf("chain %v", err)
+	}
+	bb, err := cp.BestBlock()
 	if err != nil {
 		return "", fmt.Errorf("bestBlock %v", err)
 	}
@@ -1596,13 +1786,13 @@ func (g *gitBackEnd) pluginStartVote(payload string) (string, error) {
 	}
 	// 2. Subtract TicketMaturity from block height to get into
 	// unforkable teritory
-	snapshotBlock, err := block(bb.Height -
+	snapshotBlock, err := cp.Block(bb.Height -
 		uint32(g.activeNetParams.TicketMaturity))
 	if err != nil {
 		return "", fmt.Errorf("bestBlock %v", err)
 	}
 	// 3. Get ticket pool snapshot
-	snapshot, err := snapshot(snapshotBlock.Hash)
+	snapshot, err := cp.Snapshot(snapshotBlock.Hash)
 	if err != nil {
 		return "", fmt.Errorf("snapshot %v", err)
 	}
@@ -1932,7 +2122,7 @@ func (g *gitBackEnd) pluginBallot(payload string) (string, error) {
 	}
 
 	// XXX this should become part of some sort of context
-	fiJSON, ok := decredPluginSettings[decredPluginIdentity]
+	fiJSON, ok := g.decred.setting(decredPluginIdentity)
 	if !ok {
 		return "", fmt.Errorf("full identity not set")
 	}
@@ -1947,7 +2137,7 @@ func (g *gitBackEnd) pluginBallot(payload string) (string, error) {
 	for _, v := range ballot.Votes {
 		tickets = append(tickets, v.Ticket)
 	}
-	ticketAddresses, err := largestCommitmentAddresses(tickets)
+	ticketAddresses, err := g.largestCommitmentAddresses(tickets)
 	if err != nil {
 		return "", err
 	}
@@ -2211,3 +2401,209 @@ nodata:
 
 	return string(reply), nil
 }
+
+// findCastVote returns the ballot journal entry of the provided ticket.  It
+// returns nil if the ticket did not vote.
+func findCastVote(lines []string, ticket string) (*CastVoteJournal, error) {
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		d := json.NewDecoder(strings.NewReader(line))
+
+		var action JournalAction
+		err := d.Decode(&action)
+		if err != nil {
+			return nil, fmt.Errorf("journal action: %v", err)
+		}
+		if action.Action != journalActionAdd {
+			continue
+		}
+
+		var cvj CastVoteJournal
+		err = d.Decode(&cvj)
+		if err != nil {
+			return nil, fmt.Errorf("journal add: %v", err)
+		}
+		if cvj.CastVote.Ticket == ticket {
+			return &cvj, nil
+		}
+	}
+	return nil, nil
+}
+
+// ballotCommits returns the path of the ballot journal of the provided
+// proposal and the commits in the vetted repo that changed it, oldest first.
+//
+// Function must be called WITH the lock held.
+func (g *gitBackEnd) ballotCommits(token string) (string, []string, error) {
+	version, err := getLatest(pijoin(g.vetted, token))
+	if err != nil {
+		return "", nil, err
+	}
+	ballot := pijoin(token, version, pluginDataDir, defaultBallotFilename)
+
+	// git log --reverse --pretty=format:%H master -- ballot
+	commits, err := g.git(g.vetted, "log", "--reverse", "--pretty=format:%H",
+		"master", "--", ballot)
+	if err != nil {
+		return "", nil, err
+	}
+	return ballot, commits, nil
+}
+
+// voteCommit returns the first of the provided commits whose ballot journal
+// contains the cast vote of the provided ticket.  It returns an empty string
+// if the vote has not been flushed yet.
+//
+// Commits are immutable so this function must be called WITHOUT the lock
+// held.
+func (g *gitBackEnd) voteCommit(ballot string, commits []string, ticket string) (string, error) {
+	for _, commit := range commits {
+		// git show commit:ballot
+		lines, err := g.git(g.vetted, "show", commit+":"+ballot)
+		if err != nil {
+			return "", err
+		}
+		cvj, err := findCastVote(lines, ticket)
+		if err != nil {
+			return "", err
+		}
+		if cvj != nil {
+			return commit, nil
+		}
+	}
+
+	return "", nil
+}
+
+// pluginVoteInclusionProof returns the proof that a cast vote made it into
+// the vetted repository and that the commit that added it was anchored in
+// dcrtime.
+func (g *gitBackEnd) pluginVoteInclusionProof(payload string) (string, error) {
+	log.Tracef("pluginVoteInclusionProof: %v", payload)
+
+	vip, err := decredplugin.DecodeVoteInclusionProof([]byte(payload))
+	if err != nil {
+		return "", fmt.Errorf("DecodeVoteInclusionProof %v", err)
+	}
+
+	// Verify proposal exists, we can run this lockless
+	if !g.propExists(g.vetted, vip.Token) {
+		return "", fmt.Errorf("proposal not found: %v", vip.Token)
+	}
+
+	// Lookup the vote in the ballot journal
+	var (
+		reply   decredplugin.VoteInclusionProofReply
+		ballot  string
+		commits []string
+	)
+	b, err := ioutil.ReadFile(pijoin(g.journals, vip.Token,
+		defaultBallotFilename))
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	cvj, err := findCastVote(strings.Split(strings.TrimSpace(string(b)),
+		"\n"), vip.Ticket)
+	if err != nil {
+		return "", err
+	}
+	if cvj == nil {
+		goto done
+	}
+	reply.CastVote = cvj.CastVote
+	reply.Receipt = cvj.Receipt
+
+	// Lookup the commits that changed the ballot
+	err = func() error {
+		g.Lock()
+		defer g.Unlock()
+
+		if g.shutdown {
+			return backend.ErrShutdown
+		}
+
+		ballot, commits, err = g.ballotCommits(vip.Token)
+		return err
+	}()
+	if err != nil {
+		return "", err
+	}
+
+	// Find the commit that added the vote outside of the lock since this
+	// runs git show for every commit.
+	reply.Commit, err = g.voteCommit(ballot, commits, vip.Ticket)
+	if err != nil {
+		return "", err
+	}
+	if reply.Commit == "" {
+		goto done
+	}
+
+	// Lookup the anchor that contains the commit
+	err = func() error {
+		g.Lock()
+		defer g.Unlock()
+
+		if g.shutdown {
+			return backend.ErrShutdown
+		}
+
+		digest, err := extendSHA1FromString(reply.Commit)
+		if err != nil {
+			return err
+		}
+		d, err := hex.DecodeString(digest)
+		if err != nil {
+			return err
+		}
+		anchor, key, err := g.readAnchorRecordByDigest(d)
+		if err != nil || anchor == nil {
+			return err
+		}
+
+		// Anchor.Digests are merkled in sorted order to get to the
+		// anchor key.
+		digests := make([]*[sha256.Size]byte, 0, len(anchor.Digests))
+		for _, v := range anchor.Digests {
+			var h [sha256.Size]byte
+			copy(h[:], v)
+			digests = append(digests, &h)
+		}
+		root := merkle.Root(digests)
+		if !bytes.Equal(root[:], key) {
+			return fmt.Errorf("invalid anchor merkle root %x", key)
+		}
+		var leaf [sha256.Size]byte
+		copy(leaf[:], d)
+		reply.AnchorMerkle = hex.EncodeToString(key)
+		reply.MerklePath = *merkle.AuthPath(digests, &leaf)
+
+		return nil
+	}()
+	if err != nil {
+		return "", err
+	}
+
+	// Ask dcrtime about the anchor outside of the lock
+	if reply.AnchorMerkle != "" {
+		vr, err := util.Verify(g.dcrtimeHost,
+			[]string{reply.AnchorMerkle})
+		if err != nil {
+			return "", fmt.Errorf("Verify: %v", err)
+		}
+		if len(vr.Digests) != 1 {
+			return "", fmt.Errorf("invalid dcrtime reply")
+		}
+		reply.VerifyDigest = vr.Digests[0]
+	}
+
+done:
+	vipr, err := decredplugin.EncodeVoteInclusionProofReply(reply)
+	if err != nil {
+		return "", fmt.Errorf("EncodeVoteInclusionProofReply: %v", err)
+	}
+
+	return string(vipr), nil
+}
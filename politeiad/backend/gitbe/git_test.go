@@ -8,6 +8,7 @@ import (
 	"bufio"
 	"bytes"
 	"compress/zlib"
+	"flag"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -19,13 +20,19 @@ import (
 	"github.com/decred/slog"
 )
 
-type testWriter struct {
-	t *testing.T
-}
+// TestMain sets the package logger once for all tests.  The backend
+// goroutines keep logging after the test that started them has returned so
+// the logger must not be swapped by the individual tests.
+func TestMain(m *testing.M) {
+	flag.Parse()
+
+	log := slog.NewBackend(os.Stdout).Logger("TEST")
+	if !testing.Verbose() {
+		log.SetLevel(slog.LevelOff)
+	}
+	UseLogger(log)
 
-func (w *testWriter) Write(p []byte) (int, error) {
-	w.t.Logf("%s", p)
-	return len(p), nil
+	os.Exit(m.Run())
 }
 
 func newGitBackEnd() *gitBackEnd {
@@ -51,8 +58,6 @@ func TestVersion(t *testing.T) {
 }
 
 func TestInit(t *testing.T) {
-	log := slog.NewBackend(&testWriter{t}).Logger("TEST")
-	UseLogger(log)
 	g := newGitBackEnd()
 	defer os.RemoveAll(g.root)
 
@@ -63,8 +68,6 @@ func TestInit(t *testing.T) {
 }
 
 func TestLog(t *testing.T) {
-	log := slog.NewBackend(&testWriter{t}).Logger("TEST")
-	UseLogger(log)
 	g := newGitBackEnd()
 	defer os.RemoveAll(g.root)
 
@@ -81,8 +84,6 @@ func TestLog(t *testing.T) {
 
 func TestFsck(t *testing.T) {
 	// Test git fsck, we build on top of that with a dcrtime fsck
-	log := slog.NewBackend(&testWriter{t}).Logger("TEST")
-	UseLogger(log)
 	g := newGitBackEnd()
 	defer os.RemoveAll(g.root)
 
@@ -75,9 +75,6 @@ const (
 	// Seconds Minutes Hours Days Months DayOfWeek
 	anchorSchedule = "0 58 * * * *" // At 58 minutes every hour
 
-	// expectedTestTX is a fake TX used by unit tests.
-	expectedTestTX = "TESTTX"
-
 	// markerAnchor is used in commit messages to determine
 	// where an anchor has been committed.  This value is
 	// parsed and therefore must be a const.
@@ -125,13 +122,10 @@ type gitBackEnd struct {
 	dcrtimeHost     string           // Dcrtimed directory
 	gitPath         string           // Path to git
 	gitTrace        bool             // Enable git tracing
-	test            bool             // Set during UT
 	exit            chan struct{}    // Close channel
 	checkAnchor     chan struct{}    // Work notification
-	plugins         []backend.Plugin // Plugins
-
-	// The following items are used for testing only
-	testAnchors map[string]bool // [digest]anchored
+	plugins         []Plugin         // Registered plugins
+	decred          *decredPlugin    // Built-in decred plugin
 }
 
 func pijoin(elements ...string) string {
@@ -676,13 +670,6 @@ func (g *gitBackEnd) deltaCommits(path string, lastAnchor []byte) ([]*[sha256.Si
 // TODO: the physical write to dcrtime needs to come out of the lock.
 func (g *gitBackEnd) anchor(digests []*[sha256.Size]byte) error {
 	// Anchor all digests
-	if g.test {
-		// We always append the anchorKey as the last element
-		x := len(digests) - 1
-		g.testAnchors[hex.EncodeToString(digests[x][:])] = false
-		return nil
-	}
-
 	return util.Timestamp(g.dcrtimeHost, digests)
 }
 
@@ -803,12 +790,21 @@ func (g *gitBackEnd) anchorRepo(path string) (*[sha256.Size]byte, error) {
 // anchor verifies if there are new commits in all repos and if that is the
 // case it drops and anchor in dcrtime for each of them.
 func (g *gitBackEnd) anchorAllRepos() error {
+	_, err := g.dropAnchor()
+	return err
+}
+
+// dropAnchor does the work for anchorAllRepos.  It returns the merkle root of
+// the anchor that was dropped or nil if there was nothing to anchor.
+//
+// This function must be called WITHOUT the lock held.
+func (g *gitBackEnd) dropAnchor() (*[sha256.Size]byte, error) {
 	log.Infof("Dropping anchor")
 	// Lock filesystem
 	g.Lock()
 	defer g.Unlock()
 	if g.shutdown {
-		return fmt.Errorf("anchorAllRepos: %v", backend.ErrShutdown)
+		return nil, fmt.Errorf("anchorAllRepos: %v", backend.ErrShutdown)
 	}
 
 	//  Anchor vetted
@@ -817,9 +813,9 @@ func (g *gitBackEnd) anchorAllRepos() error {
 	if err != nil {
 		if err == errNothingToDo {
 			log.Infof("Anchoring %v: nothing to do", g.vetted)
-			return nil
+			return nil, nil
 		}
-		return fmt.Errorf("anchor repo %v: %v", g.vetted, err)
+		return nil, fmt.Errorf("anchor repo %v: %v", g.vetted, err)
 	}
 
 	// Sync vetted to unvetted
@@ -827,12 +823,12 @@ func (g *gitBackEnd) anchorAllRepos() error {
 	// git pull --ff-only --rebase
 	err = g.gitPull(g.unvetted, true)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	log.Infof("Dropping anchor complete: %x", *mr)
 
-	return nil
+	return mr, nil
 }
 
 // periodicAnchorChecker must be run as a go routine.  It sits around and
@@ -968,11 +964,6 @@ func (g *gitBackEnd) afterAnchorVerify(vrs []v1.VerifyDigest) error {
 		if err != nil {
 			return err
 		}
-
-		// Mark test anchors as confirmed by dcrtime
-		if g.test {
-			g.testAnchors[vr.Digest] = true
-		}
 	}
 	if len(vrs) != 0 {
 		// git checkout master unvetted
@@ -1002,36 +993,10 @@ func (g *gitBackEnd) anchorAllReposCronJob() {
 // verifyAnchor asks dcrtime if an anchor has been verified and returns a TX if
 // it has.
 func (g *gitBackEnd) verifyAnchor(digest string) (*v1.VerifyDigest, error) {
-	var (
-		vr  *v1.VerifyReply
-		err error
-	)
-
-	// In test mode we fake success.
-	if g.test {
-		// Fake success
-		vr = &v1.VerifyReply{}
-		anchored, ok := g.testAnchors[digest]
-		if !ok {
-			return nil, fmt.Errorf("test not found")
-		}
-		if anchored {
-			return nil, fmt.Errorf("already anchored")
-		}
-		vr.Digests = append(vr.Digests, v1.VerifyDigest{
-			Digest: digest,
-			Result: v1.ResultOK,
-			ChainInformation: v1.ChainInformation{
-				ChainTimestamp: time.Now().Unix(),
-				Transaction:    expectedTestTX,
-			},
-		})
-	} else {
-		// Call dcrtime
-		vr, err = util.Verify(g.dcrtimeHost, []string{digest})
-		if err != nil {
-			return nil, err
-		}
+	// Call dcrtime
+	vr, err := util.Verify(g.dcrtimeHost, []string{digest})
+	if err != nil {
+		return nil, err
 	}
 
 	// Do some sanity checks
@@ -1196,7 +1161,27 @@ func (g *gitBackEnd) New(metadata []backend.MetadataStream, files []backend.File
 		return nil, err
 	}
 
-	return g.newRecord(token, metadata, fa)
+	// Give plugins a chance to veto the new record
+	args := PluginHookArgs{
+		Token:       hex.EncodeToString(token),
+		Status:      backend.MDStatusInvalid,
+		NewStatus:   backend.MDStatusUnvetted,
+		MDOverwrite: metadata,
+		FilesAdd:    files,
+	}
+	err = g.callPluginHooks(PluginPreHookNewRecord, args)
+	if err != nil {
+		return nil, err
+	}
+
+	rm, err := g.newRecord(token, metadata, fa)
+	if err != nil {
+		return nil, err
+	}
+
+	g.callPluginPostHooks(PluginPostHookNewRecord, args)
+
+	return rm, nil
 }
 
 // updateMetadata appends or overwrites in the unvetted repository.
@@ -1430,13 +1415,17 @@ func (g *gitBackEnd) _updateRecord(commit bool, id string, mdAppend, mdOverwrite
 	}
 
 	// Call plugin hooks
-	f, ok := decredPluginHooks[PluginPostHookEdit]
-	if ok {
-		log.Tracef("Calling hook: %v(%v)", PluginPostHookEdit, id)
-		err = f(id)
-		if err != nil {
-			return err
-		}
+	err = g.callPluginHooks(PluginPostHookEdit, PluginHookArgs{
+		Token:       id,
+		Status:      brm.Status,
+		NewStatus:   ns,
+		MDAppend:    mdAppend,
+		MDOverwrite: mdOverwrite,
+		FilesAdd:    hookFiles(fa),
+		FilesDel:    filesDel,
+	})
+	if err != nil {
+		return err
 	}
 
 	// git add id/recordmetadata.json
@@ -1532,6 +1521,22 @@ func (g *gitBackEnd) updateRecord(token []byte, mdAppend []backend.MetadataStrea
 			return nil, backend.ErrRecordNotFound
 		}
 
+		// Give plugins a chance to veto the update
+		status := g.recordStatus(id)
+		args := PluginHookArgs{
+			Token:       id,
+			Status:      status,
+			NewStatus:   status,
+			MDAppend:    mdAppend,
+			MDOverwrite: mdOverwrite,
+			FilesAdd:    filesAdd,
+			FilesDel:    filesDel,
+		}
+		err = g.callPluginHooks(PluginPreHookEditVetted, args)
+		if err != nil {
+			return nil, err
+		}
+
 		// Make sure there are actually changes before we commence the
 		// revision update
 
@@ -1590,6 +1595,8 @@ func (g *gitBackEnd) updateRecord(token []byte, mdAppend []backend.MetadataStrea
 				return nil, err
 			}
 
+			g.callPluginPostHooks(PluginPostHookEditVetted, args)
+
 			// g.vetted is correct!
 			return g.getRecord(token, "", g.vetted, true)
 		}
@@ -1621,9 +1628,22 @@ func (g *gitBackEnd) updateRecord(token []byte, mdAppend []backend.MetadataStrea
 	// We now are sitting in branch id
 	log.Debugf("updating unvetted %v", id)
 
-	// Do the work, if there is an error we must unwind git.
-	errReturn := g._updateRecord(true, id, mdAppend, mdOverwrite, fa,
-		filesDel)
+	// Give plugins a chance to veto the update.  Do the work, if there is
+	// an error we must unwind git.
+	status := g.recordStatus(id)
+	errReturn := g.callPluginHooks(PluginPreHookEdit, PluginHookArgs{
+		Token:       id,
+		Status:      status,
+		NewStatus:   backend.MDStatusIterationUnvetted,
+		MDAppend:    mdAppend,
+		MDOverwrite: mdOverwrite,
+		FilesAdd:    filesAdd,
+		FilesDel:    filesDel,
+	})
+	if errReturn == nil {
+		errReturn = g._updateRecord(true, id, mdAppend, mdOverwrite,
+			fa, filesDel)
+	}
 	if errReturn == nil {
 		// success
 		return g.getRecord(token, "", g.unvetted, true)
@@ -1781,7 +1801,35 @@ func (g *gitBackEnd) UpdateVettedMetadata(token []byte, mdAppend []backend.Metad
 		return backend.ErrShutdown
 	}
 
-	return g._updateVettedMetadata(token, mdAppend, mdOverwrite)
+	// git checkout master
+	err = g.gitCheckout(g.unvetted, "master")
+	if err != nil {
+		return err
+	}
+
+	// Give plugins a chance to veto the metadata update
+	id := hex.EncodeToString(token)
+	status := g.recordStatus(id)
+	args := PluginHookArgs{
+		Token:       id,
+		Status:      status,
+		NewStatus:   status,
+		MDAppend:    mdAppend,
+		MDOverwrite: mdOverwrite,
+	}
+	err = g.callPluginHooks(PluginPreHookMetadata, args)
+	if err != nil {
+		return err
+	}
+
+	err = g._updateVettedMetadata(token, mdAppend, mdOverwrite)
+	if err != nil {
+		return err
+	}
+
+	g.callPluginPostHooks(PluginPostHookMetadata, args)
+
+	return nil
 }
 
 // getRecordLock is the generic implementation of GetUnvetted/GetVetted.  It
@@ -2004,20 +2052,34 @@ func (g *gitBackEnd) GetVetted(token []byte, version string) (*backend.Record, e
 // setUnvettedStatus takes various parameters to update a record metadata and
 // status.  Note that this function must be wrapped by a function that delivers
 // the call with the unvetted repo sitting in master.  The idea is that if this
-// function fails we can simply unwind it by calling a git stash.
+// function fails we can simply unwind it by calling a git stash.  The plugin
+// hook arguments of the status change are returned for the post hooks.
 // Function must be called with the lock held.
-func (g *gitBackEnd) setUnvettedStatus(token []byte, status backend.MDStatusT, mdAppend, mdOverwrite []backend.MetadataStream) (*backend.Record, error) {
+func (g *gitBackEnd) setUnvettedStatus(token []byte, status backend.MDStatusT, mdAppend, mdOverwrite []backend.MetadataStream) (*backend.Record, *PluginHookArgs, error) {
 	// git checkout id
 	id := hex.EncodeToString(token)
 	err := g.gitCheckout(g.unvetted, id)
 	if err != nil {
-		return nil, backend.ErrRecordNotFound
+		return nil, nil, backend.ErrRecordNotFound
 	}
 
 	// Load record
 	record, err := g._getRecord(id, "", g.unvetted, false)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	// Give plugins a chance to veto the status change
+	args := PluginHookArgs{
+		Token:       id,
+		Status:      record.RecordMetadata.Status,
+		NewStatus:   status,
+		MDAppend:    mdAppend,
+		MDOverwrite: mdOverwrite,
+	}
+	err = g.callPluginHooks(PluginPreHookStatusChange, args)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// We only allow a transition from unvetted to vetted or censored
@@ -2034,25 +2096,25 @@ func (g *gitBackEnd) setUnvettedStatus(token []byte, status backend.MDStatusT, m
 		record.RecordMetadata.Timestamp = time.Now().Unix()
 		err = updateMD(g.unvetted, id, &record.RecordMetadata)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		// Handle metadata
 		err = g.updateMetadata(id, mdAppend, mdOverwrite)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		// Commit brm
 		err = g.commitMD(g.unvetted, id, "published")
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		// Create and rebase PR
 		err = g.rebasePR(id)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 	case (record.RecordMetadata.Status == backend.MDStatusUnvetted ||
@@ -2064,28 +2126,33 @@ func (g *gitBackEnd) setUnvettedStatus(token []byte, status backend.MDStatusT, m
 		record.RecordMetadata.Timestamp = time.Now().Unix()
 		err = updateMD(g.unvetted, id, &record.RecordMetadata)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		// Handle metadata
 		err = g.updateMetadata(id, mdAppend, mdOverwrite)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		// Commit brm
 		err = g.commitMD(g.unvetted, id, "censored")
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	default:
-		return nil, backend.StateTransitionError{
+		return nil, nil, backend.StateTransitionError{
 			From: record.RecordMetadata.Status,
 			To:   status,
 		}
 	}
 
-	return g._getRecord(id, "", g.unvetted, false)
+	record, err = g._getRecord(id, "", g.unvetted, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return record, &args, nil
 }
 
 // SetUnvettedStatus tries to update the status for an unvetted record. It
@@ -2102,7 +2169,8 @@ func (g *gitBackEnd) SetUnvettedStatus(token []byte, status backend.MDStatusT, m
 
 	log.Debugf("setting status %v (%v) -> %x", status,
 		backend.MDStatus[status], token)
-	record, err := g.setUnvettedStatus(token, status, mdAppend, mdOverwrite)
+	record, args, err := g.setUnvettedStatus(token, status, mdAppend,
+		mdOverwrite)
 	if err != nil {
 		// XXX this needs to call the unwind function instead
 		// git stash
@@ -2123,6 +2191,9 @@ func (g *gitBackEnd) SetUnvettedStatus(token []byte, status backend.MDStatusT, m
 		return nil, err
 	}
 
+	// Call plugin hooks
+	g.callPluginPostHooks(PluginPostHookStatusChange, *args)
+
 	return record, nil
 }
 
@@ -2132,18 +2203,21 @@ func (g *gitBackEnd) SetUnvettedStatus(token []byte, status backend.MDStatusT, m
 // that delivers the call with the unvetted repo sitting in master.  The idea
 // is that if this function fails we can simply unwind it.
 //
+// The plugin hook arguments of the status change are returned for the post
+// hooks.
+//
 // setVettedStatus must be called with the lock held.
-func (g *gitBackEnd) _setVettedStatus(token []byte, status backend.MDStatusT, mdAppend, mdOverwrite []backend.MetadataStream) (*backend.Record, error) {
+func (g *gitBackEnd) _setVettedStatus(token []byte, status backend.MDStatusT, mdAppend, mdOverwrite []backend.MetadataStream) (*backend.Record, *PluginHookArgs, error) {
 	// git checkout master
 	err := g.gitCheckout(g.unvetted, "master")
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// git pull --ff-only --rebase
 	err = g.gitPull(g.unvetted, true)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Make sure vetted exists
@@ -2151,34 +2225,47 @@ func (g *gitBackEnd) _setVettedStatus(token []byte, status backend.MDStatusT, md
 	_, err = os.Stat(pijoin(g.unvetted, id))
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, backend.ErrRecordNotFound
+			return nil, nil, backend.ErrRecordNotFound
 		}
 	}
 
 	// Make sure record is not locked.
 	md, err := loadMD(g.unvetted, id, "")
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if md.Status == backend.MDStatusArchived {
-		return nil, backend.ErrRecordArchived
+		return nil, nil, backend.ErrRecordArchived
 	}
 
 	// Load record
 	record, err := g._getRecord(id, "", g.unvetted, false)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// We only allow a transition from vetted to archived
 	if record.RecordMetadata.Status != backend.MDStatusVetted ||
 		status != backend.MDStatusArchived {
-		return nil, backend.StateTransitionError{
+		return nil, nil, backend.StateTransitionError{
 			From: record.RecordMetadata.Status,
 			To:   status,
 		}
 	}
 
+	// Give plugins a chance to veto the status change
+	args := PluginHookArgs{
+		Token:       id,
+		Status:      record.RecordMetadata.Status,
+		NewStatus:   status,
+		MDAppend:    mdAppend,
+		MDOverwrite: mdOverwrite,
+	}
+	err = g.callPluginHooks(PluginPreHookStatusChange, args)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Delete any leftover tmp branch. There shouldn't be one.
 	idTmp := id + "_tmp"
 	_ = g.gitBranchDelete(g.unvetted, idTmp)
@@ -2186,7 +2273,7 @@ func (g *gitBackEnd) _setVettedStatus(token []byte, status backend.MDStatusT, md
 	// Checkout temporary branch
 	err = g.gitNewBranch(g.unvetted, idTmp)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Update MD first
@@ -2195,28 +2282,33 @@ func (g *gitBackEnd) _setVettedStatus(token []byte, status backend.MDStatusT, md
 	record.RecordMetadata.Timestamp = time.Now().Unix()
 	err = updateMD(g.unvetted, id, &record.RecordMetadata)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Handle metadata
 	err = g.updateMetadata(id, mdAppend, mdOverwrite)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Commit changes
 	err = g.commitMD(g.unvetted, id, "archived")
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Create and rebase PR
 	err = g.rebasePR(idTmp)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return g._getRecord(id, "", g.unvetted, false)
+	record, err = g._getRecord(id, "", g.unvetted, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return record, &args, nil
 }
 
 // SetVettedStatus tries to update the status for a vetted record.  It returns
@@ -2233,7 +2325,8 @@ func (g *gitBackEnd) SetVettedStatus(token []byte, status backend.MDStatusT, mdA
 
 	log.Debugf("setting status %v (%v) -> %x", status,
 		backend.MDStatus[status], token)
-	record, err := g._setVettedStatus(token, status, mdAppend, mdOverwrite)
+	record, args, err := g._setVettedStatus(token, status, mdAppend,
+		mdOverwrite)
 	if err != nil {
 		err2 := g.gitUnwind(g.unvetted)
 		if err2 != nil {
@@ -2252,6 +2345,9 @@ func (g *gitBackEnd) SetVettedStatus(token []byte, status backend.MDStatusT, mdA
 		return nil, err
 	}
 
+	// Call plugin hooks
+	g.callPluginPostHooks(PluginPostHookStatusChange, *args)
+
 	return record, nil
 }
 
@@ -2319,12 +2415,166 @@ func (g *gitBackEnd) Inventory(vettedCount, branchCount uint, includeFiles bool)
 	return pr, br, nil
 }
 
-// GetPlugins returns a list of currently supported plugins and their settings.
+// GetPlugins returns a list of the registered plugins and their settings.
 //
 // GetPlugins satisfies the backend interface.
 func (g *gitBackEnd) GetPlugins() ([]backend.Plugin, error) {
 	log.Debugf("GetPlugins")
-	return g.plugins, nil
+
+	g.Lock()
+	defer g.Unlock()
+
+	plugins := make([]backend.Plugin, 0, len(g.plugins))
+	for _, v := range g.plugins {
+		commands, err := pluginCommandDescriptors(v)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", v.ID(), err)
+		}
+		plugins = append(plugins, backend.Plugin{
+			ID:       v.ID(),
+			Version:  v.Version(),
+			Settings: v.Settings(),
+			Commands: commands,
+		})
+	}
+	return plugins, nil
+}
+
+// SetPluginSetting sets a plugin setting.  A setting is removed when its
+// value is empty.  Secret settings are applied but not reported by
+// GetPlugins.
+func (g *gitBackEnd) SetPluginSetting(id, key, value string) error {
+	log.Debugf("SetPluginSetting %v %v", id, key)
+
+	p, err := g.pluginLock(id)
+	if err != nil {
+		return err
+	}
+	return p.SetSetting(key, value)
+}
+
+// AnchorStatus returns the last anchor of every anchored repo, the
+// unconfirmed anchors and up to count anchors of the anchor history.  When
+// verify is set dcrtime is asked for the status of the unconfirmed anchors.
+//
+// AnchorStatus satisfies the backend interface.
+func (g *gitBackEnd) AnchorStatus(count uint, verify bool) (*backend.AnchorStatus, error) {
+	log.Tracef("AnchorStatus: %v %v", count, verify)
+
+	as, err := g.anchorStatus(count)
+	if err != nil {
+		return nil, err
+	}
+	if !verify {
+		return as, nil
+	}
+
+	// Ask dcrtime without the lock held since this may take a while.
+	for _, u := range as.Unconfirmed {
+		av := backend.AnchorVerification{
+			Merkle: u,
+		}
+		vr, err := g.verifyAnchor(u)
+		if err != nil {
+			av.Error = err.Error()
+		} else {
+			av.Transaction = vr.ChainInformation.Transaction
+			av.ChainTimestamp = vr.ChainInformation.ChainTimestamp
+		}
+		as.Verifications = append(as.Verifications, av)
+	}
+
+	return as, nil
+}
+
+// anchorStatus gathers the anchor records for AnchorStatus.
+//
+// This function must be called WITHOUT the lock held.
+func (g *gitBackEnd) anchorStatus(count uint) (*backend.AnchorStatus, error) {
+	// Lock filesystem
+	g.Lock()
+	defer g.Unlock()
+	if g.shutdown {
+		return nil, backend.ErrShutdown
+	}
+
+	err := g.gitCheckout(g.vetted, "master")
+	if err != nil {
+		return nil, err
+	}
+
+	var as backend.AnchorStatus
+
+	// Only the vetted repo is anchored.
+	la, err := g.readLastAnchorRecord()
+	if err != nil {
+		return nil, err
+	}
+	if len(la.Last) != 0 {
+		as.LastAnchors = append(as.LastAnchors, backend.LastAnchorRecord{
+			Repo:   filepath.Base(g.vetted),
+			Last:   hex.EncodeToString(unextendSHA256(la.Last)),
+			Merkle: hex.EncodeToString(la.Merkle),
+			Time:   la.Time,
+		})
+	}
+
+	ua, err := g.readUnconfirmedAnchorRecord()
+	if err != nil {
+		return nil, err
+	}
+	as.Unconfirmed = make([]string, 0, len(ua.Merkles))
+	for _, m := range ua.Merkles {
+		as.Unconfirmed = append(as.Unconfirmed, hex.EncodeToString(m))
+	}
+
+	as.Anchors, err = g.readAnchorHistory(count)
+	if err != nil {
+		return nil, err
+	}
+
+	// Confirmed anchors store the dcrtime chain information in the
+	// anchors directory.
+	for k, v := range as.Anchors {
+		if !v.Confirmed {
+			continue
+		}
+		ci, err := g.git(g.vetted, "show", "master:"+
+			defaultAnchorsDirectory+"/"+v.Merkle)
+		if err != nil {
+			return nil, fmt.Errorf("chain information %v: %v",
+				v.Merkle, err)
+		}
+		var c v1.ChainInformation
+		err = json.Unmarshal([]byte(strings.Join(ci, "\n")), &c)
+		if err != nil {
+			return nil, fmt.Errorf("chain information %v: %v",
+				v.Merkle, err)
+		}
+		as.Anchors[k].Transaction = c.Transaction
+		as.Anchors[k].ChainTimestamp = c.ChainTimestamp
+	}
+
+	return &as, nil
+}
+
+// Anchor drops an anchor for all repos immediately instead of waiting for
+// the anchor cron job.  It returns the merkle root of the anchor or an empty
+// string if there was nothing to anchor.
+//
+// Anchor satisfies the backend interface.
+func (g *gitBackEnd) Anchor() (string, error) {
+	log.Tracef("Anchor")
+
+	mr, err := g.dropAnchor()
+	if err != nil {
+		return "", err
+	}
+	if mr == nil {
+		return "", nil
+	}
+
+	return hex.EncodeToString(mr[:]), nil
 }
 
 // Plugin send a passthrough command. The return values are: incomming command
@@ -2332,41 +2582,19 @@ func (g *gitBackEnd) GetPlugins() ([]backend.Plugin, error) {
 // execute.
 //
 // Plugin satisfies the backend interface.
-func (g *gitBackEnd) Plugin(command, payload string) (string, string, error) {
-	log.Debugf("Plugin: %v", command)
-	switch command {
-	case decredplugin.CmdAuthorizeVote:
-		payload, err := g.pluginAuthorizeVote(payload)
-		return decredplugin.CmdAuthorizeVote, payload, err
-	case decredplugin.CmdStartVote:
-		payload, err := g.pluginStartVote(payload)
-		return decredplugin.CmdStartVote, payload, err
-	case decredplugin.CmdBallot:
-		payload, err := g.pluginBallot(payload)
-		return decredplugin.CmdBallot, payload, err
-	case decredplugin.CmdProposalVotes:
-		payload, err := g.pluginProposalVotes(payload)
-		return decredplugin.CmdProposalVotes, payload, err
-	case decredplugin.CmdBestBlock:
-		payload, err := g.pluginBestBlock()
-		return decredplugin.CmdBestBlock, payload, err
-	case decredplugin.CmdNewComment:
-		payload, err := g.pluginNewComment(payload)
-		return decredplugin.CmdNewComment, payload, err
-	case decredplugin.CmdLikeComment:
-		payload, err := g.pluginLikeComment(payload)
-		return decredplugin.CmdLikeComment, payload, err
-	case decredplugin.CmdCensorComment:
-		payload, err := g.pluginCensorComment(payload)
-		return decredplugin.CmdCensorComment, payload, err
-	case decredplugin.CmdGetComments:
-		payload, err := g.pluginGetComments(payload)
-		return decredplugin.CmdGetComments, payload, err
-	case decredplugin.CmdProposalCommentsLikes:
-		payload, err := g.pluginGetProposalCommentsLikes(payload)
-		return decredplugin.CmdProposalCommentsLikes, payload, err
-	}
-	return "", "", fmt.Errorf("invalid payload command") // XXX this needs to become a type error
+func (g *gitBackEnd) Plugin(id, command, payload string) (string, string, error) {
+	log.Debugf("Plugin: %v %v", id, command)
+
+	p, err := g.pluginLock(id)
+	if err != nil {
+		return "", "", err
+	}
+	c, ok := p.Commands()[command]
+	if !ok {
+		return "", "", fmt.Errorf("invalid payload command") // XXX this needs to become a type error
+	}
+	payload, err = c.Handler(payload)
+	return command, payload, err
 }
 
 // Close shuts down the backend.  It obtains the lock and sets the shutdown
@@ -2526,16 +2754,20 @@ func New(anp *chaincfg.Params, root string, dcrtimeHost string, gitPath string,
 		gitTrace:        gitTrace,
 		exit:            make(chan struct{}),
 		checkAnchor:     make(chan struct{}),
-		testAnchors:     make(map[string]bool),
-		plugins:         []backend.Plugin{getDecredPlugin(anp.Name != "mainnet")},
 	}
+
+	// Register built-in plugins
+	g.decred = newDecredPlugin(g, anp.Name != "mainnet")
 	idJSON, err := id.Marshal()
 	if err != nil {
 		return nil, err
 	}
-	setDecredPluginSetting(decredPluginIdentity, string(idJSON))
-	setDecredPluginSetting(decredPluginJournals, g.journals)
-	setDecredPluginHook(PluginPostHookEdit, g.decredPluginPostEdit)
+	g.decred.setSetting(decredPluginIdentity, string(idJSON))
+	g.decred.setSetting(decredPluginJournals, g.journals)
+	err = g.RegisterPlugin(g.decred)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create jounals path
 	// XXX this needs to move into plugin init
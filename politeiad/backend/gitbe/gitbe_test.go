@@ -10,23 +10,44 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/decred/dcrd/chaincfg"
+	dcrdataapi "github.com/decred/dcrdata/api/types"
+	"github.com/decred/dcrtime/merkle"
+	"github.com/decred/politeia/decredplugin"
+	pd "github.com/decred/politeia/politeiad/api/v1"
 	"github.com/decred/politeia/politeiad/api/v1/mime"
 	"github.com/decred/politeia/politeiad/backend"
 	"github.com/decred/politeia/util"
-	"github.com/decred/slog"
+	"github.com/decred/politeia/util/dcrtimesim"
 )
 
+// newTestDcrtime starts a local dcrtime stand-in.  The caller is responsible
+// for closing it.
+func newTestDcrtime(t *testing.T) (*dcrtimesim.Server, string) {
+	t.Helper()
+
+	sim := dcrtimesim.New(dcrtimesim.DefaultConfirmations)
+	host, err := sim.Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sim, host
+}
+
 func validateMD(got, want *backend.RecordMetadata) error {
 	if got.Iteration != want.Iteration+1 ||
 		got.Status != backend.MDStatusVetted ||
@@ -55,9 +76,6 @@ func TestExtendUnextend(t *testing.T) {
 }
 
 func TestAnchorWithCommits(t *testing.T) {
-	log := slog.NewBackend(&testWriter{t}).Logger("TEST")
-	UseLogger(log)
-
 	dir, err := ioutil.TempDir("", "politeia.test")
 	if err != nil {
 		t.Fatal(err)
@@ -65,12 +83,15 @@ func TestAnchorWithCommits(t *testing.T) {
 	defer os.RemoveAll(dir)
 
 	// Initialize stuff we need
-	g, err := New(&chaincfg.TestNet3Params, dir, "", "", nil,
+	sim, host := newTestDcrtime(t)
+	defer sim.Close()
+
+	g, err := New(&chaincfg.TestNet3Params, dir, host, "", nil,
 		testing.Verbose())
 	if err != nil {
 		t.Fatal(err)
 	}
-	g.test = true
+	defer g.Close()
 
 	// Create 5 unvetted records
 	propCount := 5
@@ -297,6 +318,7 @@ func TestAnchorWithCommits(t *testing.T) {
 	}
 
 	// Complete anchor
+	sim.Confirm()
 	t.Logf("===== COMPLETE ANCHOR PROCESS =====")
 	err = g.anchorChecker()
 	if err != nil {
@@ -379,6 +401,7 @@ func TestAnchorWithCommits(t *testing.T) {
 	}
 
 	// Complete anchor
+	sim.Confirm()
 	t.Logf("===== COMPLETE INTERLEAVED ANCHOR PROCESS =====")
 	err = g.anchorChecker()
 	if err != nil {
@@ -457,3 +480,1050 @@ func TestFilePathVersion(t *testing.T) {
 		t.Fatalf("invalid dir, expected 33, got %v", splitFile)
 	}
 }
+
+func TestVoteInclusionProof(t *testing.T) {
+	dir, err := ioutil.TempDir("", "politeia.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sim, host := newTestDcrtime(t)
+	defer sim.Close()
+
+	g, err := New(&chaincfg.TestNet3Params, dir, host, "", nil,
+		testing.Verbose())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+
+	// Create and vet a record
+	payload := "this is a proposal"
+	rm, err := g.New([]backend.MetadataStream{}, []backend.File{{
+		Name:    "index.md",
+		MIME:    mime.DetectMimeType([]byte(payload)),
+		Digest:  hex.EncodeToString(util.Digest([]byte(payload))),
+		Payload: base64.StdEncoding.EncodeToString([]byte(payload)),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := hex.DecodeString(rm.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	emptyMD := []backend.MetadataStream{}
+	_, err = g.SetUnvettedStatus(token, backend.MDStatusVetted, emptyMD,
+		emptyMD)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proof := func(ticket string) *decredplugin.VoteInclusionProofReply {
+		t.Helper()
+		payload, err := decredplugin.EncodeVoteInclusionProof(
+			decredplugin.VoteInclusionProof{
+				Token:  rm.Token,
+				Ticket: ticket,
+			})
+		if err != nil {
+			t.Fatal(err)
+		}
+		reply, err := g.pluginVoteInclusionProof(string(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		vipr, err := decredplugin.DecodeVoteInclusionProofReply(
+			[]byte(reply))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return vipr
+	}
+
+	// Journal a vote
+	ticket := strings.Repeat("a", 64)
+	cvj, err := encodeCastVoteJournal(CastVoteJournal{
+		CastVote: decredplugin.CastVote{
+			Token:     rm.Token,
+			Ticket:    ticket,
+			VoteBit:   "1",
+			Signature: "signature",
+		},
+		Receipt: "receipt",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir = pijoin(g.journals, rm.Token)
+	err = os.MkdirAll(dir, 0774)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = g.journal.Journal(pijoin(dir, defaultBallotFilename),
+		string(journalAdd)+string(cvj))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Unknown votes return an empty proof
+	vipr := proof(strings.Repeat("b", 64))
+	if vipr.CastVote.Ticket != "" {
+		t.Fatalf("unexpected vote %v", vipr.CastVote.Ticket)
+	}
+
+	// Journaled votes have not been committed yet
+	vipr = proof(ticket)
+	if vipr.CastVote.Ticket != ticket || vipr.Receipt != "receipt" ||
+		vipr.Commit != "" {
+		t.Fatalf("unexpected proof %v", spew.Sdump(vipr))
+	}
+
+	// Flush and anchor the vote
+	err = g.flushVoteJournals()
+	if err != nil {
+		t.Fatal(err)
+	}
+	vipr = proof(ticket)
+	if vipr.Commit == "" || vipr.AnchorMerkle != "" {
+		t.Fatalf("unexpected proof %v", spew.Sdump(vipr))
+	}
+	err = g.anchorAllRepos()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := sim.Confirm()
+	err = g.anchorChecker()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Verify the merkle path from the commit to the anchor
+	vipr = proof(ticket)
+	root, err := merkle.VerifyAuthPath(&vipr.MerklePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(root[:]) != vipr.AnchorMerkle {
+		t.Fatalf("invalid merkle path root %x wanted %v", *root,
+			vipr.AnchorMerkle)
+	}
+	if vipr.VerifyDigest.Digest != vipr.AnchorMerkle ||
+		vipr.VerifyDigest.ChainInformation.Transaction != tx {
+		t.Fatalf("unexpected dcrtime result %v",
+			spew.Sdump(vipr.VerifyDigest))
+	}
+}
+
+func TestAnchorStatus(t *testing.T) {
+	dir, err := ioutil.TempDir("", "politeia.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sim, host := newTestDcrtime(t)
+	defer sim.Close()
+
+	g, err := New(&chaincfg.TestNet3Params, dir, host, "", nil,
+		testing.Verbose())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+
+	// Nothing has been anchored yet
+	as, err := g.AnchorStatus(0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(as.LastAnchors) != 0 || len(as.Unconfirmed) != 0 ||
+		len(as.Anchors) != 0 || len(as.Verifications) != 0 {
+		t.Fatalf("unexpected anchor status %v", spew.Sdump(as))
+	}
+
+	// Create and vet a record
+	payload := "this is a proposal"
+	rm, err := g.New([]backend.MetadataStream{}, []backend.File{{
+		Name:    "index.md",
+		MIME:    mime.DetectMimeType([]byte(payload)),
+		Digest:  hex.EncodeToString(util.Digest([]byte(payload))),
+		Payload: base64.StdEncoding.EncodeToString([]byte(payload)),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := hex.DecodeString(rm.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	emptyMD := []backend.MetadataStream{}
+	_, err = g.SetUnvettedStatus(token, backend.MDStatusVetted, emptyMD,
+		emptyMD)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Force an anchor
+	merkle, err := g.Anchor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merkle == "" {
+		t.Fatalf("expected anchor")
+	}
+
+	// Nothing left to anchor
+	m, err := g.Anchor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m != "" {
+		t.Fatalf("unexpected anchor %v", m)
+	}
+
+	// Anchor is unconfirmed
+	as, err = g.AnchorStatus(0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(as.LastAnchors) != 1 || as.LastAnchors[0].Merkle != merkle {
+		t.Fatalf("unexpected last anchors %v", spew.Sdump(as.LastAnchors))
+	}
+	if len(as.Unconfirmed) != 1 || as.Unconfirmed[0] != merkle {
+		t.Fatalf("unexpected unconfirmed %v", as.Unconfirmed)
+	}
+	if len(as.Anchors) != 1 || as.Anchors[0].Merkle != merkle ||
+		as.Anchors[0].Confirmed || len(as.Anchors[0].Digests) == 0 ||
+		len(as.Anchors[0].Digests) != len(as.Anchors[0].Messages) {
+		t.Fatalf("unexpected anchors %v", spew.Sdump(as.Anchors))
+	}
+	if len(as.Verifications) != 1 ||
+		as.Verifications[0].ChainTimestamp != 0 ||
+		!strings.Contains(as.Verifications[0].Error, "Not anchored") {
+		t.Fatalf("unexpected verifications %v",
+			spew.Sdump(as.Verifications))
+	}
+
+	// Anchor is confirmed by dcrtime
+	tx := sim.Confirm()
+	as, err = g.AnchorStatus(0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(as.Verifications) != 1 ||
+		as.Verifications[0].Transaction != tx ||
+		as.Verifications[0].ChainTimestamp == 0 {
+		t.Fatalf("unexpected verifications %v",
+			spew.Sdump(as.Verifications))
+	}
+
+	// Confirm anchor
+	err = g.anchorChecker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	as, err = g.AnchorStatus(1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(as.Unconfirmed) != 0 || len(as.Verifications) != 0 {
+		t.Fatalf("unexpected anchor status %v", spew.Sdump(as))
+	}
+	if len(as.Anchors) != 1 || !as.Anchors[0].Confirmed ||
+		as.Anchors[0].Transaction != tx ||
+		as.Anchors[0].ChainTimestamp == 0 {
+		t.Fatalf("unexpected anchors %v", spew.Sdump(as.Anchors))
+	}
+}
+
+// testChainProvider is a chain provider that always fails.
+type testChainProvider struct {
+	calls int
+	err   error
+}
+
+func (p *testChainProvider) Name() string {
+	return "test"
+}
+
+func (p *testChainProvider) BestBlock() (*dcrdataapi.BlockDataBasic, error) {
+	p.calls++
+	return nil, p.err
+}
+
+func (p *testChainProvider) Block(uint32) (*dcrdataapi.BlockDataBasic, error) {
+	p.calls++
+	return nil, p.err
+}
+
+func (p *testChainProvider) Snapshot(string) ([]string, error) {
+	p.calls++
+	return nil, p.err
+}
+
+func (p *testChainProvider) Transactions([]string) ([]dcrdataapi.TrimmedTx, error) {
+	p.calls++
+	return nil, p.err
+}
+
+func testChainFixture() chainFixture {
+	commitAmt := 1.5
+	return chainFixture{
+		Blocks: []dcrdataapi.BlockDataBasic{
+			{Height: 1, Hash: strings.Repeat("1", 64), Time: 1500000000},
+			{Height: 2, Hash: strings.Repeat("2", 64), Time: 1500000300},
+		},
+		Snapshots: map[string][]string{
+			strings.Repeat("1", 64): {strings.Repeat("a", 64)},
+		},
+		Transactions: []dcrdataapi.TrimmedTx{{
+			TxID: strings.Repeat("a", 64),
+			Vout: []dcrdataapi.Vout{{
+				ScriptPubKeyDecoded: dcrdataapi.ScriptPubKey{
+					Addresses: []string{"TsAddress"},
+					CommitAmt: &commitAmt,
+				},
+			}},
+		}},
+	}
+}
+
+func TestChainFailover(t *testing.T) {
+	failing := &testChainProvider{err: fmt.Errorf("connection refused")}
+	fp := &failoverProvider{
+		providers: []chainProvider{
+			failing,
+			&fixtureProvider{fixture: testChainFixture()},
+		},
+		retries: 2,
+	}
+
+	bb, err := fp.BestBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bb.Height != 2 {
+		t.Fatalf("unexpected best block %v", bb.Height)
+	}
+	if failing.calls != 3 {
+		t.Fatalf("unexpected calls %v", failing.calls)
+	}
+
+	// Unsupported calls are not retried
+	failing.calls = 0
+	failing.err = errChainUnsupported
+	tickets, err := fp.Snapshot(strings.Repeat("1", 64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != 1 || failing.calls != 1 {
+		t.Fatalf("unexpected snapshot %v calls %v", tickets,
+			failing.calls)
+	}
+
+	// All providers fail
+	_, err = fp.Block(42)
+	if err == nil {
+		t.Fatalf("expected failure")
+	}
+}
+
+func TestChainProviders(t *testing.T) {
+	f := testChainFixture()
+
+	// dcrdata
+	dcrdata := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/block/best":
+				util.RespondWithJSON(w, http.StatusOK, f.Blocks[1])
+			case "/api/block/1":
+				util.RespondWithJSON(w, http.StatusOK, f.Blocks[0])
+			case "/api/stake/pool/b/" + f.Blocks[0].Hash + "/full":
+				util.RespondWithJSON(w, http.StatusOK,
+					f.Snapshots[f.Blocks[0].Hash])
+			case "/api/txs/trimmed":
+				util.RespondWithJSON(w, http.StatusOK,
+					f.Transactions)
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+	defer dcrdata.Close()
+
+	// dcrd JSON-RPC
+	dcrd := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			user, pass, ok := r.BasicAuth()
+			if !ok || user != "user" || pass != "pass" {
+				http.Error(w, "unauthorized",
+					http.StatusUnauthorized)
+				return
+			}
+			var req util.DcrdRequest
+			err := json.NewDecoder(r.Body).Decode(&req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var result interface{}
+			switch req.Method {
+			case "getbestblock":
+				result = map[string]interface{}{
+					"hash":   f.Blocks[1].Hash,
+					"height": f.Blocks[1].Height,
+				}
+			case "getblockhash":
+				result = f.Blocks[0].Hash
+			case "getblockheader":
+				result = map[string]interface{}{
+					"hash": f.Blocks[0].Hash,
+					"time": f.Blocks[0].Time,
+				}
+			case "getrawtransaction":
+				result = f.Transactions[0]
+			}
+			util.RespondWithJSON(w, http.StatusOK,
+				map[string]interface{}{
+					"result": result,
+					"error":  nil,
+				})
+		}))
+	defer dcrd.Close()
+
+	// fixture
+	dir, err := ioutil.TempDir("", "politeia.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fixture := filepath.Join(dir, "fixture.json")
+	b, err := json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(fixture, b, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	settings := func(providers string) map[string]string {
+		return map[string]string{
+			decredPluginChainProviders: providers,
+			decredPluginChainRetries:   "0",
+			decredPluginDcrdata:        dcrdata.URL,
+			decredPluginDcrdHost:       dcrd.URL,
+			decredPluginDcrdUser:       "user",
+			decredPluginDcrdPass:       "pass",
+			decredPluginChainFixture:   fixture,
+		}
+	}
+
+	// dcrd can't serve snapshots so it can't be the only provider.
+	_, err = newChainProvider(settings(chainProviderDcrd))
+	if err == nil {
+		t.Fatalf("expected dcrd only providers to fail")
+	}
+
+	for _, name := range []string{chainProviderDcrdata,
+		chainProviderDcrd, chainProviderFixture} {
+		var cp chainProvider
+		if name == chainProviderDcrd {
+			cp, err = newDcrdProvider(dcrd.URL, "user", "pass", "",
+				defaultChainTimeout)
+		} else {
+			cp, err = newChainProvider(settings(name))
+		}
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		if cp.Name() != name {
+			t.Fatalf("unexpected provider %v", cp.Name())
+		}
+
+		bb, err := cp.BestBlock()
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		if !reflect.DeepEqual(bb.Hash, f.Blocks[1].Hash) ||
+			bb.Height != f.Blocks[1].Height {
+			t.Fatalf("%v: unexpected best block %v", name,
+				spew.Sdump(bb))
+		}
+		bdb, err := cp.Block(1)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		if bdb.Hash != f.Blocks[0].Hash || bdb.Time != f.Blocks[0].Time {
+			t.Fatalf("%v: unexpected block %v", name,
+				spew.Sdump(bdb))
+		}
+		tickets, err := cp.Snapshot(f.Blocks[0].Hash)
+		if name == chainProviderDcrd {
+			if err != errChainUnsupported {
+				t.Fatalf("%v: expected unsupported got %v",
+					name, err)
+			}
+		} else if err != nil || !reflect.DeepEqual(tickets,
+			f.Snapshots[f.Blocks[0].Hash]) {
+			t.Fatalf("%v: unexpected snapshot %v %v", name,
+				tickets, err)
+		}
+		ttx, err := cp.Transactions([]string{f.Transactions[0].TxID})
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		if len(ttx) != 1 || !reflect.DeepEqual(ttx[0].Vout,
+			f.Transactions[0].Vout) {
+			t.Fatalf("%v: unexpected transactions %v", name,
+				spew.Sdump(ttx))
+		}
+	}
+
+	// Invalid settings
+	for _, v := range [][2]string{
+		{decredPluginChainProviders, "dcrdata,moo"},
+		{decredPluginChainProviders, "dcrd"},
+		{decredPluginChainTimeout, "soon"},
+		{decredPluginChainRetries, "-1"},
+	} {
+		if validateChainSetting(v[0], v[1]) == nil {
+			t.Fatalf("expected invalid setting %v=%v", v[0], v[1])
+		}
+	}
+}
+
+func TestPluginBlockTimes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "politeia.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sim, host := newTestDcrtime(t)
+	defer sim.Close()
+
+	g, err := New(&chaincfg.TestNet3Params, dir, host, "", nil,
+		testing.Verbose())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+
+	f := testChainFixture()
+	fixture := filepath.Join(dir, "fixture.json")
+	b, err := json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(fixture, b, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range map[string]string{
+		decredPluginChainProviders: chainProviderFixture,
+		decredPluginChainFixture:   fixture,
+	} {
+		err = g.SetPluginSetting(decredplugin.ID, k, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	payload, err := decredplugin.EncodeBlockTimes(decredplugin.BlockTimes{
+		Heights: []uint32{2, 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, reply, err := g.Plugin(decredplugin.ID, decredplugin.CmdBlockTimes,
+		string(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	btr, err := decredplugin.DecodeBlockTimesReply([]byte(reply))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(btr.Timestamps, []int64{f.Blocks[1].Time,
+		f.Blocks[0].Time}) {
+		t.Fatalf("unexpected timestamps %v", btr.Timestamps)
+	}
+
+	// Unknown block
+	payload, err = decredplugin.EncodeBlockTimes(decredplugin.BlockTimes{
+		Heights: []uint32{42},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = g.Plugin(decredplugin.ID, decredplugin.CmdBlockTimes,
+		string(payload))
+	if err == nil {
+		t.Fatalf("expected unknown block")
+	}
+}
+
+// testPlugin is a plugin that records the hooks it was called on.
+type testPlugin struct {
+	id       string
+	settings map[string]string
+	hooks    []string        // Called hooks in the form hook:token
+	editErr  error           // Error returned by the post edit hook
+	vetoes   map[string]bool // Hooks that veto the operation
+}
+
+func (p *testPlugin) ID() string {
+	return p.id
+}
+
+func (p *testPlugin) Version() string {
+	return "1"
+}
+
+func (p *testPlugin) Settings() []backend.PluginSetting {
+	settings := make([]backend.PluginSetting, 0, len(p.settings))
+	for k, v := range p.settings {
+		settings = append(settings, backend.PluginSetting{
+			Key:   k,
+			Value: v,
+		})
+	}
+	return settings
+}
+
+func (p *testPlugin) SetSetting(key, value string) error {
+	if key == "invalid" {
+		return fmt.Errorf("invalid setting")
+	}
+	p.settings[key] = value
+	return nil
+}
+
+type testEcho struct {
+	Message string `json:"message"`
+	Count   uint   `json:"count,omitempty"`
+}
+
+func (p *testPlugin) Commands() map[string]PluginCommand {
+	return map[string]PluginCommand{
+		"echo": {
+			Handler: func(payload string) (string, error) {
+				return payload, nil
+			},
+			Request:     testEcho{},
+			Reply:       testEcho{},
+			Permissions: []string{pd.PluginPermissionPublic},
+		},
+	}
+}
+
+func (p *testPlugin) Hooks() map[string]PluginHookFunc {
+	hook := func(name string) PluginHookFunc {
+		return func(args PluginHookArgs) error {
+			p.hooks = append(p.hooks, name+":"+args.Token)
+			if p.vetoes[name] {
+				return backend.PluginVetoError{
+					ErrorCode:    42,
+					ErrorContext: []string{args.Token},
+				}
+			}
+			if name == PluginPostHookEdit {
+				return p.editErr
+			}
+			return nil
+		}
+	}
+	hooks := make(map[string]PluginHookFunc)
+	for _, v := range []string{
+		PluginPreHookNewRecord,
+		PluginPostHookNewRecord,
+		PluginPreHookEdit,
+		PluginPostHookEdit,
+		PluginPreHookEditVetted,
+		PluginPostHookEditVetted,
+		PluginPreHookStatusChange,
+		PluginPostHookStatusChange,
+		PluginPreHookMetadata,
+		PluginPostHookMetadata,
+	} {
+		hooks[v] = hook(v)
+	}
+	return hooks
+}
+
+func TestPluginRegistry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "politeia.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sim, host := newTestDcrtime(t)
+	defer sim.Close()
+
+	g, err := New(&chaincfg.TestNet3Params, dir, host, "", nil,
+		testing.Verbose())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+
+	// Registration
+	p := &testPlugin{
+		id:       "test",
+		settings: make(map[string]string),
+	}
+	err = g.RegisterPlugin(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []Plugin{
+		&testPlugin{id: decredplugin.ID},
+		&testPlugin{id: "test"},
+		&testPlugin{id: "Invalid1"},
+	} {
+		if g.RegisterPlugin(v) == nil {
+			t.Fatalf("expected registration of %v to fail", v.ID())
+		}
+	}
+
+	// Settings
+	err = g.SetPluginSetting("test", "color", "blue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.SetPluginSetting("test", "invalid", "x") == nil {
+		t.Fatalf("expected invalid setting")
+	}
+	if g.SetPluginSetting("moo", "color", "blue") == nil {
+		t.Fatalf("expected unknown plugin")
+	}
+	plugins, err := g.GetPlugins()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plugins) != 2 || plugins[0].ID != decredplugin.ID ||
+		plugins[1].ID != "test" {
+		t.Fatalf("unexpected plugins %v", spew.Sdump(plugins))
+	}
+	for _, v := range plugins[0].Settings {
+		if v.Key == decredPluginIdentity || v.Key == decredPluginJournals {
+			t.Fatalf("secret setting reported: %v", v.Key)
+		}
+	}
+	if !reflect.DeepEqual(plugins[1].Settings, []backend.PluginSetting{{
+		Key:   "color",
+		Value: "blue",
+	}}) {
+		t.Fatalf("unexpected settings %v", plugins[1].Settings)
+	}
+
+	// Decred plugin settings may be changed while the plugins are
+	// reported.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := g.SetPluginSetting(decredplugin.ID,
+				decredPluginChainTimeout, fmt.Sprintf("%vs", i+1))
+			if err != nil {
+				t.Error(err)
+			}
+			_, err = g.GetPlugins()
+			if err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Command descriptors
+	if len(plugins[1].Commands) != 1 {
+		t.Fatalf("unexpected commands %v", plugins[1].Commands)
+	}
+	echo := plugins[1].Commands[0]
+	if echo.Name != "echo" || !reflect.DeepEqual(echo.Permissions,
+		[]string{pd.PluginPermissionPublic}) {
+		t.Fatalf("unexpected command %v", echo)
+	}
+	var schema util.JSONSchema
+	err = json.Unmarshal(echo.RequestSchema, &schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schema.Validate([]byte(`{"message":"hello","count":2}`)) != nil ||
+		schema.Validate([]byte(`{"count":2}`)) == nil {
+		t.Fatalf("unexpected request schema %s", echo.RequestSchema)
+	}
+	for k, v := range plugins[0].Commands {
+		if k > 0 && plugins[0].Commands[k-1].Name >= v.Name {
+			t.Fatalf("commands not sorted: %v", v.Name)
+		}
+		if len(v.ReplySchema) == 0 || len(v.Permissions) == 0 {
+			t.Fatalf("incomplete descriptor: %v", v.Name)
+		}
+		if v.Name != decredplugin.CmdBestBlock && len(v.RequestSchema) == 0 {
+			t.Fatalf("missing request schema: %v", v.Name)
+		}
+	}
+
+	// Commands
+	cmd, reply, err := g.Plugin("test", "echo", "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmd != "echo" || reply != "hello" {
+		t.Fatalf("unexpected reply %v %v", cmd, reply)
+	}
+	_, _, err = g.Plugin("test", decredplugin.CmdBestBlock, "")
+	if err == nil {
+		t.Fatalf("expected invalid command")
+	}
+	_, _, err = g.Plugin("moo", "echo", "")
+	if err == nil {
+		t.Fatalf("expected unknown plugin")
+	}
+
+	// Hooks
+	payload := "this is a proposal"
+	rm, err := g.New([]backend.MetadataStream{}, []backend.File{{
+		Name:    "index.md",
+		MIME:    mime.DetectMimeType([]byte(payload)),
+		Digest:  hex.EncodeToString(util.Digest([]byte(payload))),
+		Payload: base64.StdEncoding.EncodeToString([]byte(payload)),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := hex.DecodeString(rm.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := func(payload string) []backend.File {
+		return []backend.File{{
+			Name:    "a.md",
+			MIME:    mime.DetectMimeType([]byte(payload)),
+			Digest:  hex.EncodeToString(util.Digest([]byte(payload))),
+			Payload: base64.StdEncoding.EncodeToString([]byte(payload)),
+		}}
+	}
+	emptyMD := []backend.MetadataStream{}
+
+	// A failing post edit hook aborts the update
+	p.editErr = fmt.Errorf("no edits")
+	_, err = g.UpdateUnvettedRecord(token, emptyMD, emptyMD, file("a"),
+		[]string{})
+	if err == nil {
+		t.Fatalf("expected hook failure")
+	}
+	p.editErr = nil
+	_, err = g.UpdateUnvettedRecord(token, emptyMD, emptyMD, file("b"),
+		[]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = g.SetUnvettedStatus(token, backend.MDStatusVetted, emptyMD,
+		emptyMD)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		PluginPreHookNewRecord + ":" + rm.Token,
+		PluginPostHookNewRecord + ":" + rm.Token,
+		PluginPreHookEdit + ":" + rm.Token,
+		PluginPostHookEdit + ":" + rm.Token,
+		PluginPreHookEdit + ":" + rm.Token,
+		PluginPostHookEdit + ":" + rm.Token,
+		PluginPreHookStatusChange + ":" + rm.Token,
+		PluginPostHookStatusChange + ":" + rm.Token,
+	}
+	if !reflect.DeepEqual(p.hooks, expected) {
+		t.Fatalf("unexpected hooks %v", p.hooks)
+	}
+}
+
+func TestPluginHookVeto(t *testing.T) {
+	dir, err := ioutil.TempDir("", "politeia.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sim, host := newTestDcrtime(t)
+	defer sim.Close()
+
+	g, err := New(&chaincfg.TestNet3Params, dir, host, "", nil,
+		testing.Verbose())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+	p := &testPlugin{
+		id:       "test",
+		settings: make(map[string]string),
+		vetoes:   make(map[string]bool),
+	}
+	err = g.RegisterPlugin(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := func(name, payload string) []backend.File {
+		return []backend.File{{
+			Name:    name,
+			MIME:    mime.DetectMimeType([]byte(payload)),
+			Digest:  hex.EncodeToString(util.Digest([]byte(payload))),
+			Payload: base64.StdEncoding.EncodeToString([]byte(payload)),
+		}}
+	}
+	md := func(payload string) []backend.MetadataStream {
+		return []backend.MetadataStream{{
+			ID:      12,
+			Payload: payload,
+		}}
+	}
+	emptyMD := []backend.MetadataStream{}
+	vetoed := func(hook string, err error) {
+		t.Helper()
+		veto, ok := err.(backend.PluginVetoError)
+		if !ok {
+			t.Fatalf("%v: expected veto, got %v", hook, err)
+		}
+		if veto.PluginID != "test" || veto.Hook != hook ||
+			veto.ErrorCode != 42 {
+			t.Fatalf("%v: unexpected veto %v", hook, veto)
+		}
+		p.vetoes[hook] = false
+	}
+
+	// New record
+	p.vetoes[PluginPreHookNewRecord] = true
+	_, err = g.New(emptyMD, file("index.md", "vetoed"))
+	vetoed(PluginPreHookNewRecord, err)
+	_, unvetted, err := g.Inventory(0, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unvetted) != 0 {
+		t.Fatalf("vetoed record was created: %v", spew.Sdump(unvetted))
+	}
+	rm, err := g.New(emptyMD, file("index.md", "proposal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := hex.DecodeString(rm.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Unvetted edit
+	p.vetoes[PluginPreHookEdit] = true
+	_, err = g.UpdateUnvettedRecord(token, emptyMD, emptyMD,
+		file("a.md", "a"), []string{})
+	vetoed(PluginPreHookEdit, err)
+	r, err := g.GetUnvetted(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Files) != 1 {
+		t.Fatalf("vetoed edit was applied: %v", spew.Sdump(r.Files))
+	}
+
+	// Status change
+	p.vetoes[PluginPreHookStatusChange] = true
+	_, err = g.SetUnvettedStatus(token, backend.MDStatusVetted, emptyMD,
+		emptyMD)
+	vetoed(PluginPreHookStatusChange, err)
+	_, err = g.GetVetted(token, "")
+	if err != backend.ErrRecordNotFound {
+		t.Fatalf("vetoed status change was applied: %v", err)
+	}
+	_, err = g.SetUnvettedStatus(token, backend.MDStatusVetted, emptyMD,
+		emptyMD)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Vetted edit
+	p.vetoes[PluginPreHookEditVetted] = true
+	_, err = g.UpdateVettedRecord(token, emptyMD, emptyMD,
+		file("a.md", "a"), []string{})
+	vetoed(PluginPreHookEditVetted, err)
+	r, err = g.GetVetted(token, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Version != "1" {
+		t.Fatalf("vetoed vetted edit was applied: %v", r.Version)
+	}
+
+	// Metadata
+	p.vetoes[PluginPreHookMetadata] = true
+	err = g.UpdateVettedMetadata(token, emptyMD, md("vetoed"))
+	vetoed(PluginPreHookMetadata, err)
+	r, err = g.GetVetted(token, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Metadata) != 0 {
+		t.Fatalf("vetoed metadata was applied: %v",
+			spew.Sdump(r.Metadata))
+	}
+	err = g.UpdateVettedMetadata(token, emptyMD, md("metadata"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The decred plugin does not allow edits once the vote has started
+	filename := mdFilename(g.vetted, rm.Token, decredplugin.MDStreamVoteBits)
+	err = ioutil.WriteFile(filename, []byte("{}"), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = g.UpdateVettedRecord(token, emptyMD, emptyMD,
+		file("a.md", "a"), []string{})
+	veto, ok := err.(backend.PluginVetoError)
+	if !ok || veto.PluginID != decredplugin.ID ||
+		veto.ErrorCode != int(decredplugin.ErrorStatusVoteStarted) {
+		t.Fatalf("expected vote started veto, got %v", err)
+	}
+	err = os.Remove(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = g.UpdateVettedRecord(token, emptyMD, emptyMD,
+		file("a.md", "a"), []string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Post hooks are only called for operations that were not vetoed
+	pre := make(map[string]int)
+	post := make(map[string]int)
+	for _, v := range p.hooks {
+		hook := strings.SplitN(v, ":", 2)[0]
+		if strings.HasPrefix(hook, "pre") {
+			pre[hook]++
+		} else {
+			post[hook]++
+		}
+	}
+	expectedPost := map[string]int{
+		PluginPostHookNewRecord:    1,
+		PluginPostHookStatusChange: 1,
+		PluginPostHookMetadata:     1,
+		PluginPostHookEdit:         1,
+		PluginPostHookEditVetted:   1,
+	}
+	if !reflect.DeepEqual(post, expectedPost) {
+		t.Fatalf("unexpected post hooks %v", post)
+	}
+	// The decred plugin is registered first and its veto stops the hook
+	// chain.
+	if pre[PluginPreHookEditVetted] != 2 {
+		t.Fatalf("unexpected pre hooks %v", pre)
+	}
+}
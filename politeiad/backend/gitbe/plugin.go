@@ -0,0 +1,255 @@
+// Copyright (c) 2018 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package gitbe
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/decred/politeia/politeiad/api/v1/mime"
+	"github.com/decred/politeia/politeiad/backend"
+	"github.com/decred/politeia/util"
+)
+
+// Following are the well-known plugin hooks.  Hooks are called by the
+// backend with the lock held.  Pre hooks are called before a record operation
+// is executed and may veto it by returning a backend.PluginVetoError.  Post
+// hooks are called after the operation has been committed; their errors are
+// logged since the operation can no longer be undone.
+const (
+	// PluginPreHookNewRecord is called before a new record is created.
+	PluginPreHookNewRecord = "prenewrecord"
+
+	// PluginPostHookNewRecord is called after a new record has been
+	// created.
+	PluginPostHookNewRecord = "postnewrecord"
+
+	// PluginPreHookEdit is called before an unvetted record is updated.
+	PluginPreHookEdit = "preedit"
+
+	// PluginPostHookEdit is called after an unvetted or vetted record has
+	// been updated but before the update is committed.  Unlike the other
+	// post hooks an error aborts the update.
+	PluginPostHookEdit = "postedit"
+
+	// PluginPreHookEditVetted is called before a vetted record is
+	// updated.
+	PluginPreHookEditVetted = "preeditvetted"
+
+	// PluginPostHookEditVetted is called after a vetted record has been
+	// updated.
+	PluginPostHookEditVetted = "posteditvetted"
+
+	// PluginPreHookStatusChange is called before the status of a record is
+	// changed.
+	PluginPreHookStatusChange = "prestatuschange"
+
+	// PluginPostHookStatusChange is called after the status of a record
+	// has been changed.
+	PluginPostHookStatusChange = "poststatuschange"
+
+	// PluginPreHookMetadata is called before the metadata of a vetted
+	// record is updated.
+	PluginPreHookMetadata = "premetadata"
+
+	// PluginPostHookMetadata is called after the metadata of a vetted
+	// record has been updated.
+	PluginPostHookMetadata = "postmetadata"
+)
+
+// PluginHookArgs describes the record operation that a hook is called for.
+type PluginHookArgs struct {
+	Token       string                   // Hex encoded record token
+	Status      backend.MDStatusT        // Record status before the operation
+	NewStatus   backend.MDStatusT        // Record status after the operation
+	MDAppend    []backend.MetadataStream // Metadata streams to append
+	MDOverwrite []backend.MetadataStream // Metadata streams to overwrite, new record metadata
+	FilesAdd    []backend.File           // Files to add, new record files
+	FilesDel    []string                 // Files to delete
+}
+
+// PluginCommandFunc executes a plugin command and returns the encoded reply.
+type PluginCommandFunc func(payload string) (string, error)
+
+// PluginCommand describes a plugin command and its handler.  Request and Reply
+// are values of the Go types that the payloads are JSON encoded from; their
+// JSON schemas are published in the plugin inventory.
+type PluginCommand struct {
+	Handler     PluginCommandFunc // Command handler
+	Request     interface{}       // Request payload type, nil if none
+	Reply       interface{}       // Reply payload type
+	Permissions []string          // Required permissions, see v1.PluginPermission*
+}
+
+// PluginHookFunc is called by the backend for a record operation.
+type PluginHookFunc func(args PluginHookArgs) error
+
+// Plugin is a politeiad plugin that can be registered with the git backend.
+// Plugin commands are executed without the backend lock held, it is up to
+// the plugin to obtain it when needed.
+type Plugin interface {
+	// ID returns the plugin identifier.  It must match backend.PluginRE.
+	ID() string
+
+	// Version returns the plugin version.
+	Version() string
+
+	// Settings returns the settings that are reported in the plugin
+	// inventory.  Secret settings must not be returned.
+	Settings() []backend.PluginSetting
+
+	// SetSetting validates and applies a setting.  A setting is removed
+	// when its value is empty.
+	SetSetting(key, value string) error
+
+	// Commands returns the commands that are handled by the plugin.
+	Commands() map[string]PluginCommand // [command]command
+
+	// Hooks returns the backend hooks that the plugin wants to be called
+	// on.
+	Hooks() map[string]PluginHookFunc // [hook]handler
+}
+
+// RegisterPlugin adds a plugin to the backend.  Plugins should be registered
+// at startup, before politeiad sets up its routes.
+func (g *gitBackEnd) RegisterPlugin(p Plugin) error {
+	log.Debugf("RegisterPlugin %v %v", p.ID(), p.Version())
+
+	g.Lock()
+	defer g.Unlock()
+
+	if backend.PluginRE.FindString(p.ID()) != p.ID() {
+		return fmt.Errorf("invalid plugin id: %v", p.ID())
+	}
+	if g._plugin(p.ID()) != nil {
+		return fmt.Errorf("duplicate plugin: %v", p.ID())
+	}
+	g.plugins = append(g.plugins, p)
+
+	return nil
+}
+
+// _plugin returns the registered plugin with the provided id or nil if it
+// does not exist.
+//
+// This function must be called WITH the mutex held.
+func (g *gitBackEnd) _plugin(id string) Plugin {
+	for _, v := range g.plugins {
+		if v.ID() == id {
+			return v
+		}
+	}
+	return nil
+}
+
+// pluginLock returns the registered plugin with the provided id.
+//
+// This function must be called WITHOUT the mutex held.
+func (g *gitBackEnd) pluginLock(id string) (Plugin, error) {
+	g.Lock()
+	defer g.Unlock()
+
+	p := g._plugin(id)
+	if p == nil {
+		return nil, fmt.Errorf("unknown plugin: %v", id)
+	}
+	return p, nil
+}
+
+// pluginCommandDescriptors returns the descriptors of the commands of the
+// provided plugin sorted by name.
+func pluginCommandDescriptors(p Plugin) ([]backend.PluginCommandDescriptor, error) {
+	commands := p.Commands()
+	descriptors := make([]backend.PluginCommandDescriptor, 0, len(commands))
+	for k, v := range commands {
+		d := backend.PluginCommandDescriptor{
+			Name:        k,
+			Permissions: v.Permissions,
+		}
+		if v.Request != nil {
+			b, err := json.Marshal(util.NewJSONSchema(v.Request))
+			if err != nil {
+				return nil, err
+			}
+			d.RequestSchema = b
+		}
+		b, err := json.Marshal(util.NewJSONSchema(v.Reply))
+		if err != nil {
+			return nil, err
+		}
+		d.ReplySchema = b
+		descriptors = append(descriptors, d)
+	}
+	sort.Slice(descriptors, func(i, j int) bool {
+		return descriptors[i].Name < descriptors[j].Name
+	})
+	return descriptors, nil
+}
+
+// callPluginHooks calls the provided hook of all registered plugins in
+// registration order.  It stops at the first hook that fails.  Vetoes are
+// returned as a backend.PluginVetoError that identifies the plugin and hook.
+//
+// This function must be called WITH the mutex held.
+func (g *gitBackEnd) callPluginHooks(hook string, args PluginHookArgs) error {
+	for _, p := range g.plugins {
+		f, ok := p.Hooks()[hook]
+		if !ok {
+			continue
+		}
+		log.Tracef("Calling hook: %v %v(%v)", p.ID(), hook, args.Token)
+		err := f(args)
+		if veto, ok := err.(backend.PluginVetoError); ok {
+			veto.PluginID = p.ID()
+			veto.Hook = hook
+			return veto
+		} else if err != nil {
+			return fmt.Errorf("%v %v: %v", p.ID(), hook, err)
+		}
+	}
+	return nil
+}
+
+// callPluginPostHooks calls the provided post hook of all registered plugins
+// and logs failures.
+//
+// This function must be called WITH the mutex held.
+func (g *gitBackEnd) callPluginPostHooks(hook string, args PluginHookArgs) {
+	err := g.callPluginHooks(hook, args)
+	if err != nil {
+		log.Errorf("callPluginPostHooks: %v", err)
+	}
+}
+
+// recordStatus returns the status of the record with the provided id in the
+// currently checked out branch of the unvetted repo.  MDStatusInvalid is
+// returned when the record metadata can not be loaded.
+//
+// This function must be called WITH the mutex held.
+func (g *gitBackEnd) recordStatus(id string) backend.MDStatusT {
+	md, err := loadMD(g.unvetted, id, "")
+	if err != nil {
+		return backend.MDStatusInvalid
+	}
+	return md.Status
+}
+
+// hookFiles converts verified files back into backend files so that they can
+// be handed to plugin hooks.
+func hookFiles(fa []file) []backend.File {
+	files := make([]backend.File, 0, len(fa))
+	for _, v := range fa {
+		files = append(files, backend.File{
+			Name:    v.name,
+			MIME:    mime.DetectMimeType(v.payload),
+			Digest:  hex.EncodeToString(v.digest),
+			Payload: base64.StdEncoding.EncodeToString(v.payload),
+		})
+	}
+	return files
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2018 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/decred/dcrtime/api/v1"
+	"github.com/decred/politeia/util/dcrtimesim"
+)
+
+var (
+	listen = flag.String("listen", net.JoinHostPort("127.0.0.1",
+		v1.DefaultTestnetTimePort), "Listen address")
+	confirmations = flag.Int("confirmations",
+		dcrtimesim.DefaultConfirmations, "Required confirmations")
+	flushInterval = flag.Duration("flush", time.Minute,
+		"Time between anchors of timestamped digests")
+	blockInterval = flag.Duration("blockinterval", 30*time.Second,
+		"Time between blocks")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: dcrtimesim [flags]\n")
+	fmt.Fprintf(os.Stderr, " flags:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\n dcrtimesim is a local dcrtime stand-in. "+
+		"Point politeiad at it with --dcrtimehost=http://<listen>\n")
+}
+
+func _main() error {
+	flag.Usage = usage
+	flag.Parse()
+
+	s := dcrtimesim.New(*confirmations)
+	s.SetBlockInterval(*blockInterval)
+	host, err := s.Start(*listen)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	fmt.Printf("Listening on %v\n", host)
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+
+	flush := time.NewTicker(*flushInterval)
+	defer flush.Stop()
+	block := time.NewTicker(*blockInterval)
+	defer block.Stop()
+	for {
+		select {
+		case <-interrupt:
+			return nil
+		case <-flush.C:
+			tx := s.Flush()
+			if tx != "" {
+				fmt.Printf("Anchored digests in TX %v\n", tx)
+			}
+		case t := <-block.C:
+			height := s.MineBlock(t.Unix())
+			fmt.Printf("Mined block %v\n", height)
+		}
+	}
+}
+
+func main() {
+	err := _main()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -67,6 +68,8 @@ func usage() {
 		"identity\n")
 	fmt.Fprintf(os.Stderr, "  plugins           - Retrieve plugin "+
 		"inventory\n")
+	fmt.Fprintf(os.Stderr, "  plugincmd         - Execute plugin "+
+		"command <plugin> <command> [field=value]...\n")
 	fmt.Fprintf(os.Stderr, "  inventory         - Inventory records "+
 		"<vetted count> <branches count>\n")
 	fmt.Fprintf(os.Stderr, "  new               - Create new record "+
@@ -83,6 +86,10 @@ func usage() {
 		"token:<token>\n")
 	fmt.Fprintf(os.Stderr, "  updatevettedmd    - Update vetted record "+
 		"metadata [actionmdid:metadata]... token:<token>\n")
+	fmt.Fprintf(os.Stderr, "  anchorstatus      - Anchor history and "+
+		"status [count] [verify]\n")
+	fmt.Fprintf(os.Stderr, "  anchor            - Drop anchor "+
+		"immediately\n")
 	fmt.Fprintf(os.Stderr, "\n")
 	fmt.Fprintf(os.Stderr, " metadata<id> is the word metadata followed "+
 		"by digits. Example with 2 metadata records "+
@@ -91,6 +98,10 @@ func usage() {
 	fmt.Fprintf(os.Stderr, " actionmdid is an action + metadatastream id "+
 		"E.g. appendmetadata0:{\"foo\":\"bar\"} or "+
 		"overwritemetadata12:{\"bleh\":\"truff\"}\n")
+	fmt.Fprintf(os.Stderr, " field=value sets a top level field of the "+
+		"plugin command payload. Strings, numbers and booleans are "+
+		"taken literally, all other values must be JSON. E.g. "+
+		"token=abcd parentid=0 'options=[{\"id\":\"yes\"}]'\n")
 
 	fmt.Fprintf(os.Stderr, "\n")
 }
@@ -266,26 +277,21 @@ func pluginInventory() (*v1.PluginInventoryReply, error) {
 	return &ir, nil
 }
 
-func plugin() error {
-	flags := flag.Args()[1:] // Chop off action.
-
-	if len(flags) != 4 {
-		return fmt.Errorf("not enough parameters")
-	}
-
+// pluginCommand sends a command to a plugin and returns the verified reply.
+func pluginCommand(pluginID, command, commandID, payload string) (*v1.PluginCommandReply, error) {
 	challenge, err := util.Random(v1.ChallengeSize)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	b, err := json.Marshal(v1.PluginCommand{
 		Challenge: hex.EncodeToString(challenge),
-		ID:        flags[0],
-		Command:   flags[1],
-		CommandID: flags[2],
-		Payload:   flags[3],
+		ID:        pluginID,
+		Command:   command,
+		CommandID: commandID,
+		Payload:   payload,
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if *printJson {
@@ -294,25 +300,25 @@ func plugin() error {
 
 	c, err := util.NewClient(verify, *rpccert)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	req, err := http.NewRequest("POST", *rpchost+v1.PluginCommandRoute,
 		bytes.NewReader(b))
 	if err != nil {
-		return err
+		return nil, err
 	}
 	req.SetBasicAuth(*rpcuser, *rpcpass)
 	r, err := c.Do(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer r.Body.Close()
 	if r.StatusCode != http.StatusOK {
 		e, err := getErrorFromResponse(r)
 		if err != nil {
-			return fmt.Errorf("%v", r.Status)
+			return nil, fmt.Errorf("%v", r.Status)
 		}
-		return fmt.Errorf("%v: %v", r.Status, e)
+		return nil, fmt.Errorf("%v: %v", r.Status, e)
 	}
 
 	bodyBytes := util.ConvertBodyToByteArray(r.Body, *printJson)
@@ -320,17 +326,178 @@ func plugin() error {
 	var pcr v1.PluginCommandReply
 	err = json.Unmarshal(bodyBytes, &pcr)
 	if err != nil {
-		return fmt.Errorf("Could node unmarshal "+
+		return nil, fmt.Errorf("Could node unmarshal "+
 			"PluginCommandReply: %v", err)
 	}
 
 	// Fetch remote identity
 	id, err := identity.LoadPublicIdentity(*identityFilename)
+	if err != nil {
+		return nil, err
+	}
+
+	err = util.VerifyChallenge(id, challenge, pcr.Response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pcr, nil
+}
+
+func plugin() error {
+	flags := flag.Args()[1:] // Chop off action.
+
+	if len(flags) != 4 {
+		return fmt.Errorf("not enough parameters")
+	}
+
+	_, err := pluginCommand(flags[0], flags[1], flags[2], flags[3])
+	return err
+}
+
+// buildPluginPayload builds a plugin command payload out of field=value
+// arguments.  Values are converted according to the type of the field in the
+// request schema.
+func buildPluginPayload(schema *util.JSONSchema, args []string) ([]byte, error) {
+	if schema.Type != util.JSONSchemaObject {
+		if len(args) != 0 {
+			return nil, fmt.Errorf("command payload is not an object")
+		}
+		return []byte("null"), nil
+	}
+
+	payload := make(map[string]json.RawMessage, len(args))
+	for _, v := range args {
+		kv := strings.SplitN(v, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid argument, expected "+
+				"field=value: %v", v)
+		}
+		field, value := kv[0], kv[1]
+		fs, ok := schema.Properties[field]
+		if !ok {
+			fs = schema.AdditionalProperties
+		}
+		if fs == nil {
+			fields := make([]string, 0, len(schema.Properties))
+			for k := range schema.Properties {
+				fields = append(fields, k)
+			}
+			sort.Strings(fields)
+			return nil, fmt.Errorf("unknown field %v, expected one "+
+				"of: %v", field, strings.Join(fields, ", "))
+		}
+
+		var (
+			b   []byte
+			err error
+		)
+		switch fs.Type {
+		case util.JSONSchemaString:
+			b, err = json.Marshal(value)
+		case util.JSONSchemaInteger, util.JSONSchemaNumber:
+			_, err = strconv.ParseFloat(value, 64)
+			b = []byte(value)
+		case util.JSONSchemaBoolean:
+			var f bool
+			f, err = strconv.ParseBool(value)
+			b = []byte(strconv.FormatBool(f))
+		default:
+			b = []byte(value)
+			if !json.Valid(b) {
+				err = fmt.Errorf("not JSON")
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %v %v: %v", field,
+				value, err)
+		}
+		payload[field] = b
+	}
+
+	return json.Marshal(payload)
+}
+
+// pluginCmd builds a plugin command from its descriptor in the plugin
+// inventory, validates it and validates the reply.
+func pluginCmd() error {
+	flags := flag.Args()[1:] // Chop off action.
+
+	if len(flags) < 2 {
+		return fmt.Errorf("not enough parameters")
+	}
+
+	pi, err := pluginInventory()
+	if err != nil {
+		return err
+	}
+	var descriptor *v1.PluginCommandDescriptor
+	for _, p := range pi.Plugins {
+		if p.ID != flags[0] {
+			continue
+		}
+		for k, c := range p.Commands {
+			if c.Name == flags[1] {
+				descriptor = &p.Commands[k]
+				break
+			}
+		}
+	}
+	if descriptor == nil {
+		return fmt.Errorf("unknown plugin command: %v %v", flags[0],
+			flags[1])
+	}
+
+	// Build and validate payload
+	var payload string
+	if len(descriptor.RequestSchema) != 0 {
+		var schema util.JSONSchema
+		err = json.Unmarshal(descriptor.RequestSchema, &schema)
+		if err != nil {
+			return fmt.Errorf("invalid request schema: %v", err)
+		}
+		b, err := buildPluginPayload(&schema, flags[2:])
+		if err != nil {
+			return err
+		}
+		err = schema.Validate(b)
+		if err != nil {
+			return fmt.Errorf("invalid payload: %v", err)
+		}
+		payload = string(b)
+	} else if len(flags) > 2 {
+		return fmt.Errorf("%v does not take a payload", descriptor.Name)
+	}
+
+	reply, err := pluginCommand(flags[0], flags[1], flags[1], payload)
 	if err != nil {
 		return err
 	}
 
-	return util.VerifyChallenge(id, challenge, pcr.Response)
+	// Validate reply
+	if len(descriptor.ReplySchema) != 0 {
+		var schema util.JSONSchema
+		err = json.Unmarshal(descriptor.ReplySchema, &schema)
+		if err != nil {
+			return fmt.Errorf("invalid reply schema: %v", err)
+		}
+		err = schema.Validate([]byte(reply.Payload))
+		if err != nil {
+			return fmt.Errorf("invalid reply: %v", err)
+		}
+	}
+
+	if !*printJson {
+		var b bytes.Buffer
+		err = json.Indent(&b, []byte(reply.Payload), "", "  ")
+		if err != nil {
+			fmt.Println(reply.Payload)
+		} else {
+			fmt.Println(b.String())
+		}
+	}
+
+	return nil
 }
 
 func getPluginInventory() error {
@@ -341,15 +508,25 @@ func getPluginInventory() error {
 
 	for _, v := range pr.Plugins {
 		fmt.Printf("Plugin ID      : %v\n", v.ID)
-		if len(v.Settings) > 0 {
-			fmt.Printf("Plugin settings: %v = %v\n",
-				v.Settings[0].Key,
-				v.Settings[0].Value)
-		}
-		for _, vv := range v.Settings[1:] {
+		fmt.Printf("Plugin version : %v\n", v.Version)
+		for k, vv := range v.Settings {
+			if k == 0 {
+				fmt.Printf("Plugin settings: %v = %v\n", vv.Key,
+					vv.Value)
+				continue
+			}
 			fmt.Printf("                 %v = %v\n", vv.Key,
 				vv.Value)
 		}
+		for k, vv := range v.Commands {
+			if k == 0 {
+				fmt.Printf("Plugin commands: %v (%v)\n", vv.Name,
+					strings.Join(vv.Permissions, ", "))
+				continue
+			}
+			fmt.Printf("                 %v (%v)\n", vv.Name,
+				strings.Join(vv.Permissions, ", "))
+		}
 	}
 
 	return nil
@@ -440,6 +617,191 @@ func inventory() error {
 	return nil
 }
 
+func anchorStatus() error {
+	flags := flag.Args()[1:] // Chop off action.
+
+	as := v1.AnchorStatus{}
+	for _, v := range flags {
+		if v == "verify" {
+			as.Verify = true
+			continue
+		}
+		count, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid anchor count: %v", v)
+		}
+		as.Count = uint(count)
+	}
+
+	challenge, err := util.Random(v1.ChallengeSize)
+	if err != nil {
+		return err
+	}
+	as.Challenge = hex.EncodeToString(challenge)
+	b, err := json.Marshal(as)
+	if err != nil {
+		return err
+	}
+
+	if *printJson {
+		fmt.Println(string(b))
+	}
+
+	c, err := util.NewClient(verify, *rpccert)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", *rpchost+v1.AnchorStatusRoute,
+		bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(*rpcuser, *rpcpass)
+	r, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		e, err := getErrorFromResponse(r)
+		if err != nil {
+			return fmt.Errorf("%v", r.Status)
+		}
+		return fmt.Errorf("%v: %v", r.Status, e)
+	}
+
+	bodyBytes := util.ConvertBodyToByteArray(r.Body, *printJson)
+
+	var asr v1.AnchorStatusReply
+	err = json.Unmarshal(bodyBytes, &asr)
+	if err != nil {
+		return fmt.Errorf("Could node unmarshal "+
+			"AnchorStatusReply: %v", err)
+	}
+
+	// Fetch remote identity
+	id, err := identity.LoadPublicIdentity(*identityFilename)
+	if err != nil {
+		return err
+	}
+
+	err = util.VerifyChallenge(id, challenge, asr.Response)
+	if err != nil {
+		return err
+	}
+
+	if *printJson {
+		return nil
+	}
+
+	for _, v := range asr.LastAnchors {
+		fmt.Printf("Last anchor (%v)\n", v.Repo)
+		fmt.Printf("  Commit     : %v\n", v.Last)
+		fmt.Printf("  Merkle     : %v\n", v.Merkle)
+		fmt.Printf("  Time       : %v\n", time.Unix(v.Time, 0))
+	}
+	for _, v := range asr.Unconfirmed {
+		fmt.Printf("Unconfirmed  : %v\n", v)
+	}
+	for _, v := range asr.Verifications {
+		fmt.Printf("Verification : %v\n", v.Merkle)
+		switch {
+		case v.Error != "":
+			fmt.Printf("  Error      : %v\n", v.Error)
+		case v.ChainTimestamp == 0:
+			fmt.Printf("  Status     : not enough confirmations\n")
+		default:
+			fmt.Printf("  Transaction: %v\n", v.Transaction)
+			fmt.Printf("  Timestamp  : %v\n",
+				time.Unix(v.ChainTimestamp, 0))
+		}
+	}
+	for _, v := range asr.Anchors {
+		fmt.Printf("Anchor       : %v\n", v.Merkle)
+		fmt.Printf("  Time       : %v\n", time.Unix(v.Time, 0))
+		fmt.Printf("  Confirmed  : %v\n", v.Confirmed)
+		if v.Confirmed {
+			fmt.Printf("  Transaction: %v\n", v.Transaction)
+			fmt.Printf("  Timestamp  : %v\n",
+				time.Unix(v.ChainTimestamp, 0))
+		}
+		for k, d := range v.Digests {
+			fmt.Printf("  Commit     : %v %v\n", d, v.Messages[k])
+		}
+	}
+
+	return nil
+}
+
+func anchor() error {
+	challenge, err := util.Random(v1.ChallengeSize)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(v1.Anchor{
+		Challenge: hex.EncodeToString(challenge),
+	})
+	if err != nil {
+		return err
+	}
+
+	if *printJson {
+		fmt.Println(string(b))
+	}
+
+	c, err := util.NewClient(verify, *rpccert)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", *rpchost+v1.AnchorRoute,
+		bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(*rpcuser, *rpcpass)
+	r, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		e, err := getErrorFromResponse(r)
+		if err != nil {
+			return fmt.Errorf("%v", r.Status)
+		}
+		return fmt.Errorf("%v: %v", r.Status, e)
+	}
+
+	bodyBytes := util.ConvertBodyToByteArray(r.Body, *printJson)
+
+	var ar v1.AnchorReply
+	err = json.Unmarshal(bodyBytes, &ar)
+	if err != nil {
+		return fmt.Errorf("Could node unmarshal AnchorReply: %v", err)
+	}
+
+	// Fetch remote identity
+	id, err := identity.LoadPublicIdentity(*identityFilename)
+	if err != nil {
+		return err
+	}
+
+	err = util.VerifyChallenge(id, challenge, ar.Response)
+	if err != nil {
+		return err
+	}
+
+	if !*printJson {
+		if ar.Merkle == "" {
+			fmt.Printf("Nothing to anchor\n")
+		} else {
+			fmt.Printf("Anchor merkle: %v\n", ar.Merkle)
+		}
+	}
+
+	return nil
+}
+
 func getFile(filename string) (*v1.File, *[sha256.Size]byte, error) {
 	var err error
 
@@ -1305,6 +1667,8 @@ func _main() error {
 				return getIdentity()
 			case "plugin":
 				return plugin()
+			case "plugincmd":
+				return pluginCmd()
 			case "plugininventory":
 				return getPluginInventory()
 			case "inventory":
@@ -1321,6 +1685,10 @@ func _main() error {
 				return updateRecord(true)
 			case "updatevettedmd":
 				return updateVettedMetadata()
+			case "anchorstatus":
+				return anchorStatus()
+			case "anchor":
+				return anchor()
 			default:
 				return fmt.Errorf("invalid action: %v", a)
 			}
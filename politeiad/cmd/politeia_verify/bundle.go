@@ -0,0 +1,202 @@
+// Copyright (c) 2018 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	pd "github.com/decred/politeia/politeiad/api/v1"
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/util"
+)
+
+// verifier accumulates the failures that are encountered while verifying
+// the signatures and receipts of a proposal bundle.
+type verifier struct {
+	server   *identity.PublicIdentity // politeiad identity
+	checked  int                      // Number of signatures checked
+	failures []string                 // Failed verifications
+}
+
+func (v *verifier) fail(format string, args ...interface{}) {
+	v.failures = append(v.failures, fmt.Sprintf(format, args...))
+}
+
+// verifySignature verifies that signature is a valid signature of msg made
+// by the hex encoded public key.
+func (v *verifier) verifySignature(publicKey, signature, msg string) bool {
+	v.checked++
+	id, err := util.IdentityFromString(publicKey)
+	if err != nil {
+		return false
+	}
+	sig, err := util.ConvertSignature(signature)
+	if err != nil {
+		return false
+	}
+	return id.VerifyMessage([]byte(msg), sig)
+}
+
+// verifyReceipt verifies that receipt is a politeiad signature of the client
+// signature.
+func (v *verifier) verifyReceipt(receipt, clientSignature string) bool {
+	v.checked++
+	sig, err := util.ConvertSignature(receipt)
+	if err != nil {
+		return false
+	}
+	return v.server.VerifyMessage([]byte(clientSignature), sig)
+}
+
+func (v *verifier) verifyProposal(p www.ProposalRecord) {
+	files := make([]pd.File, 0, len(p.Files))
+	for _, f := range p.Files {
+		files = append(files, pd.File{
+			Name:    f.Name,
+			MIME:    f.MIME,
+			Digest:  f.Digest,
+			Payload: f.Payload,
+		})
+	}
+	csr := pd.CensorshipRecord{
+		Token:     p.CensorshipRecord.Token,
+		Merkle:    p.CensorshipRecord.Merkle,
+		Signature: p.CensorshipRecord.Signature,
+	}
+	v.checked++
+	if err := pd.Verify(*v.server, csr, files); err != nil {
+		v.fail("proposal %v: censorship record: %v", csr.Token, err)
+	}
+	if !v.verifySignature(p.PublicKey, p.Signature, csr.Merkle) {
+		v.fail("proposal %v: invalid author signature", csr.Token)
+	}
+}
+
+func (v *verifier) verifyComment(c www.Comment) {
+	// The text of censored comments has been removed so only the
+	// receipt can be verified.
+	if !c.Censored && !v.verifySignature(c.PublicKey, c.Signature,
+		c.Token+c.ParentID+c.Comment) {
+		v.fail("comment %v: invalid signature", c.CommentID)
+	}
+	if !v.verifyReceipt(c.Receipt, c.Signature) {
+		v.fail("comment %v: invalid receipt", c.CommentID)
+	}
+}
+
+func (v *verifier) verifyLikeComment(lc www.LikeComment) {
+	if !v.verifySignature(lc.PublicKey, lc.Signature,
+		lc.Token+lc.CommentID+lc.Action) {
+		v.fail("comment %v vote %v: invalid signature", lc.CommentID,
+			lc.Signature)
+	}
+	if !v.verifyReceipt(lc.Receipt, lc.Signature) {
+		v.fail("comment %v vote %v: invalid receipt", lc.CommentID,
+			lc.Signature)
+	}
+}
+
+func (v *verifier) verifyCensorComment(cc www.CensorComment) {
+	if !v.verifySignature(cc.PublicKey, cc.Signature,
+		cc.Token+cc.CommentID+cc.Reason) {
+		v.fail("censored comment %v: invalid signature", cc.CommentID)
+	}
+	if !v.verifyReceipt(cc.Receipt, cc.Signature) {
+		v.fail("censored comment %v: invalid receipt", cc.CommentID)
+	}
+}
+
+func (v *verifier) verifyAuthorizeVote(av www.AuthorizeVote, avr www.AuthorizeVoteReply, version string) {
+	if !v.verifySignature(av.PublicKey, av.Signature,
+		av.Token+version+av.Action) {
+		v.fail("authorize vote %v: invalid signature", av.Token)
+	}
+	if !v.verifyReceipt(avr.Receipt, av.Signature) {
+		v.fail("authorize vote %v: invalid receipt", av.Token)
+	}
+}
+
+func (v *verifier) verifyStartVote(sv www.StartVote) {
+	if !v.verifySignature(sv.PublicKey, sv.Signature, sv.Vote.Token) {
+		v.fail("start vote %v: invalid signature", sv.Vote.Token)
+	}
+}
+
+// verifyBundle verifies every signature and server receipt that is contained
+// in a proposal bundle.
+func verifyBundle(b www.ProposalBundleReply) (*verifier, error) {
+	server, err := util.IdentityFromString(b.ServerPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	v := verifier{
+		server: server,
+	}
+
+	token := b.Proposal.CensorshipRecord.Token
+	v.verifyProposal(b.Proposal)
+	for _, c := range b.Comments {
+		if c.Token != token {
+			v.fail("comment %v: wrong token %v", c.CommentID, c.Token)
+			continue
+		}
+		v.verifyComment(c)
+	}
+	for _, lc := range b.CommentsLikes {
+		if lc.Token != token {
+			v.fail("comment %v vote: wrong token %v", lc.CommentID,
+				lc.Token)
+			continue
+		}
+		v.verifyLikeComment(lc)
+	}
+	for _, cc := range b.CensoredComments {
+		if cc.Token != token {
+			v.fail("censored comment %v: wrong token %v",
+				cc.CommentID, cc.Token)
+			continue
+		}
+		v.verifyCensorComment(cc)
+	}
+	if b.AuthorizeVote != nil && b.AuthorizeVoteReply != nil {
+		v.verifyAuthorizeVote(*b.AuthorizeVote, *b.AuthorizeVoteReply,
+			b.Proposal.Version)
+	}
+	if b.StartVote != nil {
+		if b.StartVote.Vote.Token != token {
+			v.fail("start vote: wrong token %v",
+				b.StartVote.Vote.Token)
+		} else {
+			v.verifyStartVote(*b.StartVote)
+		}
+	}
+
+	return &v, nil
+}
+
+// verifyBundleFile verifies the proposal bundle that is stored in filename
+// and prints the result.
+func verifyBundleFile(filename string) error {
+	payload, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	var b www.ProposalBundleReply
+	err = json.Unmarshal(payload, &b)
+	if err != nil {
+		return err
+	}
+
+	v, err := verifyBundle(b)
+	if err != nil {
+		return err
+	}
+
+	return printResult(v, "Bundle")
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2018 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/decred/dcrtime/merkle"
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	www "github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// sign returns the hex encoded signature of msg.
+func sign(id *identity.FullIdentity, msg string) string {
+	sig := id.SignMessage([]byte(msg))
+	return hex.EncodeToString(sig[:])
+}
+
+// newTestBundle returns a proposal bundle that is signed by the provided
+// server and user identities.
+func newTestBundle(t *testing.T, server, user *identity.FullIdentity) www.ProposalBundleReply {
+	t.Helper()
+
+	token := strings.Repeat("a", 64)
+	payload := []byte("This is a proposal\n")
+	digest := sha256.Sum256(payload)
+	root := merkle.Root([]*[sha256.Size]byte{&digest})
+	mr := hex.EncodeToString(root[:])
+	userKey := hex.EncodeToString(user.Public.Key[:])
+
+	comment := www.Comment{
+		Token:     token,
+		ParentID:  "0",
+		Comment:   "a comment",
+		PublicKey: userKey,
+		CommentID: "1",
+	}
+	comment.Signature = sign(user, comment.Token+comment.ParentID+
+		comment.Comment)
+	comment.Receipt = sign(server, comment.Signature)
+
+	censored := www.Comment{
+		Token:     token,
+		ParentID:  "0",
+		PublicKey: userKey,
+		CommentID: "2",
+		Censored:  true,
+	}
+	censored.Signature = sign(user, censored.Token+censored.ParentID+
+		"censored comment")
+	censored.Receipt = sign(server, censored.Signature)
+
+	like := www.LikeComment{
+		Token:     token,
+		CommentID: "1",
+		Action:    "1",
+		PublicKey: userKey,
+	}
+	like.Signature = sign(user, like.Token+like.CommentID+like.Action)
+	like.Receipt = sign(server, like.Signature)
+
+	cc := www.CensorComment{
+		Token:     token,
+		CommentID: "2",
+		Reason:    "spam",
+		PublicKey: userKey,
+	}
+	cc.Signature = sign(user, cc.Token+cc.CommentID+cc.Reason)
+	cc.Receipt = sign(server, cc.Signature)
+
+	av := www.AuthorizeVote{
+		Action:    "authorize",
+		Token:     token,
+		PublicKey: userKey,
+	}
+	av.Signature = sign(user, av.Token+"1"+av.Action)
+
+	sv := www.StartVote{
+		PublicKey: userKey,
+		Vote: www.Vote{
+			Token: token,
+		},
+	}
+	sv.Signature = sign(user, sv.Vote.Token)
+
+	return www.ProposalBundleReply{
+		ServerPublicKey: hex.EncodeToString(server.Public.Key[:]),
+		Proposal: www.ProposalRecord{
+			Version:   "1",
+			PublicKey: userKey,
+			Signature: sign(user, mr),
+			Files: []www.File{{
+				Name:    "index.md",
+				MIME:    "text/plain; charset=utf-8",
+				Digest:  hex.EncodeToString(digest[:]),
+				Payload: base64.StdEncoding.EncodeToString(payload),
+			}},
+			CensorshipRecord: www.CensorshipRecord{
+				Token:     token,
+				Merkle:    mr,
+				Signature: sign(server, mr+token),
+			},
+		},
+		Comments:         []www.Comment{comment, censored},
+		CommentsLikes:    []www.LikeComment{like},
+		CensoredComments: []www.CensorComment{cc},
+		AuthorizeVote:    &av,
+		AuthorizeVoteReply: &www.AuthorizeVoteReply{
+			Action:  av.Action,
+			Receipt: sign(server, av.Signature),
+		},
+		StartVote: &sv,
+	}
+}
+
+func TestVerifyBundle(t *testing.T) {
+	server, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		name     string
+		tamper   func(b *www.ProposalBundleReply)
+		failures int
+	}{
+		{"valid", func(b *www.ProposalBundleReply) {}, 0},
+		{"file payload", func(b *www.ProposalBundleReply) {
+			b.Proposal.Files[0].Payload = base64.StdEncoding.
+				EncodeToString([]byte("This is another proposal\n"))
+		}, 1},
+		{"censorship record token", func(b *www.ProposalBundleReply) {
+			b.Proposal.CensorshipRecord.Token = strings.Repeat("b", 64)
+		}, 6},
+		{"author signature", func(b *www.ProposalBundleReply) {
+			b.Proposal.Signature = sign(other, b.Proposal.
+				CensorshipRecord.Merkle)
+		}, 1},
+		{"comment text", func(b *www.ProposalBundleReply) {
+			b.Comments[0].Comment = "another comment"
+		}, 1},
+		{"comment receipt", func(b *www.ProposalBundleReply) {
+			b.Comments[1].Receipt = sign(other,
+				b.Comments[1].Signature)
+		}, 1},
+		{"comment vote action", func(b *www.ProposalBundleReply) {
+			b.CommentsLikes[0].Action = "-1"
+		}, 1},
+		{"censor reason", func(b *www.ProposalBundleReply) {
+			b.CensoredComments[0].Reason = "off topic"
+		}, 1},
+		{"authorize vote receipt", func(b *www.ProposalBundleReply) {
+			b.AuthorizeVoteReply.Receipt = "00"
+		}, 1},
+		{"authorize vote version", func(b *www.ProposalBundleReply) {
+			b.Proposal.Version = "2"
+		}, 1},
+		{"start vote token", func(b *www.ProposalBundleReply) {
+			b.StartVote.Vote.Token = strings.Repeat("b", 64)
+		}, 1},
+		{"start vote signature", func(b *www.ProposalBundleReply) {
+			b.StartVote.Signature = sign(other,
+				b.StartVote.Vote.Token)
+		}, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b := newTestBundle(t, server, user)
+			test.tamper(&b)
+			v, err := verifyBundle(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(v.failures) != test.failures {
+				t.Fatalf("got failures %v, want %v", v.failures,
+					test.failures)
+			}
+		})
+	}
+
+	b := newTestBundle(t, server, user)
+	b.ServerPublicKey = "invalid"
+	if _, err := verifyBundle(b); err == nil {
+		t.Fatal("expected invalid server key error")
+	}
+}
@@ -0,0 +1,509 @@
+// Copyright (c) 2018 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg"
+	"github.com/decred/dcrd/chaincfg/chainec"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/dcrutil"
+	"github.com/decred/dcrd/wire"
+	dcrdataapi "github.com/decred/dcrdata/api/types"
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/util"
+)
+
+// cliVerifier verifies the stream of JSON objects that is printed by a
+// politeiawwwcli command.
+type cliVerifier struct {
+	verifier
+
+	version string // Proposal version of authorizevote requests
+	dcrdata string // dcrdata host used to look up ticket commitments
+	objects int    // Number of objects verified
+
+	castVotes []www.CastVote // Cast votes whose signatures are verified
+}
+
+// cliCommands contains the politeiawwwcli commands whose output can be
+// verified.  Every command verifies the objects that are printed by a
+// single invocation of the command, in the order they are printed.
+var cliCommands = map[string]func(*cliVerifier, *json.Decoder) error{
+	"getproposal":   (*cliVerifier).verifyGetProposal,
+	"getcomments":   (*cliVerifier).verifyGetComments,
+	"newcomment":    (*cliVerifier).verifyNewComment,
+	"votecomment":   (*cliVerifier).verifyVoteComment,
+	"censorcomment": (*cliVerifier).verifyCensorCommentCmd,
+	"authorizevote": (*cliVerifier).verifyAuthorizeVoteCmd,
+	"startvote":     (*cliVerifier).verifyStartVoteCmd,
+	"proposalvotes": (*cliVerifier).verifyProposalVotes,
+	"vote":          (*cliVerifier).verifyVote,
+}
+
+// cliCommandNames returns the sorted names of the verifiable commands.
+func cliCommandNames() []string {
+	names := make([]string, 0, len(cliCommands))
+	for k := range cliCommands {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// decode decodes the next object of the command output into obj.
+func (v *cliVerifier) decode(d *json.Decoder, obj interface{}) error {
+	err := d.Decode(obj)
+	if err == io.EOF {
+		return fmt.Errorf("incomplete command output")
+	} else if err != nil {
+		return err
+	}
+	v.objects++
+	return nil
+}
+
+func (v *cliVerifier) verifyGetProposal(d *json.Decoder) error {
+	var pdr www.ProposalDetailsReply
+	if err := v.decode(d, &pdr); err != nil {
+		return err
+	}
+	v.verifyProposal(pdr.Proposal)
+	return nil
+}
+
+func (v *cliVerifier) verifyGetComments(d *json.Decoder) error {
+	var gcr www.GetCommentsReply
+	if err := v.decode(d, &gcr); err != nil {
+		return err
+	}
+	for _, c := range gcr.Comments {
+		v.verifyComment(c)
+	}
+	return nil
+}
+
+func (v *cliVerifier) verifyNewComment(d *json.Decoder) error {
+	var nc www.NewComment
+	if err := v.decode(d, &nc); err != nil {
+		return err
+	}
+	var ncr www.NewCommentReply
+	if err := v.decode(d, &ncr); err != nil {
+		return err
+	}
+	if ncr.Comment.Signature != nc.Signature {
+		v.fail("comment %v: reply does not match request",
+			ncr.Comment.CommentID)
+	}
+	v.verifyComment(ncr.Comment)
+	return nil
+}
+
+func (v *cliVerifier) verifyVoteComment(d *json.Decoder) error {
+	var lc www.LikeComment
+	if err := v.decode(d, &lc); err != nil {
+		return err
+	}
+	var lcr www.LikeCommentReply
+	if err := v.decode(d, &lcr); err != nil {
+		return err
+	}
+	lc.Receipt = lcr.Receipt
+	v.verifyLikeComment(lc)
+	return nil
+}
+
+func (v *cliVerifier) verifyCensorCommentCmd(d *json.Decoder) error {
+	var cc www.CensorComment
+	if err := v.decode(d, &cc); err != nil {
+		return err
+	}
+	var ccr www.CensorCommentReply
+	if err := v.decode(d, &ccr); err != nil {
+		return err
+	}
+	cc.Receipt = ccr.Receipt
+	v.verifyCensorComment(cc)
+	return nil
+}
+
+func (v *cliVerifier) verifyAuthorizeVoteCmd(d *json.Decoder) error {
+	if v.version == "" {
+		return fmt.Errorf("authorizevote requires -version")
+	}
+	var av www.AuthorizeVote
+	if err := v.decode(d, &av); err != nil {
+		return err
+	}
+	var avr www.AuthorizeVoteReply
+	if err := v.decode(d, &avr); err != nil {
+		return err
+	}
+	v.verifyAuthorizeVote(av, avr, v.version)
+	return nil
+}
+
+func (v *cliVerifier) verifyStartVoteCmd(d *json.Decoder) error {
+	var sv www.StartVote
+	if err := v.decode(d, &sv); err != nil {
+		return err
+	}
+	var svr www.StartVoteReply
+	if err := v.decode(d, &svr); err != nil {
+		return err
+	}
+	v.verifyStartVote(sv)
+	v.verifyStartVoteReply(&sv, svr, nil)
+	return nil
+}
+
+func (v *cliVerifier) verifyProposalVotes(d *json.Decoder) error {
+	var vrr www.VoteResultsReply
+	if err := v.decode(d, &vrr); err != nil {
+		return err
+	}
+	v.verifyStartVote(vrr.StartVote)
+	v.verifyStartVoteReply(&vrr.StartVote, vrr.StartVoteReply,
+		vrr.CastVotes)
+	v.castVotes = append(v.castVotes, vrr.CastVotes...)
+	return nil
+}
+
+func (v *cliVerifier) verifyVote(d *json.Decoder) error {
+	var b www.Ballot
+	if err := v.decode(d, &b); err != nil {
+		return err
+	}
+	var br www.BallotReply
+	if err := v.decode(d, &br); err != nil {
+		return err
+	}
+	if len(br.Receipts) != len(b.Votes) {
+		v.fail("ballot: got %v receipts for %v votes",
+			len(br.Receipts), len(b.Votes))
+		return nil
+	}
+	for i, r := range br.Receipts {
+		// Failed votes do not carry a receipt.
+		if r.Error != "" {
+			continue
+		}
+		cv := b.Votes[i]
+		if r.ClientSignature != cv.Signature {
+			v.fail("cast vote %v: receipt does not match vote",
+				cv.Ticket)
+			continue
+		}
+		if !v.verifyReceipt(r.Signature, r.ClientSignature) {
+			v.fail("cast vote %v: invalid receipt", cv.Ticket)
+		}
+		v.castVotes = append(v.castVotes, cv)
+	}
+	return nil
+}
+
+// ticketMaturities contains the ticket maturities of the networks that
+// politeiad can run on.  politeiad adds the ticket maturity to the end height
+// of a vote since the ticket snapshot is taken in the past.
+var ticketMaturities = []uint16{
+	chaincfg.MainNetParams.TicketMaturity,
+	chaincfg.TestNet3Params.TicketMaturity,
+}
+
+// verifyStartVoteReply verifies that the vote details that were returned by
+// the server are consistent with the start vote and that all cast votes were
+// made by eligible tickets using valid vote bits.
+func (v *cliVerifier) verifyStartVoteReply(sv *www.StartVote, svr www.StartVoteReply, votes []www.CastVote) {
+	start, err := strconv.ParseUint(svr.StartBlockHeight, 10, 32)
+	if err != nil {
+		v.fail("start vote reply: invalid start block height %v",
+			svr.StartBlockHeight)
+		return
+	}
+	end, err := strconv.ParseUint(svr.EndHeight, 10, 32)
+	if err != nil {
+		v.fail("start vote reply: invalid end height %v", svr.EndHeight)
+		return
+	}
+	if _, err := chainhash.NewHashFromStr(svr.StartBlockHash); err != nil {
+		v.fail("start vote reply: invalid start block hash %v",
+			svr.StartBlockHash)
+	}
+	if sv == nil {
+		return
+	}
+
+	token := sv.Vote.Token
+	valid := false
+	for _, tm := range ticketMaturities {
+		if start+uint64(sv.Vote.Duration)+uint64(tm) == end {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		v.fail("start vote reply %v: end height %v does not match "+
+			"start height %v and duration %v", token, end, start,
+			sv.Vote.Duration)
+	}
+
+	// politeiawwwcli strips the eligible tickets unless raw JSON output
+	// was requested.
+	eligible := make(map[string]struct{}, len(svr.EligibleTickets))
+	for _, t := range svr.EligibleTickets {
+		if _, err := chainhash.NewHashFromStr(t); err != nil {
+			eligible = nil
+			break
+		}
+		eligible[t] = struct{}{}
+	}
+
+	voted := make(map[string]struct{}, len(votes))
+	for _, cv := range votes {
+		if cv.Token != token {
+			v.fail("cast vote %v: wrong token %v", cv.Ticket, cv.Token)
+			continue
+		}
+		if _, ok := voted[cv.Ticket]; ok {
+			v.fail("cast vote %v: duplicate vote", cv.Ticket)
+		}
+		voted[cv.Ticket] = struct{}{}
+		if eligible != nil {
+			if _, ok := eligible[cv.Ticket]; !ok {
+				v.fail("cast vote %v: ticket not eligible",
+					cv.Ticket)
+			}
+		}
+
+		bit, err := strconv.ParseUint(cv.VoteBit, 16, 64)
+		if err != nil || bit == 0 || sv.Vote.Mask&bit != bit {
+			v.fail("cast vote %v: invalid vote bit %v", cv.Ticket,
+				cv.VoteBit)
+			continue
+		}
+		found := false
+		for _, o := range sv.Vote.Options {
+			if o.Bits == bit {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.fail("cast vote %v: unknown vote bit %v", cv.Ticket,
+				cv.VoteBit)
+		}
+	}
+}
+
+// commitmentAddresses returns the largest commitment address of every
+// ticket.  The ticket transactions are retrieved from dcrdata.
+func (v *cliVerifier) commitmentAddresses(tickets []string) (map[string]string, error) {
+	reqBody, err := json.Marshal(dcrdataapi.Txns{
+		Transactions: tickets,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	host := v.dcrdata
+	if !strings.HasSuffix(host, "/") {
+		host += "/"
+	}
+	client := &http.Client{
+		Timeout: time.Minute,
+	}
+	r, err := client.Post(host+"api/txs/trimmed",
+		"application/json; charset=utf-8", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("dcrdata: %v", r.Status)
+	}
+
+	var ttxs []dcrdataapi.TrimmedTx
+	err = json.NewDecoder(r.Body).Decode(&ttxs)
+	if err != nil {
+		return nil, fmt.Errorf("dcrdata: %v", err)
+	}
+
+	// Best is address with largest commit amount.
+	addrs := make(map[string]string, len(ttxs))
+	for _, ttx := range ttxs {
+		var bestAddr string
+		var bestAmount float64
+		for _, vout := range ttx.Vout {
+			spk := vout.ScriptPubKeyDecoded
+			if spk.CommitAmt == nil || len(spk.Addresses) == 0 {
+				continue
+			}
+			if *spk.CommitAmt > bestAmount {
+				bestAddr = spk.Addresses[0]
+				bestAmount = *spk.CommitAmt
+			}
+		}
+		if bestAddr != "" {
+			addrs[ttx.TxID] = bestAddr
+		}
+	}
+	return addrs, nil
+}
+
+// verifyMessage verifies a message is properly signed.
+// Copied from https://github.com/decred/dcrd/blob/0fc55252f912756c23e641839b1001c21442c38a/rpcserver.go#L5605
+func verifyMessage(address, message, signature string) (bool, error) {
+	// Decode the provided address.
+	addr, err := dcrutil.DecodeAddress(address)
+	if err != nil {
+		return false, fmt.Errorf("Could not decode address: %v",
+			err)
+	}
+
+	// Only P2PKH addresses are valid for signing.
+	pkh, ok := addr.(*dcrutil.AddressPubKeyHash)
+	if !ok {
+		return false, fmt.Errorf("Address is not a pay-to-pubkey-hash "+
+			"address: %v", address)
+	}
+
+	// Decode base64 signature.
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false, fmt.Errorf("Malformed base64 encoding: %v", err)
+	}
+
+	// Validate the signature - this just shows that it was valid at all.
+	// we will compare it with the key next.
+	var buf bytes.Buffer
+	wire.WriteVarString(&buf, 0, "Decred Signed Message:\n")
+	wire.WriteVarString(&buf, 0, message)
+	expectedMessageHash := chainhash.HashB(buf.Bytes())
+	pk, wasCompressed, err := chainec.Secp256k1.RecoverCompact(sig,
+		expectedMessageHash)
+	if err != nil {
+		// Mirror Bitcoin Core behavior, which treats error in
+		// RecoverCompact as invalid signature.
+		return false, nil
+	}
+
+	// Reconstruct the pubkey hash.
+	var serializedPK []byte
+	if wasCompressed {
+		serializedPK = pk.SerializeCompressed()
+	} else {
+		serializedPK = pk.SerializeUncompressed()
+	}
+	a, err := dcrutil.NewAddressSecpPubKey(serializedPK, pkh.Net())
+	if err != nil {
+		// Again mirror Bitcoin Core behavior, which treats error in
+		// public key reconstruction as invalid signature.
+		return false, nil
+	}
+
+	// Return boolean if addresses match.
+	return a.EncodeAddress() == address, nil
+}
+
+// verifyCastVoteSignatures verifies that every cast vote was signed by the
+// largest commitment address of its ticket.
+func (v *cliVerifier) verifyCastVoteSignatures() error {
+	if len(v.castVotes) == 0 {
+		return nil
+	}
+	tickets := make([]string, 0, len(v.castVotes))
+	for _, cv := range v.castVotes {
+		tickets = append(tickets, cv.Ticket)
+	}
+	addrs, err := v.commitmentAddresses(tickets)
+	if err != nil {
+		return fmt.Errorf("commitment addresses: %v", err)
+	}
+
+	for _, cv := range v.castVotes {
+		v.checked++
+		addr, ok := addrs[cv.Ticket]
+		if !ok {
+			v.fail("cast vote %v: commitment address not found",
+				cv.Ticket)
+			continue
+		}
+		sig, err := hex.DecodeString(cv.Signature)
+		if err != nil {
+			v.fail("cast vote %v: invalid signature", cv.Ticket)
+			continue
+		}
+		ok, err = verifyMessage(addr, cv.Token+cv.Ticket+cv.VoteBit,
+			base64.StdEncoding.EncodeToString(sig))
+		if err != nil || !ok {
+			v.fail("cast vote %v: invalid signature", cv.Ticket)
+		}
+	}
+	return nil
+}
+
+// verifyOutput verifies the output of one or more invocations of a
+// politeiawwwcli command.
+func (v *cliVerifier) verifyOutput(command string, r io.Reader) error {
+	verify, ok := cliCommands[command]
+	if !ok {
+		return fmt.Errorf("unsupported command %v, must be one of: %v",
+			command, strings.Join(cliCommandNames(), ", "))
+	}
+
+	d := json.NewDecoder(r)
+	for d.More() {
+		if err := verify(v, d); err != nil {
+			return fmt.Errorf("%v: %v", command, err)
+		}
+	}
+	if v.objects == 0 {
+		return fmt.Errorf("no %v output found", command)
+	}
+
+	return v.verifyCastVoteSignatures()
+}
+
+// verifyCLIFile verifies the output of a politeiawwwcli command that is
+// stored in filename against the provided politeiad public key and prints
+// the result.
+func verifyCLIFile(command, filename, publicKey string) error {
+	server, err := util.IdentityFromString(publicKey)
+	if err != nil {
+		return err
+	}
+	v := cliVerifier{
+		verifier: verifier{
+			server: server,
+		},
+		version: *versionFlag,
+		dcrdata: *dcrdataFlag,
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := v.verifyOutput(command, f); err != nil {
+		return err
+	}
+
+	return printResult(&v.verifier, "Output")
+}
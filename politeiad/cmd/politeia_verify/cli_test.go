@@ -0,0 +1,406 @@
+// Copyright (c) 2018 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	dcrdataapi "github.com/decred/dcrdata/api/types"
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	www "github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// Ticket votes that were signed by the commitment address of the ticket.
+const (
+	testCommitment = "TsmxEcU9G1zQxsckmHpgh2MxCL7HAMgRvfz"
+	testVoteSigC   = "1fd3157d59fde89ada14cbfaf433c03f2607a50801924f0eba1a34" +
+		"e4cce45c22cf01b2e165a41b7bc9e4ecda77afb50b74ed8a0fa8cad15ba6f046aa3e" +
+		"fd7517dd"
+	testVoteSigD = "20b12e8e72df4187b4e6b5614c9c82e3e769f370fcb8b74c34cb8d4f" +
+		"7e773580ab5423adf2818c80bfcd15554d4389055a28f2030aa1249f013d665aac34" +
+		"d7c97f"
+)
+
+// newTestDcrdata returns a fake dcrdata that returns a ticket transaction
+// with the test commitment address for every requested ticket, except for
+// the tickets in unknown.
+func newTestDcrdata(t *testing.T, unknown ...string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/txs/trimmed" {
+			http.NotFound(w, r)
+			return
+		}
+		var txns dcrdataapi.Txns
+		if err := json.NewDecoder(r.Body).Decode(&txns); err != nil {
+			t.Errorf("decode: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		commitAmt := 10.0
+		ttxs := make([]dcrdataapi.TrimmedTx, 0, len(txns.Transactions))
+		for _, tx := range txns.Transactions {
+			ttx := dcrdataapi.TrimmedTx{
+				TxID: tx,
+			}
+			addr := testCommitment
+			for _, u := range unknown {
+				if tx == u {
+					addr = ""
+				}
+			}
+			if addr != "" {
+				ttx.Vout = []dcrdataapi.Vout{{
+					ScriptPubKeyDecoded: dcrdataapi.ScriptPubKey{
+						Addresses: []string{addr},
+						CommitAmt: &commitAmt,
+					},
+				}}
+			}
+			ttxs = append(ttxs, ttx)
+		}
+		json.NewEncoder(w).Encode(ttxs)
+	}))
+}
+
+func TestVerifyOutput(t *testing.T) {
+	server, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token := strings.Repeat("a", 64)
+	ticketC := strings.Repeat("c", 64)
+	ticketD := strings.Repeat("d", 64)
+	userKey := user.Public.String()
+
+	newComment := func() (www.NewComment, www.NewCommentReply) {
+		nc := www.NewComment{
+			Token:     token,
+			ParentID:  "0",
+			Comment:   "a comment",
+			PublicKey: userKey,
+		}
+		nc.Signature = sign(user, nc.Token+nc.ParentID+nc.Comment)
+		return nc, www.NewCommentReply{
+			Comment: www.Comment{
+				Token:     nc.Token,
+				ParentID:  nc.ParentID,
+				Comment:   nc.Comment,
+				Signature: nc.Signature,
+				PublicKey: nc.PublicKey,
+				CommentID: "1",
+				Receipt:   sign(server, nc.Signature),
+			},
+		}
+	}
+	likeComment := func() (www.LikeComment, www.LikeCommentReply) {
+		lc := www.LikeComment{
+			Token:     token,
+			CommentID: "1",
+			Action:    "1",
+			PublicKey: userKey,
+		}
+		lc.Signature = sign(user, lc.Token+lc.CommentID+lc.Action)
+		return lc, www.LikeCommentReply{
+			Total:   1,
+			Result:  1,
+			Receipt: sign(server, lc.Signature),
+		}
+	}
+	censorComment := func() (www.CensorComment, www.CensorCommentReply) {
+		cc := www.CensorComment{
+			Token:     token,
+			CommentID: "1",
+			Reason:    "spam",
+			PublicKey: userKey,
+		}
+		cc.Signature = sign(user, cc.Token+cc.CommentID+cc.Reason)
+		return cc, www.CensorCommentReply{
+			Receipt: sign(server, cc.Signature),
+		}
+	}
+	authorizeVote := func() (www.AuthorizeVote, www.AuthorizeVoteReply) {
+		av := www.AuthorizeVote{
+			Action:    "authorize",
+			Token:     token,
+			PublicKey: userKey,
+		}
+		av.Signature = sign(user, av.Token+"1"+av.Action)
+		return av, www.AuthorizeVoteReply{
+			Action:  av.Action,
+			Receipt: sign(server, av.Signature),
+		}
+	}
+	startVote := func() (www.StartVote, www.StartVoteReply) {
+		sv := www.StartVote{
+			PublicKey: userKey,
+			Vote: www.Vote{
+				Token:    token,
+				Mask:     3,
+				Duration: 2016,
+				Options: []www.VoteOption{
+					{Id: "no", Bits: 1},
+					{Id: "yes", Bits: 2},
+				},
+			},
+		}
+		sv.Signature = sign(user, sv.Vote.Token)
+		return sv, www.StartVoteReply{
+			StartBlockHeight: "100",
+			StartBlockHash:   strings.Repeat("b", 64),
+			EndHeight:        "2372",
+			EligibleTickets:  []string{ticketC, ticketD},
+		}
+	}
+	castVotes := func() []www.CastVote {
+		return []www.CastVote{{
+			Token:     token,
+			Ticket:    ticketC,
+			VoteBit:   "2",
+			Signature: testVoteSigC,
+		}, {
+			Token:     token,
+			Ticket:    ticketD,
+			VoteBit:   "2",
+			Signature: testVoteSigD,
+		}}
+	}
+	proposalVotes := func() www.VoteResultsReply {
+		sv, svr := startVote()
+		return www.VoteResultsReply{
+			StartVote:      sv,
+			StartVoteReply: svr,
+			CastVotes:      castVotes(),
+		}
+	}
+	ballot := func() (www.Ballot, www.BallotReply) {
+		b := www.Ballot{Votes: castVotes()}
+		var br www.BallotReply
+		for _, v := range b.Votes {
+			br.Receipts = append(br.Receipts, www.CastVoteReply{
+				ClientSignature: v.Signature,
+				Signature:       sign(server, v.Signature),
+			})
+		}
+		return b, br
+	}
+
+	var tests = []struct {
+		name     string
+		command  string
+		version  string
+		unknown  []string // Tickets without a commitment address
+		objects  func() []interface{}
+		failures int
+		wantErr  bool
+	}{
+		{"getproposal", "getproposal", "", nil, func() []interface{} {
+			b := newTestBundle(t, server, user)
+			return []interface{}{
+				www.ProposalDetailsReply{Proposal: b.Proposal},
+			}
+		}, 0, false},
+		{"getproposal tampered file", "getproposal", "", nil,
+			func() []interface{} {
+				b := newTestBundle(t, server, user)
+				b.Proposal.Files[0].Payload = "dGFtcGVyZWQK"
+				return []interface{}{
+					www.ProposalDetailsReply{Proposal: b.Proposal},
+				}
+			}, 1, false},
+		{"getcomments", "getcomments", "", nil, func() []interface{} {
+			b := newTestBundle(t, server, user)
+			return []interface{}{
+				www.GetCommentsReply{Comments: b.Comments},
+			}
+		}, 0, false},
+		{"getcomments tampered comment", "getcomments", "", nil,
+			func() []interface{} {
+				b := newTestBundle(t, server, user)
+				b.Comments[0].Comment = "another comment"
+				return []interface{}{
+					www.GetCommentsReply{Comments: b.Comments},
+				}
+			}, 1, false},
+		{"getcomments repeated", "getcomments", "", nil,
+			func() []interface{} {
+				b := newTestBundle(t, server, user)
+				gcr := www.GetCommentsReply{Comments: b.Comments}
+				return []interface{}{gcr, gcr}
+			}, 0, false},
+		{"newcomment", "newcomment", "", nil, func() []interface{} {
+			nc, ncr := newComment()
+			return []interface{}{nc, ncr}
+		}, 0, false},
+		{"newcomment other reply", "newcomment", "", nil,
+			func() []interface{} {
+				nc, ncr := newComment()
+				nc.Signature = sign(other, "other comment")
+				return []interface{}{nc, ncr}
+			}, 1, false},
+		{"votecomment", "votecomment", "", nil, func() []interface{} {
+			lc, lcr := likeComment()
+			return []interface{}{lc, lcr}
+		}, 0, false},
+		{"votecomment tampered action", "votecomment", "", nil,
+			func() []interface{} {
+				lc, lcr := likeComment()
+				lc.Action = "-1"
+				return []interface{}{lc, lcr}
+			}, 1, false},
+		{"censorcomment", "censorcomment", "", nil, func() []interface{} {
+			cc, ccr := censorComment()
+			return []interface{}{cc, ccr}
+		}, 0, false},
+		{"censorcomment tampered receipt", "censorcomment", "", nil,
+			func() []interface{} {
+				cc, ccr := censorComment()
+				ccr.Receipt = sign(other, cc.Signature)
+				return []interface{}{cc, ccr}
+			}, 1, false},
+		{"authorizevote", "authorizevote", "1", nil,
+			func() []interface{} {
+				av, avr := authorizeVote()
+				return []interface{}{av, avr}
+			}, 0, false},
+		{"authorizevote wrong version", "authorizevote", "2", nil,
+			func() []interface{} {
+				av, avr := authorizeVote()
+				return []interface{}{av, avr}
+			}, 1, false},
+		{"authorizevote without version", "authorizevote", "", nil,
+			func() []interface{} {
+				av, avr := authorizeVote()
+				return []interface{}{av, avr}
+			}, 0, true},
+		{"startvote", "startvote", "", nil, func() []interface{} {
+			sv, svr := startVote()
+			return []interface{}{sv, svr}
+		}, 0, false},
+		{"startvote wrong end height", "startvote", "", nil,
+			func() []interface{} {
+				sv, svr := startVote()
+				svr.EndHeight = "2373"
+				return []interface{}{sv, svr}
+			}, 1, false},
+		{"startvote missing reply", "startvote", "", nil,
+			func() []interface{} {
+				sv, _ := startVote()
+				return []interface{}{sv}
+			}, 0, true},
+		{"proposalvotes", "proposalvotes", "", nil,
+			func() []interface{} {
+				return []interface{}{proposalVotes()}
+			}, 0, false},
+		{"proposalvotes tampered vote bit", "proposalvotes", "", nil,
+			func() []interface{} {
+				vrr := proposalVotes()
+				vrr.CastVotes[0].VoteBit = "1"
+				return []interface{}{vrr}
+			}, 1, false},
+		{"proposalvotes swapped signature", "proposalvotes", "", nil,
+			func() []interface{} {
+				vrr := proposalVotes()
+				vrr.CastVotes[0].Signature = testVoteSigD
+				return []interface{}{vrr}
+			}, 1, false},
+		{"proposalvotes ticket not eligible", "proposalvotes", "", nil,
+			func() []interface{} {
+				vrr := proposalVotes()
+				vrr.StartVoteReply.EligibleTickets = []string{ticketC}
+				return []interface{}{vrr}
+			}, 1, false},
+		{"proposalvotes unknown commitment", "proposalvotes", "",
+			[]string{ticketD}, func() []interface{} {
+				return []interface{}{proposalVotes()}
+			}, 1, false},
+		{"vote", "vote", "", nil, func() []interface{} {
+			b, br := ballot()
+			return []interface{}{b, br}
+		}, 0, false},
+		{"vote tampered vote bit", "vote", "", nil, func() []interface{} {
+			b, br := ballot()
+			b.Votes[1].VoteBit = "1"
+			return []interface{}{b, br}
+		}, 1, false},
+		{"vote tampered receipt", "vote", "", nil, func() []interface{} {
+			b, br := ballot()
+			br.Receipts[0].Signature = sign(other,
+				br.Receipts[0].ClientSignature)
+			return []interface{}{b, br}
+		}, 1, false},
+		{"vote receipt of other vote", "vote", "", nil,
+			func() []interface{} {
+				b, br := ballot()
+				br.Receipts[0] = br.Receipts[1]
+				return []interface{}{b, br}
+			}, 1, false},
+		{"vote failed vote", "vote", "", nil, func() []interface{} {
+			b, br := ballot()
+			b.Votes[1].VoteBit = "1"
+			br.Receipts[1] = www.CastVoteReply{
+				ClientSignature: b.Votes[1].Signature,
+				Error:           "invalid signature",
+			}
+			return []interface{}{b, br}
+		}, 0, false},
+		{"unknown command", "getuser", "", nil, func() []interface{} {
+			return []interface{}{www.LoginReply{}}
+		}, 0, true},
+		{"no output", "getcomments", "", nil, func() []interface{} {
+			return nil
+		}, 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dcrdata := newTestDcrdata(t, test.unknown...)
+			defer dcrdata.Close()
+
+			var buf bytes.Buffer
+			e := json.NewEncoder(&buf)
+			for _, o := range test.objects() {
+				if err := e.Encode(o); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			v := cliVerifier{
+				verifier: verifier{
+					server: &server.Public,
+				},
+				version: test.version,
+				dcrdata: dcrdata.URL,
+			}
+			err := v.verifyOutput(test.command, &buf)
+			if test.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(v.failures) != test.failures {
+				t.Fatalf("got failures %v, want %v", v.failures,
+					test.failures)
+			}
+		})
+	}
+}
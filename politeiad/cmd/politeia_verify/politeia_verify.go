@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 
 	"github.com/agl/ed25519"
 	"github.com/decred/dcrtime/merkle"
@@ -23,9 +24,18 @@ var (
 	signatureFlag = flag.String("s", "", "record censorship signature")
 	jsonInFlag    = flag.String("jsonin", "", "JSON record file")
 	jsonOutFlag   = flag.Bool("jsonout", false, "return output as JSON")
+	bundleFlag    = flag.String("bundle", "", "JSON proposal bundle file")
+	cliFlag       = flag.String("cli", "", "politeiawwwcli JSON output file")
+	commandFlag   = flag.String("command", "", "politeiawwwcli command")
+	dcrdataFlag   = flag.String("dcrdata", defaultDcrdata, "dcrdata host")
+	versionFlag   = flag.String("version", "", "proposal version")
 	verboseFlag   = flag.Bool("v", false, "verbose output")
 )
 
+// defaultDcrdata is the dcrdata host that is used to look up the ticket
+// commitment addresses of cast votes.
+const defaultDcrdata = "https://explorer.dcrdata.org/"
+
 type record struct {
 	CensorshipRecord censorshipRecord `json:"censorshiprecord"`
 	ServerPublicKey  string           `json:"serverPubkey"`
@@ -38,7 +48,8 @@ type censorshipRecord struct {
 }
 
 type output struct {
-	Success bool `json:"success"`
+	Success  bool     `json:"success"`
+	Failures []string `json:"failures,omitempty"`
 }
 
 func usage() {
@@ -54,6 +65,24 @@ func usage() {
 	fmt.Fprintf(os.Stderr, "  -jsonin <filename> - A path to a JSON file which "+
 		"represents the record. If this option is set, the other input "+
 		"options (-k, -t, -s) should not be provided.\n")
+	fmt.Fprintf(os.Stderr, "  -bundle <filename> - A path to a JSON proposal "+
+		"bundle as exported by politeiawwwcli. Every signature and "+
+		"receipt in the bundle is verified. If this option is set, "+
+		"no other input options should be provided.\n")
+	fmt.Fprintf(os.Stderr, "  -cli <filename>    - A path to a file with the "+
+		"JSON output of a politeiawwwcli command. Comment signatures "+
+		"and receipts, comment vote and censor receipts, cast vote "+
+		"signatures and receipts, vote authorizations and start vote "+
+		"details are verified. Requires -k and -command.\n")
+	fmt.Fprintf(os.Stderr, "  -command <command> - The politeiawwwcli command "+
+		"that printed the -cli output, one of: %v\n",
+		strings.Join(cliCommandNames(), ", "))
+	fmt.Fprintf(os.Stderr, "  -version <version> - Proposal version that was "+
+		"used to authorize a vote, required with -command "+
+		"authorizevote\n")
+	fmt.Fprintf(os.Stderr, "  -dcrdata <url>     - dcrdata host that is used "+
+		"to look up the ticket commitment addresses of cast votes "+
+		"(default: %v)\n", defaultDcrdata)
 	fmt.Fprintf(os.Stderr, "  -jsonout           - JSON output\n")
 	fmt.Fprintf(os.Stderr, "\n")
 }
@@ -91,8 +120,55 @@ func verifyRecord(key [ed25519.PublicKeySize]byte, merkle, token string, signatu
 	return ed25519.Verify(&key, []byte(merkle+token), &signature)
 }
 
+// printResult prints the outcome of a verifier run.
+func printResult(v *verifier, what string) error {
+	if *jsonOutFlag {
+		bytes, err := json.Marshal(output{
+			Success:  len(v.failures) == 0,
+			Failures: v.failures,
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(bytes))
+		return nil
+	}
+
+	if len(v.failures) != 0 {
+		if *verboseFlag {
+			return fmt.Errorf("%v failed verification:\n  %v", what,
+				strings.Join(v.failures, "\n  "))
+		}
+		return fmt.Errorf("%v failed verification", what)
+	}
+
+	if *verboseFlag {
+		fmt.Printf("%v successfully verified (%v signatures checked)\n",
+			what, v.checked)
+	} else {
+		fmt.Printf("%v successfully verified\n", what)
+	}
+	return nil
+}
+
 func _main() error {
 	flag.Parse()
+	if *bundleFlag != "" {
+		if *publicKeyFlag != "" || *jsonInFlag != "" {
+			usage()
+			return fmt.Errorf("must only provide -bundle")
+		}
+		return verifyBundleFile(*bundleFlag)
+	}
+	if *cliFlag != "" {
+		if *publicKeyFlag == "" || *commandFlag == "" ||
+			*jsonInFlag != "" {
+			usage()
+			return fmt.Errorf("must provide -k and -command with -cli")
+		}
+		return verifyCLIFile(*commandFlag, *cliFlag, *publicKeyFlag)
+	}
 	if (*publicKeyFlag == "" || *tokenFlag == "" || *signatureFlag == "") &&
 		*jsonInFlag == "" {
 		usage()
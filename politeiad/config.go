@@ -74,6 +74,8 @@ type config struct {
 	DcrtimeCert string `long:"dcrtimecert" description:"File containing the https certificate file for dcrtimehost"`
 	Identity    string `long:"identity" description:"File containing the politeiad identity file"`
 	GitTrace    bool   `long:"gittrace" description:"Enable git tracing in logs"`
+
+	PluginSettings []string `long:"pluginsetting" description:"Plugin setting in the form pluginid,key=value -- May be specified multiple times"`
 }
 
 // serviceOptions defines the configuration options for the daemon as a service
@@ -441,6 +443,14 @@ func loadConfig() (*config, []string, error) {
 	// duplicate addresses.
 	cfg.Listeners = normalizeAddresses(cfg.Listeners, port)
 
+	// A plain http dcrtime host, e.g. a local dcrtimesim instance, may be
+	// specified by prefixing the host with http://.
+	timeScheme := "https://"
+	if strings.HasPrefix(cfg.DcrtimeHost, "http://") {
+		timeScheme = "http://"
+		cfg.DcrtimeHost = strings.TrimPrefix(cfg.DcrtimeHost, timeScheme)
+	}
+	cfg.DcrtimeHost = strings.TrimPrefix(cfg.DcrtimeHost, "https://")
 	if cfg.TestNet {
 		var timeHost string
 		if len(cfg.DcrtimeHost) == 0 {
@@ -460,7 +470,7 @@ func loadConfig() (*config, []string, error) {
 		cfg.DcrtimeHost = util.NormalizeAddress(timeHost,
 			v1.DefaultMainnetTimePort)
 	}
-	cfg.DcrtimeHost = "https://" + cfg.DcrtimeHost
+	cfg.DcrtimeHost = timeScheme + cfg.DcrtimeHost
 
 	if len(cfg.DcrtimeCert) != 0 && !util.FileExists(cfg.DcrtimeCert) {
 		cfg.DcrtimeCert = cleanAndExpandPath(cfg.DcrtimeCert)
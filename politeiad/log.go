@@ -43,12 +43,14 @@ var (
 
 	log      = backendLog.Logger("POLI")
 	gitbeLog = backendLog.Logger("GITB")
+	utilLog  = backendLog.Logger("UTIL")
 )
 
 // subsystemLoggers maps each subsystem identifier to its associated logger.
 var subsystemLoggers = map[string]slog.Logger{
 	"POLI": log,
 	"GITB": gitbeLog,
+	"UTIL": utilLog,
 }
 
 // initLogRotator initializes the logging rotater to write logs to logFile and
@@ -16,6 +16,7 @@ import (
 	"os"
 	"os/signal"
 	"runtime/debug"
+	"strings"
 	"syscall"
 	"time"
 
@@ -41,7 +42,6 @@ type politeia struct {
 	cfg      *config
 	router   *mux.Router
 	identity *identity.FullIdentity
-	plugins  map[string]v1.Plugin
 }
 
 func remoteAddr(r *http.Request) string {
@@ -60,13 +60,27 @@ func convertBackendPluginSetting(bpi backend.PluginSetting) v1.PluginSetting {
 	}
 }
 
+func convertBackendPluginCommandDescriptor(bpc backend.PluginCommandDescriptor) v1.PluginCommandDescriptor {
+	return v1.PluginCommandDescriptor{
+		Name:          bpc.Name,
+		RequestSchema: bpc.RequestSchema,
+		ReplySchema:   bpc.ReplySchema,
+		Permissions:   bpc.Permissions,
+	}
+}
+
 func convertBackendPlugin(bpi backend.Plugin) v1.Plugin {
 	p := v1.Plugin{
-		ID: bpi.ID,
+		ID:      bpi.ID,
+		Version: bpi.Version,
 	}
 	for _, v := range bpi.Settings {
 		p.Settings = append(p.Settings, convertBackendPluginSetting(v))
 	}
+	for _, v := range bpi.Commands {
+		p.Commands = append(p.Commands,
+			convertBackendPluginCommandDescriptor(v))
+	}
 
 	return p
 }
@@ -142,6 +156,36 @@ func convertFrontendMetadataStream(mds []v1.MetadataStream) []backend.MetadataSt
 	return m
 }
 
+func convertBackendAnchorRecord(ar backend.AnchorRecord) v1.AnchorRecord {
+	return v1.AnchorRecord{
+		Merkle:         ar.Merkle,
+		Time:           ar.Time,
+		Confirmed:      ar.Confirmed,
+		Digests:        ar.Digests,
+		Messages:       ar.Messages,
+		Transaction:    ar.Transaction,
+		ChainTimestamp: ar.ChainTimestamp,
+	}
+}
+
+func convertBackendLastAnchor(la backend.LastAnchorRecord) v1.LastAnchor {
+	return v1.LastAnchor{
+		Repo:   la.Repo,
+		Last:   la.Last,
+		Merkle: la.Merkle,
+		Time:   la.Time,
+	}
+}
+
+func convertBackendAnchorVerification(av backend.AnchorVerification) v1.AnchorVerification {
+	return v1.AnchorVerification{
+		Merkle:         av.Merkle,
+		Transaction:    av.Transaction,
+		ChainTimestamp: av.ChainTimestamp,
+		Error:          av.Error,
+	}
+}
+
 func (p *politeia) convertBackendRecord(br backend.Record) v1.Record {
 	rm := br.RecordMetadata
 
@@ -180,6 +224,22 @@ func (p *politeia) convertBackendRecord(br backend.Record) v1.Record {
 	return pr
 }
 
+// parsePluginSetting parses a plugin setting in the form pluginid,key=value.
+func parsePluginSetting(s string) (string, *backend.PluginSetting, error) {
+	idSetting := strings.SplitN(s, ",", 2)
+	if len(idSetting) != 2 {
+		return "", nil, fmt.Errorf("invalid plugin setting: %v", s)
+	}
+	keyValue := strings.SplitN(idSetting[1], "=", 2)
+	if len(keyValue) != 2 || keyValue[0] == "" {
+		return "", nil, fmt.Errorf("invalid plugin setting: %v", s)
+	}
+	return idSetting[0], &backend.PluginSetting{
+		Key:   keyValue[0],
+		Value: keyValue[1],
+	}, nil
+}
+
 func (p *politeia) respondWithUserError(w http.ResponseWriter,
 	errorCode v1.ErrorStatusT, errorContext []string) {
 	util.RespondWithJSON(w, http.StatusBadRequest, v1.UserErrorReply{
@@ -188,6 +248,18 @@ func (p *politeia) respondWithUserError(w http.ResponseWriter,
 	})
 }
 
+// respondWithPluginVeto replies with the plugin specific error of a vetoed
+// record operation.
+func (p *politeia) respondWithPluginVeto(w http.ResponseWriter,
+	veto backend.PluginVetoError) {
+	util.RespondWithJSON(w, http.StatusBadRequest, v1.UserErrorReply{
+		ErrorCode:       v1.ErrorStatusPluginVeto,
+		ErrorContext:    veto.ErrorContext,
+		PluginID:        veto.PluginID,
+		PluginErrorCode: veto.ErrorCode,
+	})
+}
+
 func (p *politeia) respondWithServerError(w http.ResponseWriter, errorCode int64) {
 	log.Errorf("Stacktrace (NOT A REAL CRASH): %s", debug.Stack())
 	util.RespondWithJSON(w, http.StatusInternalServerError, v1.ServerErrorReply{
@@ -238,6 +310,13 @@ func (p *politeia) newRecord(w http.ResponseWriter, r *http.Request) {
 	rm, err := p.backend.New(convertFrontendMetadataStream(t.Metadata),
 		convertFrontendFiles(t.Files))
 	if err != nil {
+		// Check for plugin veto.
+		if veto, ok := err.(backend.PluginVetoError); ok {
+			log.Errorf("%v New record vetoed: %v",
+				remoteAddr(r), veto)
+			p.respondWithPluginVeto(w, veto)
+			return
+		}
 		// Check for content error.
 		if contentErr, ok := err.(backend.ContentVerificationError); ok {
 			log.Errorf("%v New record content error: %v",
@@ -340,6 +419,13 @@ func (p *politeia) updateRecord(w http.ResponseWriter, r *http.Request, vetted b
 			p.respondWithUserError(w, v1.ErrorStatusNoChanges, nil)
 			return
 		}
+		// Check for plugin veto.
+		if veto, ok := err.(backend.PluginVetoError); ok {
+			log.Errorf("%v update %v record vetoed: %v",
+				remoteAddr(r), cmd, veto)
+			p.respondWithPluginVeto(w, veto)
+			return
+		}
 		// Check for content error.
 		if contentErr, ok := err.(backend.ContentVerificationError); ok {
 			log.Errorf("%v update %v record content error: %v",
@@ -620,6 +706,13 @@ func (p *politeia) setVettedStatus(w http.ResponseWriter, r *http.Request) {
 			p.respondWithUserError(w, v1.ErrorStatusInvalidRecordStatusTransition, nil)
 			return
 		}
+		// Check for plugin veto.
+		if veto, ok := err.(backend.PluginVetoError); ok {
+			log.Errorf("%v Set status vetoed: %v",
+				remoteAddr(r), veto)
+			p.respondWithPluginVeto(w, veto)
+			return
+		}
 		// Generic internal error.
 		errorCode := time.Now().Unix()
 		log.Errorf("%v Set status error code %v: %v",
@@ -680,6 +773,13 @@ func (p *politeia) setUnvettedStatus(w http.ResponseWriter, r *http.Request) {
 			p.respondWithUserError(w, v1.ErrorStatusInvalidRecordStatusTransition, nil)
 			return
 		}
+		// Check for plugin veto.
+		if veto, ok := err.(backend.PluginVetoError); ok {
+			log.Errorf("%v Set unvetted status vetoed: %v",
+				remoteAddr(r), veto)
+			p.respondWithPluginVeto(w, veto)
+			return
+		}
 		// Generic internal error.
 		errorCode := time.Now().Unix()
 		log.Errorf("%v Set unvetted status error code %v: %v",
@@ -734,6 +834,13 @@ func (p *politeia) updateVettedMetadata(w http.ResponseWriter, r *http.Request)
 			p.respondWithUserError(w, v1.ErrorStatusNoChanges, nil)
 			return
 		}
+		// Check for plugin veto.
+		if veto, ok := err.(backend.PluginVetoError); ok {
+			log.Errorf("%v Update vetted metadata vetoed: %v",
+				remoteAddr(r), veto)
+			p.respondWithPluginVeto(w, veto)
+			return
+		}
 		// Check for content error.
 		if contentErr, ok := err.(backend.ContentVerificationError); ok {
 			log.Errorf("%v update vetted metadata content error: %v",
@@ -775,14 +882,26 @@ func (p *politeia) pluginInventory(w http.ResponseWriter, r *http.Request) {
 		p.respondWithUserError(w, v1.ErrorStatusInvalidChallenge, nil)
 		return
 	}
+	plugins, err := p.backend.GetPlugins()
+	if err != nil {
+		// Generic internal error.
+		errorCode := time.Now().Unix()
+		log.Errorf("%v %v: backend get plugins failed: %v",
+			remoteAddr(r), errorCode, err)
+		p.respondWithServerError(w, errorCode)
+		return
+	}
+
 	response := p.identity.SignMessage(challenge)
 
 	reply := v1.PluginInventoryReply{
 		Response: hex.EncodeToString(response[:]),
+		Plugins:  make([]v1.Plugin, 0, len(plugins)),
 	}
 
-	for _, v := range p.plugins {
-		reply.Plugins = append(reply.Plugins, v)
+	// Plugins are returned in registration order
+	for _, v := range plugins {
+		reply.Plugins = append(reply.Plugins, convertBackendPlugin(v))
 	}
 
 	util.RespondWithJSON(w, http.StatusOK, reply)
@@ -803,7 +922,8 @@ func (p *politeia) pluginCommand(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cid, payload, err := p.backend.Plugin(pc.Command, pc.Payload)
+	cid, payload, err := p.backend.Plugin(pc.ID, pc.Command,
+		pc.Payload)
 	if err != nil {
 		// Generic internal error.
 		errorCode := time.Now().Unix()
@@ -826,6 +946,91 @@ func (p *politeia) pluginCommand(w http.ResponseWriter, r *http.Request) {
 	util.RespondWithJSON(w, http.StatusOK, reply)
 }
 
+func (p *politeia) anchorStatus(w http.ResponseWriter, r *http.Request) {
+	var as v1.AnchorStatus
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&as); err != nil {
+		p.respondWithUserError(w, v1.ErrorStatusInvalidRequestPayload,
+			nil)
+		return
+	}
+
+	challenge, err := hex.DecodeString(as.Challenge)
+	if err != nil || len(challenge) != v1.ChallengeSize {
+		p.respondWithUserError(w, v1.ErrorStatusInvalidChallenge, nil)
+		return
+	}
+
+	bas, err := p.backend.AnchorStatus(as.Count, as.Verify)
+	if err != nil {
+		// Generic internal error.
+		errorCode := time.Now().Unix()
+		log.Errorf("%v Anchor status error code %v: %v", remoteAddr(r),
+			errorCode, err)
+		p.respondWithServerError(w, errorCode)
+		return
+	}
+
+	response := p.identity.SignMessage(challenge)
+	reply := v1.AnchorStatusReply{
+		Response:      hex.EncodeToString(response[:]),
+		LastAnchors:   make([]v1.LastAnchor, 0, len(bas.LastAnchors)),
+		Unconfirmed:   bas.Unconfirmed,
+		Anchors:       make([]v1.AnchorRecord, 0, len(bas.Anchors)),
+		Verifications: make([]v1.AnchorVerification, 0, len(bas.Verifications)),
+	}
+	for _, v := range bas.LastAnchors {
+		reply.LastAnchors = append(reply.LastAnchors,
+			convertBackendLastAnchor(v))
+	}
+	for _, v := range bas.Anchors {
+		reply.Anchors = append(reply.Anchors,
+			convertBackendAnchorRecord(v))
+	}
+	for _, v := range bas.Verifications {
+		reply.Verifications = append(reply.Verifications,
+			convertBackendAnchorVerification(v))
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, reply)
+}
+
+func (p *politeia) anchor(w http.ResponseWriter, r *http.Request) {
+	var a v1.Anchor
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&a); err != nil {
+		p.respondWithUserError(w, v1.ErrorStatusInvalidRequestPayload,
+			nil)
+		return
+	}
+
+	challenge, err := hex.DecodeString(a.Challenge)
+	if err != nil || len(challenge) != v1.ChallengeSize {
+		p.respondWithUserError(w, v1.ErrorStatusInvalidChallenge, nil)
+		return
+	}
+
+	merkle, err := p.backend.Anchor()
+	if err != nil {
+		// Generic internal error.
+		errorCode := time.Now().Unix()
+		log.Errorf("%v Anchor error code %v: %v", remoteAddr(r),
+			errorCode, err)
+		p.respondWithServerError(w, errorCode)
+		return
+	}
+
+	response := p.identity.SignMessage(challenge)
+	reply := v1.AnchorReply{
+		Response: hex.EncodeToString(response[:]),
+		Merkle:   merkle,
+	}
+
+	log.Infof("Anchor %v: merkle %v", remoteAddr(r), merkle)
+
+	util.RespondWithJSON(w, http.StatusOK, reply)
+}
+
 func logging(f http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Trace incoming request
@@ -916,8 +1121,7 @@ func _main() error {
 
 	// Setup application context.
 	p := &politeia{
-		cfg:     loadedCfg,
-		plugins: make(map[string]v1.Plugin),
+		cfg: loadedCfg,
 	}
 
 	// Load identity.
@@ -948,11 +1152,22 @@ func _main() error {
 
 	// Setup backend.
 	gitbe.UseLogger(gitbeLog)
+	util.UseLogger(utilLog)
 	b, err := gitbe.New(activeNetParams.Params, loadedCfg.DataDir,
 		loadedCfg.DcrtimeHost, "", p.identity, loadedCfg.GitTrace)
 	if err != nil {
 		return err
 	}
+	for _, v := range loadedCfg.PluginSettings {
+		id, setting, err := parsePluginSetting(v)
+		if err != nil {
+			return err
+		}
+		err = b.SetPluginSetting(id, setting.Key, setting.Value)
+		if err != nil {
+			return fmt.Errorf("plugin setting %v: %v", v, err)
+		}
+	}
 	p.backend = b
 
 	// Setup mux
@@ -981,6 +1196,9 @@ func _main() error {
 		p.setVettedStatus, permissionAuth)
 	p.addRoute(http.MethodPost, v1.UpdateVettedMetadataRoute,
 		p.updateVettedMetadata, permissionAuth)
+	p.addRoute(http.MethodPost, v1.AnchorStatusRoute, p.anchorStatus,
+		permissionAuth)
+	p.addRoute(http.MethodPost, v1.AnchorRoute, p.anchor, permissionAuth)
 
 	// Setup plugins
 	plugins, err := p.backend.GetPlugins()
@@ -995,15 +1213,6 @@ func _main() error {
 			permissionAuth)
 
 		for _, v := range plugins {
-			// make sure we only have lowercase names
-			if backend.PluginRE.FindString(v.ID) != v.ID {
-				return fmt.Errorf("invalid plugin id: %v", v.ID)
-			}
-			if _, found := p.plugins[v.ID]; found {
-				return fmt.Errorf("duplicate plugin: %v", v.ID)
-			}
-
-			p.plugins[v.ID] = convertBackendPlugin(v)
 			log.Infof("Registered plugin: %v", v.ID)
 		}
 	}
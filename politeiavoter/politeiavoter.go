@@ -930,6 +930,44 @@ func (c *ctx) login(email, password string) (*v1.LoginReply, error) {
 	return &lr, nil
 }
 
+// loginSignature logs in by signing a login challenge with the provided
+// identity.  This does not require the password of the user.
+func (c *ctx) loginSignature(fi *identity.FullIdentity) (*v1.LoginReply, error) {
+	pk := hex.EncodeToString(fi.Public.Key[:])
+	responseBody, err := c.makeRequest("POST", v1.RouteLoginChallenge,
+		v1.LoginChallenge{PublicKey: pk})
+	if err != nil {
+		return nil, err
+	}
+
+	var lcr v1.LoginChallengeReply
+	err = json.Unmarshal(responseBody, &lcr)
+	if err != nil {
+		return nil, fmt.Errorf("Could not unmarshal "+
+			"LoginChallengeReply: %v", err)
+	}
+
+	sig := fi.SignMessage([]byte(v1.LoginChallengePrefix + lcr.Challenge))
+	ls := v1.LoginSignature{
+		PublicKey: pk,
+		Challenge: lcr.Challenge,
+		Signature: hex.EncodeToString(sig[:]),
+	}
+	responseBody, err = c.makeRequest("POST", v1.RouteLoginSignature, ls)
+	if err != nil {
+		return nil, err
+	}
+
+	var lr v1.LoginReply
+	err = json.Unmarshal(responseBody, &lr)
+	if err != nil {
+		return nil, fmt.Errorf("Could not unmarshal LoginReply: %v",
+			err)
+	}
+
+	return &lr, nil
+}
+
 func (c *ctx) _startVote(sv *v1.StartVote) (*v1.StartVoteReply, error) {
 	responseBody, err := c.makeRequest("POST", v1.RouteStartVote, sv)
 	if err != nil {
@@ -947,19 +985,26 @@ func (c *ctx) _startVote(sv *v1.StartVote) (*v1.StartVoteReply, error) {
 }
 
 func (c *ctx) startVote(args []string) error {
-	if len(args) != 4 {
+	if len(args) != 2 && len(args) != 4 {
 		return fmt.Errorf("startvote: not enough arguments, expected:" +
-			"identityfile email password token")
+			"identityfile [email password] token")
 	}
 
-	// startvote identityfile email password token
+	// startvote identityfile [email password] token
 	fi, err := identity.LoadFullIdentity(args[0])
 	if err != nil {
 		return err
 	}
+	token := args[len(args)-1]
 
-	// Login as admin
-	lr, err := c.login(args[1], args[2])
+	// Login as admin.  The identity is used to login when no email and
+	// password are provided.
+	var lr *v1.LoginReply
+	if len(args) == 2 {
+		lr, err = c.loginSignature(fi)
+	} else {
+		lr, err = c.login(args[1], args[2])
+	}
 	if err != nil {
 		return err
 	}
@@ -970,7 +1015,7 @@ func (c *ctx) startVote(args []string) error {
 	sv := v1.StartVote{
 		PublicKey: hex.EncodeToString(c.id.Key[:]),
 		Vote: v1.Vote{
-			Token:    args[3],
+			Token:    token,
 			Mask:     0x03, // bit 0 no, bit 1 yes
 			Duration: 2016, // 1 week
 			Options: []v1.VoteOption{
@@ -1,61 +1,95 @@
 package main
 
 import (
+	"encoding/hex"
 	"fmt"
-	"os"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/decred/politeia/politeiawww/api/v1"
 	"github.com/decred/politeia/politeiawww/database"
-	"github.com/decred/politeia/util"
 	"github.com/google/uuid"
 )
 
-// _logAdminAction logs a string to the admin log file.
+// _logAdminAction appends an action to the admin audit log.  The entry is
+// linked to the previous entry and signed by the server identity.
 //
 // This function must be called WITH the mutex held.
-func (b *backend) _logAdminAction(adminUser *database.User, content string) error {
-	if b.test {
-		return nil
+func (b *backend) _logAdminAction(adminUser *database.User, e database.AdminLogEntry) error {
+	prev, err := b.db.AdminLogEntryLatest()
+	switch err {
+	case nil:
+		e.Index = prev.Index + 1
+		e.PrevHash = prev.Hash
+	case database.ErrAdminLogEntryNotFound:
+		e.Index = 0
+		e.PrevHash = ""
+	default:
+		return err
 	}
 
-	f, err := os.OpenFile(b.cfg.AdminLogFile, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0640)
+	e.Timestamp = time.Now().Unix()
+	e.AdminID = adminUser.ID
+	e.AdminUsername = adminUser.Username
+	e.PublicKey = b.auditIdentity.Public.String()
+	e.Hash, err = database.AdminLogEntryHash(e)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
+	sig := b.auditIdentity.SignMessage([]byte(e.Hash))
+	e.Signature = hex.EncodeToString(sig[:])
 
-	dateTimeStr := time.Now().UTC().Format("2006-01-02 15:04:05")
-	_, err = fmt.Fprintf(f, "%v,%v,%v,%v\n", dateTimeStr,
-		adminUser.ID, adminUser.Username, content)
-	return err
+	return b.db.AdminLogEntryNew(e)
 }
 
-// logAdminAction logs a string to the admin log file.
+// logAdminAction appends an action to the admin audit log.
 //
 // This function must be called WITHOUT the mutex held.
-func (b *backend) logAdminAction(adminUser *database.User, content string) error {
+func (b *backend) logAdminAction(adminUser *database.User, e database.AdminLogEntry) error {
 	b.Lock()
 	defer b.Unlock()
 
-	return b._logAdminAction(adminUser, content)
+	return b._logAdminAction(adminUser, e)
+}
+
+// logAdminUserAction logs an admin action on a specific user.  The role is
+// only logged for role actions.
+//
+// This function must be called WITHOUT the mutex held.
+func (b *backend) logAdminUserAction(adminUser, user *database.User, action v1.UserManageActionT, role v1.UserRoleT, reasonForAction string) error {
+	actionStr := v1.UserManageAction[action]
+	if role != 0 {
+		actionStr += " " + v1.UserRole[role]
+	}
+	return b.logAdminAction(adminUser, database.AdminLogEntry{
+		Action: actionStr,
+		UserID: user.ID.String(),
+		Reason: reasonForAction,
+	})
 }
 
-// logAdminUserAction logs an admin action on a specific user.
+// logAdminCommentAction logs an admin action on a proposal comment.
 //
 // This function must be called WITHOUT the mutex held.
-func (b *backend) logAdminUserAction(adminUser, user *database.User, action v1.UserManageActionT, reasonForAction string) error {
-	return b.logAdminAction(adminUser, fmt.Sprintf("%v,%v,%v,%v",
-		v1.UserManageAction[action], user.ID, user.Username, reasonForAction))
+func (b *backend) logAdminCommentAction(adminUser *database.User, token, commentID, action, reason string) error {
+	return b.logAdminAction(adminUser, database.AdminLogEntry{
+		Action:    action,
+		Token:     token,
+		CommentID: commentID,
+		Reason:    reason,
+	})
 }
 
 // logAdminProposalAction logs an admin action on a proposal.
 //
 // This function must be called WITHOUT the mutex held.
 func (b *backend) logAdminProposalAction(adminUser *database.User, token, action, reason string) error {
-	return b.logAdminAction(adminUser, fmt.Sprintf("%v,%v,%v", action, token, reason))
+	return b.logAdminAction(adminUser, database.AdminLogEntry{
+		Action: action,
+		Token:  token,
+		Reason: reason,
+	})
 }
 
 func (b *backend) ProcessManageUser(mu *v1.ManageUser, adminUser *database.User) (*v1.ManageUserReply, error) {
@@ -103,6 +137,36 @@ func (b *backend) ProcessManageUser(mu *v1.ManageUser, adminUser *database.User)
 		user.Deactivated = true
 	case v1.UserManageReactivate:
 		user.Deactivated = false
+	case v1.UserManageGrantRole:
+		if !validUserRole(mu.Role) {
+			return nil, v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidUserRole,
+			}
+		}
+		user.Roles |= uint64(mu.Role)
+	case v1.UserManageRevokeRole:
+		if !validUserRole(mu.Role) {
+			return nil, v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidUserRole,
+			}
+		}
+
+		// Super admins can't revoke their own super admin role in
+		// order to prevent locking everyone out of user management.
+		if mu.Role == v1.UserRoleSuperAdmin && user.ID == adminUser.ID {
+			return nil, v1.UserError{
+				ErrorCode: v1.ErrorStatusUserActionNotAllowed,
+			}
+		}
+
+		// The legacy admin flag implies the super admin role so it
+		// is cleared along with it.
+		user.Roles &^= uint64(mu.Role)
+		if mu.Role == v1.UserRoleSuperAdmin {
+			user.Admin = false
+		}
+	case v1.UserManageResetTOTP:
+		resetTOTP(user)
 	default:
 		return nil, fmt.Errorf("unsupported user edit action: %v",
 			v1.UserManageAction[mu.Action])
@@ -114,6 +178,14 @@ func (b *backend) ProcessManageUser(mu *v1.ManageUser, adminUser *database.User)
 		return nil, err
 	}
 
+	// Deactivated users are logged out everywhere.
+	if mu.Action == v1.UserManageDeactivate {
+		err = b.revokeSessions(user.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if !b.test {
 		b.fireEvent(EventTypeUserManage, EventDataUserManage{
 			AdminUser:  adminUser,
@@ -174,6 +246,77 @@ func (b *backend) ProcessUsers(users *v1.Users) (*v1.UsersReply, error) {
 	return &reply, nil
 }
 
+func convertWWWAdminLogEntryFromDatabaseAdminLogEntry(e database.AdminLogEntry) v1.AdminLogEntry {
+	return v1.AdminLogEntry{
+		Index:         e.Index,
+		Timestamp:     e.Timestamp,
+		AdminID:       e.AdminID.String(),
+		AdminUsername: e.AdminUsername,
+		Action:        e.Action,
+		UserID:        e.UserID,
+		Token:         e.Token,
+		CommentID:     e.CommentID,
+		Reason:        e.Reason,
+		PrevHash:      e.PrevHash,
+		Hash:          e.Hash,
+		PublicKey:     e.PublicKey,
+		Signature:     e.Signature,
+	}
+}
+
+// ProcessAdminLog returns the admin audit log entries that match all of the
+// provided filters, oldest first.
+func (b *backend) ProcessAdminLog(al *v1.AdminLog) (*v1.AdminLogReply, error) {
+	// Validate the user ID filters.
+	for _, id := range []string{al.AdminID, al.UserID} {
+		if id == "" {
+			continue
+		}
+		if _, err := uuid.Parse(id); err != nil {
+			return nil, v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidUUID,
+			}
+		}
+	}
+
+	reply := v1.AdminLogReply{
+		Entries:   make([]v1.AdminLogEntry, 0),
+		PublicKey: b.auditIdentity.Public.String(),
+	}
+	err := b.db.AllAdminLogEntries(func(e *database.AdminLogEntry) {
+		switch {
+		case reply.Next != 0:
+			// The page is full and the start of the next page
+			// is known.
+			return
+		case e.Index < al.Start:
+			return
+		case al.AdminID != "" && e.AdminID.String() != al.AdminID:
+			return
+		case al.UserID != "" && e.UserID != al.UserID:
+			return
+		case al.Token != "" && e.Token != al.Token:
+			return
+		case al.After != 0 && e.Timestamp < al.After:
+			return
+		case al.Before != 0 && e.Timestamp >= al.Before:
+			return
+		}
+
+		if len(reply.Entries) == v1.AdminLogPageSize {
+			reply.Next = e.Index
+			return
+		}
+		reply.Entries = append(reply.Entries,
+			convertWWWAdminLogEntryFromDatabaseAdminLogEntry(*e))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &reply, nil
+}
+
 // ProcessUserPaymentsRescan allows an admin to rescan a user's paywall address
 // to check for any payments that may have been missed by paywall polling.
 func (b *backend) ProcessUserPaymentsRescan(upr v1.UserPaymentsRescan) (*v1.UserPaymentsRescanReply, error) {
@@ -184,8 +327,8 @@ func (b *backend) ProcessUserPaymentsRescan(upr v1.UserPaymentsRescan) (*v1.User
 	}
 
 	// Fetch user payments
-	payments, err := util.FetchTxsForAddressNotBefore(user.NewUserPaywallAddress,
-		user.NewUserPaywallTxNotBefore)
+	payments, err := b.txFetcher.FetchTxsForAddressNotBefore(
+		user.NewUserPaywallAddress, user.NewUserPaywallTxNotBefore)
 	if err != nil {
 		return nil, fmt.Errorf("FetchTxsForAddressNotBefore: %v", err)
 	}
@@ -314,3 +457,139 @@ func (b *backend) ProcessUserPaymentsRescan(upr v1.UserPaymentsRescan) (*v1.User
 		NewCredits: newCreditsWWW,
 	}, nil
 }
+
+// ProcessReportedComments returns the comment moderation queue.  It contains
+// all comments that have unresolved reports, ordered by the number of reports
+// that were filed against them.
+func (b *backend) ProcessReportedComments() (*v1.ReportedCommentsReply, error) {
+	log.Tracef("ProcessReportedComments")
+
+	// Group the reports by comment.
+	reports := make(map[string][]database.CommentReport)
+	err := b.db.AllCommentReports(func(r *database.CommentReport) {
+		key := r.Token + r.CommentID
+		reports[key] = append(reports[key], *r)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	usernames := make(map[uuid.UUID]string)
+	comments := make([]v1.ReportedComment, 0, len(reports))
+	for _, rs := range reports {
+		// Comments that no longer exist or that have been censored
+		// have nothing left to moderate.
+		b.RLock()
+		c, err := b._getInventoryRecordComment(rs[0].Token, rs[0].CommentID)
+		b.RUnlock()
+		if err != nil || c.Censored {
+			continue
+		}
+
+		rc := v1.ReportedComment{
+			Comment: *c,
+			Count:   uint64(len(rs)),
+			Reports: make([]v1.CommentReport, 0, len(rs)),
+		}
+		for _, r := range rs {
+			username, ok := usernames[r.UserID]
+			if !ok {
+				u, err := b.db.UserGetById(r.UserID)
+				if err != nil {
+					return nil, err
+				}
+				if u != nil {
+					username = u.Username
+				}
+				usernames[r.UserID] = username
+			}
+
+			rc.Reports = append(rc.Reports, v1.CommentReport{
+				UserID:    r.UserID.String(),
+				Username:  username,
+				Reason:    r.Reason,
+				Timestamp: r.Timestamp,
+			})
+		}
+		sort.Slice(rc.Reports, func(i, j int) bool {
+			return rc.Reports[i].Timestamp < rc.Reports[j].Timestamp
+		})
+
+		comments = append(comments, rc)
+	}
+
+	// Most reported comments first, oldest reports first for ties.
+	sort.Slice(comments, func(i, j int) bool {
+		if comments[i].Count != comments[j].Count {
+			return comments[i].Count > comments[j].Count
+		}
+		return comments[i].Reports[0].Timestamp <
+			comments[j].Reports[0].Timestamp
+	})
+
+	return &v1.ReportedCommentsReply{
+		Comments: comments,
+	}, nil
+}
+
+// ProcessResolveCommentReport resolves the reports that were filed against a
+// comment by either dismissing them or by censoring the comment.
+func (b *backend) ProcessResolveCommentReport(rcr v1.ResolveCommentReport, adminUser *database.User) (*v1.ResolveCommentReportReply, error) {
+	log.Debugf("ProcessResolveCommentReport: %v: %v", rcr.Token,
+		rcr.CommentID)
+
+	// Ensure there are reports to resolve.
+	reports, err := b.db.CommentReportsGet(rcr.Token, rcr.CommentID)
+	if err != nil {
+		return nil, err
+	}
+	if len(reports) == 0 {
+		return nil, v1.UserError{
+			ErrorCode: v1.ErrorStatusCommentReportNotFound,
+		}
+	}
+
+	var reply v1.ResolveCommentReportReply
+	switch rcr.Action {
+	case v1.CommentReportActionDismiss:
+		err = checkPublicKeyAndSignature(adminUser, rcr.PublicKey,
+			rcr.Signature, rcr.Token, rcr.CommentID, rcr.Reason)
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(rcr.Reason) == "" {
+			return nil, v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			}
+		}
+	case v1.CommentReportActionCensor:
+		ccr, err := b.ProcessCensorComment(v1.CensorComment{
+			Token:     rcr.Token,
+			CommentID: rcr.CommentID,
+			Reason:    rcr.Reason,
+			Signature: rcr.Signature,
+			PublicKey: rcr.PublicKey,
+		}, adminUser)
+		if err != nil {
+			return nil, err
+		}
+		reply.Receipt = ccr.Receipt
+	default:
+		return nil, v1.UserError{
+			ErrorCode: v1.ErrorStatusInvalidReportAction,
+		}
+	}
+
+	err = b.db.CommentReportsDelete(rcr.Token, rcr.CommentID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = b.logAdminCommentAction(adminUser, rcr.Token, rcr.CommentID,
+		v1.CommentReportAction[rcr.Action], rcr.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	return &reply, nil
+}
@@ -1,13 +1,19 @@
 package main
 
 import (
+	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"strings"
 	"testing"
 
+	"github.com/decred/politeia/decredplugin"
+	pd "github.com/decred/politeia/politeiad/api/v1"
 	"github.com/decred/politeia/politeiad/api/v1/identity"
 	www "github.com/decred/politeia/politeiawww/api/v1"
 	"github.com/decred/politeia/politeiawww/database"
+	"github.com/decred/politeia/util"
 )
 
 func createUnverifiedUser(t *testing.T, b *backend) (*database.User, *identity.FullIdentity) {
@@ -60,3 +66,318 @@ func TestProcessManageUser(t *testing.T) {
 
 	b.db.Close()
 }
+
+// Tests reporting a comment and dismissing the reports from the moderation
+// queue.
+func TestProcessCommentReports(t *testing.T) {
+	b := createBackend(t)
+	nu, adminID := createAndVerifyUser(t, b)
+	adminUser, _ := b.db.UserGet(nu.Email)
+	nu, userID := createAndVerifyUser(t, b)
+	user, _ := b.db.UserGet(nu.Email)
+
+	token := strings.Repeat("0", 64)
+	commentID := "1"
+	b.inventory[token] = &inventoryRecord{
+		comments: map[string]www.Comment{
+			commentID: {
+				Token:     token,
+				CommentID: commentID,
+				Comment:   "unit test",
+			},
+		},
+	}
+
+	// Report the comment
+	reason := "spam"
+	sig := userID.SignMessage([]byte(token + commentID + reason))
+	rc := www.ReportComment{
+		Token:     token,
+		CommentID: commentID,
+		Reason:    reason,
+		Signature: hex.EncodeToString(sig[:]),
+		PublicKey: hex.EncodeToString(userID.Public.Key[:]),
+	}
+	_, err := b.ProcessReportComment(rc, user)
+	assertSuccess(t, err)
+
+	// A user may only report a comment once
+	_, err = b.ProcessReportComment(rc, user)
+	assertError(t, err, www.ErrorStatusCommentAlreadyReported)
+
+	// The comment must show up in the moderation queue
+	rcr, err := b.ProcessReportedComments()
+	assertSuccess(t, err)
+	if len(rcr.Comments) != 1 || rcr.Comments[0].Count != 1 {
+		t.Fatalf("unexpected moderation queue: %v", rcr.Comments)
+	}
+	if rcr.Comments[0].Reports[0].Reason != reason {
+		t.Fatalf("expected reason %v, was %v", reason,
+			rcr.Comments[0].Reports[0].Reason)
+	}
+
+	// Dismiss the reports
+	reason = "not spam"
+	sig = adminID.SignMessage([]byte(token + commentID + reason))
+	resolve := www.ResolveCommentReport{
+		Token:     token,
+		CommentID: commentID,
+		Action:    www.CommentReportActionDismiss,
+		Reason:    reason,
+		Signature: hex.EncodeToString(sig[:]),
+		PublicKey: hex.EncodeToString(adminID.Public.Key[:]),
+	}
+	_, err = b.ProcessResolveCommentReport(resolve, adminUser)
+	assertSuccess(t, err)
+
+	rcr, err = b.ProcessReportedComments()
+	assertSuccess(t, err)
+	if len(rcr.Comments) != 0 {
+		t.Fatalf("expected empty moderation queue, got %v", rcr.Comments)
+	}
+
+	// Resolving again must fail since there are no reports left
+	_, err = b.ProcessResolveCommentReport(resolve, adminUser)
+	assertError(t, err, www.ErrorStatusCommentReportNotFound)
+
+	// Report the comment again and censor it.  Censoring goes through the
+	// decred plugin.
+	server, _ := setupTestPoliteiad(t, b, func(pc pd.PluginCommand) (string, error) {
+		switch pc.Command {
+		case decredplugin.CmdBestBlock:
+			return "100", nil
+		case decredplugin.CmdCensorComment:
+			cc, err := decredplugin.DecodeCensorComment([]byte(pc.Payload))
+			if err != nil {
+				return "", err
+			}
+			payload, err := decredplugin.EncodeCensorCommentReply(
+				decredplugin.CensorCommentReply{
+					Receipt: "receipt " + cc.CommentID,
+				})
+			return string(payload), err
+		}
+		return "", fmt.Errorf("unexpected command %v", pc.Command)
+	})
+	defer server.Close()
+
+	_, err = b.ProcessReportComment(rc, user)
+	assertSuccess(t, err)
+	reason = "spam"
+	sig = adminID.SignMessage([]byte(token + commentID + reason))
+	resolve.Action = www.CommentReportActionCensor
+	resolve.Reason = reason
+	resolve.Signature = hex.EncodeToString(sig[:])
+	rrr, err := b.ProcessResolveCommentReport(resolve, adminUser)
+	assertSuccess(t, err)
+	if rrr.Receipt != "receipt "+commentID {
+		t.Fatalf("unexpected receipt %v", rrr.Receipt)
+	}
+
+	c := b.inventory[token].comments[commentID]
+	if !c.Censored || c.Comment != "" {
+		t.Fatalf("expected censored comment, got %v", c)
+	}
+	rcr, err = b.ProcessReportedComments()
+	assertSuccess(t, err)
+	if len(rcr.Comments) != 0 {
+		t.Fatalf("expected empty moderation queue, got %v", rcr.Comments)
+	}
+
+	// Censored comments can't be reported again.
+	_, err = b.ProcessReportComment(rc, user)
+	assertError(t, err, www.ErrorStatusCommentNotFound)
+
+	b.db.Close()
+}
+
+// Tests granting and revoking user roles.
+func TestProcessManageUserRoles(t *testing.T) {
+	b := createBackend(t)
+	nu, _ := createAndVerifyUser(t, b)
+	adminUser, _ := b.db.UserGet(nu.Email)
+	adminUser.Admin = true
+	err := b.db.UserUpdate(*adminUser)
+	assertSuccess(t, err)
+	nu, _ = createAndVerifyUser(t, b)
+	user, _ := b.db.UserGet(nu.Email)
+
+	// The legacy admin flag implies all roles
+	for role := range www.UserRole {
+		if !userHasRole(adminUser, role) {
+			t.Fatalf("expected admin to hold role %v",
+				www.UserRole[role])
+		}
+		if userHasRole(user, role) {
+			t.Fatalf("unexpected role %v", www.UserRole[role])
+		}
+	}
+
+	// Invalid roles are rejected
+	mu := www.ManageUser{
+		UserID: user.ID.String(),
+		Action: www.UserManageGrantRole,
+		Reason: "unit test",
+		Role:   www.UserRoleModerator | www.UserRoleReviewer,
+	}
+	_, err = b.ProcessManageUser(&mu, adminUser)
+	assertError(t, err, www.ErrorStatusInvalidUserRole)
+
+	// Grant the moderator role
+	mu.Role = www.UserRoleModerator
+	_, err = b.ProcessManageUser(&mu, adminUser)
+	assertSuccess(t, err)
+	user, _ = b.db.UserGet(nu.Email)
+	if !userHasRole(user, www.UserRoleModerator) ||
+		userHasRole(user, www.UserRoleVoteAdmin) {
+		t.Fatalf("unexpected roles %v", userRoles(user))
+	}
+	lr, err := b.CreateLoginReply(user, 0)
+	assertSuccess(t, err)
+	if lr.IsAdmin || lr.Roles != uint64(www.UserRoleModerator) {
+		t.Fatalf("unexpected login reply roles %v", lr.Roles)
+	}
+
+	// Revoke the moderator role
+	mu.Action = www.UserManageRevokeRole
+	_, err = b.ProcessManageUser(&mu, adminUser)
+	assertSuccess(t, err)
+	user, _ = b.db.UserGet(nu.Email)
+	if userRoles(user) != 0 {
+		t.Fatalf("unexpected roles %v", userRoles(user))
+	}
+
+	// Super admins can't revoke their own super admin role
+	mu.UserID = adminUser.ID.String()
+	mu.Role = www.UserRoleSuperAdmin
+	_, err = b.ProcessManageUser(&mu, adminUser)
+	assertError(t, err, www.ErrorStatusUserActionNotAllowed)
+
+	// Revoking the super admin role clears the legacy admin flag
+	mu.UserID = user.ID.String()
+	mu.Action = www.UserManageGrantRole
+	_, err = b.ProcessManageUser(&mu, adminUser)
+	assertSuccess(t, err)
+	user, _ = b.db.UserGet(nu.Email)
+	mu.UserID = adminUser.ID.String()
+	mu.Action = www.UserManageRevokeRole
+	_, err = b.ProcessManageUser(&mu, user)
+	assertSuccess(t, err)
+	adminUser, _ = b.db.UserGet(adminUser.Email)
+	if adminUser.Admin || userRoles(adminUser) != 0 {
+		t.Fatalf("unexpected roles %v", userRoles(adminUser))
+	}
+
+	b.db.Close()
+}
+
+// Tests that admin actions are appended to the hash chained audit log and
+// that the log can be queried and verified.
+func TestAdminLog(t *testing.T) {
+	b := createBackend(t)
+	nu, _ := createAndVerifyUser(t, b)
+	adminUser, _ := b.db.UserGet(nu.Email)
+	nu, _ = createAndVerifyUser(t, b)
+	user, _ := b.db.UserGet(nu.Email)
+
+	token := strings.Repeat("a", 64)
+	err := b.logAdminUserAction(adminUser, user,
+		www.UserManageClearUserPaywall, 0, "unit test")
+	assertSuccess(t, err)
+	err = b.logAdminProposalAction(adminUser, token, "censor", "spam")
+	assertSuccess(t, err)
+	err = b.logAdminUserAction(adminUser, user,
+		www.UserManageDeactivate, 0, "unit test")
+	assertSuccess(t, err)
+
+	// The chain links every entry to the previous one.
+	var (
+		entries []database.AdminLogEntry
+		prev    *database.AdminLogEntry
+	)
+	err = b.db.AllAdminLogEntries(func(e *database.AdminLogEntry) {
+		entries = append(entries, *e)
+	})
+	assertSuccess(t, err)
+	if len(entries) != 3 {
+		t.Fatalf("unexpected entries %v", entries)
+	}
+	for i := range entries {
+		err = database.VerifyAdminLogEntry(&entries[i], prev)
+		assertSuccess(t, err)
+		prev = &entries[i]
+	}
+
+	// Modified entries are detected.
+	tampered := entries[1]
+	tampered.Reason = "tampered"
+	err = database.VerifyAdminLogEntry(&tampered, &entries[0])
+	if err == nil {
+		t.Fatalf("expected modified entry to be rejected")
+	}
+	tampered.Hash, err = database.AdminLogEntryHash(tampered)
+	assertSuccess(t, err)
+	err = database.VerifyAdminLogEntry(&tampered, &entries[0])
+	if err == nil {
+		t.Fatalf("expected entry with invalid signature to be rejected")
+	}
+	err = database.VerifyAdminLogEntry(&entries[2], &entries[0])
+	if err == nil {
+		t.Fatalf("expected removed entry to be detected")
+	}
+
+	// Query the log by target user and verify the returned entries the way
+	// a client would.
+	alr, err := b.ProcessAdminLog(&www.AdminLog{
+		UserID: user.ID.String(),
+	})
+	assertSuccess(t, err)
+	if len(alr.Entries) != 2 || alr.Next != 0 {
+		t.Fatalf("unexpected reply %v", alr)
+	}
+	for _, e := range alr.Entries {
+		if e.UserID != user.ID.String() ||
+			e.AdminID != adminUser.ID.String() {
+			t.Fatalf("unexpected entry %v", e)
+		}
+		hash, signature := e.Hash, e.Signature
+		e.Hash, e.Signature = "", ""
+		payload, err := json.Marshal(e)
+		assertSuccess(t, err)
+		h := sha256.Sum256(payload)
+		if hex.EncodeToString(h[:]) != hash {
+			t.Fatalf("entry %v: hash mismatch", e.Index)
+		}
+		id, err := util.IdentityFromString(alr.PublicKey)
+		assertSuccess(t, err)
+		sig, err := util.ConvertSignature(signature)
+		assertSuccess(t, err)
+		if !id.VerifyMessage([]byte(hash), sig) {
+			t.Fatalf("entry %v: invalid signature", e.Index)
+		}
+	}
+
+	alr, err = b.ProcessAdminLog(&www.AdminLog{
+		Token: token,
+	})
+	assertSuccess(t, err)
+	if len(alr.Entries) != 1 || alr.Entries[0].Index != 1 {
+		t.Fatalf("unexpected reply %v", alr)
+	}
+
+	alr, err = b.ProcessAdminLog(&www.AdminLog{
+		Start:  2,
+		Before: entries[2].Timestamp + 1,
+	})
+	assertSuccess(t, err)
+	if len(alr.Entries) != 1 || alr.Entries[0].Index != 2 {
+		t.Fatalf("unexpected reply %v", alr)
+	}
+
+	_, err = b.ProcessAdminLog(&www.AdminLog{
+		AdminID: "invalid",
+	})
+	assertError(t, err, www.ErrorStatusInvalidUUID)
+
+	b.db.Close()
+}
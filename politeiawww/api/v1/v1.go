@@ -2,6 +2,9 @@ package v1
 
 import (
 	"fmt"
+
+	dcrtime "github.com/decred/dcrtime/api/v1"
+	"github.com/decred/dcrtime/merkle"
 )
 
 type ErrorStatusT int
@@ -10,12 +13,23 @@ type PropStatusT int
 type PropVoteStatusT int
 type UserManageActionT int
 type EmailNotificationT int
+type CommentReportActionT int
+type UserRoleT uint64
+type APIKeyScopeT uint64
+type WebhookEventT uint64
 
 const (
 	PoliteiaWWWAPIVersion = 1 // API version this backend understands
 
-	CsrfToken = "X-CSRF-Token"    // CSRF token for replies
-	Forward   = "X-Forwarded-For" // Proxy header
+	CsrfToken    = "X-CSRF-Token"    // CSRF token for replies
+	Forward      = "X-Forwarded-For" // Proxy header
+	APIKeyHeader = "X-API-Key"       // API key for scripted access
+
+	// Webhook request headers.  The signature is the hex encoded
+	// HMAC-SHA256 of the request body keyed with the webhook secret.
+	WebhookEventHeader     = "X-Politeia-Event"     // Event name
+	WebhookDeliveryHeader  = "X-Politeia-Delivery"  // Delivery id
+	WebhookSignatureHeader = "X-Politeia-Signature" // Body signature
 
 	RouteUserMe                   = "/user/me"
 	RouteNewUser                  = "/user/new"
@@ -33,8 +47,24 @@ const (
 	RouteUserDetails              = "/user/{userid:[0-9a-zA-Z-]{36}}"
 	RouteManageUser               = "/user/manage"
 	RouteEditUser                 = "/user/edit"
+	RouteSetTOTP                  = "/user/totp"
+	RouteVerifyTOTP               = "/user/totp/verify"
+	RouteAPIKeys                  = "/user/apikeys"
+	RouteNewAPIKey                = "/user/apikeys/new"
+	RouteRevokeAPIKey             = "/user/apikeys/revoke"
+	RouteSessions                 = "/user/sessions"
+	RouteRevokeSessions           = "/user/sessions/revoke"
 	RouteUsers                    = "/users"
+	RouteAdminLog                 = "/admin/log"
+	RouteEmailOutbox              = "/admin/emails"
+	RouteEmailTemplates           = "/admin/emailtemplates"
+	RouteWebhooks                 = "/admin/webhooks"
+	RouteNewWebhook               = "/admin/webhooks/new"
+	RouteDeleteWebhook            = "/admin/webhooks/delete"
+	RouteWebhookDeliveries        = "/admin/webhooks/deliveries"
 	RouteLogin                    = "/login"
+	RouteLoginChallenge           = "/login/challenge"
+	RouteLoginSignature           = "/login/signature"
 	RouteLogout                   = "/logout"
 	RouteSecret                   = "/secret"
 	RouteProposalPaywallDetails   = "/proposals/paywall"
@@ -50,6 +80,9 @@ const (
 	RouteNewComment               = "/comments/new"
 	RouteLikeComment              = "/comments/like"
 	RouteCensorComment            = "/comments/censor"
+	RouteReportComment            = "/comments/report"
+	RouteReportedComments         = "/comments/reported"
+	RouteResolveCommentReport     = "/comments/report/resolve"
 	RouteCommentsGet              = "/proposals/{token:[A-z0-9]{64}}/comments"
 	RouteAuthorizeVote            = "/proposals/authorizevote"
 	RouteStartVote                = "/proposals/startvote"
@@ -60,6 +93,13 @@ const (
 	RouteAllVoteStatus            = "/proposals/votestatus"
 	RouteVoteStatus               = "/proposals/{token:[A-z0-9]{64}}/votestatus"
 	RoutePropsStats               = "/proposals/stats"
+	RouteProposalBundle           = "/proposals/{token:[A-z0-9]{64}}/bundle"
+	RouteVoteInclusionProof       = "/proposals/{token:[A-z0-9]{64}}/votes/{ticket:[A-z0-9]{64}}/proof"
+	RouteCensorshipReport         = "/censorship"
+	RouteProposalsFeed            = "/feeds/proposals"
+	RouteVotingFeed               = "/feeds/voting"
+	RouteVoteResultsFeed          = "/feeds/results"
+	RouteCommentsFeed             = "/feeds/proposals/{token:[A-z0-9]{64}}/comments"
 	RouteUnauthenticatedWebSocket = "/ws"
 	RouteAuthenticatedWebSocket   = "/aws"
 
@@ -70,6 +110,18 @@ const (
 	// verification token expires
 	VerificationExpiryHours = 24
 
+	// LoginChallengeSize is the size of a login challenge in bytes
+	LoginChallengeSize = 32
+
+	// LoginChallengeExpirySeconds is the number of seconds before a
+	// login challenge expires
+	LoginChallengeExpirySeconds = 120
+
+	// LoginChallengePrefix is prepended to a login challenge before it
+	// is signed.  This prevents a login challenge from being used to
+	// obtain a signature that is valid for any other message.
+	LoginChallengePrefix = "politeia login:"
+
 	// PolicyMaxImages is the maximum number of images accepted
 	// when creating a new proposal
 	PolicyMaxImages = 5
@@ -114,6 +166,31 @@ const (
 	// for the routes that return lists of users
 	UserListPageSize = 20
 
+	// AdminLogPageSize is the maximum number of admin log entries
+	// returned by the admin log route
+	AdminLogPageSize = 100
+
+	// CensorshipReportPageSize is the maximum number of censorship
+	// actions returned by the censorship report route.  It does not
+	// apply to CSV reports.
+	CensorshipReportPageSize = 100
+
+	// Censorship report formats
+	CensorshipReportFormatJSON = "json"
+	CensorshipReportFormatCSV  = "csv"
+
+	// FeedPageSize is the maximum number of entries returned by the feed
+	// routes
+	FeedPageSize = 50
+
+	// Feed formats
+	FeedFormatAtom = "atom"
+	FeedFormatRSS  = "rss"
+
+	// Censorship action types
+	CensorshipActionProposal = "proposal"
+	CensorshipActionComment  = "comment"
+
 	// Error status codes
 	ErrorStatusInvalid                     ErrorStatusT = 0
 	ErrorStatusInvalidEmailOrPassword      ErrorStatusT = 1
@@ -173,6 +250,23 @@ const (
 	ErrorStatusEmailNotVerified            ErrorStatusT = 55
 	ErrorStatusInvalidPropVersion          ErrorStatusT = 56
 	ErrorStatusInvalidUUID                 ErrorStatusT = 57
+	ErrorStatusReportReasonCannotBeBlank   ErrorStatusT = 58
+	ErrorStatusCommentAlreadyReported      ErrorStatusT = 59
+	ErrorStatusCommentReportNotFound       ErrorStatusT = 60
+	ErrorStatusInvalidReportAction         ErrorStatusT = 61
+	ErrorStatusVoteNotFound                ErrorStatusT = 62
+	ErrorStatusInvalidUserRole             ErrorStatusT = 63
+	ErrorStatusTOTPRequired                ErrorStatusT = 64
+	ErrorStatusInvalidTOTPCode             ErrorStatusT = 65
+	ErrorStatusTOTPAlreadyEnabled          ErrorStatusT = 66
+	ErrorStatusTOTPNotSet                  ErrorStatusT = 67
+	ErrorStatusInvalidAPIKey               ErrorStatusT = 68
+	ErrorStatusAPIKeyScopeNotAllowed       ErrorStatusT = 69
+	ErrorStatusAPIKeyNotFound              ErrorStatusT = 70
+	ErrorStatusInvalidLoginChallenge       ErrorStatusT = 71
+	ErrorStatusSessionNotFound             ErrorStatusT = 72
+	ErrorStatusWebhookNotFound             ErrorStatusT = 73
+	ErrorStatusTooManyLoginChallenges      ErrorStatusT = 74
 
 	// Proposal state codes
 	//
@@ -216,6 +310,14 @@ const (
 	UserManageUnlock                          UserManageActionT = 5
 	UserManageDeactivate                      UserManageActionT = 6
 	UserManageReactivate                      UserManageActionT = 7
+	UserManageGrantRole                       UserManageActionT = 8
+	UserManageRevokeRole                      UserManageActionT = 9
+	UserManageResetTOTP                       UserManageActionT = 10
+
+	// Comment report resolve actions
+	CommentReportActionInvalid CommentReportActionT = 0 // Invalid action
+	CommentReportActionDismiss CommentReportActionT = 1 // Dismiss reports
+	CommentReportActionCensor  CommentReportActionT = 2 // Censor comment
 
 	// Authorize vote actions
 	AuthVoteActionAuthorize = "authorize" // Authorize a proposal vote
@@ -231,6 +333,38 @@ const (
 	NotificationEmailAdminProposalVoteAuthorized EmailNotificationT = 1 << 6
 	NotificationEmailCommentOnMyProposal         EmailNotificationT = 1 << 7
 	NotificationEmailCommentOnMyComment          EmailNotificationT = 1 << 8
+
+	// NotificationEmailDigestable contains the email notification types
+	// that can be delivered in a daily or weekly digest instead of one
+	// email per event.
+	NotificationEmailDigestable = NotificationEmailMyProposalVoteStarted |
+		NotificationEmailRegularProposalVetted |
+		NotificationEmailRegularProposalEdited |
+		NotificationEmailRegularProposalVoteStarted |
+		NotificationEmailCommentOnMyProposal |
+		NotificationEmailCommentOnMyComment
+
+	// User roles.  A user may hold any combination of roles.  The super
+	// admin role implies all other roles.
+	UserRoleModerator  UserRoleT = 1 << 0 // Censor and resolve comments
+	UserRoleReviewer   UserRoleT = 1 << 1 // Review and vet proposals
+	UserRoleVoteAdmin  UserRoleT = 1 << 2 // Start proposal votes
+	UserRoleSuperAdmin UserRoleT = 1 << 3 // Manage users and their roles
+
+	// API key scopes.  Scopes only grant access to routes that the owner
+	// of the key is allowed to use.
+	APIKeyScopeRead      APIKeyScopeT = 1 << 0 // Read only routes
+	APIKeyScopeComment   APIKeyScopeT = 1 << 1 // Submit and vote on comments
+	APIKeyScopeVoteAdmin APIKeyScopeT = 1 << 2 // Start proposal votes
+
+	// Webhook events.  A webhook may subscribe to any combination of
+	// events.
+	WebhookEventProposalSubmitted    WebhookEventT = 1 << 0 // New unvetted proposal
+	WebhookEventProposalStatusChange WebhookEventT = 1 << 1 // Proposal status set by an admin
+	WebhookEventProposalEdited       WebhookEventT = 1 << 2 // Public proposal edited
+	WebhookEventVoteAuthorized       WebhookEventT = 1 << 3 // Vote authorized or revoked by the author
+	WebhookEventVoteStarted          WebhookEventT = 1 << 4 // Proposal vote started
+	WebhookEventComment              WebhookEventT = 1 << 5 // New comment on a proposal
 )
 
 var (
@@ -312,6 +446,23 @@ var (
 		ErrorStatusEmailNotVerified:            "email address is not verified",
 		ErrorStatusInvalidPropVersion:          "invalid proposal version",
 		ErrorStatusInvalidUUID:                 "invalid user UUID",
+		ErrorStatusReportReasonCannotBeBlank:   "report reason cannot be blank",
+		ErrorStatusCommentAlreadyReported:      "comment has already been reported by user",
+		ErrorStatusCommentReportNotFound:       "comment report not found",
+		ErrorStatusInvalidReportAction:         "invalid comment report action",
+		ErrorStatusVoteNotFound:                "vote not found",
+		ErrorStatusInvalidUserRole:             "invalid user role",
+		ErrorStatusTOTPRequired:                "two-factor authentication required",
+		ErrorStatusInvalidTOTPCode:             "invalid two-factor authentication code",
+		ErrorStatusTOTPAlreadyEnabled:          "two-factor authentication already enabled",
+		ErrorStatusTOTPNotSet:                  "two-factor authentication has not been set",
+		ErrorStatusInvalidAPIKey:               "invalid API key",
+		ErrorStatusAPIKeyScopeNotAllowed:       "API key scope does not allow this action",
+		ErrorStatusAPIKeyNotFound:              "API key not found",
+		ErrorStatusInvalidLoginChallenge:       "invalid or expired login challenge",
+		ErrorStatusSessionNotFound:             "session not found",
+		ErrorStatusWebhookNotFound:             "webhook not found",
+		ErrorStatusTooManyLoginChallenges:      "too many outstanding login challenges",
 	}
 
 	// PropStatus converts propsal status codes to human readable text
@@ -344,6 +495,43 @@ var (
 		UserManageUnlock:                          "unlock user",
 		UserManageDeactivate:                      "deactivate user",
 		UserManageReactivate:                      "reactivate user",
+		UserManageGrantRole:                       "grant user role",
+		UserManageRevokeRole:                      "revoke user role",
+		UserManageResetTOTP:                       "reset two-factor authentication",
+	}
+
+	// APIKeyScope converts API key scopes to human readable text
+	APIKeyScope = map[APIKeyScopeT]string{
+		APIKeyScopeRead:      "read",
+		APIKeyScopeComment:   "comment",
+		APIKeyScopeVoteAdmin: "voteadmin",
+	}
+
+	// WebhookEvent converts webhook events to the event names that are
+	// sent in the WebhookEventHeader header and the payload
+	WebhookEvent = map[WebhookEventT]string{
+		WebhookEventProposalSubmitted:    "proposal.submitted",
+		WebhookEventProposalStatusChange: "proposal.statuschange",
+		WebhookEventProposalEdited:       "proposal.edited",
+		WebhookEventVoteAuthorized:       "vote.authorized",
+		WebhookEventVoteStarted:          "vote.started",
+		WebhookEventComment:              "comment.new",
+	}
+
+	// UserRole converts user roles to human readable text
+	UserRole = map[UserRoleT]string{
+		UserRoleModerator:  "moderator",
+		UserRoleReviewer:   "reviewer",
+		UserRoleVoteAdmin:  "voteadmin",
+		UserRoleSuperAdmin: "superadmin",
+	}
+
+	// CommentReportAction converts comment report actions to human
+	// readable text
+	CommentReportAction = map[CommentReportActionT]string{
+		CommentReportActionInvalid: "invalid action",
+		CommentReportActionDismiss: "dismiss comment reports",
+		CommentReportActionCensor:  "censor reported comment",
 	}
 )
 
@@ -431,8 +619,10 @@ func (e PDError) Error() string {
 // PDErrorReply is an error reply returned from Politeiad whenever an
 // error occurs.
 type PDErrorReply struct {
-	ErrorCode    int
-	ErrorContext []string
+	ErrorCode       int
+	ErrorContext    []string
+	PluginID        string // Plugin that vetoed the request
+	PluginErrorCode int    // Plugin specific error code
 }
 
 // ErrorReply are replies that the server returns a when it encounters an
@@ -631,16 +821,210 @@ type AbridgedUser struct {
 	Username string `json:"username"`
 }
 
+// AdminLog retrieves the entries of the admin audit log that match all of the
+// provided filters, oldest first.  At most AdminLogPageSize entries are
+// returned; use Start to retrieve the next page.
+type AdminLog struct {
+	AdminID string `schema:"adminid"` // Only entries of this admin
+	UserID  string `schema:"userid"`  // Only entries that target this user
+	Token   string `schema:"token"`   // Only entries that target this proposal
+	After   int64  `schema:"after"`   // Only entries at or after this UNIX timestamp
+	Before  int64  `schema:"before"`  // Only entries before this UNIX timestamp
+	Start   uint64 `schema:"start"`   // Index of the first entry to consider
+}
+
+// AdminLogEntry is an entry of the admin audit log.  Hash is the SHA256
+// digest of the JSON encoded entry with empty Hash and Signature fields and
+// Signature is the signature of Hash by the server audit identity.  Every
+// entry includes the hash of the previous entry.
+type AdminLogEntry struct {
+	Index         uint64 `json:"index"`               // Position in the log
+	Timestamp     int64  `json:"timestamp"`           // UNIX timestamp of the action
+	AdminID       string `json:"adminid"`             // ID of the admin
+	AdminUsername string `json:"adminusername"`       // Username of the admin
+	Action        string `json:"action"`              // Description of the action
+	UserID        string `json:"userid,omitempty"`    // Target user ID
+	Token         string `json:"token,omitempty"`     // Target proposal censorship token
+	CommentID     string `json:"commentid,omitempty"` // Target comment ID
+	Reason        string `json:"reason"`              // Reason given by the admin
+	PrevHash      string `json:"prevhash"`            // Hash of the previous entry
+	Hash          string `json:"hash"`                // Hash of this entry
+	PublicKey     string `json:"publickey"`           // Server audit public key
+	Signature     string `json:"signature"`           // Signature of Hash
+}
+
+// AdminLogReply returns the matching admin log entries.  Next is the index
+// that should be used as Start to retrieve the next page; it is zero once the
+// end of the log has been reached.
+type AdminLogReply struct {
+	Entries   []AdminLogEntry `json:"entries"`   // Matching entries
+	Next      uint64          `json:"next"`      // Start of the next page
+	PublicKey string          `json:"publickey"` // Server audit public key
+}
+
+// EmailOutbox retrieves the emails that have not been delivered yet.  Emails
+// are retried with an exponential backoff and dead lettered once delivery has
+// been abandoned.
+type EmailOutbox struct {
+	DeadLetter bool `schema:"deadletter"` // Only return dead lettered emails
+}
+
+// OutboxEmail is an email that has not been delivered yet.
+type OutboxEmail struct {
+	ID          string `json:"id"`          // Unique email id
+	Subject     string `json:"subject"`     // Email subject
+	Recipients  int    `json:"recipients"`  // Number of recipients
+	CreatedAt   int64  `json:"createdat"`   // UNIX timestamp of when the email was queued
+	Attempts    uint64 `json:"attempts"`    // Number of failed delivery attempts
+	NextAttempt int64  `json:"nextattempt"` // UNIX timestamp of the next delivery attempt
+	LastError   string `json:"lasterror"`   // Error of the last failed delivery attempt
+	DeadLetter  bool   `json:"deadletter"`  // Whether delivery has been abandoned
+}
+
+// EmailOutboxReply returns the undelivered emails, oldest first.
+type EmailOutboxReply struct {
+	Emails []OutboxEmail `json:"emails"`
+}
+
+// EmailTemplates renders a preview of every email template with sample data
+// in the provided locale.  The default locale is used if no locale is
+// provided.
+type EmailTemplates struct {
+	Locale string `schema:"locale"` // Locale to render the templates in
+}
+
+// EmailTemplatePreview is an email template rendered with sample data.
+type EmailTemplatePreview struct {
+	Name    string `json:"name"`           // Template name
+	Subject string `json:"subject"`        // Email subject
+	Text    string `json:"text"`           // Plain text body
+	HTML    string `json:"html,omitempty"` // HTML body, if customized
+}
+
+// EmailTemplatesReply returns the rendered email templates and the locales
+// that are available.
+type EmailTemplatesReply struct {
+	Locale    string                 `json:"locale"`    // Locale of the previews
+	Locales   []string               `json:"locales"`   // Available locales
+	Templates []EmailTemplatePreview `json:"templates"` // Rendered templates
+}
+
+// Webhook is an URL that is notified of proposal and vote events.  The secret
+// of a webhook is never returned after it has been created.
+type Webhook struct {
+	ID        string `json:"id"`        // Unique webhook id
+	URL       string `json:"url"`       // URL the events are posted to
+	Events    uint64 `json:"events"`    // Events the webhook subscribes to
+	CreatedAt int64  `json:"createdat"` // UNIX timestamp of creation
+	CreatedBy string `json:"createdby"` // ID of the admin that created the webhook
+}
+
+// NewWebhook registers a new webhook.  Events are delivered as JSON encoded
+// WebhookPayload POST requests signed with the secret, see
+// WebhookSignatureHeader.  A random secret is generated if none is provided.
+type NewWebhook struct {
+	URL    string `json:"url"`    // HTTP or HTTPS URL
+	Secret string `json:"secret"` // HMAC secret, optional
+	Events uint64 `json:"events"` // Events to subscribe to
+}
+
+// NewWebhookReply returns the new webhook.  The secret is only returned once.
+type NewWebhookReply struct {
+	Webhook Webhook `json:"webhook"`
+	Secret  string  `json:"secret"`
+}
+
+// Webhooks requests all registered webhooks.
+type Webhooks struct{}
+
+// WebhooksReply returns the registered webhooks.
+type WebhooksReply struct {
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+// DeleteWebhook deletes a webhook and its delivery log.
+type DeleteWebhook struct {
+	ID string `json:"id"` // Webhook id
+}
+
+// DeleteWebhookReply is the reply to the DeleteWebhook command.
+type DeleteWebhookReply struct{}
+
+// WebhookDeliveries requests the delivery log of a webhook.
+type WebhookDeliveries struct {
+	ID string `schema:"id"` // Webhook id
+}
+
+// WebhookDelivery is an entry of the delivery log of a webhook.  Failed
+// deliveries are retried with an exponential backoff until they are
+// abandoned.
+type WebhookDelivery struct {
+	ID          string `json:"id"`          // Unique delivery id
+	Event       string `json:"event"`       // Event name
+	CreatedAt   int64  `json:"createdat"`   // UNIX timestamp of the event
+	Attempts    uint64 `json:"attempts"`    // Number of delivery attempts
+	NextAttempt int64  `json:"nextattempt"` // UNIX timestamp of the next attempt, 0 if done
+	StatusCode  int    `json:"statuscode"`  // HTTP status code of the last attempt
+	LastError   string `json:"lasterror"`   // Error of the last failed attempt
+	Delivered   bool   `json:"delivered"`   // Whether the event was delivered
+	Abandoned   bool   `json:"abandoned"`   // Whether delivery has been abandoned
+}
+
+// WebhookDeliveriesReply returns the delivery log of a webhook, newest first.
+type WebhookDeliveriesReply struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+}
+
+// WebhookPayload is the body of a webhook request.  Fields that do not apply
+// to the event are omitted.  The names and authors of proposals are only
+// included once the proposal is public.
+type WebhookPayload struct {
+	ID        string      `json:"id"`                  // Unique delivery id
+	Event     string      `json:"event"`               // Event name
+	Timestamp int64       `json:"timestamp"`           // UNIX timestamp of the event
+	Token     string      `json:"token"`               // Censorship token of the proposal
+	Name      string      `json:"name,omitempty"`      // Proposal name
+	Username  string      `json:"username,omitempty"`  // User that caused the event
+	Status    PropStatusT `json:"status,omitempty"`    // New proposal status
+	Version   string      `json:"version,omitempty"`   // Proposal version
+	Action    string      `json:"action,omitempty"`    // Vote authorization action
+	CommentID string      `json:"commentid,omitempty"` // Comment ID
+	ParentID  string      `json:"parentid,omitempty"`  // Parent comment ID
+}
+
 // Login attempts to login the user.  Note that by necessity the password
 // travels in the clear.
 type Login struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	Code     string `json:"code,omitempty"` // TOTP or recovery code
+}
+
+// LoginChallenge requests a challenge that must be signed with the active
+// identity of the user in order to login without a password.
+type LoginChallenge struct {
+	PublicKey string `json:"publickey"` // Active public key of the user
+}
+
+// LoginChallengeReply returns a single use login challenge.
+type LoginChallengeReply struct {
+	Challenge string `json:"challenge"` // Hex encoded random challenge
+	Expiry    int64  `json:"expiry"`    // Unix timestamp of challenge expiry
+}
+
+// LoginSignature attempts to login the user with a signature of a login
+// challenge.  The signature is of LoginChallengePrefix+Challenge.
+type LoginSignature struct {
+	PublicKey string `json:"publickey"`      // Active public key of the user
+	Challenge string `json:"challenge"`      // Challenge from LoginChallengeReply
+	Signature string `json:"signature"`      // Signature of the challenge
+	Code      string `json:"code,omitempty"` // TOTP or recovery code
 }
 
 // LoginReply is used to reply to the Login command.
 type LoginReply struct {
 	IsAdmin            bool   `json:"isadmin"`            // Set if user is an admin
+	Roles              uint64 `json:"roles"`              // Effective user roles
 	UserID             string `json:"userid"`             // User id
 	Email              string `json:"email"`              // User email
 	Username           string `json:"username"`           // Username
@@ -652,6 +1036,8 @@ type LoginReply struct {
 	ProposalCredits    uint64 `json:"proposalcredits"`    // Number of the proposal credits the user has available to spend
 	LastLoginTime      int64  `json:"lastlogintime"`      // Unix timestamp of last login date
 	SessionMaxAge      int64  `json:"sessionmaxage"`      // Unix timestamp of session max age
+	TOTPEnabled        bool   `json:"totpenabled"`        // Set if two-factor authentication is enabled
+	TOTPRequired       bool   `json:"totprequired"`       // Set if the user must enable two-factor authentication
 }
 
 //Logout attempts to log the user out.
@@ -948,6 +1334,10 @@ type LikeComment struct {
 	Action    string `json:"action"`    // Up or downvote (1, -1)
 	Signature string `json:"signature"` // Client Signature of Token+CommentID+Action
 	PublicKey string `json:"publickey"` // Pubkey used for Signature
+
+	// Only used on Get/Reply
+	Receipt   string `json:"receipt,omitempty"`   // Server signature of client signature
+	Timestamp int64  `json:"timestamp,omitempty"` // Received UNIX timestamp
 }
 
 // LikeCommentReply returns the current up/down vote result.
@@ -967,6 +1357,10 @@ type CensorComment struct {
 	Reason    string `json:"reason"`    // Reason the comment was censored
 	Signature string `json:"signature"` // Client signature of Token+CommentID+Reason
 	PublicKey string `json:"publickey"` // Pubkey used for signature
+
+	// Only used on Get/Reply
+	Receipt   string `json:"receipt,omitempty"`   // Server signature of client signature
+	Timestamp int64  `json:"timestamp,omitempty"` // Received UNIX timestamp
 }
 
 // CensorCommentReply returns a receipt if the comment was successfully
@@ -975,6 +1369,141 @@ type CensorCommentReply struct {
 	Receipt string `json:"receipt"` // Server signature of client signature
 }
 
+// ReportComment allows a user to flag a comment for review by an admin.
+type ReportComment struct {
+	Token     string `json:"token"`     // Proposal censorship token
+	CommentID string `json:"commentid"` // Comment ID
+	Reason    string `json:"reason"`    // Reason the comment was reported
+	Signature string `json:"signature"` // Client signature of Token+CommentID+Reason
+	PublicKey string `json:"publickey"` // Pubkey used for signature
+}
+
+// ReportCommentReply is used to reply to the ReportComment command.
+type ReportCommentReply struct{}
+
+// CommentReport is a single report that was filed against a comment.
+type CommentReport struct {
+	UserID    string `json:"userid"`    // ID of reporting user
+	Username  string `json:"username"`  // Username of reporting user
+	Reason    string `json:"reason"`    // Reason the comment was reported
+	Timestamp int64  `json:"timestamp"` // Received UNIX timestamp
+}
+
+// ReportedComment is a comment that is waiting in the moderation queue along
+// with all the reports that were filed against it.
+type ReportedComment struct {
+	Comment Comment         `json:"comment"` // Reported comment
+	Count   uint64          `json:"count"`   // Number of reports
+	Reports []CommentReport `json:"reports"` // Reports filed against comment
+}
+
+// ReportedComments retrieves the comment moderation queue.
+type ReportedComments struct{}
+
+// ReportedCommentsReply returns all comments that have unresolved reports,
+// ordered by number of reports.
+type ReportedCommentsReply struct {
+	Comments []ReportedComment `json:"comments"`
+}
+
+// ResolveCommentReport allows an admin to resolve the reports filed against
+// a comment by either dismissing them or by censoring the comment.  The
+// signature and public key are from the admin that resolved the reports.
+type ResolveCommentReport struct {
+	Token     string               `json:"token"`     // Proposal censorship token
+	CommentID string               `json:"commentid"` // Comment ID
+	Action    CommentReportActionT `json:"action"`    // Resolve action
+	Reason    string               `json:"reason"`    // Reason for the action
+	Signature string               `json:"signature"` // Client signature of Token+CommentID+Reason
+	PublicKey string               `json:"publickey"` // Pubkey used for signature
+}
+
+// ResolveCommentReportReply returns the censor comment receipt if the
+// comment was censored.
+type ResolveCommentReportReply struct {
+	Receipt string `json:"receipt,omitempty"` // Server signature of client signature
+}
+
+// ProposalBundle retrieves a public proposal together with its entire
+// discussion.
+type ProposalBundle struct{}
+
+// ProposalBundleReply is a self contained archive of a public proposal and
+// its discussion.  Every signature and server receipt that it contains can be
+// verified offline against the politeiad public key.
+type ProposalBundleReply struct {
+	ServerPublicKey    string              `json:"serverpubkey"`                 // politeiad public key
+	Timestamp          int64               `json:"timestamp"`                    // Bundle creation UNIX timestamp
+	Proposal           ProposalRecord      `json:"proposal"`                     // Proposal including files and censorship record
+	Comments           []Comment           `json:"comments"`                     // Comments including receipts
+	CommentsLikes      []LikeComment       `json:"commentslikes"`                // Comment votes including receipts
+	CensoredComments   []CensorComment     `json:"censoredcomments"`             // Comment censor records including receipts
+	AuthorizeVote      *AuthorizeVote      `json:"authorizevote,omitempty"`      // Vote authorization by proposal author
+	AuthorizeVoteReply *AuthorizeVoteReply `json:"authorizevotereply,omitempty"` // Vote authorization receipt
+	StartVote          *StartVote          `json:"startvote,omitempty"`          // Vote parameters
+	StartVoteReply     *StartVoteReply     `json:"startvotereply,omitempty"`     // Vote snapshot
+}
+
+// VoteInclusionProof retrieves the proof that a ticket vote was included in
+// the politeiad repository and anchored in dcrtime.
+type VoteInclusionProof struct{}
+
+// VoteInclusionProofReply links a cast vote to the git commit that added it
+// to the politeiad repository and to the dcrtime anchor of that commit.
+// Commit is empty until the vote has been flushed into the repository and
+// AnchorMerkle is empty until the commit has been anchored.
+type VoteInclusionProofReply struct {
+	CastVote     CastVote             `json:"castvote"`     // Cast vote
+	Receipt      string               `json:"receipt"`      // Server signature of the cast vote signature
+	Commit       string               `json:"commit"`       // Git commit that added the vote
+	AnchorMerkle string               `json:"anchormerkle"` // Merkle root of the anchored commits
+	MerklePath   merkle.Branch        `json:"merklepath"`   // Path from the commit digest to AnchorMerkle
+	VerifyDigest dcrtime.VerifyDigest `json:"verifydigest"` // dcrtime verification of AnchorMerkle
+}
+
+// CensorshipReport retrieves the censorship actions that have been taken on
+// proposals and comments, newest first.  At most CensorshipReportPageSize
+// actions are returned; use Start to retrieve the next page.  The report is
+// returned as JSON unless Format is CensorshipReportFormatCSV.
+type CensorshipReport struct {
+	Start  uint64 `schema:"start"`  // Number of actions to skip
+	Format string `schema:"format"` // Report format, json or csv
+}
+
+// CensorshipAction is a proposal or comment censorship.  Proposal censorship
+// signatures are of Token+PropStatusCensored+Reason and are only available
+// for proposals that were censored after they started being recorded.  The
+// receipt of a proposal censorship is the politeiad signature of the SHA256
+// digest of the censorship signature.  Comment censorship signatures are of
+// Token+CommentID+Reason and the receipt is the politeiad signature of the
+// comment censorship signature.
+type CensorshipAction struct {
+	Type      string `json:"type"`                // Proposal or comment censorship
+	Token     string `json:"token"`               // Proposal censorship token
+	CommentID string `json:"commentid,omitempty"` // Comment ID
+	Timestamp int64  `json:"timestamp"`           // UNIX timestamp of the censorship
+	Reason    string `json:"reason"`              // Reason given by the admin
+	PublicKey string `json:"publickey"`           // Admin public key
+	Signature string `json:"signature"`           // Admin signature
+	Receipt   string `json:"receipt,omitempty"`   // Server receipt
+}
+
+// CensorshipReportReply returns a page of censorship actions.  Next is the
+// Start of the next page; it is zero once all actions have been returned.
+type CensorshipReportReply struct {
+	ServerPublicKey string             `json:"serverpubkey"` // politeiad public key
+	Total           uint64             `json:"total"`        // Total number of actions
+	Next            uint64             `json:"next"`         // Start of the next page
+	Actions         []CensorshipAction `json:"actions"`      // Censorship actions
+}
+
+// Feed retrieves an Atom or RSS feed of the public proposal activity.  The
+// newest FeedPageSize entries are returned.  The feed is returned as Atom
+// unless Format is FeedFormatRSS.
+type Feed struct {
+	Format string `schema:"format"` // Feed format, atom or rss
+}
+
 // CommentLike describes the voting action an user has given
 // to a comment (e.g: up or down vote)
 type CommentLike struct {
@@ -1036,28 +1565,131 @@ type UserDetailsReply struct {
 
 // ManageUser performs the given action on a user.
 type ManageUser struct {
-	UserID string            `json:"userid"` // User id
-	Action UserManageActionT `json:"action"` // Action
-	Reason string            `json:"reason"` // Admin reason for action
+	UserID string            `json:"userid"`         // User id
+	Action UserManageActionT `json:"action"`         // Action
+	Reason string            `json:"reason"`         // Admin reason for action
+	Role   UserRoleT         `json:"role,omitempty"` // Role to grant or revoke
 }
 
 // ManageUserReply is the reply for the ManageUserReply command.
 type ManageUserReply struct{}
 
 // EditUser edits a user's preferences.
+//
+// Notification types that are set in EmailDigestDaily or EmailDigestWeekly
+// are delivered in a single daily or weekly digest instead of one email per
+// event.  Only the NotificationEmailDigestable types can be digested and a
+// type can not be part of both digests.
 type EditUser struct {
 	EmailNotifications *uint64 `json:"emailnotifications"` // Notify the user via emails
+	EmailDigestDaily   *uint64 `json:"emaildigestdaily"`   // Notifications delivered in a daily digest
+	EmailDigestWeekly  *uint64 `json:"emaildigestweekly"`  // Notifications delivered in a weekly digest
+	Locale             *string `json:"locale"`             // Locale of the emails, e.g. "en" or "pt-BR"
 }
 
 // EditUserReply is the reply for the EditUser command.
 type EditUserReply struct{}
 
+// SetTOTP starts the two-factor authentication enrollment of the logged in
+// user.  A new secret is generated that replaces any pending secret.  The
+// secret is not used for logins until it has been verified using VerifyTOTP.
+type SetTOTP struct{}
+
+// SetTOTPReply returns the TOTP secret and the otpauth URI that is used to
+// add the secret to an authenticator app.
+type SetTOTPReply struct {
+	Secret string `json:"secret"` // Base32 encoded TOTP secret
+	URI    string `json:"uri"`    // otpauth URI of the secret
+}
+
+// VerifyTOTP verifies a code of the pending TOTP secret and enables
+// two-factor authentication for the logged in user.
+type VerifyTOTP struct {
+	Code string `json:"code"` // TOTP code
+}
+
+// VerifyTOTPReply returns the single use recovery codes that can be used in
+// place of a TOTP code when logging in.  These are only returned once.
+type VerifyTOTPReply struct {
+	RecoveryCodes []string `json:"recoverycodes"`
+}
+
+// APIKey describes an API key of the logged in user.  The secret part of the
+// key is never returned after it has been created.
+type APIKey struct {
+	ID        string `json:"id"`        // Unique key id
+	Name      string `json:"name"`      // User supplied description
+	Scopes    uint64 `json:"scopes"`    // Scopes that the key grants
+	CreatedAt int64  `json:"createdat"` // Unix timestamp of key creation
+	LastUsed  int64  `json:"lastused"`  // Unix timestamp of last use
+}
+
+// NewAPIKey creates a new API key for the logged in user.  API keys are sent
+// in the APIKeyHeader header as an alternative to session cookies and are
+// exempt from CSRF protection.
+type NewAPIKey struct {
+	Name   string `json:"name"`   // Description of the key
+	Scopes uint64 `json:"scopes"` // Scopes that the key grants
+}
+
+// NewAPIKeyReply returns the new API key.  The token is only returned once.
+type NewAPIKeyReply struct {
+	APIKey APIKey `json:"apikey"`
+	Token  string `json:"token"` // Token that is sent in APIKeyHeader
+}
+
+// APIKeys requests all API keys of the logged in user.
+type APIKeys struct{}
+
+// APIKeysReply returns the API keys of the logged in user.
+type APIKeysReply struct {
+	APIKeys []APIKey `json:"apikeys"`
+}
+
+// RevokeAPIKey deletes an API key of the logged in user.
+type RevokeAPIKey struct {
+	ID string `json:"id"` // Key id
+}
+
+// RevokeAPIKeyReply is the reply to the RevokeAPIKey command.
+type RevokeAPIKeyReply struct{}
+
+// Session is an active login session of a user.
+type Session struct {
+	ID        string `json:"id"`        // Session id
+	CreatedAt int64  `json:"createdat"` // Unix timestamp of login
+	LastSeen  int64  `json:"lastseen"`  // Unix timestamp of last use
+	IP        string `json:"ip"`        // IP address of the client that logged in
+	UserAgent string `json:"useragent"` // User agent of the client that logged in
+	Current   bool   `json:"current"`   // Set if this is the session of the request
+}
+
+// Sessions requests the active sessions of the logged in user.
+type Sessions struct{}
+
+// SessionsReply returns the active sessions of the logged in user.
+type SessionsReply struct {
+	Sessions []Session `json:"sessions"`
+}
+
+// RevokeSessions logs out sessions of the logged in user.  Either a single
+// session or all sessions other than the current one are revoked.
+type RevokeSessions struct {
+	ID  string `json:"id,omitempty"`  // Session id
+	All bool   `json:"all,omitempty"` // Revoke all other sessions
+}
+
+// RevokeSessionsReply is the reply to the RevokeSessions command.
+type RevokeSessionsReply struct{}
+
 // User represents an individual user.
 type User struct {
 	ID                              string         `json:"id"`
 	Email                           string         `json:"email"`
 	Username                        string         `json:"username"`
 	Admin                           bool           `json:"isadmin"`
+	Roles                           uint64         `json:"roles"`
+	TOTPEnabled                     bool           `json:"totpenabled"`
 	NewUserPaywallAddress           string         `json:"newuserpaywalladdress"`
 	NewUserPaywallAmount            uint64         `json:"newuserpaywallamount"`
 	NewUserPaywallTx                string         `json:"newuserpaywalltx"`
@@ -1076,6 +1708,9 @@ type User struct {
 	Identities                      []UserIdentity `json:"identities"`
 	ProposalCredits                 uint64         `json:"proposalcredits"`
 	EmailNotifications              uint64         `json:"emailnotifications"` // Notify the user via emails
+	EmailDigestDaily                uint64         `json:"emaildigestdaily"`   // Notifications delivered in a daily digest
+	EmailDigestWeekly               uint64         `json:"emaildigestweekly"`  // Notifications delivered in a weekly digest
+	Locale                          string         `json:"locale"`             // Locale of the emails sent to the user
 }
 
 // UserIdentity represents a user's unique identity.
@@ -1111,9 +1746,19 @@ type ProposalsStatsReply struct {
 
 // Websocket commands
 const (
-	WSCError     = "error"
-	WSCPing      = "ping"
-	WSCSubscribe = "subscribe"
+	WSCError          = "error"
+	WSCPing           = "ping"
+	WSCSubscribe      = "subscribe"
+	WSCNewProposal    = "newproposal"    // Admins and authors only, see WSNewProposal
+	WSCProposalStatus = "proposalstatus" // Proposal status changes, see WSProposalStatus
+	WSCNewComment     = "newcomment"     // Per proposal, see WSSubscriptionSeparator
+	WSCVoteStarted    = "votestarted"    // Proposal votes that were started
+	WSCVoteTally      = "votetally"      // Per proposal, see WSSubscriptionSeparator
+
+	// WSSubscriptionSeparator separates a command from the censorship
+	// token of the proposal in subscriptions to per proposal commands,
+	// e.g. newcomment:<token>.
+	WSSubscriptionSeparator = ":"
 )
 
 // WSHeader is required to be sent before any other command. The point is to
@@ -1142,3 +1787,51 @@ type WSSubscribe struct {
 type WSPing struct {
 	Timestamp int64 `json:"timestamp"` // Server side timestamp
 }
+
+// WSNewProposal is a server side push that notifies admins and the author of
+// a newly submitted proposal.  Only the token is sent because unvetted
+// proposals are not public.
+type WSNewProposal struct {
+	Token string `json:"token"` // Censorship token
+}
+
+// WSProposalStatus is a server side push that notifies the client of a
+// proposal status change.  The name is only sent for public proposals.  Status
+// changes other than public and abandoned are only pushed to admins and to the
+// author of the proposal since they reveal unvetted proposals.
+type WSProposalStatus struct {
+	Token  string      `json:"token"`          // Censorship token
+	Status PropStatusT `json:"status"`         // New proposal status
+	Name   string      `json:"name,omitempty"` // Proposal name
+}
+
+// WSNewComment is a server side push that notifies the client of a new
+// comment on a proposal it subscribed to.
+type WSNewComment struct {
+	Comment Comment `json:"comment"`
+}
+
+// WSVoteStarted is a server side push that notifies the client that the vote
+// on a proposal has started.
+type WSVoteStarted struct {
+	Token            string       `json:"token"`            // Censorship token
+	Name             string       `json:"name"`             // Proposal name
+	StartBlockHeight string       `json:"startblockheight"` // Block height
+	EndHeight        string       `json:"endheight"`        // Height of vote end
+	Options          []VoteOption `json:"options"`          // Vote options
+}
+
+// WSVoteTally is a server side push that notifies the client of votes that
+// were cast on a proposal it subscribed to.  Votes contains the number of
+// votes that each option received since the previous update and
+// OptionsResult the total number of votes of each option.
+type WSVoteTally struct {
+	Token              string             `json:"token"`              // Censorship token
+	Votes              []VoteOptionResult `json:"votes"`              // Votes received per option
+	OptionsResult      []VoteOptionResult `json:"optionsresult"`      // Total votes per option
+	TotalVotes         uint64             `json:"totalvotes"`         // Total number of votes
+	NumOfEligibleVotes int                `json:"numofeligiblevotes"` // Total number of eligible votes
+	QuorumVotes        uint64             `json:"quorumvotes"`        // Number of votes required for a quorum
+	EndHeight          string             `json:"endheight"`          // Vote end height
+	BlocksRemaining    uint64             `json:"blocksremaining"`    // Blocks until the vote ends
+}
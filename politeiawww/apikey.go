@@ -0,0 +1,237 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/politeiawww/database"
+	"github.com/decred/politeia/util"
+)
+
+const (
+	// apiKeyIDSize is the size of an API key id in bytes.
+	apiKeyIDSize = 8
+
+	// apiKeySecretSize is the size of an API key secret in bytes.
+	apiKeySecretSize = 32
+
+	// apiKeyNameMaxLength is the maximum length of an API key name.
+	apiKeyNameMaxLength = 64
+
+	// apiKeyLastUsedInterval is the minimum interval between updates of
+	// the last used timestamp of an API key.  This prevents a database
+	// write on every request.
+	apiKeyLastUsedInterval = time.Minute
+)
+
+var (
+	// apiKeyReadRoutes are the GET routes that can be accessed with
+	// APIKeyScopeRead.  Routes that require a role and routes that manage
+	// credentials are left out.
+	apiKeyReadRoutes = map[string]struct{}{
+		v1.RouteVersion:                {},
+		v1.RouteAllVetted:              {},
+		v1.RouteProposalDetails:        {},
+		v1.RoutePolicy:                 {},
+		v1.RouteCommentsGet:            {},
+		v1.RouteUserProposals:          {},
+		v1.RouteActiveVote:             {},
+		v1.RouteVoteResults:            {},
+		v1.RouteAllVoteStatus:          {},
+		v1.RouteVoteStatus:             {},
+		v1.RouteProposalBundle:         {},
+		v1.RouteVoteInclusionProof:     {},
+		v1.RouteCensorshipReport:       {},
+		v1.RouteProposalsFeed:          {},
+		v1.RouteVotingFeed:             {},
+		v1.RouteVoteResultsFeed:        {},
+		v1.RouteCommentsFeed:           {},
+		v1.RouteUserDetails:            {},
+		v1.RoutePropsStats:             {},
+		v1.RouteProposalPaywallDetails: {},
+		v1.RouteUserMe:                 {},
+		v1.RouteVerifyUserPayment:      {},
+		v1.RouteUserCommentsLikes:      {},
+		v1.RouteUserProposalCredits:    {},
+		v1.RouteProposalPaywallPayment: {},
+	}
+
+	// apiKeyRouteScopes maps the routes that modify state to the scope
+	// that an API key requires in order to access them.  All other routes
+	// can't be accessed with API keys.
+	apiKeyRouteScopes = map[string]v1.APIKeyScopeT{
+		v1.RouteNewComment:  v1.APIKeyScopeComment,
+		v1.RouteLikeComment: v1.APIKeyScopeComment,
+		v1.RouteStartVote:   v1.APIKeyScopeVoteAdmin,
+	}
+)
+
+// apiKeyScope returns the scope that an API key requires in order to access
+// the provided route.  False is returned if the route can't be accessed with
+// an API key.
+func apiKeyScope(method, route string) (v1.APIKeyScopeT, bool) {
+	if method == http.MethodGet {
+		_, ok := apiKeyReadRoutes[route]
+		return v1.APIKeyScopeRead, ok
+	}
+	scope, ok := apiKeyRouteScopes[route]
+	return scope, ok
+}
+
+// validAPIKeyScopes returns whether scopes is a non empty combination of
+// known scopes.
+func validAPIKeyScopes(scopes uint64) bool {
+	var all uint64
+	for scope := range v1.APIKeyScope {
+		all |= uint64(scope)
+	}
+	return scopes != 0 && scopes&^all == 0
+}
+
+// hashAPIKeySecret returns the SHA256 digest of an API key secret.
+func hashAPIKeySecret(secret string) []byte {
+	h := sha256.Sum256([]byte(secret))
+	return h[:]
+}
+
+func convertWWWAPIKeyFromDatabaseAPIKey(k database.APIKey) v1.APIKey {
+	return v1.APIKey{
+		ID:        k.ID,
+		Name:      k.Name,
+		Scopes:    k.Scopes,
+		CreatedAt: k.CreatedAt,
+		LastUsed:  k.LastUsed,
+	}
+}
+
+// authenticateAPIKey verifies an API key token and returns the matching API
+// key.  Keys of deactivated users are rejected.  The last used timestamp of
+// the key is updated.
+func (b *backend) authenticateAPIKey(token string) (*database.APIKey, error) {
+	invalid := v1.UserError{
+		ErrorCode: v1.ErrorStatusInvalidAPIKey,
+	}
+
+	// Tokens have the format <id>.<secret>
+	s := strings.SplitN(token, ".", 2)
+	if len(s) != 2 {
+		return nil, invalid
+	}
+	k, err := b.db.APIKeyGet(s[0])
+	if err == database.ErrAPIKeyNotFound {
+		return nil, invalid
+	} else if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(k.Digest, hashAPIKeySecret(s[1])) != 1 {
+		return nil, invalid
+	}
+	user, err := b.db.UserGetById(k.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.Deactivated {
+		return nil, invalid
+	}
+
+	now := time.Now()
+	if now.Sub(time.Unix(k.LastUsed, 0)) >= apiKeyLastUsedInterval {
+		k.LastUsed = now.Unix()
+		err = b.db.APIKeyUpdate(*k)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return k, nil
+}
+
+// ProcessNewAPIKey creates a new API key for the user.
+func (b *backend) ProcessNewAPIKey(nak *v1.NewAPIKey, user *database.User) (*v1.NewAPIKeyReply, error) {
+	nak.Name = strings.TrimSpace(nak.Name)
+	if nak.Name == "" || len(nak.Name) > apiKeyNameMaxLength ||
+		!validAPIKeyScopes(nak.Scopes) {
+		return nil, v1.UserError{
+			ErrorCode: v1.ErrorStatusInvalidInput,
+		}
+	}
+
+	id, err := util.Random(apiKeyIDSize)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := util.Random(apiKeySecretSize)
+	if err != nil {
+		return nil, err
+	}
+	k := database.APIKey{
+		ID:        hex.EncodeToString(id),
+		UserID:    user.ID,
+		Name:      nak.Name,
+		Digest:    hashAPIKeySecret(hex.EncodeToString(secret)),
+		Scopes:    nak.Scopes,
+		CreatedAt: time.Now().Unix(),
+	}
+	err = b.db.APIKeyNew(k)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.NewAPIKeyReply{
+		APIKey: convertWWWAPIKeyFromDatabaseAPIKey(k),
+		Token:  k.ID + "." + hex.EncodeToString(secret),
+	}, nil
+}
+
+// ProcessAPIKeys returns the API keys of the user sorted by creation time.
+func (b *backend) ProcessAPIKeys(user *database.User) (*v1.APIKeysReply, error) {
+	keys, err := b.db.APIKeysGet(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt < keys[j].CreatedAt
+	})
+
+	reply := v1.APIKeysReply{
+		APIKeys: make([]v1.APIKey, 0, len(keys)),
+	}
+	for _, k := range keys {
+		reply.APIKeys = append(reply.APIKeys,
+			convertWWWAPIKeyFromDatabaseAPIKey(k))
+	}
+
+	return &reply, nil
+}
+
+// ProcessRevokeAPIKey deletes an API key of the user.
+func (b *backend) ProcessRevokeAPIKey(rak *v1.RevokeAPIKey, user *database.User) (*v1.RevokeAPIKeyReply, error) {
+	notFound := v1.UserError{
+		ErrorCode: v1.ErrorStatusAPIKeyNotFound,
+	}
+
+	k, err := b.db.APIKeyGet(rak.ID)
+	if err == database.ErrAPIKeyNotFound {
+		return nil, notFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Users can only revoke their own keys.
+	if k.UserID != user.ID {
+		return nil, notFound
+	}
+
+	err = b.db.APIKeyDelete(k.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.RevokeAPIKeyReply{}, nil
+}
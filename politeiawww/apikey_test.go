@@ -0,0 +1,158 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/politeiawww/database"
+	"github.com/decred/slog"
+)
+
+// Tests creating, using, listing and revoking API keys.
+func TestProcessAPIKeys(t *testing.T) {
+	b := createBackend(t)
+	nu, _ := createAndVerifyUser(t, b)
+	user, _ := b.db.UserGet(nu.Email)
+	nu, _ = createAndVerifyUser(t, b)
+	otherUser, _ := b.db.UserGet(nu.Email)
+
+	// Keys require a name and known scopes
+	invalid := []www.NewAPIKey{
+		{Name: " ", Scopes: uint64(www.APIKeyScopeRead)},
+		{Name: strings.Repeat("a", apiKeyNameMaxLength+1),
+			Scopes: uint64(www.APIKeyScopeRead)},
+		{Name: "bot", Scopes: 0},
+		{Name: "bot", Scopes: 1 << 10},
+	}
+	for _, v := range invalid {
+		_, err := b.ProcessNewAPIKey(&v, user)
+		assertError(t, err, www.ErrorStatusInvalidInput)
+	}
+
+	nakr, err := b.ProcessNewAPIKey(&www.NewAPIKey{
+		Name:   "bot",
+		Scopes: uint64(www.APIKeyScopeRead | www.APIKeyScopeComment),
+	}, user)
+	assertSuccess(t, err)
+
+	// Only valid tokens authenticate and using a key updates its last
+	// used timestamp
+	for _, token := range []string{"", nakr.APIKey.ID,
+		nakr.APIKey.ID + ".00", "00." + nakr.Token} {
+		_, err = b.authenticateAPIKey(token)
+		assertError(t, err, www.ErrorStatusInvalidAPIKey)
+	}
+	k, err := b.authenticateAPIKey(nakr.Token)
+	assertSuccess(t, err)
+	if k.UserID != user.ID || k.LastUsed == 0 {
+		t.Fatalf("unexpected API key %v", k)
+	}
+	akr, err := b.ProcessAPIKeys(user)
+	assertSuccess(t, err)
+	if len(akr.APIKeys) != 1 || akr.APIKeys[0].LastUsed != k.LastUsed {
+		t.Fatalf("unexpected API keys %v", akr.APIKeys)
+	}
+
+	// API keys never show up as users
+	err = b.db.AllUsers(func(u *database.User) {
+		if u.ID != user.ID && u.ID != otherUser.ID {
+			t.Fatalf("unexpected user %v", u.ID)
+		}
+	})
+	assertSuccess(t, err)
+
+	// Users can only revoke their own keys
+	rak := www.RevokeAPIKey{ID: nakr.APIKey.ID}
+	_, err = b.ProcessRevokeAPIKey(&rak, otherUser)
+	assertError(t, err, www.ErrorStatusAPIKeyNotFound)
+	_, err = b.ProcessRevokeAPIKey(&rak, user)
+	assertSuccess(t, err)
+	_, err = b.authenticateAPIKey(nakr.Token)
+	assertError(t, err, www.ErrorStatusInvalidAPIKey)
+	_, err = b.ProcessRevokeAPIKey(&rak, user)
+	assertError(t, err, www.ErrorStatusAPIKeyNotFound)
+
+	b.db.Close()
+}
+
+// Tests that API keys are only accepted on routes that their scopes allow and
+// that they take the place of the session user.
+func TestAPIKeyMiddleware(t *testing.T) {
+	// Rejected requests are logged and the log rotator is not
+	// initialized in tests.
+	defer log.SetLevel(log.Level())
+	log.SetLevel(slog.LevelOff)
+
+	b := createBackend(t)
+	p := &politeiawww{backend: b}
+	nu, _ := createAndVerifyUser(t, b)
+	user, _ := b.db.UserGet(nu.Email)
+
+	nakr, err := b.ProcessNewAPIKey(&www.NewAPIKey{
+		Name:   "bot",
+		Scopes: uint64(www.APIKeyScopeRead),
+	}, user)
+	assertSuccess(t, err)
+
+	var userID string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		userID, _ = p.getSessionUUID(r)
+	}
+
+	tests := []struct {
+		method string
+		route  string
+		token  string
+		status int
+		userID string
+	}{
+		{http.MethodGet, www.RouteUserMe, nakr.Token, http.StatusOK,
+			user.ID.String()},
+		{http.MethodGet, www.RouteUserMe, "invalid",
+			http.StatusUnauthorized, ""},
+		{http.MethodPost, www.RouteNewComment, nakr.Token,
+			http.StatusForbidden, ""},
+		{http.MethodPost, www.RouteNewAPIKey, nakr.Token,
+			http.StatusForbidden, ""},
+		{http.MethodGet, www.RouteAPIKeys, nakr.Token,
+			http.StatusForbidden, ""},
+		{http.MethodGet, www.RouteSessions, nakr.Token,
+			http.StatusForbidden, ""},
+		{http.MethodGet, www.RouteUsers, nakr.Token,
+			http.StatusForbidden, ""},
+		{http.MethodGet, www.RouteAllUnvetted, nakr.Token,
+			http.StatusForbidden, ""},
+	}
+	request := func(method, route, token string) int {
+		userID = ""
+		r := httptest.NewRequest(method, route, nil)
+		r.Header.Set(www.APIKeyHeader, token)
+		w := httptest.NewRecorder()
+		p.apiKey(method, route, handler)(w, r)
+		return w.Code
+	}
+	for _, test := range tests {
+		status := request(test.method, test.route, test.token)
+		if status != test.status || userID != test.userID {
+			t.Fatalf("%v %v: got %v %v, want %v %v", test.method,
+				test.route, status, userID, test.status,
+				test.userID)
+		}
+	}
+
+	// The keys of deactivated users are rejected.
+	user.Deactivated = true
+	err = b.db.UserUpdate(*user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := request(http.MethodGet, www.RouteUserMe, nakr.Token)
+	if status != http.StatusUnauthorized || userID != "" {
+		t.Fatalf("deactivated user: got %v %v", status, userID)
+	}
+
+	b.db.Close()
+}
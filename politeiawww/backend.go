@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"os"
 	"regexp"
 	"strconv"
 	"strings"
@@ -52,6 +53,7 @@ type MDStreamChanges struct {
 	AdminPubKey         string           `json:"adminpubkey"`                   // Identity of the administrator
 	NewStatus           pd.RecordStatusT `json:"newstatus"`                     // NewStatus
 	StatusChangeMessage string           `json:"statuschangemessage,omitempty"` // Status change message
+	Signature           string           `json:"signature,omitempty"`           // Admin signature of Token+www status+StatusChangeMessage
 	Timestamp           int64            `json:"timestamp"`                     // Timestamp of the change
 }
 
@@ -67,10 +69,25 @@ type backend struct {
 	db              database.Database
 	cfg             *config
 	params          *chaincfg.Params
-	client          *http.Client // politeiad client
+	client          *http.Client   // politeiad client
+	txFetcher       util.TxFetcher // Paywall transaction source
 	eventManager    *EventManager
 	userPubkeys     map[string]string               // [pubkey][userid]
 	userPaywallPool map[uuid.UUID]paywallPoolMember // [userid][paywallPoolMember]
+	auditIdentity   *identity.FullIdentity          // Signs the admin audit log
+	emailOutboxWake chan struct{}                   // Wakes up the email outbox
+	digestMtx       sync.Mutex                      // Serializes updates of the pending email digests
+	templates       *emailTemplates                 // Customized email templates, may be nil
+	webhookClient   *http.Client                    // Delivers webhook requests
+	webhookWake     chan struct{}                   // Wakes up the webhook deliveries
+
+	// Vote tallies are kept up to date with the accepted ballots in
+	// order to avoid fetching the cast votes from politeiad.
+	tallyMtx         sync.Mutex            // lock for vote tallies and block data
+	voteTallies      map[string]*voteTally // [token]voteTally
+	bestBlock        uint64                // Cached best block height
+	bestBlockFetched time.Time             // Time the best block was fetched
+	blockTimes       map[uint64]time.Time  // [height]block timestamp
 
 	// These properties are only used for testing.
 	test                   bool
@@ -94,6 +111,10 @@ type backend struct {
 
 	// User vote action on each comment
 	userLikeActionByCommentID map[string]map[string]map[string]int64 // [token][userid][commentid]action
+
+	// Outstanding login challenges
+	loginChallenges    map[string]loginChallenge // [challenge]loginChallenge
+	loginChallengeKeys map[string][]string       // [publickey][]challenge, oldest first
 }
 
 type BackendProposalMetadata struct {
@@ -305,6 +326,30 @@ func (b *backend) getUsernameById(userIdStr string) string {
 	return user.Username
 }
 
+// loginFailed records a failed login attempt for the user and emails the user
+// once the account gets locked.
+func (b *backend) loginFailed(user *database.User) error {
+	if checkUserIsLocked(user.FailedLoginAttempts) {
+		return nil
+	}
+
+	user.FailedLoginAttempts++
+	err := b.db.UserUpdate(*user)
+	if err != nil {
+		return err
+	}
+
+	// Check if the user is locked again so we can send an
+	// email.
+	if checkUserIsLocked(user.FailedLoginAttempts) && !b.test {
+		// This is conditional on the email server
+		// being setup.
+		return b.emailUserLocked(user.Email)
+	}
+
+	return nil
+}
+
 func (b *backend) login(l *www.Login) loginReplyWithError {
 	// Get user from db.
 	user, err := b.db.UserGet(l.Email)
@@ -330,28 +375,11 @@ func (b *backend) login(l *www.Login) loginReplyWithError {
 	err = bcrypt.CompareHashAndPassword(user.HashedPassword,
 		[]byte(l.Password))
 	if err != nil {
-		if !checkUserIsLocked(user.FailedLoginAttempts) {
-			user.FailedLoginAttempts++
-			err := b.db.UserUpdate(*user)
-			if err != nil {
-				return loginReplyWithError{
-					reply: nil,
-					err:   err,
-				}
-			}
-
-			// Check if the user is locked again so we can send an
-			// email.
-			if checkUserIsLocked(user.FailedLoginAttempts) && !b.test {
-				// This is conditional on the email server
-				// being setup.
-				err := b.emailUserLocked(user.Email)
-				if err != nil {
-					return loginReplyWithError{
-						reply: nil,
-						err:   err,
-					}
-				}
+		err := b.loginFailed(user)
+		if err != nil {
+			return loginReplyWithError{
+				reply: nil,
+				err:   err,
 			}
 		}
 
@@ -365,10 +393,17 @@ func (b *backend) login(l *www.Login) loginReplyWithError {
 		}
 	}
 
+	return b.completeLogin(user, l.Code)
+}
+
+// completeLogin checks that a user whose credentials have been verified is
+// allowed to login, verifies the two-factor authentication code and updates
+// the last login time of the user.
+func (b *backend) completeLogin(user *database.User, code string) loginReplyWithError {
 	// Check that the user is verified.
 	if user.NewUserVerificationToken != nil {
 		log.Debugf("Login failure for %v: user not yet verified",
-			l.Email)
+			user.Email)
 		return loginReplyWithError{
 			reply: nil,
 			err: www.UserError{
@@ -379,7 +414,7 @@ func (b *backend) login(l *www.Login) loginReplyWithError {
 
 	// Check if the user account is deactivated.
 	if user.Deactivated {
-		log.Debugf("Login failure for %v: user deactivated", l.Email)
+		log.Debugf("Login failure for %v: user deactivated", user.Email)
 		return loginReplyWithError{
 			reply: nil,
 			err: www.UserError{
@@ -391,7 +426,7 @@ func (b *backend) login(l *www.Login) loginReplyWithError {
 	// Check if user is locked due to too many login attempts
 	if checkUserIsLocked(user.FailedLoginAttempts) {
 		log.Debugf("Login failure for %v: user locked",
-			l.Email)
+			user.Email)
 		return loginReplyWithError{
 			reply: nil,
 			err: www.UserError{
@@ -400,10 +435,50 @@ func (b *backend) login(l *www.Login) loginReplyWithError {
 		}
 	}
 
+	// Check the two-factor authentication code.  Invalid codes count
+	// as failed login attempts so that codes can't be brute forced.
+	if user.TOTPEnabled {
+		if code == "" {
+			log.Debugf("Login failure for %v: two-factor "+
+				"authentication code required", user.Email)
+			return loginReplyWithError{
+				reply: nil,
+				err: www.UserError{
+					ErrorCode: www.ErrorStatusTOTPRequired,
+				},
+			}
+		}
+		ok, err := checkTOTP(user, code)
+		if err != nil {
+			return loginReplyWithError{
+				reply: nil,
+				err:   err,
+			}
+		}
+		if !ok {
+			err := b.loginFailed(user)
+			if err != nil {
+				return loginReplyWithError{
+					reply: nil,
+					err:   err,
+				}
+			}
+
+			log.Debugf("Login failure for %v: invalid two-factor "+
+				"authentication code", user.Email)
+			return loginReplyWithError{
+				reply: nil,
+				err: www.UserError{
+					ErrorCode: www.ErrorStatusInvalidTOTPCode,
+				},
+			}
+		}
+	}
+
 	lastLoginTime := user.LastLoginTime
 	user.FailedLoginAttempts = 0
 	user.LastLoginTime = time.Now().Unix()
-	err = b.db.UserUpdate(*user)
+	err := b.db.UserUpdate(*user)
 	if err != nil {
 		return loginReplyWithError{
 			reply: nil,
@@ -823,7 +898,7 @@ func (b *backend) emailResetPassword(user *database.User, rp www.ResetPassword,
 	}
 
 	// Only set the token if email verification is disabled.
-	if b.cfg.SMTP == nil {
+	if b.cfg.Mailer == nil {
 		rpr.VerificationToken = hex.EncodeToString(token)
 	}
 
@@ -879,7 +954,14 @@ func (b *backend) verifyResetPassword(user *database.User, rp www.ResetPassword,
 	user.HashedPassword = hashedPassword
 	user.FailedLoginAttempts = 0
 
-	return b.db.UserUpdate(*user)
+	err = b.db.UserUpdate(*user)
+	if err != nil {
+		return err
+	}
+
+	// Log out all sessions since they may belong to whoever
+	// compromised the old password.
+	return b.revokeSessions(user.ID)
 }
 
 // loadInventory calls the politeaid RPC call to load the current inventory.
@@ -918,7 +1000,10 @@ func (b *backend) CreateLoginReply(user *database.User, lastLoginTime int64) (*w
 	}
 
 	reply := www.LoginReply{
-		IsAdmin:         user.Admin,
+		IsAdmin:         userHasRole(user, www.UserRoleSuperAdmin),
+		Roles:           uint64(userRoles(user)),
+		TOTPEnabled:     user.TOTPEnabled,
+		TOTPRequired:    b.totpRequired(user),
 		UserID:          user.ID.String(),
 		Email:           user.Email,
 		Username:        user.Username,
@@ -1100,7 +1185,7 @@ func (b *backend) ProcessNewUser(u www.NewUser) (*www.NewUserReply, error) {
 	}
 
 	// Only set the token if email verification is disabled.
-	if b.cfg.SMTP == nil {
+	if b.cfg.Mailer == nil {
 		reply.VerificationToken = hex.EncodeToString(token)
 	}
 	return &reply, nil
@@ -1272,7 +1357,7 @@ func (b *backend) ProcessResendVerification(rv *www.ResendVerification) (*www.Re
 	}
 
 	// Only set the token if email verification is disabled.
-	if b.cfg.SMTP == nil {
+	if b.cfg.Mailer == nil {
 		rvr.VerificationToken = hex.EncodeToString(token)
 	}
 	return &rvr, nil
@@ -1339,7 +1424,7 @@ func (b *backend) ProcessUpdateUserKey(user *database.User, u www.UpdateUserKey)
 	}
 
 	// Only set the token if email verification is disabled.
-	if b.cfg.SMTP == nil {
+	if b.cfg.Mailer == nil {
 		reply.VerificationToken = hex.EncodeToString(token)
 	}
 	return &reply, nil
@@ -1426,6 +1511,15 @@ func (b *backend) ProcessVerifyUpdateUserKey(user *database.User, vu www.VerifyU
 // ProcessLogin checks that a user exists, is verified, and has
 // the correct password.
 func (b *backend) ProcessLogin(l www.Login) (*www.LoginReply, error) {
+	return loginWithMinimumWait(func() loginReplyWithError {
+		return b.login(&l)
+	})
+}
+
+// loginWithMinimumWait executes the provided login function and only returns
+// once MinimumLoginWaitTime has passed so that the outcome of a login can't
+// be inferred from the response time.
+func loginWithMinimumWait(f func() loginReplyWithError) (*www.LoginReply, error) {
 	var (
 		r       loginReplyWithError
 		login   = make(chan loginReplyWithError)
@@ -1433,7 +1527,7 @@ func (b *backend) ProcessLogin(l www.Login) (*www.LoginReply, error) {
 	)
 
 	go func() {
-		login <- b.login(&l)
+		login <- f()
 	}()
 	go func() {
 		time.Sleep(MinimumLoginWaitTime)
@@ -1770,6 +1864,7 @@ func (b *backend) ProcessSetProposalStatus(sps www.SetProposalStatus, user *data
 		Timestamp:           time.Now().Unix(),
 		NewStatus:           newStatus,
 		StatusChangeMessage: sps.StatusChangeMessage,
+		Signature:           sps.Signature,
 	}
 
 	var ok bool
@@ -1784,10 +1879,17 @@ func (b *backend) ProcessSetProposalStatus(sps www.SetProposalStatus, user *data
 		return nil, err
 	}
 
-	// Create challenge
-	challenge, err := util.Random(pd.ChallengeSize)
-	if err != nil {
-		return nil, err
+	// Create challenge.  The challenge of a censorship is the digest of
+	// the admin signature so that the politeiad challenge response is a
+	// receipt of the censorship.
+	var challenge []byte
+	if sps.ProposalStatus == www.PropStatusCensored {
+		challenge = util.Digest([]byte(sps.Signature))
+	} else {
+		challenge, err = util.Random(pd.ChallengeSize)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// XXX Expensive to lock but do it for now.
@@ -1923,6 +2025,20 @@ func (b *backend) ProcessSetProposalStatus(sps www.SetProposalStatus, user *data
 		return nil, err
 	}
 
+	// Record the censorship receipt.  The censorship already happened
+	// so a failure is only logged.
+	if sps.ProposalStatus == www.PropStatusCensored {
+		err = b.db.CensorshipReceiptNew(database.CensorshipReceipt{
+			Token:     sps.Token,
+			Signature: sps.Signature,
+			Receipt:   challengeResponse,
+		})
+		if err != nil {
+			log.Errorf("ProcessSetProposalStatus: "+
+				"CensorshipReceiptNew %v: %v", sps.Token, err)
+		}
+	}
+
 	// politeiad returns the record without the files
 	// attached. Add files back onto the record.
 	updatedRecord.Files = ir.record.Files
@@ -2007,9 +2123,9 @@ func (b *backend) ProcessProposalDetails(propDetails www.ProposalsDetails, user
 	}
 
 	// The title and files for unvetted proposals should not be viewable by
-	// non-admins; only the proposal meta data (status, censorship data,
+	// non-reviewers; only the proposal meta data (status, censorship data,
 	// etc) should be publicly viewable.
-	isUserAdmin := user != nil && user.Admin
+	isUserAdmin := user != nil && userHasRole(user, www.UserRoleReviewer)
 
 	var isUserTheAuthor bool
 	if user != nil {
@@ -2460,6 +2576,63 @@ func (b *backend) ProcessCensorComment(cc www.CensorComment, user *database.User
 	return &ccrWWW, nil
 }
 
+// ProcessReportComment files a report against a proposal comment so that it
+// shows up in the admin moderation queue.  A user may only report a given
+// comment once.
+func (b *backend) ProcessReportComment(rc www.ReportComment, user *database.User) (*www.ReportCommentReply, error) {
+	log.Debugf("ProcessReportComment: %v: %v", rc.Token, rc.CommentID)
+
+	// Verify authenticity.
+	err := checkPublicKeyAndSignature(user, rc.PublicKey, rc.Signature,
+		rc.Token, rc.CommentID, rc.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure report reason is present.
+	if strings.TrimSpace(rc.Reason) == "" {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusReportReasonCannotBeBlank,
+		}
+	}
+
+	// Ensure the proposal exists.
+	b.RLock()
+	_, err = b._getInventoryRecord(rc.Token)
+	if err != nil {
+		b.RUnlock()
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusProposalNotFound,
+		}
+	}
+
+	// Ensure comment exists and has not been censored.
+	c, err := b._getInventoryRecordComment(rc.Token, rc.CommentID)
+	b.RUnlock()
+	if err != nil || c.Censored {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusCommentNotFound,
+		}
+	}
+
+	err = b.db.CommentReportNew(database.CommentReport{
+		Token:     rc.Token,
+		CommentID: rc.CommentID,
+		UserID:    user.ID,
+		Reason:    rc.Reason,
+		Timestamp: time.Now().Unix(),
+	})
+	if err == database.ErrCommentReportExists {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusCommentAlreadyReported,
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("CommentReportNew: %v", err)
+	}
+
+	return &www.ReportCommentReply{}, nil
+}
+
 // ProcessCommentGet returns all comments for a given proposal. If the user
 // is logged in, returns the user's last access time for the given proposal.
 // Else, returns 0 as the access time
@@ -2622,6 +2795,22 @@ func (b *backend) ProcessCastVotes(ballot *www.Ballot) (*www.BallotReply, error)
 		return nil, err
 	}
 	brr := convertBallotReplyFromDecredPlugin(*br)
+
+	// Receipts are in the same order as the votes of the ballot.
+	accepted := make([]www.CastVote, 0, len(ballot.Votes))
+	for i, v := range brr.Receipts {
+		if v.Error == "" && i < len(ballot.Votes) {
+			accepted = append(accepted, ballot.Votes[i])
+		}
+	}
+	b.countBallot(accepted)
+
+	if len(accepted) > 0 {
+		b.fireEvent(EventTypeBallotCast, EventDataBallotCast{
+			Votes: accepted,
+		})
+	}
+
 	return &brr, nil
 }
 
@@ -2933,13 +3122,49 @@ func (b *backend) ProcessVoteResults(token string) (*www.VoteResultsReply, error
 	return &wvrr, nil
 }
 
+// ProcessVoteInclusionProof returns the proof that the vote of the provided
+// ticket was included in the politeiad repository and anchored in dcrtime.
+func (b *backend) ProcessVoteInclusionProof(token, ticket string) (*www.VoteInclusionProofReply, error) {
+	log.Tracef("ProcessVoteInclusionProof: %v %v", token, ticket)
+
+	ir, err := b.getInventoryRecord(token)
+	if err != nil {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusProposalNotFound,
+		}
+	}
+	if ir.record.Status != pd.RecordStatusPublic {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusWrongStatus,
+		}
+	}
+	if ir.voting.StartBlockHash == "" {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusWrongVoteStatus,
+		}
+	}
+
+	vipr, err := b.getVoteInclusionProofFromPlugin(token, ticket)
+	if err != nil {
+		return nil, err
+	}
+	if vipr.CastVote.Ticket == "" {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusVoteNotFound,
+		}
+	}
+
+	reply := convertVoteInclusionProofReplyFromDecredplugin(*vipr)
+	return &reply, nil
+}
+
 // ProcessGetAllVoteStatus returns the vote status of all public proposals
 func (b *backend) ProcessGetAllVoteStatus() (*www.GetAllVoteStatusReply, error) {
 	log.Tracef("ProcessGetAllVoteStatus")
 
 	// We need to determine best block height here in order to set
 	// the voting status
-	bestBlock, err := b.getBestBlock()
+	bestBlock, err := b.cachedBestBlock()
 	if err != nil {
 		return nil, err
 	}
@@ -2958,23 +3183,12 @@ func (b *backend) ProcessGetAllVoteStatus() (*www.GetAllVoteStatusReply, error)
 			continue
 		}
 
-		vrr, err := b.getVoteResultsFromPlugin(i.record.CensorshipRecord.Token)
+		vsr, err := b.voteStatus(*i, bestBlock)
 		if err != nil {
 			return nil, err
 		}
 
-		vsr := www.VoteStatusReply{
-			Token:              i.record.CensorshipRecord.Token,
-			Status:             getVoteStatus(*i, bestBlock),
-			TotalVotes:         uint64(len(vrr.CastVotes)),
-			OptionsResult:      convertVoteResultsFromDecredplugin(*vrr),
-			EndHeight:          i.voting.EndHeight,
-			NumOfEligibleVotes: len(i.voting.EligibleTickets),
-			QuorumPercentage:   vrr.StartVote.Vote.QuorumPercentage,
-			PassPercentage:     vrr.StartVote.Vote.PassPercentage,
-		}
-
-		gavsr.VotesStatus = append(gavsr.VotesStatus, vsr)
+		gavsr.VotesStatus = append(gavsr.VotesStatus, *vsr)
 	}
 
 	return &gavsr, nil
@@ -2996,26 +3210,12 @@ func (b *backend) ProcessVoteStatus(token string) (*www.VoteStatusReply, error)
 		}
 	}
 
-	bestBlock, err := b.getBestBlock()
+	bestBlock, err := b.cachedBestBlock()
 	if err != nil {
 		return nil, err
 	}
 
-	vrr, err := b.getVoteResultsFromPlugin(token)
-	if err != nil {
-		return nil, err
-	}
-
-	return &www.VoteStatusReply{
-		Token:              token,
-		TotalVotes:         uint64(len(vrr.CastVotes)),
-		Status:             getVoteStatus(ir, bestBlock),
-		OptionsResult:      convertVoteResultsFromDecredplugin(*vrr),
-		EndHeight:          ir.voting.EndHeight,
-		NumOfEligibleVotes: len(ir.voting.EligibleTickets),
-		QuorumPercentage:   vrr.StartVote.Vote.QuorumPercentage,
-		PassPercentage:     vrr.StartVote.Vote.PassPercentage,
-	}, nil
+	return b.voteStatus(ir, bestBlock)
 }
 
 // ProcessUserCommentsLikes returns the votes an user has for the comments of a given proposal
@@ -3348,6 +3548,76 @@ func (b *backend) getVoteResultsFromPlugin(token string) (*decredplugin.VoteResu
 	return vrr, nil
 }
 
+// getVoteInclusionProofFromPlugin fetches the inclusion proof of a ticket
+// vote from the decred plugin.
+func (b *backend) getVoteInclusionProofFromPlugin(token, ticket string) (*decredplugin.VoteInclusionProofReply, error) {
+	payload, err := decredplugin.EncodeVoteInclusionProof(
+		decredplugin.VoteInclusionProof{
+			Token:  token,
+			Ticket: ticket,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	challenge, err := util.Random(pd.ChallengeSize)
+	if err != nil {
+		return nil, err
+	}
+
+	pc := pd.PluginCommand{
+		Challenge: hex.EncodeToString(challenge),
+		ID:        decredplugin.ID,
+		Command:   decredplugin.CmdVoteInclusionProof,
+		CommandID: decredplugin.CmdVoteInclusionProof + " " + ticket,
+		Payload:   string(payload),
+	}
+
+	responseBody, err := b.makeRequest(http.MethodPost,
+		pd.PluginCommandRoute, pc)
+	if err != nil {
+		return nil, err
+	}
+
+	var reply pd.PluginCommandReply
+	err = json.Unmarshal(responseBody, &reply)
+	if err != nil {
+		return nil, fmt.Errorf("Could not unmarshal "+
+			"PluginCommandReply: %v", err)
+	}
+
+	// Verify the challenge.
+	err = util.VerifyChallenge(b.cfg.Identity, challenge, reply.Response)
+	if err != nil {
+		return nil, err
+	}
+
+	return decredplugin.DecodeVoteInclusionProofReply([]byte(reply.Payload))
+}
+
+// loadAuditIdentity loads the identity that signs the admin audit log.  A new
+// identity is created if the file does not exist.
+func loadAuditIdentity(filename string) (*identity.FullIdentity, error) {
+	id, err := identity.LoadFullIdentity(filename)
+	if err == nil {
+		return id, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("LoadFullIdentity: %v", err)
+	}
+
+	id, err = identity.New()
+	if err != nil {
+		return nil, err
+	}
+	err = id.Save(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Save: %v", err)
+	}
+
+	return id, nil
+}
+
 // NewBackend creates a new backend context for use in www and tests.
 func NewBackend(cfg *config) (*backend, error) {
 	// Setup database.
@@ -3365,6 +3635,26 @@ func NewBackend(cfg *config) (*backend, error) {
 		userPaywallPool:           make(map[uuid.UUID]paywallPoolMember),
 		numOfPropsByUserID:        make(map[string]int),
 		userLikeActionByCommentID: make(map[string]map[string]map[string]int64),
+		loginChallenges:           make(map[string]loginChallenge),
+		loginChallengeKeys:        make(map[string][]string),
+		webhookClient:             newWebhookClient(),
+		webhookWake:               make(chan struct{}, 1),
+		voteTallies:               make(map[string]*voteTally),
+		blockTimes:                make(map[uint64]time.Time),
+	}
+
+	// Setup the identity that signs the admin audit log
+	b.auditIdentity, err = loadAuditIdentity(cfg.AuditIdentityFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load the customized email templates
+	if cfg.TemplateDir != "" {
+		b.templates, err = loadEmailTemplates(cfg.TemplateDir)
+		if err != nil {
+			return nil, fmt.Errorf("loadEmailTemplates: %v", err)
+		}
 	}
 
 	// Setup pubkey-userid map
@@ -3376,6 +3666,19 @@ func NewBackend(cfg *config) (*backend, error) {
 	// Setup events
 	b.initEventManager()
 
+	// Start delivering the queued emails and digests.
+	if cfg.Mailer != nil {
+		b.emailOutboxWake = make(chan struct{}, 1)
+		go b.runEmailOutbox()
+		go b.runEmailDigests()
+	}
+
+	// Setup the transaction source for paywall payments.
+	b.txFetcher, err = newTxFetcher(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	// Set up the code that checks for paywall payments.
 	err = b.initPaywallChecker()
 	if err != nil {
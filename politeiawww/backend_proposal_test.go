@@ -1,15 +1,20 @@
 package main
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/csv"
 	"encoding/hex"
+	"fmt"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/decred/politeia/politeiad/api/v1/identity"
 
 	"github.com/decred/dcrtime/merkle"
+	"github.com/decred/politeia/decredplugin"
 	pd "github.com/decred/politeia/politeiad/api/v1"
 	www "github.com/decred/politeia/politeiawww/api/v1"
 	"github.com/decred/politeia/politeiawww/database"
@@ -611,3 +616,137 @@ func TestPublishedProposal(t *testing.T) {
 //
 //	b.db.Close()
 //}
+
+// Tests that the censorship report lists the censored proposals and
+// comments, newest first, and that it can be paged and exported as CSV.
+func TestCensorshipReport(t *testing.T) {
+	b := createBackend(t)
+
+	count := www.CensorshipReportPageSize + 1
+	server, _ := setupTestPoliteiad(t, b, func(pc pd.PluginCommand) (string, error) {
+		if pc.Command != decredplugin.CmdCensoredComments {
+			return "", fmt.Errorf("unexpected command %v", pc.Command)
+		}
+		gcc, err := decredplugin.DecodeGetCensoredComments(
+			[]byte(pc.Payload))
+		if err != nil {
+			return "", err
+		}
+		if gcc.Token != "" {
+			return "", fmt.Errorf("unexpected token %v", gcc.Token)
+		}
+		reply, err := decredplugin.EncodeGetCensoredCommentsReply(
+			decredplugin.GetCensoredCommentsReply{
+				CensoredComments: []decredplugin.CensorComment{{
+					Token:     fmt.Sprintf("%064d", 0),
+					CommentID: "2",
+					Reason:    "spam",
+					Timestamp: int64(count + 1),
+					Receipt:   "receipt",
+				}},
+			})
+		return string(reply), err
+	})
+	defer server.Close()
+
+	for i := 0; i < count; i++ {
+		token := fmt.Sprintf("%064d", i)
+		b.inventory[token] = &inventoryRecord{
+			record: pd.Record{
+				CensorshipRecord: pd.CensorshipRecord{
+					Token:     token,
+					Signature: "signature",
+				},
+			},
+			changes: []MDStreamChanges{
+				{
+					NewStatus: pd.RecordStatusCensored,
+					Timestamp: int64(i + 1),
+					// Commas and quotes must be escaped in CSV
+					StatusChangeMessage: `spam, "ads"`,
+					AdminPubKey:         "pubkey",
+					Signature:           fmt.Sprintf("signature%v", i),
+				},
+			},
+		}
+	}
+	// Only the newest proposal censorship has a receipt
+	err := b.db.CensorshipReceiptNew(database.CensorshipReceipt{
+		Token:     fmt.Sprintf("%064d", count-1),
+		Signature: fmt.Sprintf("signature%v", count-1),
+		Receipt:   "proposal receipt",
+	})
+	assertSuccess(t, err)
+
+	// Status changes other than censorship are not reported
+	b.inventory[strings.Repeat("f", 64)] = &inventoryRecord{
+		changes: []MDStreamChanges{
+			{
+				NewStatus: pd.RecordStatusPublic,
+				Timestamp: int64(count + 2),
+			},
+		},
+	}
+
+	crr, err := b.ProcessCensorshipReport(www.CensorshipReport{})
+	assertSuccess(t, err)
+	if crr.Total != uint64(count+1) ||
+		crr.Next != www.CensorshipReportPageSize ||
+		len(crr.Actions) != www.CensorshipReportPageSize {
+		t.Fatalf("unexpected reply total %v next %v actions %v",
+			crr.Total, crr.Next, len(crr.Actions))
+	}
+	a := crr.Actions[0]
+	if a.Type != www.CensorshipActionComment || a.CommentID != "2" ||
+		a.Timestamp != int64(count+1) || a.Receipt != "receipt" {
+		t.Fatalf("unexpected comment action %v", a)
+	}
+	a = crr.Actions[1]
+	if a.Type != www.CensorshipActionProposal ||
+		a.Timestamp != int64(count) || a.Receipt != "proposal receipt" {
+		t.Fatalf("unexpected proposal action %v", a)
+	}
+	a = crr.Actions[2]
+	if a.Type != www.CensorshipActionProposal ||
+		a.Timestamp != int64(count-1) || a.Receipt != "" {
+		t.Fatalf("unexpected proposal action %v", a)
+	}
+
+	crr, err = b.ProcessCensorshipReport(www.CensorshipReport{
+		Start: crr.Next,
+	})
+	assertSuccess(t, err)
+	if crr.Next != 0 || len(crr.Actions) != 2 ||
+		crr.Actions[1].Timestamp != 1 {
+		t.Fatalf("unexpected reply %v", crr)
+	}
+
+	// CSV reports contain all actions
+	crr, err = b.ProcessCensorshipReport(www.CensorshipReport{
+		Format: www.CensorshipReportFormatCSV,
+	})
+	assertSuccess(t, err)
+	if crr.Next != 0 || len(crr.Actions) != count+1 {
+		t.Fatalf("unexpected reply next %v actions %v", crr.Next,
+			len(crr.Actions))
+	}
+
+	var buf bytes.Buffer
+	err = writeCensorshipReportCSV(&buf, crr)
+	assertSuccess(t, err)
+	records, err := csv.NewReader(&buf).ReadAll()
+	assertSuccess(t, err)
+	if len(records) != count+2 ||
+		records[count+1][1] != fmt.Sprintf("%064d", 0) ||
+		records[count+1][4] != `spam, "ads"` {
+		t.Fatalf("unexpected csv %v", records)
+	}
+
+	_, err = b.ProcessCensorshipReport(www.CensorshipReport{
+		Format: "xml",
+	})
+	assertErrorWithContext(t, err, www.ErrorStatusInvalidInput,
+		[]string{"invalid format"})
+
+	b.db.Close()
+}
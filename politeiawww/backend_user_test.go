@@ -3,8 +3,12 @@ package main
 import (
 	"bytes"
 	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"math/rand"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"runtime/debug"
@@ -14,6 +18,7 @@ import (
 
 	"github.com/agl/ed25519"
 	"github.com/decred/dcrd/chaincfg"
+	pd "github.com/decred/politeia/politeiad/api/v1"
 	"github.com/decred/politeia/politeiad/api/v1/identity"
 	www "github.com/decred/politeia/politeiawww/api/v1"
 	"github.com/decred/politeia/util"
@@ -83,10 +88,13 @@ func createBackend(t *testing.T) *backend {
 	defer os.RemoveAll(dir)
 
 	cfg := &config{
-		DataDir:       filepath.Join(dir, "data"),
-		PaywallAmount: 1e7,
-		PaywallXpub:   "tpubVobLtToNtTq6TZNw4raWQok35PRPZou53vegZqNubtBTJMMFmuMpWybFCfweJ52N8uZJPZZdHE5SRnBBuuRPfC5jdNstfKjiAs8JtbYG9jx",
-		TestNet:       true,
+		DataDir:           filepath.Join(dir, "data"),
+		AuditIdentityFile: filepath.Join(dir, "auditidentity.json"),
+		PaywallAmount:     1e7,
+		PaywallXpub:       "tpubVobLtToNtTq6TZNw4raWQok35PRPZou53vegZqNubtBTJMMFmuMpWybFCfweJ52N8uZJPZZdHE5SRnBBuuRPfC5jdNstfKjiAs8JtbYG9jx",
+		TestNet:           true,
+		TxFetchers:        util.TxFetcherFixture,
+		TxFixture:         filepath.Join(dir, "txfixture.json"),
 	}
 
 	b, err := NewBackend(cfg)
@@ -100,6 +108,53 @@ func createBackend(t *testing.T) *backend {
 	return b
 }
 
+// testPluginHandler answers a politeiad plugin command with the payload of
+// the reply.
+type testPluginHandler func(pc pd.PluginCommand) (string, error)
+
+// setupTestPoliteiad points the backend to a fake politeiad that answers the
+// plugin commands with the provided handler.  It returns the server, which
+// the caller must close, and the politeiad identity.
+func setupTestPoliteiad(t *testing.T, b *backend, handler testPluginHandler) (*httptest.Server, *identity.FullIdentity) {
+	id, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var pc pd.PluginCommand
+		err := json.NewDecoder(r.Body).Decode(&pc)
+		if err != nil {
+			t.Errorf("decode plugin command: %v", err)
+		}
+		challenge, err := hex.DecodeString(pc.Challenge)
+		if err != nil {
+			t.Errorf("decode challenge: %v", err)
+		}
+		payload, err := handler(pc)
+		if err != nil {
+			util.RespondWithJSON(w, http.StatusBadRequest,
+				pd.UserErrorReply{
+					ErrorCode: pd.ErrorStatusInvalidRequestPayload,
+				})
+			return
+		}
+		response := id.SignMessage(challenge)
+		util.RespondWithJSON(w, http.StatusOK, pd.PluginCommandReply{
+			Response:  hex.EncodeToString(response[:]),
+			ID:        pc.ID,
+			Command:   pc.Command,
+			CommandID: pc.CommandID,
+			Payload:   payload,
+		})
+	}))
+
+	b.cfg.RPCHost = server.URL
+	b.cfg.Identity = &id.Public
+	b.client = server.Client()
+	return server, id
+}
+
 func assertSuccess(t *testing.T, err error) {
 	if err != nil {
 		userErr, ok := err.(www.UserError)
@@ -481,6 +536,210 @@ func TestLoginWithVerifiedUser(t *testing.T) {
 	b.db.Close()
 }
 
+// Tests enabling two-factor authentication and logging in with TOTP and
+// recovery codes.
+func TestLoginWithTOTP(t *testing.T) {
+	b := createBackend(t)
+	u, _ := createAndVerifyUser(t, b)
+	user, _ := b.db.UserGet(u.Email)
+
+	// Verifying requires a pending secret
+	_, err := b.ProcessVerifyTOTP(&www.VerifyTOTP{}, user)
+	assertError(t, err, www.ErrorStatusTOTPNotSet)
+
+	str, err := b.ProcessSetTOTP(user)
+	assertSuccess(t, err)
+	if !strings.Contains(str.URI, str.Secret) {
+		t.Fatalf("unexpected uri %v", str.URI)
+	}
+
+	// Enable two-factor authentication
+	step := util.TOTPStep(time.Now())
+	_, err = b.ProcessVerifyTOTP(&www.VerifyTOTP{Code: "000000x"}, user)
+	assertError(t, err, www.ErrorStatusInvalidTOTPCode)
+	code, err := util.TOTPCode(str.Secret, step)
+	assertSuccess(t, err)
+	vtr, err := b.ProcessVerifyTOTP(&www.VerifyTOTP{Code: code}, user)
+	assertSuccess(t, err)
+	if len(vtr.RecoveryCodes) != totpRecoveryCodes {
+		t.Fatalf("unexpected recovery codes %v", vtr.RecoveryCodes)
+	}
+	_, err = b.ProcessSetTOTP(user)
+	assertError(t, err, www.ErrorStatusTOTPAlreadyEnabled)
+
+	// Logins require a valid code that has not been used before
+	l := www.Login{
+		Email:    u.Email,
+		Password: u.Password,
+	}
+	_, err = b.ProcessLogin(l)
+	assertError(t, err, www.ErrorStatusTOTPRequired)
+	l.Code = code
+	_, err = b.ProcessLogin(l)
+	assertError(t, err, www.ErrorStatusInvalidTOTPCode)
+	l.Code, err = util.TOTPCode(str.Secret, step+1)
+	assertSuccess(t, err)
+	lr, err := b.ProcessLogin(l)
+	assertSuccess(t, err)
+	if !lr.TOTPEnabled {
+		t.Fatalf("expected two-factor authentication to be enabled")
+	}
+
+	// Recovery codes can only be used once
+	l.Code = strings.ToUpper(vtr.RecoveryCodes[0])
+	_, err = b.ProcessLogin(l)
+	assertSuccess(t, err)
+	_, err = b.ProcessLogin(l)
+	assertError(t, err, www.ErrorStatusInvalidTOTPCode)
+
+	// Invalid codes count as failed login attempts
+	user, _ = b.db.UserGet(u.Email)
+	if user.FailedLoginAttempts != 1 {
+		t.Fatalf("unexpected failed login attempts %v",
+			user.FailedLoginAttempts)
+	}
+
+	// Admins are required to enable two-factor authentication
+	b.cfg.AdminTOTP = true
+	if b.totpRequired(user) {
+		t.Fatalf("unexpected two-factor authentication requirement")
+	}
+	user.Roles = uint64(www.UserRoleModerator)
+	resetTOTP(user)
+	if !b.totpRequired(user) {
+		t.Fatalf("expected two-factor authentication requirement")
+	}
+
+	b.db.Close()
+}
+
+// Tests logging in by signing a login challenge with the active identity.
+func TestLoginWithSignature(t *testing.T) {
+	b := createBackend(t)
+	u, id := createAndVerifyUser(t, b)
+	pk := hex.EncodeToString(id.Public.Key[:])
+	other, err := generateIdentity()
+	assertSuccess(t, err)
+
+	sign := func(id *identity.FullIdentity, challenge string) string {
+		sig := id.SignMessage([]byte(www.LoginChallengePrefix + challenge))
+		return hex.EncodeToString(sig[:])
+	}
+
+	_, err = b.ProcessLoginChallenge(&www.LoginChallenge{PublicKey: "x"})
+	assertError(t, err, www.ErrorStatusInvalidPublicKey)
+
+	// Challenges must be signed by the key they were issued for
+	lcr, err := b.ProcessLoginChallenge(&www.LoginChallenge{PublicKey: pk})
+	assertSuccess(t, err)
+	ls := www.LoginSignature{
+		PublicKey: pk,
+		Challenge: lcr.Challenge,
+		Signature: sign(other, lcr.Challenge),
+	}
+	_, err = b.ProcessLoginSignature(ls)
+	assertError(t, err, www.ErrorStatusInvalidSignature)
+
+	// Invalid signatures count as failed login attempts
+	user, _ := b.db.UserGet(u.Email)
+	if user.FailedLoginAttempts != 1 {
+		t.Fatalf("unexpected failed login attempts %v",
+			user.FailedLoginAttempts)
+	}
+
+	// Challenges can only be used once
+	_, err = b.ProcessLoginSignature(ls)
+	assertError(t, err, www.ErrorStatusInvalidLoginChallenge)
+
+	lcr, err = b.ProcessLoginChallenge(&www.LoginChallenge{PublicKey: pk})
+	assertSuccess(t, err)
+	ls.Challenge = lcr.Challenge
+	ls.Signature = sign(id, lcr.Challenge)
+	lr, err := b.ProcessLoginSignature(ls)
+	assertSuccess(t, err)
+	if lr.UserID != user.ID.String() || lr.PublicKey != pk {
+		t.Fatalf("unexpected login reply %v", lr)
+	}
+
+	// Keys that are not the active identity of a user can't login
+	otherPK := hex.EncodeToString(other.Public.Key[:])
+	lcr, err = b.ProcessLoginChallenge(&www.LoginChallenge{
+		PublicKey: otherPK,
+	})
+	assertSuccess(t, err)
+	_, err = b.ProcessLoginSignature(www.LoginSignature{
+		PublicKey: otherPK,
+		Challenge: lcr.Challenge,
+		Signature: sign(other, lcr.Challenge),
+	})
+	assertError(t, err, www.ErrorStatusInvalidSignature)
+
+	// Expired challenges are rejected
+	lcr, err = b.ProcessLoginChallenge(&www.LoginChallenge{PublicKey: pk})
+	assertSuccess(t, err)
+	b.Lock()
+	c := b.loginChallenges[lcr.Challenge]
+	c.expiry = time.Now().Add(-time.Second)
+	b.loginChallenges[lcr.Challenge] = c
+	b.Unlock()
+	ls.Challenge = lcr.Challenge
+	ls.Signature = sign(id, lcr.Challenge)
+	_, err = b.ProcessLoginSignature(ls)
+	assertError(t, err, www.ErrorStatusInvalidLoginChallenge)
+
+	// Only the most recent challenges of a public key remain valid
+	var challenges []string
+	for i := 0; i <= maxLoginChallengesPerKey; i++ {
+		lcr, err = b.ProcessLoginChallenge(&www.LoginChallenge{
+			PublicKey: pk,
+		})
+		assertSuccess(t, err)
+		challenges = append(challenges, lcr.Challenge)
+	}
+	if len(b.loginChallengeKeys[pk]) != maxLoginChallengesPerKey {
+		t.Fatalf("unexpected challenges %v", b.loginChallengeKeys[pk])
+	}
+	if b.useLoginChallenge(challenges[0], pk) {
+		t.Fatalf("discarded challenge was accepted")
+	}
+
+	// The number of outstanding challenges is limited, although public
+	// keys can still replace their own challenges, and expired challenges
+	// are pruned
+	expiry := time.Now().Add(time.Minute)
+	for i := len(b.loginChallenges); i < maxLoginChallenges; i++ {
+		key := fmt.Sprintf("key%v", i)
+		challenge := fmt.Sprintf("challenge%v", i)
+		b.loginChallenges[challenge] = loginChallenge{
+			publicKey: key,
+			expiry:    expiry,
+		}
+		b.loginChallengeKeys[key] = []string{challenge}
+	}
+	_, err = b.ProcessLoginChallenge(&www.LoginChallenge{PublicKey: otherPK})
+	assertError(t, err, www.ErrorStatusTooManyLoginChallenges)
+	_, err = b.ProcessLoginChallenge(&www.LoginChallenge{PublicKey: pk})
+	assertSuccess(t, err)
+	if b.useLoginChallenge(challenges[1], pk) {
+		t.Fatalf("discarded challenge was accepted")
+	}
+	if !b.useLoginChallenge(challenges[2], pk) {
+		t.Fatalf("challenge was not accepted")
+	}
+	for k, v := range b.loginChallenges {
+		v.expiry = time.Now().Add(-time.Second)
+		b.loginChallenges[k] = v
+	}
+	_, err = b.ProcessLoginChallenge(&www.LoginChallenge{PublicKey: otherPK})
+	assertSuccess(t, err)
+	if len(b.loginChallenges) != 1 || len(b.loginChallengeKeys) != 1 {
+		t.Fatalf("expired challenges were not pruned: %v %v",
+			len(b.loginChallenges), len(b.loginChallengeKeys))
+	}
+
+	b.db.Close()
+}
+
 // Tests changing a user's password with an incorrect current password
 // and a malformed new password.
 func TestProcessChangePasswordWithBadPasswords(t *testing.T) {
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/decred/politeia/decredplugin"
+	pd "github.com/decred/politeia/politeiad/api/v1"
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/util"
+)
+
+// getPluginCommentsLikes fetches all comment votes of a proposal, including
+// the server receipts, from the decred plugin.
+//
+// This function must be called WITHOUT the mutex held.
+func (b *backend) getPluginCommentsLikes(token string) ([]www.LikeComment, error) {
+	challenge, err := util.Random(pd.ChallengeSize)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := decredplugin.EncodeGetProposalCommentsLikes(
+		decredplugin.GetProposalCommentsLikes{
+			Token: token,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	pc := pd.PluginCommand{
+		Challenge: hex.EncodeToString(challenge),
+		ID:        decredplugin.ID,
+		Command:   decredplugin.CmdProposalCommentsLikes,
+		CommandID: decredplugin.CmdProposalCommentsLikes,
+		Payload:   string(payload),
+	}
+
+	responseBody, err := b.makeRequest(http.MethodPost,
+		pd.PluginCommandRoute, pc)
+	if err != nil {
+		return nil, err
+	}
+
+	var reply pd.PluginCommandReply
+	err = json.Unmarshal(responseBody, &reply)
+	if err != nil {
+		return nil, fmt.Errorf("Could not unmarshal "+
+			"PluginCommandReply: %v", err)
+	}
+
+	// Verify the challenge.
+	err = util.VerifyChallenge(b.cfg.Identity, challenge, reply.Response)
+	if err != nil {
+		return nil, err
+	}
+
+	gpclr, err := decredplugin.DecodeGetProposalCommentsLikesReply(
+		[]byte(reply.Payload))
+	if err != nil {
+		return nil, err
+	}
+
+	likes := make([]www.LikeComment, 0, len(gpclr.CommentsLikes))
+	for _, v := range gpclr.CommentsLikes {
+		likes = append(likes, convertDecredLikeCommentToWWWLikeComment(v))
+	}
+
+	return likes, nil
+}
+
+// getPluginCensoredComments fetches the censor records of all censored
+// comments of a proposal from the decred plugin.
+//
+// This function must be called WITHOUT the mutex held.
+func (b *backend) getPluginCensoredComments(token string) ([]www.CensorComment, error) {
+	challenge, err := util.Random(pd.ChallengeSize)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := decredplugin.EncodeGetCensoredComments(
+		decredplugin.GetCensoredComments{
+			Token: token,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	pc := pd.PluginCommand{
+		Challenge: hex.EncodeToString(challenge),
+		ID:        decredplugin.ID,
+		Command:   decredplugin.CmdCensoredComments,
+		CommandID: decredplugin.CmdCensoredComments,
+		Payload:   string(payload),
+	}
+
+	responseBody, err := b.makeRequest(http.MethodPost,
+		pd.PluginCommandRoute, pc)
+	if err != nil {
+		return nil, err
+	}
+
+	var reply pd.PluginCommandReply
+	err = json.Unmarshal(responseBody, &reply)
+	if err != nil {
+		return nil, fmt.Errorf("Could not unmarshal "+
+			"PluginCommandReply: %v", err)
+	}
+
+	// Verify the challenge.
+	err = util.VerifyChallenge(b.cfg.Identity, challenge, reply.Response)
+	if err != nil {
+		return nil, err
+	}
+
+	gccr, err := decredplugin.DecodeGetCensoredCommentsReply(
+		[]byte(reply.Payload))
+	if err != nil {
+		return nil, err
+	}
+
+	censored := make([]www.CensorComment, 0, len(gccr.CensoredComments))
+	for _, v := range gccr.CensoredComments {
+		censored = append(censored,
+			convertDecredCensorCommentToWWWCensorComment(v))
+	}
+
+	return censored, nil
+}
+
+// decodeAuthorizeVote returns the vote authorization that is stored in the
+// record metadata, or nil if the vote has never been authorized.
+func decodeAuthorizeVote(record pd.Record) (*decredplugin.AuthorizeVote, error) {
+	for _, m := range record.Metadata {
+		if m.ID != decredplugin.MDStreamAuthorizeVote {
+			continue
+		}
+
+		d := json.NewDecoder(strings.NewReader(m.Payload))
+		var av decredplugin.AuthorizeVote
+		if err := d.Decode(&av); err == io.EOF {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		return &av, nil
+	}
+	return nil, nil
+}
+
+// ProcessProposalBundle returns a public proposal together with its entire
+// discussion and all of the server receipts that were issued for it.
+func (b *backend) ProcessProposalBundle(token string) (*www.ProposalBundleReply, error) {
+	log.Tracef("ProcessProposalBundle: %v", token)
+
+	pdr, err := b.ProcessProposalDetails(www.ProposalsDetails{
+		Token: token,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if pdr.Proposal.State != www.PropStateVetted {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusWrongStatus,
+		}
+	}
+
+	reply := www.ProposalBundleReply{
+		ServerPublicKey: hex.EncodeToString(b.cfg.Identity.Key[:]),
+		Timestamp:       time.Now().Unix(),
+		Proposal:        pdr.Proposal,
+	}
+
+	b.RLock()
+	ir, err := b._getInventoryRecord(token)
+	if err != nil {
+		b.RUnlock()
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusProposalNotFound,
+		}
+	}
+	reply.Comments = make([]www.Comment, 0, len(ir.comments))
+	for _, c := range ir.comments {
+		reply.Comments = append(reply.Comments, c)
+	}
+	if ir.voting.StartBlockHash != "" {
+		sv := ir.votebits
+		svr := ir.voting
+		reply.StartVote = &sv
+		reply.StartVoteReply = &svr
+	}
+	av, err := decodeAuthorizeVote(ir.record)
+	b.RUnlock()
+	if err != nil {
+		return nil, fmt.Errorf("decodeAuthorizeVote: %v", err)
+	}
+
+	// Comment IDs are sequential so order the comments the same way
+	// they were made.
+	sort.Slice(reply.Comments, func(i, j int) bool {
+		ci, _ := strconv.ParseUint(reply.Comments[i].CommentID, 10, 64)
+		cj, _ := strconv.ParseUint(reply.Comments[j].CommentID, 10, 64)
+		return ci < cj
+	})
+
+	if av != nil {
+		reply.AuthorizeVote = &www.AuthorizeVote{
+			Action:    av.Action,
+			Token:     av.Token,
+			Signature: av.Signature,
+			PublicKey: av.PublicKey,
+		}
+		reply.AuthorizeVoteReply = &www.AuthorizeVoteReply{
+			Action:  av.Action,
+			Receipt: av.Receipt,
+		}
+	}
+
+	reply.CommentsLikes, err = b.getPluginCommentsLikes(token)
+	if err != nil {
+		return nil, fmt.Errorf("getPluginCommentsLikes: %v", err)
+	}
+	reply.CensoredComments, err = b.getPluginCensoredComments(token)
+	if err != nil {
+		return nil, fmt.Errorf("getPluginCensoredComments: %v", err)
+	}
+
+	return &reply, nil
+}
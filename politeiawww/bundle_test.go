@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/decred/politeia/decredplugin"
+	pd "github.com/decred/politeia/politeiad/api/v1"
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/slog"
+)
+
+// Tests that the proposal bundle contains the proposal discussion and the
+// receipts that politeiad returns for it.
+func TestProcessProposalBundle(t *testing.T) {
+	defer log.SetLevel(log.Level())
+	log.SetLevel(slog.LevelOff)
+
+	b := createBackend(t)
+	defer b.db.Close()
+
+	public := strings.Repeat("1", 64)
+	unvetted := strings.Repeat("2", 64)
+	server, id := setupTestPoliteiad(t, b, func(pc pd.PluginCommand) (string, error) {
+		switch pc.Command {
+		case decredplugin.CmdProposalCommentsLikes:
+			gpcl, err := decredplugin.DecodeGetProposalCommentsLikes(
+				[]byte(pc.Payload))
+			if err != nil {
+				return "", err
+			}
+			reply, err := decredplugin.EncodeGetProposalCommentsLikesReply(
+				decredplugin.GetProposalCommentsLikesReply{
+					CommentsLikes: []decredplugin.LikeComment{{
+						Token:     gpcl.Token,
+						CommentID: "1",
+						Action:    "1",
+						Receipt:   "like receipt",
+					}},
+				})
+			return string(reply), err
+		case decredplugin.CmdCensoredComments:
+			gcc, err := decredplugin.DecodeGetCensoredComments(
+				[]byte(pc.Payload))
+			if err != nil {
+				return "", err
+			}
+			reply, err := decredplugin.EncodeGetCensoredCommentsReply(
+				decredplugin.GetCensoredCommentsReply{
+					CensoredComments: []decredplugin.CensorComment{{
+						Token:     gcc.Token,
+						CommentID: "2",
+						Reason:    "spam",
+						Receipt:   "censor receipt",
+					}},
+				})
+			return string(reply), err
+		}
+		return "", fmt.Errorf("unexpected command %v", pc.Command)
+	})
+	defer server.Close()
+
+	newRecord := func(token string, status pd.RecordStatusT) *inventoryRecord {
+		return &inventoryRecord{
+			record: pd.Record{
+				Status: status,
+				CensorshipRecord: pd.CensorshipRecord{
+					Token: token,
+				},
+			},
+			comments: make(map[string]www.Comment),
+		}
+	}
+	b.inventory[unvetted] = newRecord(unvetted, pd.RecordStatusNotReviewed)
+	ir := newRecord(public, pd.RecordStatusPublic)
+	ir.record.Metadata = []pd.MetadataStream{{
+		ID: decredplugin.MDStreamAuthorizeVote,
+		Payload: `{"action":"authorize","token":"` + public +
+			`","receipt":"authorize receipt"}`,
+	}}
+	for _, id := range []string{"10", "2", "1"} {
+		ir.comments[id] = www.Comment{
+			Token:     public,
+			CommentID: id,
+			Receipt:   "comment receipt " + id,
+		}
+	}
+	ir.votebits = www.StartVote{Vote: www.Vote{Token: public}}
+	ir.voting = www.StartVoteReply{StartBlockHash: "hash"}
+	b.inventory[public] = ir
+
+	_, err := b.ProcessProposalBundle(unvetted)
+	assertError(t, err, www.ErrorStatusWrongStatus)
+	_, err = b.ProcessProposalBundle(strings.Repeat("3", 64))
+	assertError(t, err, www.ErrorStatusProposalNotFound)
+
+	reply, err := b.ProcessProposalBundle(public)
+	assertSuccess(t, err)
+	if reply.ServerPublicKey != id.Public.String() ||
+		reply.Proposal.CensorshipRecord.Token != public {
+		t.Fatalf("unexpected proposal %v %v", reply.ServerPublicKey,
+			reply.Proposal)
+	}
+
+	// Comments are ordered by their IDs.
+	if len(reply.Comments) != 3 || reply.Comments[0].CommentID != "1" ||
+		reply.Comments[1].CommentID != "2" ||
+		reply.Comments[2].CommentID != "10" ||
+		reply.Comments[2].Receipt != "comment receipt 10" {
+		t.Fatalf("unexpected comments %v", reply.Comments)
+	}
+	if len(reply.CommentsLikes) != 1 ||
+		reply.CommentsLikes[0].Token != public ||
+		reply.CommentsLikes[0].Receipt != "like receipt" {
+		t.Fatalf("unexpected comments likes %v", reply.CommentsLikes)
+	}
+	if len(reply.CensoredComments) != 1 ||
+		reply.CensoredComments[0].Token != public ||
+		reply.CensoredComments[0].Receipt != "censor receipt" {
+		t.Fatalf("unexpected censored comments %v",
+			reply.CensoredComments)
+	}
+	if reply.AuthorizeVote == nil || reply.AuthorizeVoteReply == nil ||
+		reply.AuthorizeVote.Token != public ||
+		reply.AuthorizeVoteReply.Receipt != "authorize receipt" {
+		t.Fatalf("unexpected vote authorization %v %v",
+			reply.AuthorizeVote, reply.AuthorizeVoteReply)
+	}
+	if reply.StartVote == nil || reply.StartVoteReply == nil ||
+		reply.StartVote.Vote.Token != public ||
+		reply.StartVoteReply.StartBlockHash != "hash" {
+		t.Fatalf("unexpected vote %v %v", reply.StartVote,
+			reply.StartVoteReply)
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	pd "github.com/decred/politeia/politeiad/api/v1"
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/politeiawww/database"
+)
+
+// _getProposalCensorshipActions returns the censorship actions that are
+// recorded in the changes metadata of the proposals in the inventory.  The
+// receipts are looked up by the admin signature of the censorship.
+//
+// This function must be called WITH the mutex held.
+func (b *backend) _getProposalCensorshipActions(receipts map[string]string) []www.CensorshipAction {
+	actions := make([]www.CensorshipAction, 0, b.numOfCensored)
+	for token, ir := range b.inventory {
+		for _, c := range ir.changes {
+			if c.NewStatus != pd.RecordStatusCensored {
+				continue
+			}
+			actions = append(actions, www.CensorshipAction{
+				Type:      www.CensorshipActionProposal,
+				Token:     token,
+				Timestamp: c.Timestamp,
+				Reason:    c.StatusChangeMessage,
+				PublicKey: c.AdminPubKey,
+				Signature: c.Signature,
+				Receipt:   receipts[c.Signature],
+			})
+		}
+	}
+	return actions
+}
+
+// getCensorshipActions returns all proposal and comment censorship actions,
+// newest first.
+//
+// This function must be called WITHOUT the mutex held.
+func (b *backend) getCensorshipActions() ([]www.CensorshipAction, error) {
+	// The proposal censorship receipts are kept in the database.
+	receipts := make(map[string]string)
+	err := b.db.AllCensorshipReceipts(func(r *database.CensorshipReceipt) {
+		if r.Signature != "" {
+			receipts[r.Signature] = r.Receipt
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("AllCensorshipReceipts: %v", err)
+	}
+
+	b.RLock()
+	actions := b._getProposalCensorshipActions(receipts)
+	b.RUnlock()
+
+	// The comment censor records are kept in the decred plugin
+	// journals.
+	censored, err := b.getPluginCensoredComments("")
+	if err != nil {
+		return nil, fmt.Errorf("getPluginCensoredComments: %v", err)
+	}
+	for _, v := range censored {
+		actions = append(actions, www.CensorshipAction{
+			Type:      www.CensorshipActionComment,
+			Token:     v.Token,
+			CommentID: v.CommentID,
+			Timestamp: v.Timestamp,
+			Reason:    v.Reason,
+			PublicKey: v.PublicKey,
+			Signature: v.Signature,
+			Receipt:   v.Receipt,
+		})
+	}
+
+	sort.Slice(actions, func(i, j int) bool {
+		if actions[i].Timestamp != actions[j].Timestamp {
+			return actions[i].Timestamp > actions[j].Timestamp
+		}
+		if actions[i].Token != actions[j].Token {
+			return actions[i].Token < actions[j].Token
+		}
+		ci, _ := strconv.ParseUint(actions[i].CommentID, 10, 64)
+		cj, _ := strconv.ParseUint(actions[j].CommentID, 10, 64)
+		return ci < cj
+	})
+
+	return actions, nil
+}
+
+// ProcessCensorshipReport returns a page of the public record of all
+// censorship actions.  CSV reports are exports and are not paged; they contain
+// all actions after Start.
+func (b *backend) ProcessCensorshipReport(cr www.CensorshipReport) (*www.CensorshipReportReply, error) {
+	log.Tracef("ProcessCensorshipReport: %v", cr.Start)
+
+	switch cr.Format {
+	case "", www.CensorshipReportFormatJSON, www.CensorshipReportFormatCSV:
+	default:
+		return nil, www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidInput,
+			ErrorContext: []string{"invalid format"},
+		}
+	}
+
+	actions, err := b.getCensorshipActions()
+	if err != nil {
+		return nil, err
+	}
+
+	reply := www.CensorshipReportReply{
+		ServerPublicKey: hex.EncodeToString(b.cfg.Identity.Key[:]),
+		Total:           uint64(len(actions)),
+		Actions:         []www.CensorshipAction{},
+	}
+	if cr.Start >= reply.Total {
+		return &reply, nil
+	}
+	end := cr.Start + www.CensorshipReportPageSize
+	if cr.Format == www.CensorshipReportFormatCSV {
+		end = reply.Total
+	}
+	if end < reply.Total {
+		reply.Next = end
+	} else {
+		end = reply.Total
+	}
+	reply.Actions = actions[cr.Start:end]
+
+	return &reply, nil
+}
+
+// writeCensorshipReportCSV writes the censorship actions of a report as CSV,
+// preceded by a header row.
+func writeCensorshipReportCSV(w io.Writer, crr *www.CensorshipReportReply) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{"type", "token", "commentid", "timestamp",
+		"reason", "publickey", "signature", "receipt"})
+	if err != nil {
+		return err
+	}
+	for _, v := range crr.Actions {
+		err := cw.Write([]string{
+			v.Type,
+			v.Token,
+			v.CommentID,
+			strconv.FormatInt(v.Timestamp, 10),
+			v.Reason,
+			v.PublicKey,
+			v.Signature,
+			v.Receipt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/decred/politeia/politeiawww/sharedconfig"
 	"github.com/syndtr/goleveldb/leveldb"
 	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
 var (
@@ -27,6 +28,7 @@ var (
 	dumpDb     = flag.Bool("dump", false, "Dump the entire politeiawww database contents or contents for a specific user. Parameters: [email]")
 	setAdmin   = flag.Bool("setadmin", false, "Set the admin flag for a user. Parameters: <email> <true/false>")
 	testnet    = flag.Bool("testnet", false, "Whether to check the testnet database or not.")
+	verifyLog  = flag.Bool("verifyadminlog", false, "Verify the hash chain and the signatures of the admin audit log. Parameters: [pubkey]")
 	dbDir      = ""
 )
 
@@ -75,6 +77,63 @@ func dumpAction() error {
 		} else if string(key) == localdb.LastPaywallAddressIndex {
 			fmt.Printf("Key    : %v\n", string(key))
 			fmt.Printf("Record : %v\n", binary.LittleEndian.Uint64(value))
+		} else if strings.HasPrefix(string(key), localdb.APIKeyPrefix) {
+			k, err := localdb.DecodeAPIKey(value)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Key    : %v\n", string(key))
+			fmt.Printf("Record : %v", spew.Sdump(k))
+		} else if strings.HasPrefix(string(key), localdb.SessionPrefix) {
+			s, err := localdb.DecodeSession(value)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Key    : %v\n", string(key))
+			fmt.Printf("Record : %v", spew.Sdump(s))
+		} else if strings.HasPrefix(string(key), localdb.AdminLogPrefix) {
+			e, err := localdb.DecodeAdminLogEntry(value)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Key    : %v\n", string(key))
+			fmt.Printf("Record : %v", spew.Sdump(e))
+		} else if strings.HasPrefix(string(key), localdb.EmailPrefix) {
+			e, err := localdb.DecodeEmail(value)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Key    : %v\n", string(key))
+			fmt.Printf("Record : %v", spew.Sdump(e))
+		} else if strings.HasPrefix(string(key), localdb.EmailDigestPrefix) {
+			d, err := localdb.DecodeEmailDigest(value)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Key    : %v\n", string(key))
+			fmt.Printf("Record : %v", spew.Sdump(d))
+		} else if strings.HasPrefix(string(key), localdb.WebhookPrefix) {
+			w, err := localdb.DecodeWebhook(value)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Key    : %v\n", string(key))
+			fmt.Printf("Record : %v", spew.Sdump(w))
+		} else if strings.HasPrefix(string(key),
+			localdb.WebhookDeliveryPrefix) {
+			d, err := localdb.DecodeWebhookDelivery(value)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Key    : %v\n", string(key))
+			fmt.Printf("Record : %v", spew.Sdump(d))
 		} else {
 			u, err := localdb.DecodeUser(value)
 			if err != nil {
@@ -196,6 +255,66 @@ func addCreditsAction() error {
 	return nil
 }
 
+func verifyAdminLogAction() error {
+	// The public key of the server audit identity is optional.  If it is
+	// not provided, all entries must be signed by the key of the first
+	// entry.
+	var pubkey string
+	args := flag.Args()
+	if len(args) == 1 {
+		pubkey = args[0]
+	}
+
+	userdb, err := leveldb.OpenFile(dbDir, &opt.Options{
+		ErrorIfMissing: true,
+	})
+	if err != nil {
+		return err
+	}
+	defer userdb.Close()
+
+	var (
+		prev  *database.AdminLogEntry
+		count uint64
+	)
+	iter := userdb.NewIterator(util.BytesPrefix([]byte(localdb.AdminLogPrefix)),
+		nil)
+	defer iter.Release()
+	for iter.Next() {
+		e, err := localdb.DecodeAdminLogEntry(iter.Value())
+		if err != nil {
+			return err
+		}
+
+		if pubkey == "" {
+			pubkey = e.PublicKey
+		}
+		if e.PublicKey != pubkey {
+			return fmt.Errorf("entry %v: unexpected public key %v",
+				e.Index, e.PublicKey)
+		}
+		err = database.VerifyAdminLogEntry(e, prev)
+		if err != nil {
+			return err
+		}
+
+		prev = e
+		count++
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+
+	if count == 0 {
+		fmt.Printf("Admin log is empty\n")
+		return nil
+	}
+	fmt.Printf("Admin log verified: %v entries signed by %v\n", count,
+		pubkey)
+	fmt.Printf("Last entry hash   : %v\n", prev.Hash)
+	return nil
+}
+
 func _main() error {
 	flag.Parse()
 
@@ -226,6 +345,10 @@ func _main() error {
 		if err := setAdminAction(); err != nil {
 			return err
 		}
+	} else if *verifyLog {
+		if err := verifyAdminLogAction(); err != nil {
+			return err
+		}
 	} else {
 		flag.Usage()
 	}
@@ -123,6 +123,9 @@ func (c *Client) makeRequest(method, route string, body interface{}) ([]byte, er
 		return nil, err
 	}
 	req.Header.Add(v1.CsrfToken, c.cfg.CSRF)
+	if c.cfg.APIKey != "" {
+		req.Header.Add(v1.APIKeyHeader, c.cfg.APIKey)
+	}
 
 	// Send request
 	r, err := c.http.Do(req)
@@ -232,13 +235,45 @@ func (c *Client) Version() (*v1.VersionReply, error) {
 }
 
 func (c *Client) Login(l *v1.Login) (*v1.LoginReply, error) {
+	return c.login(v1.RouteLogin, l)
+}
+
+func (c *Client) LoginChallenge(lc *v1.LoginChallenge) (*v1.LoginChallengeReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RouteLoginChallenge, lc)
+	if err != nil {
+		return nil, err
+	}
+
+	var lcr v1.LoginChallengeReply
+	err = json.Unmarshal(responseBody, &lcr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal LoginChallengeReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := PrettyPrintJSON(lcr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &lcr, nil
+}
+
+func (c *Client) LoginSignature(ls *v1.LoginSignature) (*v1.LoginReply, error) {
+	return c.login(v1.RouteLoginSignature, ls)
+}
+
+// login sends a login request to the provided route and persists the session
+// data on success.
+func (c *Client) login(route string, l interface{}) (*v1.LoginReply, error) {
 	// Setup request
 	requestBody, err := json.Marshal(l)
 	if err != nil {
 		return nil, err
 	}
 
-	fullRoute := c.cfg.Host + v1.PoliteiaWWWAPIRoute + v1.RouteLogin
+	fullRoute := c.cfg.Host + v1.PoliteiaWWWAPIRoute + route
 
 	// Print request details
 	if c.cfg.Verbose {
@@ -836,6 +871,74 @@ func (c *Client) CensorComment(cc *v1.CensorComment) (*v1.CensorCommentReply, er
 	return &ccr, nil
 }
 
+func (c *Client) ReportComment(rc *v1.ReportComment) (*v1.ReportCommentReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RouteReportComment, rc)
+	if err != nil {
+		return nil, err
+	}
+
+	var rcr v1.ReportCommentReply
+	err = json.Unmarshal(responseBody, &rcr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal ReportCommentReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := PrettyPrintJSON(rcr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &rcr, nil
+}
+
+func (c *Client) ReportedComments() (*v1.ReportedCommentsReply, error) {
+	responseBody, err := c.makeRequest("GET", v1.RouteReportedComments, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var rcr v1.ReportedCommentsReply
+	err = json.Unmarshal(responseBody, &rcr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal ReportedCommentsReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := PrettyPrintJSON(rcr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &rcr, nil
+}
+
+func (c *Client) ResolveCommentReport(rcr *v1.ResolveCommentReport) (*v1.ResolveCommentReportReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RouteResolveCommentReport,
+		rcr)
+	if err != nil {
+		return nil, err
+	}
+
+	var reply v1.ResolveCommentReportReply
+	err = json.Unmarshal(responseBody, &reply)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal ResolveCommentReportReply: %v",
+			err)
+	}
+
+	if c.cfg.Verbose {
+		err := PrettyPrintJSON(reply)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &reply, nil
+}
+
 func (c *Client) StartVote(sv *v1.StartVote) (*v1.StartVoteReply, error) {
 	responseBody, err := c.makeRequest("POST", v1.RouteStartVote, sv)
 	if err != nil {
@@ -946,6 +1049,160 @@ func (c *Client) Users(u *v1.Users) (*v1.UsersReply, error) {
 	return &ur, nil
 }
 
+func (c *Client) AdminLog(al *v1.AdminLog) (*v1.AdminLogReply, error) {
+	responseBody, err := c.makeRequest("GET", v1.RouteAdminLog, al)
+	if err != nil {
+		return nil, err
+	}
+
+	var alr v1.AdminLogReply
+	err = json.Unmarshal(responseBody, &alr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal AdminLogReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := PrettyPrintJSON(alr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &alr, nil
+}
+
+func (c *Client) EmailOutbox(eo *v1.EmailOutbox) (*v1.EmailOutboxReply, error) {
+	responseBody, err := c.makeRequest("GET", v1.RouteEmailOutbox, eo)
+	if err != nil {
+		return nil, err
+	}
+
+	var eor v1.EmailOutboxReply
+	err = json.Unmarshal(responseBody, &eor)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal EmailOutboxReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := PrettyPrintJSON(eor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &eor, nil
+}
+
+func (c *Client) EmailTemplates(et *v1.EmailTemplates) (*v1.EmailTemplatesReply, error) {
+	responseBody, err := c.makeRequest("GET", v1.RouteEmailTemplates, et)
+	if err != nil {
+		return nil, err
+	}
+
+	var etr v1.EmailTemplatesReply
+	err = json.Unmarshal(responseBody, &etr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal EmailTemplatesReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := PrettyPrintJSON(etr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &etr, nil
+}
+
+func (c *Client) NewWebhook(nw *v1.NewWebhook) (*v1.NewWebhookReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RouteNewWebhook, nw)
+	if err != nil {
+		return nil, err
+	}
+
+	var nwr v1.NewWebhookReply
+	err = json.Unmarshal(responseBody, &nwr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal NewWebhookReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := PrettyPrintJSON(nwr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &nwr, nil
+}
+
+func (c *Client) Webhooks() (*v1.WebhooksReply, error) {
+	responseBody, err := c.makeRequest("GET", v1.RouteWebhooks, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var wr v1.WebhooksReply
+	err = json.Unmarshal(responseBody, &wr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal WebhooksReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := PrettyPrintJSON(wr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &wr, nil
+}
+
+func (c *Client) DeleteWebhook(dw *v1.DeleteWebhook) (*v1.DeleteWebhookReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RouteDeleteWebhook, dw)
+	if err != nil {
+		return nil, err
+	}
+
+	var dwr v1.DeleteWebhookReply
+	err = json.Unmarshal(responseBody, &dwr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal DeleteWebhookReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := PrettyPrintJSON(dwr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &dwr, nil
+}
+
+func (c *Client) WebhookDeliveries(wd *v1.WebhookDeliveries) (*v1.WebhookDeliveriesReply, error) {
+	responseBody, err := c.makeRequest("GET", v1.RouteWebhookDeliveries, wd)
+	if err != nil {
+		return nil, err
+	}
+
+	var wdr v1.WebhookDeliveriesReply
+	err = json.Unmarshal(responseBody, &wdr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal WebhookDeliveriesReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := PrettyPrintJSON(wdr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &wdr, nil
+}
+
 func (c *Client) ManageUser(mu *v1.ManageUser) (*v1.ManageUserReply, error) {
 	responseBody, err := c.makeRequest("POST", v1.RouteManageUser, mu)
 	if err != nil {
@@ -990,6 +1247,160 @@ func (c *Client) EditUser(eu *v1.EditUser) (*v1.EditUserReply, error) {
 	return &eur, nil
 }
 
+func (c *Client) SetTOTP(st *v1.SetTOTP) (*v1.SetTOTPReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RouteSetTOTP, st)
+	if err != nil {
+		return nil, err
+	}
+
+	var str v1.SetTOTPReply
+	err = json.Unmarshal(responseBody, &str)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal SetTOTPReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := PrettyPrintJSON(str)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &str, nil
+}
+
+func (c *Client) VerifyTOTP(vt *v1.VerifyTOTP) (*v1.VerifyTOTPReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RouteVerifyTOTP, vt)
+	if err != nil {
+		return nil, err
+	}
+
+	var vtr v1.VerifyTOTPReply
+	err = json.Unmarshal(responseBody, &vtr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal VerifyTOTPReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := PrettyPrintJSON(vtr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &vtr, nil
+}
+
+func (c *Client) NewAPIKey(nak *v1.NewAPIKey) (*v1.NewAPIKeyReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RouteNewAPIKey, nak)
+	if err != nil {
+		return nil, err
+	}
+
+	var nakr v1.NewAPIKeyReply
+	err = json.Unmarshal(responseBody, &nakr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal NewAPIKeyReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := PrettyPrintJSON(nakr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &nakr, nil
+}
+
+func (c *Client) APIKeys() (*v1.APIKeysReply, error) {
+	responseBody, err := c.makeRequest("GET", v1.RouteAPIKeys, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var akr v1.APIKeysReply
+	err = json.Unmarshal(responseBody, &akr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal APIKeysReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := PrettyPrintJSON(akr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &akr, nil
+}
+
+func (c *Client) RevokeAPIKey(rak *v1.RevokeAPIKey) (*v1.RevokeAPIKeyReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RouteRevokeAPIKey, rak)
+	if err != nil {
+		return nil, err
+	}
+
+	var rakr v1.RevokeAPIKeyReply
+	err = json.Unmarshal(responseBody, &rakr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal RevokeAPIKeyReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := PrettyPrintJSON(rakr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &rakr, nil
+}
+
+func (c *Client) Sessions() (*v1.SessionsReply, error) {
+	responseBody, err := c.makeRequest("GET", v1.RouteSessions, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var sr v1.SessionsReply
+	err = json.Unmarshal(responseBody, &sr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal SessionsReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := PrettyPrintJSON(sr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &sr, nil
+}
+
+func (c *Client) RevokeSessions(rs *v1.RevokeSessions) (*v1.RevokeSessionsReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RouteRevokeSessions, rs)
+	if err != nil {
+		return nil, err
+	}
+
+	var rsr v1.RevokeSessionsReply
+	err = json.Unmarshal(responseBody, &rsr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal RevokeSessionsReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := PrettyPrintJSON(rsr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &rsr, nil
+}
+
 func (c *Client) AuthorizeVote(av *v1.AuthorizeVote) (*v1.AuthorizeVoteReply, error) {
 	responseBody, err := c.makeRequest("POST", "/proposals/authorizevote", av)
 	if err != nil {
@@ -1035,6 +1446,83 @@ func (c *Client) VoteStatus(token string) (*v1.VoteStatusReply, error) {
 	return &vsr, nil
 }
 
+func (c *Client) CensorshipReport(cr *v1.CensorshipReport) (*v1.CensorshipReportReply, error) {
+	responseBody, err := c.makeRequest("GET", v1.RouteCensorshipReport, cr)
+	if err != nil {
+		return nil, err
+	}
+
+	var crr v1.CensorshipReportReply
+	err = json.Unmarshal(responseBody, &crr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal CensorshipReportReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := PrettyPrintJSON(crr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &crr, nil
+}
+
+// CensorshipReportCSV returns a page of the censorship report in CSV format.
+func (c *Client) CensorshipReportCSV(start uint64) ([]byte, error) {
+	return c.makeRequest("GET", v1.RouteCensorshipReport,
+		&v1.CensorshipReport{
+			Start:  start,
+			Format: v1.CensorshipReportFormatCSV,
+		})
+}
+
+func (c *Client) ProposalBundle(token string) (*v1.ProposalBundleReply, error) {
+	route := "/proposals/" + token + "/bundle"
+	responseBody, err := c.makeRequest("GET", route, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var pbr v1.ProposalBundleReply
+	err = json.Unmarshal(responseBody, &pbr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal ProposalBundleReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := PrettyPrintJSON(pbr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &pbr, nil
+}
+
+func (c *Client) VoteInclusionProof(token, ticket string) (*v1.VoteInclusionProofReply, error) {
+	route := "/proposals/" + token + "/votes/" + ticket + "/proof"
+	responseBody, err := c.makeRequest("GET", route, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var vipr v1.VoteInclusionProofReply
+	err = json.Unmarshal(responseBody, &vipr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal VoteInclusionProofReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := PrettyPrintJSON(vipr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &vipr, nil
+}
+
 func (c *Client) ActiveVotes() (*v1.ActiveVoteReply, error) {
 	responseBody, err := c.makeRequest("GET", v1.RouteActiveVote, nil)
 	if err != nil {
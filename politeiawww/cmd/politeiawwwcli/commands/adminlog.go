@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// Help message displayed for the command 'politeiawwwcli help adminlog'
+var AdminLogCmdHelpMsg = `adminlog
+
+Fetch the entries of the admin audit log, optionally filtering them by admin,
+target user, proposal token and time range.  Requires admin privileges.
+
+Flags:
+  --adminid    (string, optional)  Only entries of this admin
+  --userid     (string, optional)  Only entries that target this user
+  --token      (string, optional)  Only entries that target this proposal
+  --after      (int64, optional)   Only entries at or after this UNIX timestamp
+  --before     (int64, optional)   Only entries before this UNIX timestamp
+  --start      (uint64, optional)  Index of the first entry to consider
+
+Example:
+adminlog --userid=9b9e2bc3-e1e6-4d91-8cc3-a5ea2d6a3b6d
+
+Result:
+{
+  "entries": [
+    {
+      "index":          (uint64)  Position in the log
+      "timestamp":      (int64)   UNIX timestamp of the action
+      "adminid":        (string)  ID of the admin
+      "adminusername":  (string)  Username of the admin
+      "action":         (string)  Description of the action
+      "userid":         (string)  Target user ID
+      "token":          (string)  Target proposal censorship token
+      "commentid":      (string)  Target comment ID
+      "reason":         (string)  Reason given by the admin
+      "prevhash":       (string)  Hash of the previous entry
+      "hash":           (string)  Hash of this entry
+      "publickey":      (string)  Server audit public key
+      "signature":      (string)  Signature of hash
+    }
+  ],
+  "next":       (uint64)  Start of the next page, 0 if there are no more entries
+  "publickey":  (string)  Server audit public key
+}`
+
+type AdminLogCmd struct {
+	AdminID string `long:"adminid" description:"Only entries of this admin"`
+	UserID  string `long:"userid" description:"Only entries that target this user"`
+	Token   string `long:"token" description:"Only entries that target this proposal"`
+	After   int64  `long:"after" description:"Only entries at or after this UNIX timestamp"`
+	Before  int64  `long:"before" description:"Only entries before this UNIX timestamp"`
+	Start   uint64 `long:"start" description:"Index of the first entry to consider"`
+}
+
+func (cmd *AdminLogCmd) Execute(args []string) error {
+	alr, err := c.AdminLog(&v1.AdminLog{
+		AdminID: cmd.AdminID,
+		UserID:  cmd.UserID,
+		Token:   cmd.Token,
+		After:   cmd.After,
+		Before:  cmd.Before,
+		Start:   cmd.Start,
+	})
+	if err != nil {
+		return err
+	}
+	return Print(alr, cfg.Verbose, cfg.RawJSON)
+}
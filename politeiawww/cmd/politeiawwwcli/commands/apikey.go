@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// Help message displayed for the command 'politeiawwwcli help apikey'
+var APIKeyCmdHelpMsg = `apikey new "name" "scopes"
+apikey list
+apikey revoke "id"
+
+Create, list and revoke the API keys of the logged in user.  API keys are used
+instead of the session cookies by passing them using the --apikey flag.  The
+token of a new key is only shown once.
+
+Arguments (new):
+1. name       (string, required)   Description of the key
+2. scopes     (string, required)   Comma separated list of scopes
+
+Arguments (revoke):
+1. id         (string, required)   Key id
+
+Valid scopes are:
+read         Read only routes
+comment      Submit and vote on comments
+voteadmin    Start proposal votes
+
+Result (new):
+{
+  "apikey": {
+    "id":          (string)  Key id
+    "name":        (string)  Description of the key
+    "scopes":      (uint64)  Scopes bit flag
+    "createdat":   (int64)   Unix timestamp of key creation
+    "lastused":    (int64)   Unix timestamp of last use
+  },
+  "token":         (string)  Token that is passed using --apikey
+}`
+
+type APIKeyCmd struct {
+	New    APIKeyNewCmd    `command:"new" description:"create a new API key"`
+	List   APIKeyListCmd   `command:"list" description:"list your API keys"`
+	Revoke APIKeyRevokeCmd `command:"revoke" description:"revoke an API key"`
+}
+
+type APIKeyNewCmd struct {
+	Args struct {
+		Name   string `positional-arg-name:"name"`
+		Scopes string `positional-arg-name:"scopes"`
+	} `positional-args:"true" required:"true"`
+}
+
+func (cmd *APIKeyNewCmd) Execute(args []string) error {
+	// Parse scopes.  These can be either the numeric bit flag or a
+	// comma separated list of human readable scopes.
+	var scopes uint64
+	s, err := strconv.ParseUint(cmd.Args.Scopes, 10, 64)
+	if err == nil {
+		scopes = s
+	} else {
+		for _, name := range strings.Split(cmd.Args.Scopes, ",") {
+			var found bool
+			for k, v := range v1.APIKeyScope {
+				if v == strings.TrimSpace(name) {
+					scopes |= uint64(k)
+					found = true
+				}
+			}
+			if !found {
+				return fmt.Errorf("Invalid scope %v.  Valid scopes "+
+					"are read, comment and voteadmin", name)
+			}
+		}
+	}
+
+	// Setup request
+	nak := &v1.NewAPIKey{
+		Name:   cmd.Args.Name,
+		Scopes: scopes,
+	}
+
+	// Print request details
+	err = Print(nak, cfg.Verbose, cfg.RawJSON)
+	if err != nil {
+		return err
+	}
+
+	// Send request
+	nakr, err := c.NewAPIKey(nak)
+	if err != nil {
+		return err
+	}
+
+	// Print response details
+	return Print(nakr, cfg.Verbose, cfg.RawJSON)
+}
+
+type APIKeyListCmd struct{}
+
+func (cmd *APIKeyListCmd) Execute(args []string) error {
+	akr, err := c.APIKeys()
+	if err != nil {
+		return err
+	}
+
+	// Print response details
+	return Print(akr, cfg.Verbose, cfg.RawJSON)
+}
+
+type APIKeyRevokeCmd struct {
+	Args struct {
+		ID string `positional-arg-name:"id"`
+	} `positional-args:"true" required:"true"`
+}
+
+func (cmd *APIKeyRevokeCmd) Execute(args []string) error {
+	// Setup request
+	rak := &v1.RevokeAPIKey{
+		ID: cmd.Args.ID,
+	}
+
+	// Print request details
+	err := Print(rak, cfg.Verbose, cfg.RawJSON)
+	if err != nil {
+		return err
+	}
+
+	// Send request
+	rakr, err := c.RevokeAPIKey(rak)
+	if err != nil {
+		return err
+	}
+
+	// Print response details
+	return Print(rakr, cfg.Verbose, cfg.RawJSON)
+}
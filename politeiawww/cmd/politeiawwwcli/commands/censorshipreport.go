@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// Help message displayed for the command 'politeiawwwcli help censorshipreport'
+var CensorshipReportCmdHelpMsg = `censorshipreport
+
+Fetch the public record of all proposal and comment censorship actions, newest
+first.  At most 100 actions are returned per page.  CSV reports are not paged
+and contain all actions after --start.
+
+Flags:
+  --start      (uint64, optional)  Number of actions to skip
+  --csv        (bool, optional)    Fetch the report in CSV format
+  --out        (string, optional)  Write the report to the given file
+
+Example:
+censorshipreport --csv --out=censorship.csv
+
+Result:
+{
+  "serverpubkey":  (string)  politeiad public key
+  "total":         (uint64)  Total number of actions
+  "next":          (uint64)  Start of the next page, 0 if there are no more actions
+  "actions": [
+    {
+      "type":        (string)  Proposal or comment censorship
+      "token":       (string)  Proposal censorship token
+      "commentid":   (string)  Comment ID
+      "timestamp":   (int64)   UNIX timestamp of the censorship
+      "reason":      (string)  Reason given by the admin
+      "publickey":   (string)  Admin public key
+      "signature":   (string)  Admin signature
+      "receipt":     (string)  Server receipt
+    }
+  ]
+}`
+
+type CensorshipReportCmd struct {
+	Start uint64 `long:"start" description:"Number of actions to skip"`
+	CSV   bool   `long:"csv" description:"Fetch the report in CSV format"`
+	Out   string `long:"out" description:"Write the report to the given file"`
+}
+
+func (cmd *CensorshipReportCmd) Execute(args []string) error {
+	if !cmd.CSV {
+		crr, err := c.CensorshipReport(&v1.CensorshipReport{
+			Start: cmd.Start,
+		})
+		if err != nil {
+			return err
+		}
+		return Print(crr, cfg.Verbose, cfg.RawJSON)
+	}
+
+	report, err := c.CensorshipReportCSV(cmd.Start)
+	if err != nil {
+		return err
+	}
+	if cmd.Out == "" {
+		fmt.Printf("%s", report)
+		return nil
+	}
+	err = ioutil.WriteFile(cmd.Out, report, 0644)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Censorship report written to %v\n", cmd.Out)
+	return nil
+}
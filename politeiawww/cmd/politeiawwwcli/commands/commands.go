@@ -23,46 +23,61 @@ func SetClient(client *client.Client) {
 }
 
 type Cmds struct {
-	AuthorizeVote      AuthorizeVoteCmd      `command:"authorizevote" description:"authorize a proposal vote (must be proposal author)"`
-	CensorComment      CensorCommentCmd      `command:"censorcomment" description:"(admin) censor a proposal comment"`
-	ChangePassword     ChangePasswordCmd     `command:"changepassword" description:"change the password for the currently logged in user"`
-	CommentsLikes      CommentsLikesCmd      `command:"commentslikes" description:"fetch all the comments voted by the user on a proposal"`
-	ChangeUsername     ChangeUsernameCmd     `command:"changeusername" description:"change the username for the currently logged in user"`
-	EditProposal       EditProposalCmd       `command:"editproposal" description:"edit a proposal"`
-	ManageUser         ManageUserCmd         `command:"manageuser" description:"(admin) edit the details for the given user id"`
-	EditUser           EditUserCmd           `command:"edituser" description:"edit your user preferences"`
-	Faucet             FaucetCmd             `command:"faucet" description:"use the Decred testnet faucet to send DCR to an address"`
-	GetComments        GetCommentsCmd        `command:"getcomments" description:"fetch a proposal's comments"`
-	GetProposal        GetProposalCmd        `command:"getproposal" description:"fetch a proposal"`
-	GetUnvetted        GetUnvettedCmd        `command:"getunvetted" description:"fetch unvetted proposals"`
-	GetVetted          GetVettedCmd          `command:"getvetted" description:"fetch vetted proposals"`
-	GetPaywallPayment  GetPaywallPaymentCmd  `command:"getpaywallpayment" description:"fetch payment details for a proposal paywall payment"`
-	Help               HelpCmd               `command:"help" description:"print detailed help message of specified command"`
-	Inventory          InventoryCmd          `command:"inventory" description:"fetch the proposals that are being voted on"`
-	Login              LoginCmd              `command:"login" description:"login to Politeia"`
-	Logout             LogoutCmd             `command:"logout" description:"logout of Politeia"`
-	Me                 MeCmd                 `command:"me" description:"return the user information of the currently logged in user"`
-	NewProposal        NewProposalCmd        `command:"newproposal" description:"submit a new proposal to Politeia"`
-	NewComment         NewCommentCmd         `command:"newcomment" description:"comment on a proposal"`
-	NewUser            NewUserCmd            `command:"newuser" description:"create a new Politeia user"`
-	Policy             PolicyCmd             `command:"policy" description:"fetch server policy"`
-	ProposalPaywall    ProposalPaywallCmd    `command:"proposalpaywall" description:"fetch proposal paywall details"`
-	ProposalVotes      ProposalVotesCmd      `command:"proposalvotes" description:"fetch vote results for a specific proposal"`
-	RescanUserPayments RescanUserPaymentsCmd `command:"rescanuserpayments" description:"rescan user payments to check for missed payments"`
-	ResetPassword      ResetPasswordCmd      `command:"resetpassword" description:"change the password for a user that is not currently logged in"`
-	Secret             SecretCmd             `command:"secret" description:"ping politeiawww"`
-	SetProposalStatus  SetProposalStatusCmd  `command:"setproposalstatus" description:"(admin) set the status of a proposal"`
-	StartVote          StartVoteCmd          `command:"startvote" description:"(admin) start the voting period on a proposal"`
-	Subscribe          Subscribe             `command:"subscribe" description:"subscribe to all websocket commands and do not exit tool."`
-	Tally              TallyCmd              `command:"tally" description:"fetch the vote tally for a proposal"`
-	UpdateUserKey      UpdateUserKeyCmd      `command:"updateuserkey" description:"generate a new identity for the user"`
-	UserDetails        UserDetailsCmd        `command:"userdetails" description:"fetch a user's details by his user id"`
-	UserProposals      UserProposalsCmd      `command:"userproposals" description:"fetch all proposals submitted by a specific user"`
-	Users              UsersCmd              `command:"users" description:"fetch a list of users, optionally filtering them by email and/or username"`
-	VerifyUser         VerifyUserCmd         `command:"verifyuser" description:"verify user's email address"`
-	VerifyUserPayment  VerifyUserPaymentCmd  `command:"verifyuserpayment" description:"check if the user has paid their user registration fee"`
-	Version            VersionCmd            `command:"version" description:"fetch server info and CSRF token"`
-	Vote               VoteCmd               `command:"vote" description:"cast ticket votes for a proposal"`
-	VoteComment        VoteCommentCmd        `command:"votecomment" description:"vote on a comment"`
-	VoteStatus         VoteStatusCmd         `command:"votestatus" description:"fetch the vote status of a proposal"`
+	AdminLog             AdminLogCmd             `command:"adminlog" description:"(admin) fetch the entries of the admin audit log"`
+	APIKey               APIKeyCmd               `command:"apikey" description:"create, list and revoke API keys"`
+	AuthorizeVote        AuthorizeVoteCmd        `command:"authorizevote" description:"authorize a proposal vote (must be proposal author)"`
+	CensorComment        CensorCommentCmd        `command:"censorcomment" description:"(admin) censor a proposal comment"`
+	CensorshipReport     CensorshipReportCmd     `command:"censorshipreport" description:"fetch the public record of all censorship actions"`
+	ChangePassword       ChangePasswordCmd       `command:"changepassword" description:"change the password for the currently logged in user"`
+	CommentsLikes        CommentsLikesCmd        `command:"commentslikes" description:"fetch all the comments voted by the user on a proposal"`
+	ChangeUsername       ChangeUsernameCmd       `command:"changeusername" description:"change the username for the currently logged in user"`
+	EditProposal         EditProposalCmd         `command:"editproposal" description:"edit a proposal"`
+	ManageUser           ManageUserCmd           `command:"manageuser" description:"(admin) edit the details for the given user id"`
+	EditUser             EditUserCmd             `command:"edituser" description:"edit your user preferences"`
+	EmailOutbox          EmailOutboxCmd          `command:"emailoutbox" description:"(admin) fetch the emails that have not been delivered yet"`
+	EmailTemplates       EmailTemplatesCmd       `command:"emailtemplates" description:"(admin) render a preview of every email template"`
+	Faucet               FaucetCmd               `command:"faucet" description:"use the Decred testnet faucet to send DCR to an address"`
+	GetComments          GetCommentsCmd          `command:"getcomments" description:"fetch a proposal's comments"`
+	GetProposal          GetProposalCmd          `command:"getproposal" description:"fetch a proposal"`
+	GetUnvetted          GetUnvettedCmd          `command:"getunvetted" description:"fetch unvetted proposals"`
+	GetVetted            GetVettedCmd            `command:"getvetted" description:"fetch vetted proposals"`
+	GetPaywallPayment    GetPaywallPaymentCmd    `command:"getpaywallpayment" description:"fetch payment details for a proposal paywall payment"`
+	Help                 HelpCmd                 `command:"help" description:"print detailed help message of specified command"`
+	Inventory            InventoryCmd            `command:"inventory" description:"fetch the proposals that are being voted on"`
+	Login                LoginCmd                `command:"login" description:"login to Politeia"`
+	LoginKey             LoginKeyCmd             `command:"loginkey" description:"login to Politeia by signing a challenge with your identity"`
+	Logout               LogoutCmd               `command:"logout" description:"logout of Politeia"`
+	Me                   MeCmd                   `command:"me" description:"return the user information of the currently logged in user"`
+	NewProposal          NewProposalCmd          `command:"newproposal" description:"submit a new proposal to Politeia"`
+	NewComment           NewCommentCmd           `command:"newcomment" description:"comment on a proposal"`
+	NewUser              NewUserCmd              `command:"newuser" description:"create a new Politeia user"`
+	Policy               PolicyCmd               `command:"policy" description:"fetch server policy"`
+	ProposalBundle       ProposalBundleCmd       `command:"proposalbundle" description:"export a proposal and its discussion as a verifiable bundle"`
+	ProposalPaywall      ProposalPaywallCmd      `command:"proposalpaywall" description:"fetch proposal paywall details"`
+	ProposalVotes        ProposalVotesCmd        `command:"proposalvotes" description:"fetch vote results for a specific proposal"`
+	ReportComment        ReportCommentCmd        `command:"reportcomment" description:"report a proposal comment for moderation"`
+	ReportedComments     ReportedCommentsCmd     `command:"reportedcomments" description:"(admin) fetch the comment moderation queue"`
+	RescanUserPayments   RescanUserPaymentsCmd   `command:"rescanuserpayments" description:"rescan user payments to check for missed payments"`
+	ResetPassword        ResetPasswordCmd        `command:"resetpassword" description:"change the password for a user that is not currently logged in"`
+	ResolveCommentReport ResolveCommentReportCmd `command:"resolvecommentreport" description:"(admin) dismiss or censor a reported comment"`
+	Secret               SecretCmd               `command:"secret" description:"ping politeiawww"`
+	SetProposalStatus    SetProposalStatusCmd    `command:"setproposalstatus" description:"(admin) set the status of a proposal"`
+	Sessions             SessionsCmd             `command:"sessions" description:"list and revoke your active sessions"`
+	SetTOTP              SetTOTPCmd              `command:"settotp" description:"generate a two-factor authentication secret"`
+	StartVote            StartVoteCmd            `command:"startvote" description:"(admin) start the voting period on a proposal"`
+	Subscribe            Subscribe               `command:"subscribe" description:"subscribe to all websocket commands and do not exit tool."`
+	Tally                TallyCmd                `command:"tally" description:"fetch the vote tally for a proposal"`
+	UpdateUserKey        UpdateUserKeyCmd        `command:"updateuserkey" description:"generate a new identity for the user"`
+	UserDetails          UserDetailsCmd          `command:"userdetails" description:"fetch a user's details by his user id"`
+	UserProposals        UserProposalsCmd        `command:"userproposals" description:"fetch all proposals submitted by a specific user"`
+	Users                UsersCmd                `command:"users" description:"fetch a list of users, optionally filtering them by email and/or username"`
+	VerifyTOTP           VerifyTOTPCmd           `command:"verifytotp" description:"enable two-factor authentication"`
+	VerifyUser           VerifyUserCmd           `command:"verifyuser" description:"verify user's email address"`
+	VerifyUserPayment    VerifyUserPaymentCmd    `command:"verifyuserpayment" description:"check if the user has paid their user registration fee"`
+	Version              VersionCmd              `command:"version" description:"fetch server info and CSRF token"`
+	Vote                 VoteCmd                 `command:"vote" description:"cast ticket votes for a proposal"`
+	VoteComment          VoteCommentCmd          `command:"votecomment" description:"vote on a comment"`
+	VoteProof            VoteProofCmd            `command:"voteproof" description:"fetch and verify the inclusion proof of a ticket vote"`
+	VoteStatus           VoteStatusCmd           `command:"votestatus" description:"fetch the vote status of a proposal"`
+	Webhook              WebhookCmd              `command:"webhook" description:"(admin) register, list and delete webhooks"`
 }
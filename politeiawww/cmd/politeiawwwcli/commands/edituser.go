@@ -20,12 +20,21 @@ Valid bit flags are:
 '1 << 7' - Notification email when a comment is made on a user's proposal
 '1 << 8' - Notification email when a comment is made on a user's comment
   
+The notifications 1 << 1, 1 << 2, 1 << 3, 1 << 4, 1 << 7 and 1 << 8 can be
+delivered in a daily or weekly digest instead of one email per event.
+
 Arguments:
 1. emailnotifications (uint64, optional)  Email notification setting (bit flag)
+2. emaildigestdaily   (uint64, optional)  Notifications delivered daily (bit flag)
+3. emaildigestweekly  (uint64, optional)  Notifications delivered weekly (bit flag)
+4. locale             (string, optional)  Locale of the emails, e.g. en or pt-BR
 
 Request:
 {
   "emailnotifications":  (uint64)  Bit flag
+  "emaildigestdaily":    (uint64)  Bit flag
+  "emaildigestweekly":   (uint64)  Bit flag
+  "locale":              (string)  Locale
 }
 
 Response:
@@ -33,12 +42,18 @@ Response:
 
 type EditUserCmd struct {
 	EmailNotifications *uint64 `long:"emailnotifications" optional:"true" description:"Whether to notify via emails"`
+	EmailDigestDaily   *uint64 `long:"emaildigestdaily" optional:"true" description:"Notifications delivered in a daily digest"`
+	EmailDigestWeekly  *uint64 `long:"emaildigestweekly" optional:"true" description:"Notifications delivered in a weekly digest"`
+	Locale             *string `long:"locale" optional:"true" description:"Locale of the emails"`
 }
 
 func (cmd *EditUserCmd) Execute(args []string) error {
 	// Setup request
 	eu := &v1.EditUser{
 		EmailNotifications: cmd.EmailNotifications,
+		EmailDigestDaily:   cmd.EmailDigestDaily,
+		EmailDigestWeekly:  cmd.EmailDigestWeekly,
+		Locale:             cmd.Locale,
 	}
 
 	// Print request details
@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// Help message displayed for the command 'politeiawwwcli help emailoutbox'
+var EmailOutboxCmdHelpMsg = `emailoutbox
+
+Fetch the emails that have not been delivered yet, oldest first.  Requires
+admin privileges.
+
+Flags:
+  --deadletter  (bool, optional)  Only emails that are no longer retried
+
+Example:
+emailoutbox --deadletter
+
+Result:
+{
+  "emails": [
+    {
+      "id":           (string)  Unique email ID
+      "subject":      (string)  Email subject
+      "recipients":   (int)     Number of recipients
+      "createdat":    (int64)   UNIX timestamp of when the email was queued
+      "attempts":     (uint64)  Number of failed delivery attempts
+      "nextattempt":  (int64)   UNIX timestamp of the next delivery attempt
+      "lasterror":    (string)  Error of the last failed delivery attempt
+      "deadletter":   (bool)    Whether delivery has been abandoned
+    }
+  ]
+}`
+
+type EmailOutboxCmd struct {
+	DeadLetter bool `long:"deadletter" description:"Only emails that are no longer retried"`
+}
+
+func (cmd *EmailOutboxCmd) Execute(args []string) error {
+	eor, err := c.EmailOutbox(&v1.EmailOutbox{
+		DeadLetter: cmd.DeadLetter,
+	})
+	if err != nil {
+		return err
+	}
+	return Print(eor, cfg.Verbose, cfg.RawJSON)
+}
@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// Help message displayed for the command 'politeiawwwcli help emailtemplates'
+var EmailTemplatesCmdHelpMsg = `emailtemplates
+
+Render a preview of every email template with sample data.  Requires admin
+privileges.
+
+Flags:
+  --locale  (string, optional)  Locale to render the templates in
+  --html    (bool, optional)    Print the HTML bodies instead of the plain
+                                text bodies
+
+Example:
+emailtemplates --locale=pt-BR
+
+Result:
+Prints the subject and body of every template.  The raw reply is:
+{
+  "locale":  (string)    Locale of the previews
+  "locales": ([]string)  Available locales
+  "templates": [
+    {
+      "name":     (string)  Template name
+      "subject":  (string)  Email subject
+      "text":     (string)  Plain text body
+      "html":     (string)  HTML body, if customized
+    }
+  ]
+}`
+
+type EmailTemplatesCmd struct {
+	Locale string `long:"locale" description:"Locale to render the templates in"`
+	HTML   bool   `long:"html" description:"Print the HTML bodies"`
+}
+
+func (cmd *EmailTemplatesCmd) Execute(args []string) error {
+	etr, err := c.EmailTemplates(&v1.EmailTemplates{
+		Locale: cmd.Locale,
+	})
+	if err != nil {
+		return err
+	}
+
+	if cfg.RawJSON {
+		return Print(etr, cfg.Verbose, cfg.RawJSON)
+	}
+
+	fmt.Printf("Locale : %v\n", etr.Locale)
+	fmt.Printf("Locales: %v\n", etr.Locales)
+	for _, v := range etr.Templates {
+		body := v.Text
+		if cmd.HTML {
+			body = v.HTML
+		}
+		fmt.Printf("\n==> %v\n", v.Name)
+		fmt.Printf("Subject: %v\n", v.Subject)
+		fmt.Printf("%v\n", body)
+	}
+
+	return nil
+}
@@ -13,6 +13,8 @@ func (cmd *HelpCmd) Execute(args []string) error {
 	switch cmd.Args.Topic {
 	case "login":
 		fmt.Printf("%s\n", LoginCmdHelpMsg)
+	case "loginkey":
+		fmt.Printf("%s\n", LoginKeyCmdHelpMsg)
 	case "logout":
 		fmt.Printf("%s\n", LogoutCmdHelpMsg)
 	case "authorizevote":
@@ -53,6 +55,14 @@ func (cmd *HelpCmd) Execute(args []string) error {
 		fmt.Printf("%s\n", ManageUserCmdHelpMsg)
 	case "users":
 		fmt.Printf("%s\n", UsersCmdHelpMsg)
+	case "adminlog":
+		fmt.Printf("%s\n", AdminLogCmdHelpMsg)
+	case "emailoutbox":
+		fmt.Printf("%s\n", EmailOutboxCmdHelpMsg)
+	case "emailtemplates":
+		fmt.Printf("%s\n", EmailTemplatesCmdHelpMsg)
+	case "censorshipreport":
+		fmt.Printf("%s\n", CensorshipReportCmdHelpMsg)
 	case "verifyuser":
 		fmt.Printf("%s\n", VerifyUserCmdHelpMsg)
 	case "version":
@@ -89,6 +99,26 @@ func (cmd *HelpCmd) Execute(args []string) error {
 		fmt.Printf("%s\n", TallyCmdHelpMsg)
 	case "commentslikes":
 		fmt.Printf("%s\n", CommentsLikesCmdHelpMsg)
+	case "proposalbundle":
+		fmt.Printf("%s\n", ProposalBundleCmdHelpMsg)
+	case "reportcomment":
+		fmt.Printf("%s\n", ReportCommentCmdHelpMsg)
+	case "reportedcomments":
+		fmt.Printf("%s\n", ReportedCommentsCmdHelpMsg)
+	case "resolvecommentreport":
+		fmt.Printf("%s\n", ResolveCommentReportCmdHelpMsg)
+	case "voteproof":
+		fmt.Printf("%s\n", VoteProofCmdHelpMsg)
+	case "apikey":
+		fmt.Printf("%s\n", APIKeyCmdHelpMsg)
+	case "sessions":
+		fmt.Printf("%s\n", SessionsCmdHelpMsg)
+	case "settotp":
+		fmt.Printf("%s\n", SetTOTPCmdHelpMsg)
+	case "verifytotp":
+		fmt.Printf("%s\n", VerifyTOTPCmdHelpMsg)
+	case "webhook":
+		fmt.Printf("%s\n", WebhookCmdHelpMsg)
 	default:
 		fmt.Printf("invalid command\n")
 	}
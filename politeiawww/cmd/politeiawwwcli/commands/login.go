@@ -5,12 +5,16 @@ import "github.com/decred/politeia/politeiawww/api/v1"
 // Help message displayed for the command 'politeiawwwcli help login'
 var LoginCmdHelpMsg = `login "email" "password"
 
-Login as a user or admin.
+Login as a user or admin.  Users that have enabled two-factor authentication
+must provide a TOTP code or one of their recovery codes using --code.
 
 Arguments:
 1. email      (string, required)   Email address of user
 2. password   (string, required)   Accompanying password for provided email
 
+Flags:
+  --code      (string, optional)   TOTP or recovery code
+
 Result:
 {
   "isadmin":              (bool)    Is the user an admin
@@ -24,6 +28,8 @@ Result:
   "proposalcredits":      (uint64)  Number of available proposal credits 
   "lastlogintime":        (int64)   Unix timestamp of last login date
   "sessionmaxage":        (int64)   Unix timestamp of session max age
+  "totpenabled":          (bool)    Is two-factor authentication enabled
+  "totprequired":         (bool)    Must two-factor authentication be enabled
 }`
 
 type LoginCmd struct {
@@ -31,6 +37,7 @@ type LoginCmd struct {
 		Email    string `positional-arg-name:"email"`
 		Password string `positional-arg-name:"password"`
 	} `positional-args:"true" required:"true"`
+	Code string `long:"code" optional:"true" description:"TOTP or recovery code"`
 }
 
 func (cmd *LoginCmd) Execute(args []string) error {
@@ -47,6 +54,7 @@ func (cmd *LoginCmd) Execute(args []string) error {
 	l := &v1.Login{
 		Email:    email,
 		Password: DigestSHA3(password),
+		Code:     cmd.Code,
 	}
 
 	// Print request details
@@ -0,0 +1,88 @@
+package commands
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// Help message displayed for the command 'politeiawwwcli help loginkey'
+var LoginKeyCmdHelpMsg = `loginkey
+
+Login without a password by signing a login challenge with the user identity
+that is stored by politeiawwwcli.  The identity must be the active identity of
+the user.  Users that have enabled two-factor authentication must provide a
+TOTP code or one of their recovery codes using --code.
+
+Flags:
+  --code      (string, optional)   TOTP or recovery code
+
+Result:
+{
+  "isadmin":              (bool)    Is the user an admin
+  "userid":               (string)  User ID
+  "email":                (string)  User email
+  "username":             (string)  Username
+  "publickey":            (string)  Active public key
+  "paywalladdress":       (string)  Registration paywall address
+  "paywallamount":        (uint64)  Registration paywall amount in atoms
+  "paywalltxnotbefore":   (int64)   Minimum timestamp for paywall tx
+  "proposalcredits":      (uint64)  Number of available proposal credits 
+  "lastlogintime":        (int64)   Unix timestamp of last login date
+  "sessionmaxage":        (int64)   Unix timestamp of session max age
+  "totpenabled":          (bool)    Is two-factor authentication enabled
+  "totprequired":         (bool)    Must two-factor authentication be enabled
+}`
+
+type LoginKeyCmd struct {
+	Code string `long:"code" optional:"true" description:"TOTP or recovery code"`
+}
+
+func (cmd *LoginKeyCmd) Execute(args []string) error {
+	// Check for user identity
+	if cfg.Identity == nil {
+		return fmt.Errorf(ErrorNoUserIdentity)
+	}
+	pk := hex.EncodeToString(cfg.Identity.Public.Key[:])
+
+	// Fetch CSRF tokens
+	_, err := c.Version()
+	if err != nil {
+		return err
+	}
+
+	// Request a login challenge
+	lc := &v1.LoginChallenge{
+		PublicKey: pk,
+	}
+	err = Print(lc, cfg.Verbose, cfg.RawJSON)
+	if err != nil {
+		return err
+	}
+	lcr, err := c.LoginChallenge(lc)
+	if err != nil {
+		return err
+	}
+
+	// Sign the challenge and login
+	sig := cfg.Identity.SignMessage([]byte(v1.LoginChallengePrefix +
+		lcr.Challenge))
+	ls := &v1.LoginSignature{
+		PublicKey: pk,
+		Challenge: lcr.Challenge,
+		Signature: hex.EncodeToString(sig[:]),
+		Code:      cmd.Code,
+	}
+	err = Print(ls, cfg.Verbose, cfg.RawJSON)
+	if err != nil {
+		return err
+	}
+	lr, err := c.LoginSignature(ls)
+	if err != nil {
+		return err
+	}
+
+	// Print response details
+	return Print(lr, cfg.Verbose, cfg.RawJSON)
+}
@@ -10,7 +10,8 @@ import (
 // Help message displayed for the command 'politeiawwwcli help manageuser'
 var ManageUserCmdHelpMsg = `manageuser "userid" "action" "reason"
 
-Edit the details for the given user id. Requires admin privileges.
+Edit the details for the given user id. Requires admin privileges.  The
+grantrole and revokerole actions require the --role flag.
 
 Arguments:
 1. userid       (string, required)   User id
@@ -25,12 +26,25 @@ Valid actions are:
 5. unlocks                 Unlocks user account from failed logins
 6. deactivates             Deactivates user account
 7. reactivate              Reactivates user account
+8. grantrole               Grants a role to the user
+9. revokerole              Revokes a role from the user
+10. resettotp              Resets two-factor authentication
+
+Flags:
+  --role     (string, optional)   Role to grant or revoke
+
+Valid roles are:
+1. moderator               Censors comments and resolves comment reports
+2. reviewer                Reviews and vets proposals
+4. voteadmin               Starts proposal votes
+8. superadmin              Manages users and implies all other roles
 
 Request:
 {
   "userid":  (string)    User id
   "action":  (string)    Edit user action
   "reason":  (string)    Reason for action
+  "role":    (uint64)    Role to grant or revoke
 }
 
 Response:
@@ -42,6 +56,7 @@ type ManageUserCmd struct {
 		Action string `positional-arg-name:"action" description:"(Admin) edit user action"`
 		Reason string `positional-arg-name:"reason" description:"Reason for editing the user"`
 	} `positional-args:"true" required:"true"`
+	Role string `long:"role" optional:"true" description:"Role to grant or revoke"`
 }
 
 func (cmd *ManageUserCmd) Execute(args []string) error {
@@ -53,6 +68,9 @@ func (cmd *ManageUserCmd) Execute(args []string) error {
 		"unlock":              5,
 		"deactivate":          6,
 		"reactivate":          7,
+		"grantrole":           8,
+		"revokerole":          9,
+		"resettotp":           10,
 	}
 
 	// Parse edit user action.  This can be either the numeric
@@ -73,7 +91,30 @@ func (cmd *ManageUserCmd) Execute(args []string) error {
 			"clearpaywall          clears user registration paywall\n  " +
 			"unlock                unlocks user account from failed logins\n  " +
 			"deactivate            deactivates user account\n  " +
-			"reactivate            reactivates user account")
+			"reactivate            reactivates user account\n  " +
+			"grantrole             grants a role to the user\n  " +
+			"revokerole            revokes a role from the user\n  " +
+			"resettotp             resets two-factor authentication")
+	}
+
+	// Parse role.  This can be either the numeric role code or the
+	// human readable equivalent.
+	var role v1.UserRoleT
+	if cmd.Role != "" {
+		r, err := strconv.ParseUint(cmd.Role, 10, 64)
+		if err == nil {
+			role = v1.UserRoleT(r)
+		} else {
+			for k, v := range v1.UserRole {
+				if v == cmd.Role {
+					role = k
+				}
+			}
+			if role == 0 {
+				return fmt.Errorf("Invalid role.  Valid roles " +
+					"are moderator, reviewer, voteadmin and superadmin")
+			}
+		}
 	}
 
 	// Setup request
@@ -81,6 +122,7 @@ func (cmd *ManageUserCmd) Execute(args []string) error {
 		UserID: cmd.Args.UserID,
 		Action: action,
 		Reason: cmd.Args.Reason,
+		Role:   role,
 	}
 
 	// Print request details
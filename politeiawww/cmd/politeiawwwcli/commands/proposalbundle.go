@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// Help message displayed for the command 'politeiawwwcli help proposalbundle'
+var ProposalBundleCmdHelpMsg = `proposalbundle "token"
+
+Export a public proposal together with its comments, comment votes, censor
+records and vote details.  Every signature and server receipt in the bundle
+can be verified offline using 'politeia_verify -bundle'.
+
+Arguments:
+1. token       (string, required)  Proposal censorship token
+
+Flags:
+  --out        (string, optional)  Write the bundle to the given file
+
+Response:
+{
+  "serverpubkey":       (string)              politeiad public key
+  "timestamp":          (int64)               Bundle creation UNIX timestamp
+  "proposal":           (ProposalRecord)      Proposal including files and censorship record
+  "comments":           ([]Comment)           Comments including receipts
+  "commentslikes":      ([]LikeComment)       Comment votes including receipts
+  "censoredcomments":   ([]CensorComment)     Comment censor records including receipts
+  "authorizevote":      (AuthorizeVote)       Vote authorization by proposal author
+  "authorizevotereply": (AuthorizeVoteReply)  Vote authorization receipt
+  "startvote":          (StartVote)           Vote parameters
+  "startvotereply":     (StartVoteReply)      Vote snapshot
+}`
+
+type ProposalBundleCmd struct {
+	Args struct {
+		Token string `positional-arg-name:"token" description:"Proposal censorship token"`
+	} `positional-args:"true" required:"true"`
+	Out string `long:"out" description:"Write the bundle to the given file"`
+}
+
+func (cmd *ProposalBundleCmd) Execute(args []string) error {
+	pbr, err := c.ProposalBundle(cmd.Args.Token)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Out != "" {
+		b, err := json.MarshalIndent(pbr, "", "  ")
+		if err != nil {
+			return fmt.Errorf("MarshalIndent: %v", err)
+		}
+		err = ioutil.WriteFile(cmd.Out, b, 0644)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Proposal bundle written to %v\n", cmd.Out)
+		return nil
+	}
+
+	return Print(pbr, cfg.Verbose, cfg.RawJSON)
+}
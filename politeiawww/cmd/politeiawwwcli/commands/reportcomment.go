@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// Help message displayed for the command 'politeiawwwcli help reportcomment'
+var ReportCommentCmdHelpMsg = `reportcomment "token" "commentID" "reason"
+
+Report a comment so that it shows up in the admin moderation queue.
+
+Arguments:
+1. token       (string, required)   Proposal censorship token
+2. commentID   (string, required)   Id of the comment
+3. reason      (string, required)   Reason for reporting the comment
+
+Request:
+{
+  "token":      (string)  Censorship token
+  "commentid":  (string)  Id of comment
+  "reason":     (string)  Reason for reporting the comment
+  "signature":  (string)  Signature of report comment (Token+CommentID+Reason)
+  "publickey":  (string)  Public key used for signature
+}
+
+Response:
+{}`
+
+type ReportCommentCmd struct {
+	Args struct {
+		Token     string `positional-arg-name:"token" description:"Proposal censorship token"`
+		CommentID string `positional-arg-name:"commentID" description:"ID of the comment"`
+		Reason    string `positional-arg-name:"reason" description:"Reason for reporting the comment"`
+	} `positional-args:"true" required:"true"`
+}
+
+func (cmd *ReportCommentCmd) Execute(args []string) error {
+	token := cmd.Args.Token
+	commentID := cmd.Args.CommentID
+	reason := cmd.Args.Reason
+
+	// Check for user identity
+	if cfg.Identity == nil {
+		return fmt.Errorf(ErrorNoUserIdentity)
+	}
+
+	// Setup report comment request
+	s := cfg.Identity.SignMessage([]byte(token + commentID + reason))
+	rc := &v1.ReportComment{
+		Token:     token,
+		CommentID: commentID,
+		Reason:    reason,
+		Signature: hex.EncodeToString(s[:]),
+		PublicKey: hex.EncodeToString(cfg.Identity.Public.Key[:]),
+	}
+
+	// Print request details
+	err := Print(rc, cfg.Verbose, cfg.RawJSON)
+	if err != nil {
+		return err
+	}
+
+	// Send request
+	rcr, err := c.ReportComment(rc)
+	if err != nil {
+		return err
+	}
+
+	// Print response details
+	return Print(rcr, cfg.Verbose, cfg.RawJSON)
+}
@@ -0,0 +1,37 @@
+package commands
+
+// Help message displayed for the command 'politeiawwwcli help reportedcomments'
+var ReportedCommentsCmdHelpMsg = `reportedcomments
+
+Fetch the comment moderation queue. Requires admin privileges.
+
+Arguments:
+None
+
+Result:
+{
+  "comments": [
+    {
+      "comment":    (Comment)  Reported comment
+      "count":      (uint64)   Number of reports
+      "reports": [
+        {
+          "userid":     (string)  ID of reporting user
+          "username":   (string)  Username of reporting user
+          "reason":     (string)  Reason the comment was reported
+          "timestamp":  (int64)   Received UNIX timestamp
+        }
+      ]
+    }
+  ]
+}`
+
+type ReportedCommentsCmd struct{}
+
+func (cmd *ReportedCommentsCmd) Execute(args []string) error {
+	rcr, err := c.ReportedComments()
+	if err != nil {
+		return err
+	}
+	return Print(rcr, cfg.Verbose, cfg.RawJSON)
+}
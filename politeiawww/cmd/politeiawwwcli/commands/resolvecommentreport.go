@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/util"
+)
+
+// Help message displayed for the command 'politeiawwwcli help resolvecommentreport'
+var ResolveCommentReportCmdHelpMsg = `resolvecommentreport "token" "commentID" "action" "reason"
+
+Resolve the reports filed against a comment by either dismissing them or by
+censoring the comment. Requires admin privileges.
+
+Arguments:
+1. token       (string, required)   Proposal censorship token
+2. commentID   (string, required)   Id of the comment
+3. action      (string, required)   Resolve action (dismiss or censor)
+4. reason      (string, required)   Reason for the action
+
+Request:
+{
+  "token":      (string)  Censorship token
+  "commentid":  (string)  Id of comment
+  "action":     (int)     Resolve action
+  "reason":     (string)  Reason for the action
+  "signature":  (string)  Signature of Token+CommentID+Reason
+  "publickey":  (string)  Public key used for signature
+}
+
+Response:
+{
+  "receipt":  (string)  Server signature of censor comment signature
+}`
+
+type ResolveCommentReportCmd struct {
+	Args struct {
+		Token     string `positional-arg-name:"token" description:"Proposal censorship token"`
+		CommentID string `positional-arg-name:"commentID" description:"ID of the comment"`
+		Action    string `positional-arg-name:"action" description:"Resolve action (dismiss or censor)"`
+		Reason    string `positional-arg-name:"reason" description:"Reason for the action"`
+	} `positional-args:"true" required:"true"`
+}
+
+func (cmd *ResolveCommentReportCmd) Execute(args []string) error {
+	token := cmd.Args.Token
+	commentID := cmd.Args.CommentID
+	reason := cmd.Args.Reason
+
+	ReportActions := map[string]v1.CommentReportActionT{
+		"dismiss": v1.CommentReportActionDismiss,
+		"censor":  v1.CommentReportActionCensor,
+	}
+
+	// Parse resolve action.  This can be either the numeric
+	// action code or the human readable equivalent.
+	var action v1.CommentReportActionT
+	a, err := strconv.ParseUint(cmd.Args.Action, 10, 32)
+	if err == nil {
+		// Numeric action code found
+		action = v1.CommentReportActionT(a)
+	} else if a, ok := ReportActions[cmd.Args.Action]; ok {
+		// Human readable action code found
+		action = a
+	} else {
+		return fmt.Errorf("Invalid resolve action.  Valid actions are:\n  " +
+			"dismiss   dismisses the comment reports\n  " +
+			"censor    censors the reported comment")
+	}
+
+	// Check for user identity
+	if cfg.Identity == nil {
+		return fmt.Errorf(ErrorNoUserIdentity)
+	}
+
+	// Get server public key
+	vr, err := c.Version()
+	if err != nil {
+		return err
+	}
+
+	// Setup resolve comment report request
+	s := cfg.Identity.SignMessage([]byte(token + commentID + reason))
+	signature := hex.EncodeToString(s[:])
+	rcr := &v1.ResolveCommentReport{
+		Token:     token,
+		CommentID: commentID,
+		Action:    action,
+		Reason:    reason,
+		Signature: signature,
+		PublicKey: hex.EncodeToString(cfg.Identity.Public.Key[:]),
+	}
+
+	// Print request details
+	err = Print(rcr, cfg.Verbose, cfg.RawJSON)
+	if err != nil {
+		return err
+	}
+
+	// Send request
+	reply, err := c.ResolveCommentReport(rcr)
+	if err != nil {
+		return err
+	}
+
+	// Validate censor comment receipt
+	if action == v1.CommentReportActionCensor {
+		serverID, err := util.IdentityFromString(vr.PubKey)
+		if err != nil {
+			return err
+		}
+		receiptB, err := util.ConvertSignature(reply.Receipt)
+		if err != nil {
+			return err
+		}
+		if !serverID.VerifyMessage([]byte(signature), receiptB) {
+			return fmt.Errorf("could not verify receipt signature")
+		}
+	}
+
+	// Print response details
+	return Print(reply, cfg.Verbose, cfg.RawJSON)
+}
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// Help message displayed for the command 'politeiawwwcli help sessions'
+var SessionsCmdHelpMsg = `sessions list
+sessions revoke "id"
+sessions revoke --all
+
+List and revoke the active login sessions of the logged in user.  Revoking all
+sessions logs out every session except the current one.
+
+Arguments (revoke):
+1. id         (string, optional)   Session id
+
+Flags (revoke):
+  --all       (bool, optional)     Revoke all other sessions
+
+Result (list):
+{
+  "sessions": [
+    {
+      "id":          (string)  Session id
+      "createdat":   (int64)   Unix timestamp of login
+      "lastseen":    (int64)   Unix timestamp of last use
+      "ip":          (string)  IP address of the client that logged in
+      "useragent":   (string)  User agent of the client that logged in
+      "current":     (bool)    Is this the current session
+    }
+  ]
+}`
+
+type SessionsCmd struct {
+	List   SessionsListCmd   `command:"list" description:"list your active sessions"`
+	Revoke SessionsRevokeCmd `command:"revoke" description:"revoke one or all of your other sessions"`
+}
+
+type SessionsListCmd struct{}
+
+func (cmd *SessionsListCmd) Execute(args []string) error {
+	sr, err := c.Sessions()
+	if err != nil {
+		return err
+	}
+
+	// Print response details
+	return Print(sr, cfg.Verbose, cfg.RawJSON)
+}
+
+type SessionsRevokeCmd struct {
+	Args struct {
+		ID string `positional-arg-name:"id"`
+	} `positional-args:"true"`
+	All bool `long:"all" optional:"true" description:"revoke all other sessions"`
+}
+
+func (cmd *SessionsRevokeCmd) Execute(args []string) error {
+	if (cmd.Args.ID == "") == !cmd.All {
+		return fmt.Errorf("either a session id or --all must be " +
+			"provided")
+	}
+
+	// Setup request
+	rs := &v1.RevokeSessions{
+		ID:  cmd.Args.ID,
+		All: cmd.All,
+	}
+
+	// Print request details
+	err := Print(rs, cfg.Verbose, cfg.RawJSON)
+	if err != nil {
+		return err
+	}
+
+	// Send request
+	rsr, err := c.RevokeSessions(rs)
+	if err != nil {
+		return err
+	}
+
+	// Print response details
+	return Print(rsr, cfg.Verbose, cfg.RawJSON)
+}
@@ -0,0 +1,40 @@
+package commands
+
+import "github.com/decred/politeia/politeiawww/api/v1"
+
+// Help message displayed for the command 'politeiawwwcli help settotp'
+var SetTOTPCmdHelpMsg = `settotp
+
+Generate a new two-factor authentication secret for the logged in user.  Add
+the secret to an authenticator app and enable two-factor authentication using
+the verifytotp command.
+
+Arguments: none
+
+Result:
+{
+  "secret":   (string)  Base32 encoded TOTP secret
+  "uri":      (string)  otpauth URI of the secret
+}`
+
+type SetTOTPCmd struct{}
+
+func (cmd *SetTOTPCmd) Execute(args []string) error {
+	// Setup request
+	st := &v1.SetTOTP{}
+
+	// Print request details
+	err := Print(st, cfg.Verbose, cfg.RawJSON)
+	if err != nil {
+		return err
+	}
+
+	// Send request
+	str, err := c.SetTOTP(st)
+	if err != nil {
+		return err
+	}
+
+	// Print response details
+	return Print(str, cfg.Verbose, cfg.RawJSON)
+}
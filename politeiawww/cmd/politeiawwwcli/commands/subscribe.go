@@ -15,13 +15,18 @@ import (
 	"golang.org/x/net/publicsuffix"
 )
 
-var SubscribeCmdHelpMsg = `subscribe [auth] <ping...>
+var SubscribeCmdHelpMsg = `subscribe [auth] <subscription...>
 
 Connect and subcribe to www websocket. If auth is provided the connection will
 be made to the authenticated websocket (must be logged in).
 
-Supported commands:
+Supported subscriptions:
 	- ping (does not require authentication)
+	- newproposal (requires authentication)
+	- proposalstatus (does not require authentication)
+	- newcomment:<token> (does not require authentication)
+	- votestarted (does not require authentication)
+	- votetally:<token> (does not require authentication)
 
 Request:
 {
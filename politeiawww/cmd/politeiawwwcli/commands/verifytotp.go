@@ -0,0 +1,46 @@
+package commands
+
+import "github.com/decred/politeia/politeiawww/api/v1"
+
+// Help message displayed for the command 'politeiawwwcli help verifytotp'
+var VerifyTOTPCmdHelpMsg = `verifytotp "code"
+
+Enable two-factor authentication for the logged in user by verifying a code of
+the secret that was generated using the settotp command.  The returned recovery
+codes can be used in place of a code when logging in and are only shown once.
+
+Arguments:
+1. code       (string, required)   TOTP code
+
+Result:
+{
+  "recoverycodes":   ([]string)  Single use recovery codes
+}`
+
+type VerifyTOTPCmd struct {
+	Args struct {
+		Code string `positional-arg-name:"code"`
+	} `positional-args:"true" required:"true"`
+}
+
+func (cmd *VerifyTOTPCmd) Execute(args []string) error {
+	// Setup request
+	vt := &v1.VerifyTOTP{
+		Code: cmd.Args.Code,
+	}
+
+	// Print request details
+	err := Print(vt, cfg.Verbose, cfg.RawJSON)
+	if err != nil {
+		return err
+	}
+
+	// Send request
+	vtr, err := c.VerifyTOTP(vt)
+	if err != nil {
+		return err
+	}
+
+	// Print response details
+	return Print(vtr, cfg.Verbose, cfg.RawJSON)
+}
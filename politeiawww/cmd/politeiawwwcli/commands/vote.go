@@ -24,7 +24,10 @@ Arguments:
 Result:
 Enter the private passphrase of your wallet:
 Votes succeeded:  (int)  Number of successful votes
-Votes failed   :  (int)  Number of failed votes`
+Votes failed   :  (int)  Number of failed votes
+
+When the --json flag is set the ballot and the vote receipts are printed
+instead so that they can be audited using politeia_verify.`
 
 type VoteCmd struct {
 	Args struct {
@@ -204,6 +207,16 @@ func (cmd *VoteCmd) Execute(args []string) error {
 		}
 	}
 
+	// Print the ballot and the receipts as raw JSON when requested so
+	// that they can be verified offline.
+	if cfg.RawJSON {
+		err = Print(v1.Ballot{Votes: votes}, false, true)
+		if err != nil {
+			return err
+		}
+		return Print(br, false, true)
+	}
+
 	// Print results
 	fmt.Printf("Votes succeeded: %v\n", len(br.Receipts)-len(failedReceipts))
 	fmt.Printf("Votes failed   : %v\n", len(failedReceipts))
@@ -0,0 +1,172 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/decred/dcrtime/merkle"
+	"github.com/decred/politeia/util"
+)
+
+// Help message displayed for the command 'politeiawwwcli help voteproof'
+var VoteProofCmdHelpMsg = `voteproof "token" "ticket"
+
+Fetch and verify the proof that a ticket vote was included in the politeiad
+repository and anchored in dcrtime.  The vote receipt, the merkle path from
+the git commit to the anchor merkle root and the dcrtime merkle path from the
+anchor merkle root to the merkle root of the anchor transaction are verified.
+The vote itself can be found in the ballot journal of the commit in a clone
+of the politeiad repository.
+
+Arguments:
+1. token       (string, required)  Proposal censorship token
+2. ticket      (string, required)  Ticket hash
+
+Flags:
+  --dcrtimehost (string, optional) Also verify the anchor with this dcrtime
+                                   host, e.g. https://time.decred.org:49152
+
+Response:
+{
+  "castvote":      (CastVote)      Cast vote
+  "receipt":       (string)        Server signature of the cast vote signature
+  "commit":        (string)        Git commit that added the vote
+  "anchormerkle":  (string)        Merkle root of the anchored commits
+  "merklepath":    (merkle.Branch) Path from the commit digest to anchormerkle
+  "verifydigest":  (VerifyDigest)  dcrtime verification of anchormerkle
+}`
+
+type VoteProofCmd struct {
+	Args struct {
+		Token  string `positional-arg-name:"token" description:"Proposal censorship token"`
+		Ticket string `positional-arg-name:"ticket" description:"Ticket hash"`
+	} `positional-args:"true" required:"true"`
+	DcrtimeHost string `long:"dcrtimehost" description:"Also verify the anchor with this dcrtime host"`
+}
+
+// branchContains returns whether the provided digest is one of the hashes
+// of a merkle branch.
+func branchContains(mb merkle.Branch, digest []byte) bool {
+	for _, h := range mb.Hashes {
+		if bytes.Equal(h[:], digest) {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyMerkleBranch verifies that the merkle branch contains the leaf and
+// leads to the provided merkle root.
+func verifyMerkleBranch(mb merkle.Branch, leaf []byte, root string) error {
+	if !branchContains(mb, leaf) {
+		return fmt.Errorf("merkle path does not contain %x", leaf)
+	}
+	mr, err := merkle.VerifyAuthPath(&mb)
+	if err != nil {
+		return err
+	}
+	if hex.EncodeToString(mr[:]) != root {
+		return fmt.Errorf("merkle path root %x does not match %v", *mr,
+			root)
+	}
+	return nil
+}
+
+func (cmd *VoteProofCmd) Execute(args []string) error {
+	// Get server public key
+	vr, err := c.Version()
+	if err != nil {
+		return err
+	}
+
+	vipr, err := c.VoteInclusionProof(cmd.Args.Token, cmd.Args.Ticket)
+	if err != nil {
+		return err
+	}
+
+	// Print response details
+	err = Print(vipr, cfg.Verbose, cfg.RawJSON)
+	if err != nil {
+		return err
+	}
+
+	// Verify the vote receipt
+	serverID, err := util.IdentityFromString(vr.PubKey)
+	if err != nil {
+		return err
+	}
+	receipt, err := util.ConvertSignature(vipr.Receipt)
+	if err != nil {
+		return err
+	}
+	if !serverID.VerifyMessage([]byte(vipr.CastVote.Signature), receipt) {
+		return fmt.Errorf("could not verify vote receipt")
+	}
+	if vipr.CastVote.Token != cmd.Args.Token ||
+		vipr.CastVote.Ticket != cmd.Args.Ticket {
+		return fmt.Errorf("proof is for a different vote")
+	}
+
+	if vipr.Commit == "" {
+		fmt.Printf("Vote receipt verified; the vote has not been " +
+			"committed yet\n")
+		return nil
+	}
+	if vipr.AnchorMerkle == "" {
+		fmt.Printf("Vote receipt verified; commit %v has not been "+
+			"anchored yet\n", vipr.Commit)
+		return nil
+	}
+
+	// Verify the merkle path from the commit to the anchor.  Commit
+	// digests are SHA1 digests that have been extended to 32 bytes.
+	commit, err := hex.DecodeString(vipr.Commit)
+	if err != nil {
+		return err
+	}
+	leaf := make([]byte, 32)
+	copy(leaf, commit)
+	err = verifyMerkleBranch(vipr.MerklePath, leaf, vipr.AnchorMerkle)
+	if err != nil {
+		return fmt.Errorf("commit %v: %v", vipr.Commit, err)
+	}
+
+	// Optionally ask dcrtime ourselves instead of trusting the server
+	vd := vipr.VerifyDigest
+	if cmd.DcrtimeHost != "" {
+		dvr, err := util.Verify(cmd.DcrtimeHost,
+			[]string{vipr.AnchorMerkle})
+		if err != nil {
+			return fmt.Errorf("dcrtime Verify: %v", err)
+		}
+		if len(dvr.Digests) != 1 {
+			return fmt.Errorf("invalid dcrtime reply")
+		}
+		vd = dvr.Digests[0]
+	}
+	if vd.Digest != vipr.AnchorMerkle {
+		return fmt.Errorf("dcrtime result is for digest %v", vd.Digest)
+	}
+
+	ci := vd.ChainInformation
+	if ci.ChainTimestamp == 0 {
+		fmt.Printf("Vote receipt and commit %v verified; anchor %v has "+
+			"not been confirmed yet\n", vipr.Commit, vipr.AnchorMerkle)
+		return nil
+	}
+
+	// Verify the dcrtime merkle path from the anchor to the transaction
+	anchor, err := hex.DecodeString(vipr.AnchorMerkle)
+	if err != nil {
+		return err
+	}
+	err = verifyMerkleBranch(ci.MerklePath, anchor, ci.MerkleRoot)
+	if err != nil {
+		return fmt.Errorf("anchor %v: %v", vipr.AnchorMerkle, err)
+	}
+
+	fmt.Printf("Vote verified: included in commit %v, anchored in "+
+		"transaction %v\n", vipr.Commit, ci.Transaction)
+	return nil
+}
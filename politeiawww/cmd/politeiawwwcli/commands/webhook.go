@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// Help message displayed for the command 'politeiawwwcli help webhook'
+var WebhookCmdHelpMsg = `webhook new "url" "events"
+webhook list
+webhook delete "id"
+webhook deliveries "id"
+
+Register, list and delete the webhooks that are notified of proposal and vote
+events and fetch their delivery logs.  Requires admin privileges.  Events are
+posted as JSON and signed with the HMAC-SHA256 of the body keyed with the
+webhook secret, which is sent in the X-Politeia-Signature header.  The secret
+of a new webhook is only shown once.
+
+Arguments (new):
+1. url        (string, required)   HTTP or HTTPS URL the events are posted to
+2. events     (string, required)   Comma separated list of events
+
+Arguments (delete, deliveries):
+1. id         (string, required)   Webhook id
+
+Flags (new):
+  --secret    (string, optional)   HMAC secret of at least 16 characters.  A
+                                   random secret is generated if not set.
+
+Valid events are:
+proposal.submitted      New unvetted proposal
+proposal.statuschange   Proposal status set by an admin
+proposal.edited         Public proposal edited
+vote.authorized         Vote authorized or revoked by the author
+vote.started            Proposal vote started
+comment.new             New comment on a proposal
+
+Result (new):
+{
+  "webhook": {
+    "id":          (string)  Webhook id
+    "url":         (string)  URL the events are posted to
+    "events":      (uint64)  Events bit flag
+    "createdat":   (int64)   Unix timestamp of creation
+    "createdby":   (string)  ID of the admin that created the webhook
+  },
+  "secret":        (string)  HMAC secret
+}`
+
+type WebhookCmd struct {
+	New        WebhookNewCmd        `command:"new" description:"register a new webhook"`
+	List       WebhookListCmd       `command:"list" description:"list the registered webhooks"`
+	Delete     WebhookDeleteCmd     `command:"delete" description:"delete a webhook"`
+	Deliveries WebhookDeliveriesCmd `command:"deliveries" description:"fetch the delivery log of a webhook"`
+}
+
+type WebhookNewCmd struct {
+	Args struct {
+		URL    string `positional-arg-name:"url"`
+		Events string `positional-arg-name:"events"`
+	} `positional-args:"true" required:"true"`
+	Secret string `long:"secret" optional:"true" description:"HMAC secret"`
+}
+
+func (cmd *WebhookNewCmd) Execute(args []string) error {
+	// Parse events.  These can be either the numeric bit flag or a
+	// comma separated list of event names.
+	var events uint64
+	e, err := strconv.ParseUint(cmd.Args.Events, 10, 64)
+	if err == nil {
+		events = e
+	} else {
+		for _, name := range strings.Split(cmd.Args.Events, ",") {
+			var found bool
+			for k, v := range v1.WebhookEvent {
+				if v == strings.TrimSpace(name) {
+					events |= uint64(k)
+					found = true
+				}
+			}
+			if !found {
+				return fmt.Errorf("Invalid event %v.  See 'help "+
+					"webhook' for the valid events", name)
+			}
+		}
+	}
+
+	// Setup request
+	nw := &v1.NewWebhook{
+		URL:    cmd.Args.URL,
+		Secret: cmd.Secret,
+		Events: events,
+	}
+
+	// Print request details
+	err = Print(nw, cfg.Verbose, cfg.RawJSON)
+	if err != nil {
+		return err
+	}
+
+	// Send request
+	nwr, err := c.NewWebhook(nw)
+	if err != nil {
+		return err
+	}
+
+	// Print response details
+	return Print(nwr, cfg.Verbose, cfg.RawJSON)
+}
+
+type WebhookListCmd struct{}
+
+func (cmd *WebhookListCmd) Execute(args []string) error {
+	wr, err := c.Webhooks()
+	if err != nil {
+		return err
+	}
+
+	// Print response details
+	return Print(wr, cfg.Verbose, cfg.RawJSON)
+}
+
+type WebhookDeleteCmd struct {
+	Args struct {
+		ID string `positional-arg-name:"id"`
+	} `positional-args:"true" required:"true"`
+}
+
+func (cmd *WebhookDeleteCmd) Execute(args []string) error {
+	// Setup request
+	dw := &v1.DeleteWebhook{
+		ID: cmd.Args.ID,
+	}
+
+	// Print request details
+	err := Print(dw, cfg.Verbose, cfg.RawJSON)
+	if err != nil {
+		return err
+	}
+
+	// Send request
+	dwr, err := c.DeleteWebhook(dw)
+	if err != nil {
+		return err
+	}
+
+	// Print response details
+	return Print(dwr, cfg.Verbose, cfg.RawJSON)
+}
+
+type WebhookDeliveriesCmd struct {
+	Args struct {
+		ID string `positional-arg-name:"id"`
+	} `positional-args:"true" required:"true"`
+}
+
+func (cmd *WebhookDeliveriesCmd) Execute(args []string) error {
+	wdr, err := c.WebhookDeliveries(&v1.WebhookDeliveries{
+		ID: cmd.Args.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Print response details
+	return Print(wdr, cfg.Verbose, cfg.RawJSON)
+}
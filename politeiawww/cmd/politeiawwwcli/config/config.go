@@ -44,6 +44,7 @@ var (
 )
 
 type Config struct {
+	APIKey      string `long:"apikey" description:"API key that is sent instead of the session cookies"`
 	HomeDir     string `long:"appdata" description:"Path to application home directory"`
 	Host        string `long:"host" description:"politeiawww host"`
 	RawJSON     bool   `short:"j" long:"json" description:"Print raw JSON output"`
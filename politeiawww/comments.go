@@ -84,6 +84,8 @@ func convertDecredLikeCommentToWWWLikeComment(lc decredplugin.LikeComment) www.L
 		Action:    lc.Action,
 		Signature: lc.Signature,
 		PublicKey: lc.PublicKey,
+		Receipt:   lc.Receipt,
+		Timestamp: lc.Timestamp,
 	}
 }
 
@@ -106,6 +108,18 @@ func convertWWWCensorCommentToDecredCensorComment(cc www.CensorComment) decredpl
 	}
 }
 
+func convertDecredCensorCommentToWWWCensorComment(cc decredplugin.CensorComment) www.CensorComment {
+	return www.CensorComment{
+		Token:     cc.Token,
+		CommentID: cc.CommentID,
+		Reason:    cc.Reason,
+		Signature: cc.Signature,
+		PublicKey: cc.PublicKey,
+		Receipt:   cc.Receipt,
+		Timestamp: cc.Timestamp,
+	}
+}
+
 func convertDecredCensorCommentReplyToWWWCensorCommentReply(ccr decredplugin.CensorCommentReply) www.CensorCommentReply {
 	return www.CensorCommentReply{
 		Receipt: ccr.Receipt,
@@ -16,14 +16,12 @@ import (
 	"sort"
 	"strconv"
 	"strings"
-	"text/template"
 
 	"github.com/decred/dcrd/hdkeychain"
 	"github.com/decred/politeia/politeiad/api/v1/identity"
 	"github.com/decred/politeia/util/version"
 
 	flags "github.com/btcsuite/go-flags"
-	"github.com/dajohi/goemail"
 	"github.com/decred/politeia/politeiad/api/v1"
 	"github.com/decred/politeia/politeiawww/sharedconfig"
 	"github.com/decred/politeia/util"
@@ -33,7 +31,6 @@ const (
 	defaultLogLevel         = "info"
 	defaultLogDirname       = "logs"
 	defaultLogFilename      = "politeiawww.log"
-	adminLogFilename        = "admin.log"
 	defaultIdentityFilename = "identity.json"
 
 	defaultMainnetPort = "4443"
@@ -43,10 +40,21 @@ const (
 
 	defaultPaywallMinConfirmations = uint64(2)
 	defaultPaywallAmount           = uint64(0)
+	defaultTxFetchers              = util.TxFetcherDcrdata + "," +
+		util.TxFetcherInsight
 
 	defaultVoteDurationMin = uint32(2016)
 	defaultVoteDurationMax = uint32(4032)
 
+	// Session stores
+	sessionStoreFilesystem = "filesystem"
+	sessionStoreDatabase   = "database"
+
+	// Email transports
+	mailTransportSMTP    = "smtp"
+	mailTransportMaildir = "maildir"
+	defaultMailDirname   = "maildir"
+
 	// dust value can be found increasing the amount value until we get false
 	// from IsDustAmount function. Amounts can not be lower than dust
 	// func IsDustAmount(amount int64, relayFeePerKb int64) bool {
@@ -61,36 +69,8 @@ var (
 	defaultHTTPSCertFile = filepath.Join(sharedconfig.DefaultHomeDir, "https.cert")
 	defaultRPCCertFile   = filepath.Join(sharedconfig.DefaultHomeDir, "rpc.cert")
 	defaultCookieKeyFile = filepath.Join(sharedconfig.DefaultHomeDir, "cookie.key")
+	defaultAuditIDFile   = filepath.Join(sharedconfig.DefaultHomeDir, "auditidentity.json")
 	defaultLogDir        = filepath.Join(sharedconfig.DefaultHomeDir, defaultLogDirname)
-
-	templateNewUserEmail = template.Must(
-		template.New("new_user_email_template").Parse(templateNewUserEmailRaw))
-	templateResetPasswordEmail = template.Must(
-		template.New("reset_password_email_template").Parse(templateResetPasswordEmailRaw))
-	templateUpdateUserKeyEmail = template.Must(
-		template.New("update_user_key_email_template").Parse(templateUpdateUserKeyEmailRaw))
-	templateUserLockedResetPassword = template.Must(
-		template.New("user_locked_reset_password").Parse(templateUserLockedResetPasswordRaw))
-	templateNewProposalSubmitted = template.Must(
-		template.New("new_proposal_submitted_template").Parse(templateNewProposalSubmittedRaw))
-	templateProposalVetted = template.Must(
-		template.New("proposal_vetted_template").Parse(templateProposalVettedRaw))
-	templateProposalEdited = template.Must(
-		template.New("proposal_edited_template").Parse(templateProposalEditedRaw))
-	templateProposalVoteStarted = template.Must(
-		template.New("proposal_vote_started_template").Parse(templateProposalVoteStartedRaw))
-	templateProposalVoteAuthorized = template.Must(
-		template.New("proposal_vote_authorized_template").Parse(templateProposalVoteAuthorizedRaw))
-	templateProposalVettedForAuthor = template.Must(
-		template.New("proposal_vetted_for_author_template").Parse(templateProposalVettedForAuthorRaw))
-	templateProposalCensoredForAuthor = template.Must(
-		template.New("proposal_censored_for_author_template").Parse(templateProposalCensoredForAuthorRaw))
-	templateProposalVoteStartedForAuthor = template.Must(
-		template.New("proposal_vote_started_for_author_template").Parse(templateProposalVoteStartedForAuthorRaw))
-	templateCommentReplyOnProposal = template.Must(
-		template.New("comment_reply_on_proposal").Parse(templateCommentReplyOnProposalRaw))
-	templateCommentReplyOnComment = template.Must(
-		template.New("comment_reply_on_comment").Parse(templateCommentReplyOnCommentRaw))
 )
 
 // runServiceCommand is only set to a real function on Windows.  It is used
@@ -110,6 +90,7 @@ type config struct {
 	SimNet                   bool     `long:"simnet" description:"Use the simulation test network"`
 	Profile                  string   `long:"profile" description:"Enable HTTP profiling on given port -- NOTE port must be between 1024 and 65536"`
 	CookieKeyFile            string   `long:"cookiekey" description:"File containing the secret cookies key"`
+	AuditIdentityFile        string   `long:"auditidentity" description:"File containing the identity that signs the admin audit log"`
 	CPUProfile               string   `long:"cpuprofile" description:"Write CPU profile to the specified file"`
 	MemProfile               string   `long:"memprofile" description:"Write mem profile to the specified file"`
 	DebugLevel               string   `short:"d" long:"debuglevel" description:"Logging level for all subsystems {trace, debug, info, warn, error, critical} -- You may also specify <subsystem>=<level>,<subsystem2>=<level>,... to set the log level for individual subsystems -- Use show to list available subsystems"`
@@ -126,16 +107,29 @@ type config struct {
 	MailHost                 string `long:"mailhost" description:"Email server address in this format: <host>:<port>"`
 	MailUser                 string `long:"mailuser" description:"Email server username"`
 	MailPass                 string `long:"mailpass" description:"Email server password"`
-	SMTP                     *goemail.SMTP
+	MailCert                 string `long:"mailcert" description:"File containing the email server certificate; required when the server uses a self-signed certificate"`
+	MailTransport            string `long:"mailtransport" description:"How emails are delivered {smtp, maildir}"`
+	MailDir                  string `long:"maildir" description:"Directory the maildir transport delivers emails to"`
+	TemplateDir              string `long:"templatedir" description:"Directory containing customized and localized email templates"`
+	Mailer                   emailTransport
 	FetchIdentity            bool   `long:"fetchidentity" description:"Whether or not politeiawww fetches the identity from politeiad."`
 	WebServerAddress         string `long:"webserveraddress" description:"Address for the Politeia web server; it should have this format: <scheme>://<host>[:<port>]"`
 	Interactive              string `long:"interactive" description:"Set to i-know-this-is-a-bad-idea to turn off interactive mode during --fetchidentity."`
 	PaywallAmount            uint64 `long:"paywallamount" description:"Amount of DCR (in atoms) required for a user to register or submit a proposal."`
 	PaywallXpub              string `long:"paywallxpub" description:"Extended public key for deriving paywall addresses."`
 	MinConfirmationsRequired uint64 `long:"minconfirmations" description:"Minimum blocks confirmation for accepting paywall as paid. Only works in TestNet."`
+	TxFetchers               string `long:"txfetchers" description:"Comma separated list of transaction sources that are tried in order when checking paywall payments {dcrdata, insight, dcrd, fixture}"`
+	DcrdataURL               string `long:"dcrdataurl" description:"dcrdata API URL used by the dcrdata transaction fetcher"`
+	InsightURL               string `long:"insighturl" description:"insight API URL used by the insight transaction fetcher"`
+	DcrdHost                 string `long:"dcrdhost" description:"dcrd RPC host used by the dcrd transaction fetcher; dcrd must run with --addrindex"`
+	DcrdUser                 string `long:"dcrduser" description:"dcrd RPC user name"`
+	DcrdPass                 string `long:"dcrdpass" description:"dcrd RPC password"`
+	DcrdCert                 string `long:"dcrdcert" description:"File containing the dcrd RPC certificate"`
+	TxFixture                string `long:"txfixture" description:"JSON file that maps addresses to transactions used by the fixture transaction fetcher"`
 	VoteDurationMin          uint32 `long:"votedurationmin" description:"Minimum duration of a proposal vote in blocks"`
 	VoteDurationMax          uint32 `long:"votedurationmax" description:"Maximum duration of a proposal vote in blocks"`
-	AdminLogFile             string
+	AdminTOTP                bool   `long:"admintotp" description:"Require two-factor authentication for users that hold a role"`
+	SessionStore             string `long:"sessionstore" description:"Where session data is stored {filesystem, database}"`
 }
 
 // serviceOptions defines the configuration options for the rpc as a service
@@ -285,12 +279,17 @@ func newConfigParser(cfg *config, so *serviceOptions, options flags.Options) *fl
 	return parser
 }
 
-func initSMTP(cfg *config) error {
-	// Check that either all MailServer options are populated or none are,
-	// and then initialize the SMTP object if they're all populated.
-	cfg.SMTP = nil
-	if cfg.MailHost != "" || cfg.MailUser != "" ||
-		cfg.MailPass != "" || cfg.WebServerAddress != "" {
+func initMailer(cfg *config) error {
+	cfg.Mailer = nil
+	switch cfg.MailTransport {
+	case mailTransportSMTP:
+		// Check that either all MailServer options are populated or
+		// none are, and then initialize the SMTP object if they're all
+		// populated.
+		if cfg.MailHost == "" && cfg.MailUser == "" &&
+			cfg.MailPass == "" && cfg.WebServerAddress == "" {
+			return nil
+		}
 		if cfg.MailHost == "" || cfg.MailUser == "" ||
 			cfg.MailPass == "" || cfg.WebServerAddress == "" {
 			err := fmt.Errorf("either all or none of the " +
@@ -299,12 +298,35 @@ func initSMTP(cfg *config) error {
 			return err
 		}
 
-		var err error
-		cfg.SMTP, err = goemail.NewSMTP("smtps://" + cfg.MailUser +
-			":" + cfg.MailPass + "@" + cfg.MailHost)
+		if cfg.MailCert != "" {
+			cfg.MailCert = cleanAndExpandPath(cfg.MailCert)
+		}
+		smtp, err := newSMTPTransport(cfg.MailHost, cfg.MailUser,
+			cfg.MailPass, cfg.MailCert)
 		if err != nil {
 			return err
 		}
+		cfg.Mailer = smtp
+
+	case mailTransportMaildir:
+		if cfg.WebServerAddress == "" {
+			return fmt.Errorf("the maildir mail transport requires " +
+				"webserveraddress to be supplied")
+		}
+		if cfg.MailDir == "" {
+			cfg.MailDir = filepath.Join(cfg.DataDir, defaultMailDirname)
+		}
+		cfg.MailDir = cleanAndExpandPath(cfg.MailDir)
+
+		maildir, err := newMaildirTransport(cfg.MailDir)
+		if err != nil {
+			return err
+		}
+		cfg.Mailer = maildir
+
+	default:
+		return fmt.Errorf("invalid mail transport: %v",
+			cfg.MailTransport)
 	}
 
 	return nil
@@ -366,11 +388,15 @@ func loadConfig() (*config, []string, error) {
 		HTTPSCert:                defaultHTTPSCertFile,
 		RPCCert:                  defaultRPCCertFile,
 		CookieKeyFile:            defaultCookieKeyFile,
+		AuditIdentityFile:        defaultAuditIDFile,
 		PaywallAmount:            defaultPaywallAmount,
 		MinConfirmationsRequired: defaultPaywallMinConfirmations,
+		TxFetchers:               defaultTxFetchers,
 		Version:                  version.String(),
 		VoteDurationMin:          defaultVoteDurationMin,
 		VoteDurationMax:          defaultVoteDurationMax,
+		SessionStore:             sessionStoreFilesystem,
+		MailTransport:            mailTransportSMTP,
 	}
 
 	// Service options which are only added on Windows.
@@ -453,6 +479,12 @@ func loadConfig() (*config, []string, error) {
 		} else {
 			cfg.CookieKeyFile = preCfg.CookieKeyFile
 		}
+		if preCfg.AuditIdentityFile == defaultAuditIDFile {
+			cfg.AuditIdentityFile = filepath.Join(cfg.HomeDir,
+				"auditidentity.json")
+		} else {
+			cfg.AuditIdentityFile = preCfg.AuditIdentityFile
+		}
 	}
 
 	// Load additional config from file.
@@ -540,7 +572,10 @@ func loadConfig() (*config, []string, error) {
 	cfg.LogDir = cleanAndExpandPath(cfg.LogDir)
 	cfg.LogDir = filepath.Join(cfg.LogDir, netName(activeNetParams))
 
-	cfg.AdminLogFile = filepath.Join(cfg.LogDir, adminLogFilename)
+	cfg.AuditIdentityFile = cleanAndExpandPath(cfg.AuditIdentityFile)
+	if cfg.TemplateDir != "" {
+		cfg.TemplateDir = cleanAndExpandPath(cfg.TemplateDir)
+	}
 
 	cfg.HTTPSKey = cleanAndExpandPath(cfg.HTTPSKey)
 	cfg.HTTPSCert = cleanAndExpandPath(cfg.HTTPSCert)
@@ -631,7 +666,7 @@ func loadConfig() (*config, []string, error) {
 		log.Warnf("RPC password not set, using random value")
 	}
 
-	if err := initSMTP(&cfg); err != nil {
+	if err := initMailer(&cfg); err != nil {
 		return nil, nil, err
 	}
 
@@ -661,6 +696,56 @@ func loadConfig() (*config, []string, error) {
 			return nil, nil, fmt.Errorf("paywall extended public key is for the " +
 				"wrong network")
 		}
+
+		// Validate the transaction fetchers that are used to check
+		// paywall payments.
+		if cfg.DcrdataURL == "" {
+			cfg.DcrdataURL = util.DefaultDcrdataURL(activeNetParams.Params)
+		}
+		if cfg.InsightURL == "" {
+			cfg.InsightURL = util.DefaultInsightURL(activeNetParams.Params)
+		}
+		if cfg.DcrdCert != "" {
+			cfg.DcrdCert = cleanAndExpandPath(cfg.DcrdCert)
+		}
+		if cfg.TxFixture != "" {
+			cfg.TxFixture = cleanAndExpandPath(cfg.TxFixture)
+		}
+		for _, v := range strings.Split(cfg.TxFetchers, ",") {
+			var missing string
+			switch strings.TrimSpace(v) {
+			case util.TxFetcherDcrdata:
+				if cfg.DcrdataURL == "" {
+					missing = "dcrdataurl"
+				}
+			case util.TxFetcherInsight:
+				if cfg.InsightURL == "" {
+					missing = "insighturl"
+				}
+			case util.TxFetcherDcrd:
+				if cfg.DcrdHost == "" {
+					missing = "dcrdhost"
+				}
+			case util.TxFetcherFixture:
+				if cfg.TxFixture == "" {
+					missing = "txfixture"
+				}
+			default:
+				return nil, nil, fmt.Errorf("invalid transaction "+
+					"fetcher: %v", v)
+			}
+			if missing != "" {
+				return nil, nil, fmt.Errorf("transaction fetcher %v "+
+					"requires %v to be set", v, missing)
+			}
+		}
+	}
+
+	switch cfg.SessionStore {
+	case sessionStoreFilesystem, sessionStoreDatabase:
+	default:
+		return nil, nil, fmt.Errorf("invalid session store: %v",
+			cfg.SessionStore)
 	}
 
 	return &cfg, remainingArgs, nil
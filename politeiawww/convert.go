@@ -167,6 +167,17 @@ func convertVoteResultsReplyFromDecredplugin(vrr decredplugin.VoteResultsReply,
 	}
 }
 
+func convertVoteInclusionProofReplyFromDecredplugin(vipr decredplugin.VoteInclusionProofReply) www.VoteInclusionProofReply {
+	return www.VoteInclusionProofReply{
+		CastVote:     convertCastVoteFromDecredplugin(vipr.CastVote),
+		Receipt:      vipr.Receipt,
+		Commit:       vipr.Commit,
+		AnchorMerkle: vipr.AnchorMerkle,
+		MerklePath:   vipr.MerklePath,
+		VerifyDigest: vipr.VerifyDigest,
+	}
+}
+
 func convertPropStatusFromWWW(s www.PropStatusT) pd.RecordStatusT {
 	switch s {
 	case www.PropStatusNotFound:
@@ -326,8 +337,10 @@ func convertPropFromPD(p pd.Record) www.ProposalRecord {
 	}
 }
 
-func convertErrorStatusFromPD(s int) www.ErrorStatusT {
-	switch pd.ErrorStatusT(s) {
+func convertErrorStatusFromPD(e www.PDErrorReply) www.ErrorStatusT {
+	switch pd.ErrorStatusT(e.ErrorCode) {
+	case pd.ErrorStatusPluginVeto:
+		return convertPluginErrorFromPD(e.PluginID, e.PluginErrorCode)
 	case pd.ErrorStatusInvalidFileDigest:
 		return www.ErrorStatusInvalidFileDigest
 	case pd.ErrorStatusInvalidBase64:
@@ -351,6 +364,19 @@ func convertErrorStatusFromPD(s int) www.ErrorStatusT {
 	return www.ErrorStatusInvalid
 }
 
+// convertPluginErrorFromPD converts the error code of a plugin that vetoed a
+// politeiad request.  Unknown plugin errors are considered internal errors.
+func convertPluginErrorFromPD(pluginID string, s int) www.ErrorStatusT {
+	switch pluginID {
+	case decredplugin.ID:
+		switch decredplugin.ErrorStatusT(s) {
+		case decredplugin.ErrorStatusVoteStarted:
+			return www.ErrorStatusWrongVoteStatus
+		}
+	}
+	return www.ErrorStatusInvalid
+}
+
 func convertVoteResultsFromDecredplugin(vrr decredplugin.VoteResultsReply) []www.VoteOptionResult {
 	// counter of votes received
 	var vr uint64
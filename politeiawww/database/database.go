@@ -5,10 +5,14 @@
 package database
 
 import (
+	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
 
 	"github.com/decred/politeia/politeiad/api/v1/identity"
+	"github.com/decred/politeia/util"
 	"github.com/google/uuid"
 )
 
@@ -25,6 +29,34 @@ var (
 
 	// ErrShutdown is emitted when the database is shutting down.
 	ErrShutdown = errors.New("database is shutting down")
+
+	// ErrCommentReportExists indicates that the user has already reported
+	// the comment.
+	ErrCommentReportExists = errors.New("comment report already exists")
+
+	// ErrAPIKeyNotFound indicates that an API key was not found in the
+	// database.
+	ErrAPIKeyNotFound = errors.New("API key not found")
+
+	// ErrSessionNotFound indicates that a session was not found in the
+	// database.
+	ErrSessionNotFound = errors.New("session not found")
+
+	// ErrAdminLogEntryNotFound indicates that an admin log entry was not
+	// found in the database.
+	ErrAdminLogEntryNotFound = errors.New("admin log entry not found")
+
+	// ErrEmailNotFound indicates that an email was not found in the
+	// outbox.
+	ErrEmailNotFound = errors.New("email not found")
+
+	// ErrEmailDigestNotFound indicates that a user has no pending email
+	// digest.
+	ErrEmailDigestNotFound = errors.New("email digest not found")
+
+	// ErrWebhookNotFound indicates that a webhook was not found in the
+	// database.
+	ErrWebhookNotFound = errors.New("webhook not found")
 )
 
 // Identity wraps an ed25519 public key and timestamps to indicate if it is
@@ -95,6 +127,7 @@ type User struct {
 	Username                        string    // Unique username
 	HashedPassword                  []byte    // Blowfish hash
 	Admin                           bool      // Is user an admin
+	Roles                           uint64    // Roles granted to the user
 	PaywallAddressIndex             uint64    // Sequential id used to generate paywall address
 	NewUserPaywallAddress           string    // Address the user needs to send to
 	NewUserPaywallAmount            uint64    // Amount the user needs to send
@@ -112,6 +145,13 @@ type User struct {
 	FailedLoginAttempts             uint64    // Number of failed login a user has made in a row
 	Deactivated                     bool      // Whether the account is deactivated or not
 	EmailNotifications              uint64    // Notify the user via emails
+	EmailDigestDaily                uint64    // Notifications delivered in a daily digest
+	EmailDigestWeekly               uint64    // Notifications delivered in a weekly digest
+	Locale                          string    // Locale of the emails sent to the user
+	TOTPSecret                      string    // Pending or enabled TOTP secret
+	TOTPEnabled                     bool      // Whether two-factor authentication is enabled
+	TOTPLastStep                    uint64    // Time step of the last accepted TOTP code
+	TOTPRecoveryCodes               []string  // SHA256 digests of unused recovery codes
 
 	// Access times for proposal comments that have been accessed by the user.
 	// Each string represents a proposal token, and the int64 represents the
@@ -142,6 +182,189 @@ type User struct {
 	SpentProposalCredits []ProposalCredit
 }
 
+// CommentReport is a report filed by a user against a proposal comment.
+// Reports remain in the database until an admin resolves them.
+type CommentReport struct {
+	Token     string    // Proposal censorship token
+	CommentID string    // Comment ID
+	UserID    uuid.UUID // ID of reporting user
+	Reason    string    // Reason the comment was reported
+	Timestamp int64     // Unix timestamp of when the report was filed
+}
+
+// CensorshipReceipt is the politeiad receipt of a proposal censorship.  The
+// receipt is the politeiad signature of the SHA256 digest of the admin
+// censorship signature.
+type CensorshipReceipt struct {
+	Token     string // Proposal censorship token
+	Signature string // Admin censorship signature
+	Receipt   string // politeiad signature of the digest of Signature
+}
+
+// APIKey is a token that allows scripted access to the web server on behalf
+// of a user.  Only the digest of the secret part of the token is stored.
+type APIKey struct {
+	ID        string    // Unique key id
+	UserID    uuid.UUID // ID of the user that owns the key
+	Name      string    // User supplied description
+	Digest    []byte    // SHA256 digest of the key secret
+	Scopes    uint64    // Scopes that the key grants
+	CreatedAt int64     // Unix timestamp of when the key was created
+	LastUsed  int64     // Unix timestamp of when the key was last used
+}
+
+// Session is a web server session.  Sessions are keyed by the digest of the
+// session id so that the ids, which authenticate users, are not stored.
+type Session struct {
+	ID        string    // SHA256 digest of the session id
+	UserID    uuid.UUID // ID of the logged in user, zero if not logged in
+	Values    string    // Encoded session values, only used by the database session store
+	CreatedAt int64     // Unix timestamp of when the user logged in
+	LastSeen  int64     // Unix timestamp of when the session was last used
+	Expiry    int64     // Unix timestamp of when the session expires
+	IP        string    // IP address of the client that logged in
+	UserAgent string    // User agent of the client that logged in
+}
+
+// AdminLogEntry is an entry of the admin audit log.  Every entry includes the
+// hash of the previous entry and is signed by the server identity, which makes
+// any modification of the log evident.
+type AdminLogEntry struct {
+	Index         uint64    `json:"index"`               // Position in the log, starting at 0
+	Timestamp     int64     `json:"timestamp"`           // Unix timestamp of the action
+	AdminID       uuid.UUID `json:"adminid"`             // ID of the admin that performed the action
+	AdminUsername string    `json:"adminusername"`       // Username of the admin
+	Action        string    `json:"action"`              // Description of the action
+	UserID        string    `json:"userid,omitempty"`    // ID of the user the action applies to, if any
+	Token         string    `json:"token,omitempty"`     // Censorship token of the proposal, if any
+	CommentID     string    `json:"commentid,omitempty"` // ID of the comment, if any
+	Reason        string    `json:"reason"`              // Reason given by the admin
+	PrevHash      string    `json:"prevhash"`            // Hash of the previous entry, empty for the first entry
+	Hash          string    `json:"hash"`                // SHA256 digest of the entry, see AdminLogEntryHash
+	PublicKey     string    `json:"publickey"`           // Public key of the server identity
+	Signature     string    `json:"signature"`           // Signature of Hash by the server identity
+}
+
+// AdminLogEntryHash returns the hex encoded SHA256 digest of the JSON encoded
+// admin log entry with empty Hash and Signature fields.  The JSON encoding
+// matches the encoding of the entry in the www API so that clients can verify
+// the entries they receive.
+func AdminLogEntryHash(e AdminLogEntry) (string, error) {
+	e.Hash = ""
+	e.Signature = ""
+	b, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:]), nil
+}
+
+// VerifyAdminLogEntry verifies the hash and the signature of an admin log
+// entry and that it directly follows prev, which must be nil for the first
+// entry of the log.
+func VerifyAdminLogEntry(e, prev *AdminLogEntry) error {
+	if prev == nil {
+		if e.Index != 0 || e.PrevHash != "" {
+			return fmt.Errorf("entry %v: not the first entry", e.Index)
+		}
+	} else {
+		if e.Index != prev.Index+1 {
+			return fmt.Errorf("entry %v: expected index %v", e.Index,
+				prev.Index+1)
+		}
+		if e.PrevHash != prev.Hash {
+			return fmt.Errorf("entry %v: previous hash mismatch",
+				e.Index)
+		}
+	}
+
+	hash, err := AdminLogEntryHash(*e)
+	if err != nil {
+		return err
+	}
+	if hash != e.Hash {
+		return fmt.Errorf("entry %v: hash mismatch", e.Index)
+	}
+
+	id, err := util.IdentityFromString(e.PublicKey)
+	if err != nil {
+		return fmt.Errorf("entry %v: %v", e.Index, err)
+	}
+	sig, err := util.ConvertSignature(e.Signature)
+	if err != nil {
+		return fmt.Errorf("entry %v: %v", e.Index, err)
+	}
+	if !id.VerifyMessage([]byte(e.Hash), sig) {
+		return fmt.Errorf("entry %v: invalid signature", e.Index)
+	}
+
+	return nil
+}
+
+// Email is a message in the email outbox.  Emails stay in the outbox until
+// they have been delivered.  Emails that could not be delivered after the
+// maximum number of attempts are kept as dead letters.
+type Email struct {
+	ID          string   // Unique email id
+	Subject     string   // Email subject
+	Body        string   // Plain text body
+	HTMLBody    string   // Optional HTML body
+	To          []string // Recipients
+	BCC         []string // Blind copy recipients
+	CreatedAt   int64    // Unix timestamp of when the email was queued
+	Attempts    uint64   // Number of failed delivery attempts
+	NextAttempt int64    // Unix timestamp of the next delivery attempt
+	LastError   string   // Error of the last failed delivery attempt
+	DeadLetter  bool     // Set once delivery has been abandoned
+}
+
+// DigestEvent is a notification that is waiting to be delivered in an email
+// digest.
+type DigestEvent struct {
+	Notification uint64 // Email notification type
+	Weekly       bool   // Delivered in the weekly instead of the daily digest
+	Timestamp    int64  // Unix timestamp of the event
+	ProposalName string // Name of the proposal the event applies to
+	Username     string // Username of the user that caused the event
+	Link         string // Link to the proposal or comment
+}
+
+// EmailDigest holds the events that are waiting to be delivered to a user in
+// an email digest.
+type EmailDigest struct {
+	UserID uuid.UUID     // Unique user uuid
+	Events []DigestEvent // Pending events, oldest first
+}
+
+// Webhook is an URL that is notified of proposal and vote events.  The secret
+// is stored in the clear because it is needed to sign the requests.
+type Webhook struct {
+	ID        string    // Unique webhook id
+	URL       string    // URL the events are posted to
+	Secret    string    // HMAC secret
+	Events    uint64    // Events the webhook subscribes to
+	CreatedAt int64     // Unix timestamp of creation
+	CreatedBy uuid.UUID // ID of the admin that created the webhook
+}
+
+// WebhookDelivery is an event that is delivered to a webhook.  Deliveries
+// are kept as the delivery log of the webhook after they have been delivered
+// or abandoned.
+type WebhookDelivery struct {
+	ID          string // Unique delivery id
+	WebhookID   string // ID of the webhook
+	Event       string // Event name
+	Payload     []byte // JSON encoded request body
+	CreatedAt   int64  // Unix timestamp of the event
+	Attempts    uint64 // Number of delivery attempts
+	NextAttempt int64  // Unix timestamp of the next delivery attempt
+	StatusCode  int    // HTTP status code of the last attempt
+	LastError   string // Error of the last failed attempt
+	Delivered   bool   // Set once the event has been delivered
+	Abandoned   bool   // Set once delivery has been abandoned
+}
+
 // Database interface that is required by the web server.
 type Database interface {
 	// User functions
@@ -152,6 +375,55 @@ type Database interface {
 	UserUpdate(User) error                   // Update existing user
 	AllUsers(callbackFn func(u *User)) error // Iterate all users
 
+	// Comment report functions
+	CommentReportNew(CommentReport) error                      // Add new comment report
+	CommentReportsGet(string, string) ([]CommentReport, error) // Return all reports for a comment
+	CommentReportsDelete(string, string) error                 // Delete all reports for a comment
+	AllCommentReports(callbackFn func(r *CommentReport)) error // Iterate all comment reports
+
+	// Censorship receipt functions
+	CensorshipReceiptNew(CensorshipReceipt) error                      // Add new proposal censorship receipt
+	AllCensorshipReceipts(callbackFn func(r *CensorshipReceipt)) error // Iterate all proposal censorship receipts
+
+	// API key functions
+	APIKeyNew(APIKey) error                 // Add new API key
+	APIKeyGet(string) (*APIKey, error)      // Return API key given its id
+	APIKeyUpdate(APIKey) error              // Update existing API key
+	APIKeyDelete(string) error              // Delete API key given its id
+	APIKeysGet(uuid.UUID) ([]APIKey, error) // Return all API keys of a user
+
+	// Session functions
+	SessionSave(Session) error                        // Add or update session
+	SessionGet(string) (*Session, error)              // Return session given its id
+	SessionDelete(string) error                       // Delete session given its id
+	SessionsGet(uuid.UUID) ([]Session, error)         // Return all sessions of a user
+	SessionsDeleteByUserID(uuid.UUID, []string) error // Delete all sessions of a user except the provided ones
+
+	// Admin log functions
+	AdminLogEntryNew(AdminLogEntry) error                       // Append entry to the admin log
+	AdminLogEntryLatest() (*AdminLogEntry, error)               // Return the last entry of the admin log
+	AllAdminLogEntries(callbackFn func(e *AdminLogEntry)) error // Iterate all admin log entries in order
+
+	// Email outbox functions
+	EmailSave(Email) error                     // Create or update outbox email
+	EmailDelete(string) error                  // Delete outbox email
+	AllEmails(callbackFn func(e *Email)) error // Iterate all outbox emails
+
+	// Email digest functions
+	EmailDigestGet(uuid.UUID) (*EmailDigest, error)        // Return the pending digest of a user
+	EmailDigestSave(EmailDigest) error                     // Create or update pending digest
+	EmailDigestDelete(uuid.UUID) error                     // Delete the pending digest of a user
+	AllEmailDigests(callbackFn func(d *EmailDigest)) error // Iterate all pending digests
+
+	// Webhook functions
+	WebhookNew(Webhook) error                                       // Add new webhook
+	WebhookGet(string) (*Webhook, error)                            // Return webhook given its id
+	WebhookDelete(string) error                                     // Delete webhook and its deliveries
+	AllWebhooks(callbackFn func(w *Webhook)) error                  // Iterate all webhooks
+	WebhookDeliverySave(WebhookDelivery) error                      // Create or update delivery
+	WebhookDeliveryDelete(string) error                             // Delete delivery given its id
+	AllWebhookDeliveries(callbackFn func(d *WebhookDelivery)) error // Iterate all deliveries
+
 	// Close performs cleanup of the backend.
 	Close() error
 }
@@ -79,3 +79,202 @@ func DecodeUser(payload []byte) (*database.User, error) {
 
 	return &u, nil
 }
+
+// EncodeAPIKey encodes APIKey into a JSON byte slice.
+func EncodeAPIKey(k database.APIKey) ([]byte, error) {
+	b, err := json.Marshal(k)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// DecodeAPIKey decodes a JSON byte slice into an APIKey.
+func DecodeAPIKey(payload []byte) (*database.APIKey, error) {
+	var k database.APIKey
+
+	err := json.Unmarshal(payload, &k)
+	if err != nil {
+		return nil, err
+	}
+
+	return &k, nil
+}
+
+// EncodeSession encodes Session into a JSON byte slice.
+func EncodeSession(s database.Session) ([]byte, error) {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// DecodeSession decodes a JSON byte slice into a Session.
+func DecodeSession(payload []byte) (*database.Session, error) {
+	var s database.Session
+
+	err := json.Unmarshal(payload, &s)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// EncodeAdminLogEntry encodes AdminLogEntry into a JSON byte slice.
+func EncodeAdminLogEntry(e database.AdminLogEntry) ([]byte, error) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// DecodeAdminLogEntry decodes a JSON byte slice into an AdminLogEntry.
+func DecodeAdminLogEntry(payload []byte) (*database.AdminLogEntry, error) {
+	var e database.AdminLogEntry
+
+	err := json.Unmarshal(payload, &e)
+	if err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+// EncodeEmail encodes Email into a JSON byte slice.
+func EncodeEmail(e database.Email) ([]byte, error) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// DecodeEmail decodes a JSON byte slice into an Email.
+func DecodeEmail(payload []byte) (*database.Email, error) {
+	var e database.Email
+
+	err := json.Unmarshal(payload, &e)
+	if err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+// EncodeEmailDigest encodes EmailDigest into a JSON byte slice.
+func EncodeEmailDigest(d database.EmailDigest) ([]byte, error) {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// DecodeEmailDigest decodes a JSON byte slice into an EmailDigest.
+func DecodeEmailDigest(payload []byte) (*database.EmailDigest, error) {
+	var d database.EmailDigest
+
+	err := json.Unmarshal(payload, &d)
+	if err != nil {
+		return nil, err
+	}
+
+	return &d, nil
+}
+
+// EncodeWebhook encodes Webhook into a JSON byte slice.
+func EncodeWebhook(w database.Webhook) ([]byte, error) {
+	b, err := json.Marshal(w)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// DecodeWebhook decodes a JSON byte slice into a Webhook.
+func DecodeWebhook(payload []byte) (*database.Webhook, error) {
+	var w database.Webhook
+
+	err := json.Unmarshal(payload, &w)
+	if err != nil {
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+// EncodeWebhookDelivery encodes WebhookDelivery into a JSON byte slice.
+func EncodeWebhookDelivery(d database.WebhookDelivery) ([]byte, error) {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// DecodeWebhookDelivery decodes a JSON byte slice into a WebhookDelivery.
+func DecodeWebhookDelivery(payload []byte) (*database.WebhookDelivery, error) {
+	var d database.WebhookDelivery
+
+	err := json.Unmarshal(payload, &d)
+	if err != nil {
+		return nil, err
+	}
+
+	return &d, nil
+}
+
+// EncodeCommentReport encodes CommentReport into a JSON byte slice.
+func EncodeCommentReport(r database.CommentReport) ([]byte, error) {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// DecodeCommentReport decodes a JSON byte slice into a CommentReport.
+func DecodeCommentReport(payload []byte) (*database.CommentReport, error) {
+	var r database.CommentReport
+
+	err := json.Unmarshal(payload, &r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// EncodeCensorshipReceipt encodes CensorshipReceipt into a JSON byte slice.
+func EncodeCensorshipReceipt(r database.CensorshipReceipt) ([]byte, error) {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// DecodeCensorshipReceipt decodes a JSON byte slice into a
+// CensorshipReceipt.
+func DecodeCensorshipReceipt(payload []byte) (*database.CensorshipReceipt, error) {
+	var r database.CensorshipReceipt
+
+	err := json.Unmarshal(payload, &r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
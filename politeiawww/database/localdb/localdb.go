@@ -2,6 +2,7 @@ package localdb
 
 import (
 	"encoding/binary"
+	"fmt"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -10,6 +11,7 @@ import (
 	"github.com/decred/politeia/politeiawww/database"
 	"github.com/google/uuid"
 	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
 const (
@@ -18,6 +20,37 @@ const (
 
 	UserVersion    uint32 = 1
 	UserVersionKey        = "userversion"
+
+	// CommentReportPrefix is prepended to the keys of all comment report
+	// records.
+	CommentReportPrefix = "commentreport:"
+
+	// CensorshipReceiptPrefix is prepended to the keys of all proposal
+	// censorship receipts.
+	CensorshipReceiptPrefix = "censorshipreceipt:"
+
+	// APIKeyPrefix is prepended to the keys of all API key records.
+	APIKeyPrefix = "apikey:"
+
+	// SessionPrefix is prepended to the keys of all session records.
+	SessionPrefix = "session:"
+
+	// AdminLogPrefix is prepended to the keys of all admin log entries.
+	AdminLogPrefix = "adminlog:"
+
+	// EmailPrefix is prepended to the keys of all outbox emails.
+	EmailPrefix = "email:"
+
+	// EmailDigestPrefix is prepended to the keys of all pending email
+	// digests.
+	EmailDigestPrefix = "emaildigest:"
+
+	// WebhookPrefix is prepended to the keys of all webhook records.
+	WebhookPrefix = "webhook:"
+
+	// WebhookDeliveryPrefix is prepended to the keys of all webhook
+	// delivery records.
+	WebhookDeliveryPrefix = "webhookdelivery:"
 )
 
 var (
@@ -42,7 +75,28 @@ type Version struct {
 // and false otherwise. This is helpful when iterating the user records
 // because the DB contains some non-user records.
 func isUserRecord(key string) bool {
-	return key != UserVersionKey && key != LastPaywallAddressIndex
+	return key != UserVersionKey && key != LastPaywallAddressIndex &&
+		!strings.HasPrefix(key, CommentReportPrefix) &&
+		!strings.HasPrefix(key, CensorshipReceiptPrefix) &&
+		!strings.HasPrefix(key, APIKeyPrefix) &&
+		!strings.HasPrefix(key, SessionPrefix) &&
+		!strings.HasPrefix(key, AdminLogPrefix) &&
+		!strings.HasPrefix(key, EmailPrefix) &&
+		!strings.HasPrefix(key, EmailDigestPrefix) &&
+		!strings.HasPrefix(key, WebhookPrefix) &&
+		!strings.HasPrefix(key, WebhookDeliveryPrefix)
+}
+
+// adminLogKey returns the key of the admin log entry with the given index.
+// The index is zero padded so that entries are iterated in order.
+func adminLogKey(index uint64) string {
+	return fmt.Sprintf("%v%020d", AdminLogPrefix, index)
+}
+
+// commentReportKey returns the key prefix for all reports that were filed
+// against the given comment.
+func commentReportKey(token, commentID string) string {
+	return CommentReportPrefix + token + ":" + commentID + ":"
 }
 
 // Store new user.
@@ -264,6 +318,868 @@ func (l *localdb) AllUsers(callbackFn func(u *database.User)) error {
 	return iter.Error()
 }
 
+// CommentReportNew stores a new comment report.  A user may only report a
+// given comment once.
+//
+// CommentReportNew satisfies the backend interface.
+func (l *localdb) CommentReportNew(r database.CommentReport) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return database.ErrShutdown
+	}
+
+	log.Debugf("CommentReportNew: %v %v", r.Token, r.CommentID)
+
+	key := []byte(commentReportKey(r.Token, r.CommentID) + r.UserID.String())
+	ok, err := l.userdb.Has(key, nil)
+	if err != nil {
+		return err
+	} else if ok {
+		return database.ErrCommentReportExists
+	}
+
+	payload, err := EncodeCommentReport(r)
+	if err != nil {
+		return err
+	}
+
+	return l.userdb.Put(key, payload, nil)
+}
+
+// CommentReportsGet returns all reports that were filed against a comment.
+//
+// CommentReportsGet satisfies the backend interface.
+func (l *localdb) CommentReportsGet(token, commentID string) ([]database.CommentReport, error) {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return nil, database.ErrShutdown
+	}
+
+	log.Debugf("CommentReportsGet: %v %v", token, commentID)
+
+	reports := make([]database.CommentReport, 0)
+	iter := l.userdb.NewIterator(util.BytesPrefix(
+		[]byte(commentReportKey(token, commentID))), nil)
+	for iter.Next() {
+		r, err := DecodeCommentReport(iter.Value())
+		if err != nil {
+			iter.Release()
+			return nil, err
+		}
+		reports = append(reports, *r)
+	}
+	iter.Release()
+
+	return reports, iter.Error()
+}
+
+// CommentReportsDelete removes all reports that were filed against a comment.
+//
+// CommentReportsDelete satisfies the backend interface.
+func (l *localdb) CommentReportsDelete(token, commentID string) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return database.ErrShutdown
+	}
+
+	log.Debugf("CommentReportsDelete: %v %v", token, commentID)
+
+	batch := new(leveldb.Batch)
+	iter := l.userdb.NewIterator(util.BytesPrefix(
+		[]byte(commentReportKey(token, commentID))), nil)
+	for iter.Next() {
+		batch.Delete(append([]byte{}, iter.Key()...))
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return err
+	}
+
+	return l.userdb.Write(batch, nil)
+}
+
+// AllCommentReports iterates over all comment reports.
+//
+// AllCommentReports satisfies the backend interface.
+func (l *localdb) AllCommentReports(callbackFn func(r *database.CommentReport)) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return database.ErrShutdown
+	}
+
+	log.Debugf("AllCommentReports\n")
+
+	iter := l.userdb.NewIterator(util.BytesPrefix(
+		[]byte(CommentReportPrefix)), nil)
+	for iter.Next() {
+		r, err := DecodeCommentReport(iter.Value())
+		if err != nil {
+			iter.Release()
+			return err
+		}
+
+		callbackFn(r)
+	}
+	iter.Release()
+
+	return iter.Error()
+}
+
+// CensorshipReceiptNew stores the receipt of a proposal censorship.
+//
+// CensorshipReceiptNew satisfies the backend interface.
+func (l *localdb) CensorshipReceiptNew(r database.CensorshipReceipt) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return database.ErrShutdown
+	}
+
+	log.Debugf("CensorshipReceiptNew: %v", r.Token)
+
+	payload, err := EncodeCensorshipReceipt(r)
+	if err != nil {
+		return err
+	}
+
+	return l.userdb.Put([]byte(CensorshipReceiptPrefix+r.Token), payload,
+		nil)
+}
+
+// AllCensorshipReceipts iterates over all proposal censorship receipts.
+//
+// AllCensorshipReceipts satisfies the backend interface.
+func (l *localdb) AllCensorshipReceipts(callbackFn func(r *database.CensorshipReceipt)) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return database.ErrShutdown
+	}
+
+	log.Debugf("AllCensorshipReceipts")
+
+	iter := l.userdb.NewIterator(util.BytesPrefix(
+		[]byte(CensorshipReceiptPrefix)), nil)
+	for iter.Next() {
+		r, err := DecodeCensorshipReceipt(iter.Value())
+		if err != nil {
+			iter.Release()
+			return err
+		}
+		callbackFn(r)
+	}
+	iter.Release()
+
+	return iter.Error()
+}
+
+// APIKeyNew stores a new API key.
+//
+// APIKeyNew satisfies the backend interface.
+func (l *localdb) APIKeyNew(k database.APIKey) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return database.ErrShutdown
+	}
+
+	log.Debugf("APIKeyNew: %v %v", k.UserID, k.ID)
+
+	payload, err := EncodeAPIKey(k)
+	if err != nil {
+		return err
+	}
+
+	return l.userdb.Put([]byte(APIKeyPrefix+k.ID), payload, nil)
+}
+
+// APIKeyGet returns the API key with the given id.
+//
+// APIKeyGet satisfies the backend interface.
+func (l *localdb) APIKeyGet(id string) (*database.APIKey, error) {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return nil, database.ErrShutdown
+	}
+
+	log.Debugf("APIKeyGet: %v", id)
+
+	payload, err := l.userdb.Get([]byte(APIKeyPrefix+id), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, database.ErrAPIKeyNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return DecodeAPIKey(payload)
+}
+
+// APIKeyUpdate updates an existing API key.
+//
+// APIKeyUpdate satisfies the backend interface.
+func (l *localdb) APIKeyUpdate(k database.APIKey) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return database.ErrShutdown
+	}
+
+	log.Debugf("APIKeyUpdate: %v %v", k.UserID, k.ID)
+
+	key := []byte(APIKeyPrefix + k.ID)
+	ok, err := l.userdb.Has(key, nil)
+	if err != nil {
+		return err
+	} else if !ok {
+		return database.ErrAPIKeyNotFound
+	}
+
+	payload, err := EncodeAPIKey(k)
+	if err != nil {
+		return err
+	}
+
+	return l.userdb.Put(key, payload, nil)
+}
+
+// APIKeyDelete removes the API key with the given id.
+//
+// APIKeyDelete satisfies the backend interface.
+func (l *localdb) APIKeyDelete(id string) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return database.ErrShutdown
+	}
+
+	log.Debugf("APIKeyDelete: %v", id)
+
+	key := []byte(APIKeyPrefix + id)
+	ok, err := l.userdb.Has(key, nil)
+	if err != nil {
+		return err
+	} else if !ok {
+		return database.ErrAPIKeyNotFound
+	}
+
+	return l.userdb.Delete(key, nil)
+}
+
+// APIKeysGet returns all API keys of the given user.
+//
+// APIKeysGet satisfies the backend interface.
+func (l *localdb) APIKeysGet(userID uuid.UUID) ([]database.APIKey, error) {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return nil, database.ErrShutdown
+	}
+
+	log.Debugf("APIKeysGet: %v", userID)
+
+	keys := make([]database.APIKey, 0)
+	iter := l.userdb.NewIterator(util.BytesPrefix([]byte(APIKeyPrefix)), nil)
+	for iter.Next() {
+		k, err := DecodeAPIKey(iter.Value())
+		if err != nil {
+			iter.Release()
+			return nil, err
+		}
+		if k.UserID == userID {
+			keys = append(keys, *k)
+		}
+	}
+	iter.Release()
+
+	return keys, iter.Error()
+}
+
+// SessionSave stores a session.  Existing sessions are overwritten.
+//
+// SessionSave satisfies the backend interface.
+func (l *localdb) SessionSave(s database.Session) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return database.ErrShutdown
+	}
+
+	log.Debugf("SessionSave: %v %v", s.UserID, s.ID)
+
+	payload, err := EncodeSession(s)
+	if err != nil {
+		return err
+	}
+
+	return l.userdb.Put([]byte(SessionPrefix+s.ID), payload, nil)
+}
+
+// SessionGet returns the session with the given id.
+//
+// SessionGet satisfies the backend interface.
+func (l *localdb) SessionGet(id string) (*database.Session, error) {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return nil, database.ErrShutdown
+	}
+
+	log.Debugf("SessionGet: %v", id)
+
+	payload, err := l.userdb.Get([]byte(SessionPrefix+id), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, database.ErrSessionNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return DecodeSession(payload)
+}
+
+// SessionDelete removes the session with the given id.
+//
+// SessionDelete satisfies the backend interface.
+func (l *localdb) SessionDelete(id string) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return database.ErrShutdown
+	}
+
+	log.Debugf("SessionDelete: %v", id)
+
+	key := []byte(SessionPrefix + id)
+	ok, err := l.userdb.Has(key, nil)
+	if err != nil {
+		return err
+	} else if !ok {
+		return database.ErrSessionNotFound
+	}
+
+	return l.userdb.Delete(key, nil)
+}
+
+// SessionsGet returns all sessions of the given user.
+//
+// SessionsGet satisfies the backend interface.
+func (l *localdb) SessionsGet(userID uuid.UUID) ([]database.Session, error) {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return nil, database.ErrShutdown
+	}
+
+	log.Debugf("SessionsGet: %v", userID)
+
+	sessions := make([]database.Session, 0)
+	iter := l.userdb.NewIterator(util.BytesPrefix([]byte(SessionPrefix)), nil)
+	for iter.Next() {
+		s, err := DecodeSession(iter.Value())
+		if err != nil {
+			iter.Release()
+			return nil, err
+		}
+		if s.UserID == userID {
+			sessions = append(sessions, *s)
+		}
+	}
+	iter.Release()
+
+	return sessions, iter.Error()
+}
+
+// SessionsDeleteByUserID removes all sessions of the given user except for
+// the sessions whose ids are provided.
+//
+// SessionsDeleteByUserID satisfies the backend interface.
+func (l *localdb) SessionsDeleteByUserID(userID uuid.UUID, except []string) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return database.ErrShutdown
+	}
+
+	log.Debugf("SessionsDeleteByUserID: %v", userID)
+
+	keep := make(map[string]bool, len(except))
+	for _, v := range except {
+		keep[v] = true
+	}
+
+	batch := new(leveldb.Batch)
+	iter := l.userdb.NewIterator(util.BytesPrefix([]byte(SessionPrefix)), nil)
+	for iter.Next() {
+		s, err := DecodeSession(iter.Value())
+		if err != nil {
+			iter.Release()
+			return err
+		}
+		if s.UserID == userID && !keep[s.ID] {
+			batch.Delete([]byte(SessionPrefix + s.ID))
+		}
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return err
+	}
+
+	return l.userdb.Write(batch, nil)
+}
+
+// AdminLogEntryNew appends an entry to the admin log.  Existing entries are
+// never overwritten.
+//
+// AdminLogEntryNew satisfies the backend interface.
+func (l *localdb) AdminLogEntryNew(e database.AdminLogEntry) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return database.ErrShutdown
+	}
+
+	log.Debugf("AdminLogEntryNew: %v", e.Index)
+
+	key := []byte(adminLogKey(e.Index))
+	ok, err := l.userdb.Has(key, nil)
+	if err != nil {
+		return err
+	} else if ok {
+		return fmt.Errorf("admin log entry %v already exists", e.Index)
+	}
+
+	payload, err := EncodeAdminLogEntry(e)
+	if err != nil {
+		return err
+	}
+
+	return l.userdb.Put(key, payload, nil)
+}
+
+// AdminLogEntryLatest returns the last entry of the admin log.
+//
+// AdminLogEntryLatest satisfies the backend interface.
+func (l *localdb) AdminLogEntryLatest() (*database.AdminLogEntry, error) {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return nil, database.ErrShutdown
+	}
+
+	log.Debugf("AdminLogEntryLatest")
+
+	iter := l.userdb.NewIterator(util.BytesPrefix([]byte(AdminLogPrefix)), nil)
+	defer iter.Release()
+	if !iter.Last() {
+		if err := iter.Error(); err != nil {
+			return nil, err
+		}
+		return nil, database.ErrAdminLogEntryNotFound
+	}
+
+	return DecodeAdminLogEntry(iter.Value())
+}
+
+// AllAdminLogEntries iterates over all admin log entries in order.
+//
+// AllAdminLogEntries satisfies the backend interface.
+func (l *localdb) AllAdminLogEntries(callbackFn func(e *database.AdminLogEntry)) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return database.ErrShutdown
+	}
+
+	log.Debugf("AllAdminLogEntries")
+
+	iter := l.userdb.NewIterator(util.BytesPrefix([]byte(AdminLogPrefix)), nil)
+	for iter.Next() {
+		e, err := DecodeAdminLogEntry(iter.Value())
+		if err != nil {
+			iter.Release()
+			return err
+		}
+		callbackFn(e)
+	}
+	iter.Release()
+
+	return iter.Error()
+}
+
+// EmailSave stores an outbox email.  Existing emails are overwritten.
+//
+// EmailSave satisfies the backend interface.
+func (l *localdb) EmailSave(e database.Email) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return database.ErrShutdown
+	}
+
+	log.Debugf("EmailSave: %v", e.ID)
+
+	payload, err := EncodeEmail(e)
+	if err != nil {
+		return err
+	}
+
+	return l.userdb.Put([]byte(EmailPrefix+e.ID), payload, nil)
+}
+
+// EmailDelete removes the outbox email with the given id.
+//
+// EmailDelete satisfies the backend interface.
+func (l *localdb) EmailDelete(id string) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return database.ErrShutdown
+	}
+
+	log.Debugf("EmailDelete: %v", id)
+
+	key := []byte(EmailPrefix + id)
+	ok, err := l.userdb.Has(key, nil)
+	if err != nil {
+		return err
+	} else if !ok {
+		return database.ErrEmailNotFound
+	}
+
+	return l.userdb.Delete(key, nil)
+}
+
+// AllEmails iterates over all outbox emails.
+//
+// AllEmails satisfies the backend interface.
+func (l *localdb) AllEmails(callbackFn func(e *database.Email)) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return database.ErrShutdown
+	}
+
+	log.Debugf("AllEmails")
+
+	iter := l.userdb.NewIterator(util.BytesPrefix([]byte(EmailPrefix)), nil)
+	for iter.Next() {
+		e, err := DecodeEmail(iter.Value())
+		if err != nil {
+			iter.Release()
+			return err
+		}
+		callbackFn(e)
+	}
+	iter.Release()
+
+	return iter.Error()
+}
+
+// EmailDigestGet returns the pending email digest of the given user.
+//
+// EmailDigestGet satisfies the backend interface.
+func (l *localdb) EmailDigestGet(userID uuid.UUID) (*database.EmailDigest, error) {
+	l.RLock()
+	defer l.RUnlock()
+
+	if l.shutdown {
+		return nil, database.ErrShutdown
+	}
+
+	log.Debugf("EmailDigestGet: %v", userID)
+
+	payload, err := l.userdb.Get([]byte(EmailDigestPrefix+userID.String()),
+		nil)
+	if err == leveldb.ErrNotFound {
+		return nil, database.ErrEmailDigestNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return DecodeEmailDigest(payload)
+}
+
+// EmailDigestSave stores the pending email digest of a user.  An existing
+// digest is overwritten.
+//
+// EmailDigestSave satisfies the backend interface.
+func (l *localdb) EmailDigestSave(d database.EmailDigest) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return database.ErrShutdown
+	}
+
+	log.Debugf("EmailDigestSave: %v", d.UserID)
+
+	payload, err := EncodeEmailDigest(d)
+	if err != nil {
+		return err
+	}
+
+	return l.userdb.Put([]byte(EmailDigestPrefix+d.UserID.String()),
+		payload, nil)
+}
+
+// EmailDigestDelete removes the pending email digest of the given user.
+//
+// EmailDigestDelete satisfies the backend interface.
+func (l *localdb) EmailDigestDelete(userID uuid.UUID) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return database.ErrShutdown
+	}
+
+	log.Debugf("EmailDigestDelete: %v", userID)
+
+	key := []byte(EmailDigestPrefix + userID.String())
+	ok, err := l.userdb.Has(key, nil)
+	if err != nil {
+		return err
+	} else if !ok {
+		return database.ErrEmailDigestNotFound
+	}
+
+	return l.userdb.Delete(key, nil)
+}
+
+// AllEmailDigests iterates over all pending email digests.
+//
+// AllEmailDigests satisfies the backend interface.
+func (l *localdb) AllEmailDigests(callbackFn func(d *database.EmailDigest)) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return database.ErrShutdown
+	}
+
+	log.Debugf("AllEmailDigests")
+
+	iter := l.userdb.NewIterator(util.BytesPrefix([]byte(EmailDigestPrefix)),
+		nil)
+	for iter.Next() {
+		d, err := DecodeEmailDigest(iter.Value())
+		if err != nil {
+			iter.Release()
+			return err
+		}
+		callbackFn(d)
+	}
+	iter.Release()
+
+	return iter.Error()
+}
+
+// WebhookNew stores a new webhook.
+//
+// WebhookNew satisfies the backend interface.
+func (l *localdb) WebhookNew(w database.Webhook) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return database.ErrShutdown
+	}
+
+	log.Debugf("WebhookNew: %v %v", w.ID, w.URL)
+
+	payload, err := EncodeWebhook(w)
+	if err != nil {
+		return err
+	}
+
+	return l.userdb.Put([]byte(WebhookPrefix+w.ID), payload, nil)
+}
+
+// WebhookGet returns the webhook with the given id.
+//
+// WebhookGet satisfies the backend interface.
+func (l *localdb) WebhookGet(id string) (*database.Webhook, error) {
+	l.RLock()
+	defer l.RUnlock()
+
+	if l.shutdown {
+		return nil, database.ErrShutdown
+	}
+
+	log.Debugf("WebhookGet: %v", id)
+
+	payload, err := l.userdb.Get([]byte(WebhookPrefix+id), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, database.ErrWebhookNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return DecodeWebhook(payload)
+}
+
+// WebhookDelete removes the webhook with the given id and all of its
+// deliveries.
+//
+// WebhookDelete satisfies the backend interface.
+func (l *localdb) WebhookDelete(id string) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return database.ErrShutdown
+	}
+
+	log.Debugf("WebhookDelete: %v", id)
+
+	key := []byte(WebhookPrefix + id)
+	ok, err := l.userdb.Has(key, nil)
+	if err != nil {
+		return err
+	} else if !ok {
+		return database.ErrWebhookNotFound
+	}
+
+	batch := new(leveldb.Batch)
+	batch.Delete(key)
+	iter := l.userdb.NewIterator(util.BytesPrefix(
+		[]byte(WebhookDeliveryPrefix)), nil)
+	for iter.Next() {
+		d, err := DecodeWebhookDelivery(iter.Value())
+		if err != nil {
+			iter.Release()
+			return err
+		}
+		if d.WebhookID == id {
+			batch.Delete([]byte(WebhookDeliveryPrefix + d.ID))
+		}
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return err
+	}
+
+	return l.userdb.Write(batch, nil)
+}
+
+// AllWebhooks iterates over all webhooks.
+//
+// AllWebhooks satisfies the backend interface.
+func (l *localdb) AllWebhooks(callbackFn func(w *database.Webhook)) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return database.ErrShutdown
+	}
+
+	log.Debugf("AllWebhooks")
+
+	iter := l.userdb.NewIterator(util.BytesPrefix([]byte(WebhookPrefix)), nil)
+	for iter.Next() {
+		w, err := DecodeWebhook(iter.Value())
+		if err != nil {
+			iter.Release()
+			return err
+		}
+		callbackFn(w)
+	}
+	iter.Release()
+
+	return iter.Error()
+}
+
+// WebhookDeliverySave creates or updates a webhook delivery.
+//
+// WebhookDeliverySave satisfies the backend interface.
+func (l *localdb) WebhookDeliverySave(d database.WebhookDelivery) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return database.ErrShutdown
+	}
+
+	log.Debugf("WebhookDeliverySave: %v %v", d.WebhookID, d.ID)
+
+	payload, err := EncodeWebhookDelivery(d)
+	if err != nil {
+		return err
+	}
+
+	return l.userdb.Put([]byte(WebhookDeliveryPrefix+d.ID), payload, nil)
+}
+
+// WebhookDeliveryDelete removes the webhook delivery with the given id.
+//
+// WebhookDeliveryDelete satisfies the backend interface.
+func (l *localdb) WebhookDeliveryDelete(id string) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return database.ErrShutdown
+	}
+
+	log.Debugf("WebhookDeliveryDelete: %v", id)
+
+	return l.userdb.Delete([]byte(WebhookDeliveryPrefix+id), nil)
+}
+
+// AllWebhookDeliveries iterates over the deliveries of all webhooks.
+//
+// AllWebhookDeliveries satisfies the backend interface.
+func (l *localdb) AllWebhookDeliveries(callbackFn func(d *database.WebhookDelivery)) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return database.ErrShutdown
+	}
+
+	log.Debugf("AllWebhookDeliveries")
+
+	iter := l.userdb.NewIterator(util.BytesPrefix(
+		[]byte(WebhookDeliveryPrefix)), nil)
+	for iter.Next() {
+		d, err := DecodeWebhookDelivery(iter.Value())
+		if err != nil {
+			iter.Release()
+			return err
+		}
+		callbackFn(d)
+	}
+	iter.Release()
+
+	return iter.Error()
+}
+
 // Close shuts down the database.  All interface functions MUST return with
 // errShutdown if the backend is shutting down.
 //
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"sort"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/politeiawww/database"
+	"github.com/google/uuid"
+)
+
+const (
+	// emailDigestInterval is the interval at which the pending digests
+	// are checked for digests that are due.
+	emailDigestInterval = 15 * time.Minute
+
+	// emailDigestDaily and emailDigestWeekly are the periods of the
+	// digests.  A digest is sent once its oldest event has been pending
+	// for a full period.
+	emailDigestDaily  = 24 * time.Hour
+	emailDigestWeekly = 7 * 24 * time.Hour
+)
+
+// emailDigestPeriod returns the period of the digest in which the user
+// receives the provided notification type, or 0 if the user receives it
+// immediately.
+func emailDigestPeriod(user *database.User, n v1.EmailNotificationT) time.Duration {
+	switch {
+	case user.EmailDigestWeekly&uint64(n) != 0:
+		return emailDigestWeekly
+	case user.EmailDigestDaily&uint64(n) != 0:
+		return emailDigestDaily
+	}
+	return 0
+}
+
+// queueDigestEvent adds an event to the pending digest of the user if the
+// user receives the provided notification type in a digest.  It returns
+// whether the event was added.
+//
+// This function must be called WITHOUT the database lock held, i.e. not from
+// an iteration callback.
+func (b *backend) queueDigestEvent(user *database.User, n v1.EmailNotificationT, e database.DigestEvent) (bool, error) {
+	period := emailDigestPeriod(user, n)
+	if period == 0 {
+		return false, nil
+	}
+
+	e.Notification = uint64(n)
+	e.Weekly = period == emailDigestWeekly
+	e.Timestamp = time.Now().Unix()
+
+	b.digestMtx.Lock()
+	defer b.digestMtx.Unlock()
+
+	d, err := b.db.EmailDigestGet(user.ID)
+	if err == database.ErrEmailDigestNotFound {
+		d = &database.EmailDigest{
+			UserID: user.ID,
+		}
+	} else if err != nil {
+		return false, err
+	}
+	d.Events = append(d.Events, e)
+
+	err = b.db.EmailDigestSave(*d)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// queueDigestEvents adds an event to the pending digests of the provided
+// users.  The users must receive the notification type in a digest.
+func (b *backend) queueDigestEvents(users []database.User, n v1.EmailNotificationT, e database.DigestEvent) error {
+	for _, u := range users {
+		_, err := b.queueDigestEvent(&u, n, e)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dueDigestEvents splits the events of a digest into the events that are due
+// to be sent and the events that remain pending.  All events of a period are
+// due once the oldest one has been pending for the full period.
+func dueDigestEvents(events []database.DigestEvent, now time.Time) ([]database.DigestEvent, []database.DigestEvent) {
+	var dailyDue, weeklyDue bool
+	for _, e := range events {
+		age := now.Sub(time.Unix(e.Timestamp, 0))
+		if e.Weekly && age >= emailDigestWeekly {
+			weeklyDue = true
+		} else if !e.Weekly && age >= emailDigestDaily {
+			dailyDue = true
+		}
+	}
+
+	due := make([]database.DigestEvent, 0, len(events))
+	pending := make([]database.DigestEvent, 0, len(events))
+	for _, e := range events {
+		if (e.Weekly && weeklyDue) || (!e.Weekly && dailyDue) {
+			due = append(due, e)
+		} else {
+			pending = append(pending, e)
+		}
+	}
+	return due, pending
+}
+
+// createEmailDigestTemplateData returns the template data of a digest email
+// that summarizes the provided events.
+func createEmailDigestTemplateData(events []database.DigestEvent) emailDigestTemplateData {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp < events[j].Timestamp
+	})
+
+	var tplData emailDigestTemplateData
+	for _, e := range events {
+		ev := digestEventTemplateData{
+			ProposalName: e.ProposalName,
+			Username:     e.Username,
+			Link:         e.Link,
+		}
+		switch v1.EmailNotificationT(e.Notification) {
+		case v1.NotificationEmailRegularProposalVetted:
+			tplData.Vetted = append(tplData.Vetted, ev)
+		case v1.NotificationEmailRegularProposalEdited:
+			tplData.Edited = append(tplData.Edited, ev)
+		case v1.NotificationEmailMyProposalVoteStarted,
+			v1.NotificationEmailRegularProposalVoteStarted:
+			tplData.VoteStarted = append(tplData.VoteStarted, ev)
+		case v1.NotificationEmailCommentOnMyProposal,
+			v1.NotificationEmailCommentOnMyComment:
+			tplData.Comments = append(tplData.Comments, ev)
+		}
+	}
+
+	return tplData
+}
+
+// sendEmailDigest queues the due events of the pending digest of a user as a
+// single email.
+func (b *backend) sendEmailDigest(userID uuid.UUID, now time.Time) error {
+	b.digestMtx.Lock()
+	defer b.digestMtx.Unlock()
+
+	d, err := b.db.EmailDigestGet(userID)
+	if err == database.ErrEmailDigestNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	due, pending := dueDigestEvents(d.Events, now)
+	if len(due) == 0 {
+		return nil
+	}
+
+	user, err := b.db.UserGetById(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		// The digest can never be sent.
+		log.Errorf("sendEmailDigest: dropping digest of unknown user %v",
+			userID)
+		return b.db.EmailDigestDelete(userID)
+	}
+	if !user.Deactivated {
+		tplData := createEmailDigestTemplateData(due)
+		err = b.sendEmailTo(templateEmailDigest, &tplData, user.Locale,
+			user.Email)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(pending) == 0 {
+		return b.db.EmailDigestDelete(userID)
+	}
+	d.Events = pending
+	return b.db.EmailDigestSave(*d)
+}
+
+// sendEmailDigests queues the digest emails of all users whose digests are
+// due.  A digest that can't be sent does not prevent the digests of the other
+// users from being sent; it is retried on the next run.
+func (b *backend) sendEmailDigests(now time.Time) error {
+	users := make([]uuid.UUID, 0)
+	err := b.db.AllEmailDigests(func(d *database.EmailDigest) {
+		users = append(users, d.UserID)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, id := range users {
+		err := b.sendEmailDigest(id, now)
+		if err == database.ErrShutdown {
+			return err
+		} else if err != nil {
+			log.Errorf("sendEmailDigest %v: %v", id, err)
+		}
+	}
+
+	return nil
+}
+
+// runEmailDigests periodically sends the digests that are due until the
+// database is shut down.
+func (b *backend) runEmailDigests() {
+	for {
+		err := b.sendEmailDigests(time.Now())
+		if err == database.ErrShutdown {
+			return
+		} else if err != nil {
+			log.Errorf("sendEmailDigests: %v", err)
+		}
+
+		time.Sleep(emailDigestInterval)
+	}
+}
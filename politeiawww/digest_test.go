@@ -0,0 +1,117 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/politeiawww/database"
+	"github.com/decred/slog"
+	"github.com/google/uuid"
+)
+
+// Tests that digested notifications are accumulated and sent as a single
+// email once the digest period has passed.
+func TestEmailDigest(t *testing.T) {
+	defer log.SetLevel(log.Level())
+	log.SetLevel(slog.LevelOff)
+
+	b := createBackend(t)
+	nu, _ := createAndVerifyUser(t, b)
+	user, err := b.db.UserGet(nu.Email)
+	assertSuccess(t, err)
+	b.cfg.Mailer = &testTransport{}
+
+	// Digests can only contain digestable notifications and a
+	// notification can not be part of both digests.
+	invalid := uint64(www.NotificationEmailAdminProposalNew)
+	_, err = b.ProcessEditUser(&www.EditUser{
+		EmailDigestDaily: &invalid,
+	}, user)
+	assertErrorWithContext(t, err, www.ErrorStatusInvalidInput,
+		[]string{"notification type can not be digested"})
+	comments := uint64(www.NotificationEmailCommentOnMyProposal |
+		www.NotificationEmailCommentOnMyComment)
+	_, err = b.ProcessEditUser(&www.EditUser{
+		EmailDigestDaily:  &comments,
+		EmailDigestWeekly: &comments,
+	}, user)
+	assertErrorWithContext(t, err, www.ErrorStatusInvalidInput,
+		[]string{"notification type in both digests"})
+
+	none := uint64(0)
+	_, err = b.ProcessEditUser(&www.EditUser{
+		EmailNotifications: &comments,
+		EmailDigestDaily:   &comments,
+		EmailDigestWeekly:  &none,
+	}, user)
+	assertSuccess(t, err)
+	user, err = b.db.UserGet(nu.Email)
+	assertSuccess(t, err)
+
+	proposal := &www.ProposalRecord{
+		Name: "digested proposal",
+		CensorshipRecord: www.CensorshipRecord{
+			Token: "0000000000000000000000000000000000000000000000000000000000000000",
+		},
+	}
+	for i := 0; i < 2; i++ {
+		err = b.emailAuthorForCommentOnProposal(proposal, user,
+			"1", "commenter")
+		assertSuccess(t, err)
+	}
+
+	// No email is queued until the digest is due.
+	outbox := func() []www.OutboxEmail {
+		eor, err := b.ProcessEmailOutbox(www.EmailOutbox{})
+		assertSuccess(t, err)
+		return eor.Emails
+	}
+	if len(outbox()) != 0 {
+		t.Fatalf("unexpected email for digested notification")
+	}
+	d, err := b.db.EmailDigestGet(user.ID)
+	assertSuccess(t, err)
+	if len(d.Events) != 2 || d.Events[0].Weekly {
+		t.Fatalf("unexpected digest %v", d.Events)
+	}
+
+	// The digest of a user that no longer exists is dropped and does not
+	// prevent the other digests from being sent.
+	orphan := database.EmailDigest{
+		UserID: uuid.New(),
+		Events: d.Events,
+	}
+	err = b.db.EmailDigestSave(orphan)
+	assertSuccess(t, err)
+
+	now := time.Now()
+	err = b.sendEmailDigests(now)
+	assertSuccess(t, err)
+	if len(outbox()) != 0 {
+		t.Fatalf("unexpected digest before the period has passed")
+	}
+
+	err = b.sendEmailDigests(now.Add(emailDigestDaily))
+	assertSuccess(t, err)
+	var emails []database.Email
+	err = b.db.AllEmails(func(e *database.Email) {
+		emails = append(emails, *e)
+	})
+	assertSuccess(t, err)
+	if len(emails) != 1 || len(emails[0].To) != 1 ||
+		emails[0].To[0] != user.Email ||
+		strings.Count(emails[0].Body, "commenter on digested proposal") != 2 {
+		t.Fatalf("unexpected digest emails %v", emails)
+	}
+	for _, id := range []uuid.UUID{user.ID, orphan.UserID} {
+		_, err = b.db.EmailDigestGet(id)
+		if err != database.ErrEmailDigestNotFound {
+			t.Fatalf("expected digest %v to be removed, got %v",
+				id, err)
+		}
+	}
+
+	b.db.Close()
+}
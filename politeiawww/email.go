@@ -1,12 +1,9 @@
 package main
 
 import (
-	"bytes"
 	"fmt"
 	"net/url"
-	"text/template"
-
-	"github.com/dajohi/goemail"
+	"sort"
 
 	"github.com/decred/politeia/politeiawww/api/v1"
 	"github.com/decred/politeia/politeiawww/database"
@@ -17,16 +14,6 @@ const (
 	politeiaMailName = "Politeia"
 )
 
-func createBody(tpl *template.Template, tplData interface{}) (string, error) {
-	var buf bytes.Buffer
-	err := tpl.Execute(&buf, tplData)
-	if err != nil {
-		return "", err
-	}
-
-	return buf.String(), nil
-}
-
 func (b *backend) createEmailLink(path, email, token string) (string, error) {
 	l, err := url.Parse(b.cfg.WebServerAddress + path)
 	if err != nil {
@@ -45,36 +32,67 @@ func (b *backend) createEmailLink(path, email, token string) (string, error) {
 	return l.String(), nil
 }
 
-// sendEmail sends an email with the given subject and body, and the caller
-// must supply a function which is used to add email addresses to send the
-// email to.
+// sendEmail renders the template in the locale of every recipient and
+// queues one email per locale.  The caller must supply a function which is
+// used to add the users to send the email to.  All addresses are sent blind
+// copies of the email.
 func (b *backend) sendEmail(
-	subject, body string,
-	addToAddressesFn func(*goemail.Message) error,
+	tpl *emailTemplate, tplData interface{},
+	addUsersFn func(addFn func(*database.User)) error,
 ) error {
-	msg := goemail.NewMessage(fromAddress, subject, body)
-	err := addToAddressesFn(msg)
+	recipients := make(map[string][]string) // [locale][]email
+	err := addUsersFn(func(user *database.User) {
+		recipients[user.Locale] = append(recipients[user.Locale],
+			user.Email)
+	})
 	if err != nil {
 		return err
 	}
 
-	msg.SetName(politeiaMailName)
-	return b.cfg.SMTP.Send(msg)
+	locales := make([]string, 0, len(recipients))
+	for l := range recipients {
+		locales = append(locales, l)
+	}
+	sort.Strings(locales)
+	for _, l := range locales {
+		e, err := b.templates.render(tpl, l, tplData)
+		if err != nil {
+			return err
+		}
+		err = b.queueEmail(e, nil, recipients[l])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
-// sendEmailTo sends an email with the given subject and body to a
-// single address.
-func (b *backend) sendEmailTo(subject, body, toAddress string) error {
-	return b.sendEmail(subject, body, func(msg *goemail.Message) error {
-		msg.AddTo(toAddress)
-		return nil
-	})
+// sendEmailTo renders the template in the provided locale and queues the
+// email to a single address.
+func (b *backend) sendEmailTo(tpl *emailTemplate, tplData interface{}, locale, toAddress string) error {
+	e, err := b.templates.render(tpl, locale, tplData)
+	if err != nil {
+		return err
+	}
+
+	return b.queueEmail(e, []string{toAddress}, nil)
+}
+
+// userLocale returns the locale of the user with the provided email address.
+// The default locale is used when there is no such user.
+func (b *backend) userLocale(email string) string {
+	user, err := b.db.UserGet(email)
+	if err != nil {
+		return ""
+	}
+	return user.Locale
 }
 
 // emailNewUserVerificationLink emails the link with the new user verification token
 // if the email server is set up.
 func (b *backend) emailNewUserVerificationLink(email, token, username string) error {
-	if b.cfg.SMTP == nil {
+	if b.cfg.Mailer == nil {
 		return nil
 	}
 
@@ -90,19 +108,14 @@ func (b *backend) emailNewUserVerificationLink(email, token, username string) er
 		Link:     link,
 	}
 
-	subject := "Verify Your Email"
-	body, err := createBody(templateNewUserEmail, &tplData)
-	if err != nil {
-		return err
-	}
-
-	return b.sendEmailTo(subject, body, email)
+	return b.sendEmailTo(templateNewUserEmail, &tplData,
+		b.userLocale(email), email)
 }
 
 // emailResetPasswordVerificationLink emails the link with the reset password
 // verification token if the email server is set up.
 func (b *backend) emailResetPasswordVerificationLink(email, token string) error {
-	if b.cfg.SMTP == nil {
+	if b.cfg.Mailer == nil {
 		return nil
 	}
 
@@ -117,13 +130,8 @@ func (b *backend) emailResetPasswordVerificationLink(email, token string) error
 		Link:  link,
 	}
 
-	subject := "Reset Your Password"
-	body, err := createBody(templateResetPasswordEmail, &tplData)
-	if err != nil {
-		return err
-	}
-
-	return b.sendEmailTo(subject, body, email)
+	return b.sendEmailTo(templateResetPasswordEmail, &tplData,
+		b.userLocale(email), email)
 }
 
 // emailAuthorForVettedProposal sends an email notification for a new
@@ -133,7 +141,7 @@ func (b *backend) emailAuthorForVettedProposal(
 	authorUser *database.User,
 	adminUser *database.User,
 ) error {
-	if b.cfg.SMTP == nil {
+	if b.cfg.Mailer == nil {
 		return nil
 	}
 
@@ -154,13 +162,8 @@ func (b *backend) emailAuthorForVettedProposal(
 		StatusChangeReason: proposal.StatusChangeMessage,
 	}
 
-	subject := "Your Proposal Has Been Published"
-	body, err := createBody(templateProposalVettedForAuthor, &tplData)
-	if err != nil {
-		return err
-	}
-
-	return b.sendEmailTo(subject, body, authorUser.Email)
+	return b.sendEmailTo(templateProposalVettedForAuthor, &tplData,
+		authorUser.Locale, authorUser.Email)
 }
 
 // emailAuthorForCensoredProposal sends an email notification for a new
@@ -170,7 +173,7 @@ func (b *backend) emailAuthorForCensoredProposal(
 	authorUser *database.User,
 	adminUser *database.User,
 ) error {
-	if b.cfg.SMTP == nil {
+	if b.cfg.Mailer == nil {
 		return nil
 	}
 
@@ -191,13 +194,8 @@ func (b *backend) emailAuthorForCensoredProposal(
 		StatusChangeReason: proposal.StatusChangeMessage,
 	}
 
-	subject := "Your Proposal Has Been Censored"
-	body, err := createBody(templateProposalCensoredForAuthor, &tplData)
-	if err != nil {
-		return err
-	}
-
-	return b.sendEmailTo(subject, body, authorUser.Email)
+	return b.sendEmailTo(templateProposalCensoredForAuthor, &tplData,
+		authorUser.Locale, authorUser.Email)
 }
 
 // emailUsersForVettedProposal sends an email notification for a new
@@ -207,7 +205,7 @@ func (b *backend) emailUsersForVettedProposal(
 	authorUser *database.User,
 	adminUser *database.User,
 ) error {
-	if b.cfg.SMTP == nil {
+	if b.cfg.Mailer == nil {
 		return nil
 	}
 
@@ -225,26 +223,36 @@ func (b *backend) emailUsersForVettedProposal(
 	}
 
 	// Send email to users.
-	subject := "New Proposal Published"
-	body, err := createBody(templateProposalVetted, &tplData)
-	if err != nil {
-		return err
-	}
-
-	return b.sendEmail(subject, body, func(msg *goemail.Message) error {
-		// Add user emails to the goemail.Message
+	n := v1.NotificationEmailRegularProposalVetted
+	digestUsers := make([]database.User, 0)
+	err = b.sendEmail(templateProposalVetted, &tplData, func(addFn func(*database.User)) error {
+		// Add the users to notify
 		return b.db.AllUsers(func(user *database.User) {
 			// Don't notify the user under certain conditions.
 			if user.NewUserPaywallTx == "" || user.Deactivated ||
 				user.ID == adminUser.ID || user.ID == authorUser.ID ||
-				(user.EmailNotifications&
-					uint64(v1.NotificationEmailRegularProposalVetted)) == 0 {
+				(user.EmailNotifications&uint64(n)) == 0 {
+				return
+			}
+
+			// Users that receive digests are notified later.
+			if emailDigestPeriod(user, n) != 0 {
+				digestUsers = append(digestUsers, *user)
 				return
 			}
 
-			msg.AddBCC(user.Email)
+			addFn(user)
 		})
 	})
+	if err != nil {
+		return err
+	}
+
+	return b.queueDigestEvents(digestUsers, n, database.DigestEvent{
+		ProposalName: proposal.Name,
+		Username:     authorUser.Username,
+		Link:         tplData.Link,
+	})
 }
 
 // emailUsersForEditedProposal sends an email notification for a proposal
@@ -253,7 +261,7 @@ func (b *backend) emailUsersForEditedProposal(
 	proposal *v1.ProposalRecord,
 	authorUser *database.User,
 ) error {
-	if b.cfg.SMTP == nil {
+	if b.cfg.Mailer == nil {
 		return nil
 	}
 
@@ -272,26 +280,36 @@ func (b *backend) emailUsersForEditedProposal(
 	}
 
 	// Send email to users.
-	subject := "Proposal Edited"
-	body, err := createBody(templateProposalEdited, &tplData)
-	if err != nil {
-		return err
-	}
-
-	return b.sendEmail(subject, body, func(msg *goemail.Message) error {
-		// Add user emails to the goemail.Message
+	n := v1.NotificationEmailRegularProposalEdited
+	digestUsers := make([]database.User, 0)
+	err = b.sendEmail(templateProposalEdited, &tplData, func(addFn func(*database.User)) error {
+		// Add the users to notify
 		return b.db.AllUsers(func(user *database.User) {
 			// Don't notify the user under certain conditions.
 			if user.NewUserPaywallTx == "" || user.Deactivated ||
 				user.ID == authorUser.ID ||
-				(user.EmailNotifications&
-					uint64(v1.NotificationEmailRegularProposalEdited)) == 0 {
+				(user.EmailNotifications&uint64(n)) == 0 {
+				return
+			}
+
+			// Users that receive digests are notified later.
+			if emailDigestPeriod(user, n) != 0 {
+				digestUsers = append(digestUsers, *user)
 				return
 			}
 
-			msg.AddBCC(user.Email)
+			addFn(user)
 		})
 	})
+	if err != nil {
+		return err
+	}
+
+	return b.queueDigestEvents(digestUsers, n, database.DigestEvent{
+		ProposalName: proposal.Name,
+		Username:     authorUser.Username,
+		Link:         tplData.Link,
+	})
 }
 
 // emailUsersForProposalVoteStarted sends an email notification for a proposal
@@ -301,7 +319,7 @@ func (b *backend) emailUsersForProposalVoteStarted(
 	authorUser *database.User,
 	adminUser *database.User,
 ) error {
-	if b.cfg.SMTP == nil {
+	if b.cfg.Mailer == nil {
 		return nil
 	}
 
@@ -318,47 +336,62 @@ func (b *backend) emailUsersForProposalVoteStarted(
 		Username: authorUser.Username,
 	}
 
+	digestEvent := database.DigestEvent{
+		ProposalName: proposal.Name,
+		Username:     authorUser.Username,
+		Link:         tplData.Link,
+	}
+
 	// Send email to author.
 	if authorUser.EmailNotifications&
 		uint64(v1.NotificationEmailMyProposalVoteStarted) != 0 {
 
-		subject := "Your Proposal Has Started Voting"
-		body, err := createBody(templateProposalVoteStartedForAuthor, &tplData)
+		queued, err := b.queueDigestEvent(authorUser,
+			v1.NotificationEmailMyProposalVoteStarted, digestEvent)
 		if err != nil {
 			return err
 		}
 
-		err = b.sendEmailTo(subject, body, authorUser.Email)
-		if err != nil {
-			return err
+		if !queued {
+			err = b.sendEmailTo(templateProposalVoteStartedForAuthor,
+				&tplData, authorUser.Locale, authorUser.Email)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
-	subject := "Voting Started for Proposal"
-	body, err := createBody(templateProposalVoteStarted, &tplData)
-	if err != nil {
-		return err
-	}
-
-	return b.sendEmail(subject, body, func(msg *goemail.Message) error {
-		// Add user emails to the goemail.Message
+	n := v1.NotificationEmailRegularProposalVoteStarted
+	digestUsers := make([]database.User, 0)
+	err = b.sendEmail(templateProposalVoteStarted, &tplData, func(addFn func(*database.User)) error {
+		// Add the users to notify
 		return b.db.AllUsers(func(user *database.User) {
 			// Don't notify the user under certain conditions.
 			if user.NewUserPaywallTx == "" || user.Deactivated ||
 				user.ID == adminUser.ID ||
 				user.ID == authorUser.ID ||
-				(user.EmailNotifications&
-					uint64(v1.NotificationEmailRegularProposalVoteStarted)) == 0 {
+				(user.EmailNotifications&uint64(n)) == 0 {
 				return
 			}
 
-			msg.AddBCC(user.Email)
+			// Users that receive digests are notified later.
+			if emailDigestPeriod(user, n) != 0 {
+				digestUsers = append(digestUsers, *user)
+				return
+			}
+
+			addFn(user)
 		})
 	})
+	if err != nil {
+		return err
+	}
+
+	return b.queueDigestEvents(digestUsers, n, digestEvent)
 }
 
 func (b *backend) emailAdminsForNewSubmittedProposal(token string, propName string, username string, userEmail string) error {
-	if b.cfg.SMTP == nil {
+	if b.cfg.Mailer == nil {
 		return nil
 	}
 
@@ -374,21 +407,16 @@ func (b *backend) emailAdminsForNewSubmittedProposal(token string, propName stri
 		Email:    userEmail,
 	}
 
-	subject := "New Proposal Submitted"
-	body, err := createBody(templateNewProposalSubmitted, &tplData)
-	if err != nil {
-		return err
-	}
-
-	return b.sendEmail(subject, body, func(msg *goemail.Message) error {
-		// Add admin emails to the goemail.Message
+	return b.sendEmail(templateNewProposalSubmitted, &tplData, func(addFn func(*database.User)) error {
+		// Add the reviewers to notify
 		return b.db.AllUsers(func(user *database.User) {
-			if !user.Admin || user.Deactivated ||
+			if !userHasRole(user, v1.UserRoleReviewer) ||
+				user.Deactivated ||
 				(user.EmailNotifications&
 					uint64(v1.NotificationEmailAdminProposalNew) == 0) {
 				return
 			}
-			msg.AddBCC(user.Email)
+			addFn(user)
 		})
 	})
 }
@@ -397,7 +425,7 @@ func (b *backend) emailAdminsForProposalVoteAuthorized(
 	proposal *v1.ProposalRecord,
 	authorUser *database.User,
 ) error {
-	if b.cfg.SMTP == nil {
+	if b.cfg.Mailer == nil {
 		return nil
 	}
 
@@ -413,21 +441,16 @@ func (b *backend) emailAdminsForProposalVoteAuthorized(
 		Email:    authorUser.Email,
 	}
 
-	subject := "Proposal Authorized To Start Voting"
-	body, err := createBody(templateProposalVoteAuthorized, &tplData)
-	if err != nil {
-		return err
-	}
-
-	return b.sendEmail(subject, body, func(msg *goemail.Message) error {
-		// Add admin emails to the goemail.Message
+	return b.sendEmail(templateProposalVoteAuthorized, &tplData, func(addFn func(*database.User)) error {
+		// Add the vote administrators to notify
 		return b.db.AllUsers(func(user *database.User) {
-			if !user.Admin || user.Deactivated ||
+			if !userHasRole(user, v1.UserRoleVoteAdmin) ||
+				user.Deactivated ||
 				(user.EmailNotifications&
 					uint64(v1.NotificationEmailAdminProposalVoteAuthorized) == 0) {
 				return
 			}
-			msg.AddBCC(user.Email)
+			addFn(user)
 		})
 	})
 }
@@ -439,7 +462,7 @@ func (b *backend) emailAuthorForCommentOnProposal(
 	authorUser *database.User,
 	commentID, username string,
 ) error {
-	if b.cfg.SMTP == nil {
+	if b.cfg.Mailer == nil {
 		return nil
 	}
 
@@ -459,19 +482,24 @@ func (b *backend) emailAuthorForCommentOnProposal(
 		return nil
 	}
 
+	queued, err := b.queueDigestEvent(authorUser,
+		v1.NotificationEmailCommentOnMyProposal, database.DigestEvent{
+			ProposalName: proposal.Name,
+			Username:     username,
+			Link:         l.String(),
+		})
+	if err != nil || queued {
+		return err
+	}
+
 	tplData := commentReplyOnProposalTemplateData{
 		Commenter:    username,
 		ProposalName: proposal.Name,
 		CommentLink:  l.String(),
 	}
 
-	subject := "New Comment On Your Proposal"
-	body, err := createBody(templateCommentReplyOnProposal, &tplData)
-	if err != nil {
-		return err
-	}
-
-	return b.sendEmailTo(subject, body, authorUser.Email)
+	return b.sendEmailTo(templateCommentReplyOnProposal, &tplData,
+		authorUser.Locale, authorUser.Email)
 }
 
 // emailAuthorForCommentOnComment sends an email notification to a comment
@@ -481,7 +509,7 @@ func (b *backend) emailAuthorForCommentOnComment(
 	authorUser *database.User,
 	commentID, username string,
 ) error {
-	if b.cfg.SMTP == nil {
+	if b.cfg.Mailer == nil {
 		return nil
 	}
 
@@ -501,25 +529,30 @@ func (b *backend) emailAuthorForCommentOnComment(
 		return nil
 	}
 
+	queued, err := b.queueDigestEvent(authorUser,
+		v1.NotificationEmailCommentOnMyComment, database.DigestEvent{
+			ProposalName: proposal.Name,
+			Username:     username,
+			Link:         l.String(),
+		})
+	if err != nil || queued {
+		return err
+	}
+
 	tplData := commentReplyOnCommentTemplateData{
 		Commenter:    username,
 		ProposalName: proposal.Name,
 		CommentLink:  l.String(),
 	}
 
-	subject := "New Comment On Your Comment"
-	body, err := createBody(templateCommentReplyOnComment, &tplData)
-	if err != nil {
-		return err
-	}
-
-	return b.sendEmailTo(subject, body, authorUser.Email)
+	return b.sendEmailTo(templateCommentReplyOnComment, &tplData,
+		authorUser.Locale, authorUser.Email)
 }
 
 // emailUpdateUserKeyVerificationLink emails the link with the verification
 // token used for setting a new key pair if the email server is set up.
 func (b *backend) emailUpdateUserKeyVerificationLink(email, publicKey, token string) error {
-	if b.cfg.SMTP == nil {
+	if b.cfg.Mailer == nil {
 		return nil
 	}
 
@@ -535,20 +568,15 @@ func (b *backend) emailUpdateUserKeyVerificationLink(email, publicKey, token str
 		Link:      link,
 	}
 
-	subject := "Verify Your New Identity"
-	body, err := createBody(templateUpdateUserKeyEmail, &tplData)
-	if err != nil {
-		return err
-	}
-
-	return b.sendEmailTo(subject, body, email)
+	return b.sendEmailTo(templateUpdateUserKeyEmail, &tplData,
+		b.userLocale(email), email)
 }
 
 // emailUserLocked notifies the user its account has been locked and emails the
 // link with the reset password verification token if the email server is set
 // up.
 func (b *backend) emailUserLocked(email string) error {
-	if b.cfg.SMTP == nil {
+	if b.cfg.Mailer == nil {
 		return nil
 	}
 
@@ -563,11 +591,6 @@ func (b *backend) emailUserLocked(email string) error {
 		Link:  link,
 	}
 
-	subject := "Locked Account - Reset Your Password"
-	body, err := createBody(templateUserLockedResetPassword, &tplData)
-	if err != nil {
-		return err
-	}
-
-	return b.sendEmailTo(subject, body, email)
+	return b.sendEmailTo(templateUserLockedResetPassword, &tplData,
+		b.userLocale(email), email)
 }
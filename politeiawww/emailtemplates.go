@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+const (
+	// defaultLocale is the locale of the built-in email templates.  It is
+	// used for users that have not set a locale.
+	defaultLocale = "en"
+
+	// emailSubjectTemplate is the name of the template that overrides the
+	// default subject of an email when it is defined in a customized
+	// template.
+	emailSubjectTemplate = "subject"
+
+	emailTemplateTextExt = ".txt"
+	emailTemplateHTMLExt = ".html"
+)
+
+// emailTemplate is a built-in email template.  Its subject and bodies can be
+// customized and localized by placing template files in the template
+// directory, see loadEmailTemplates.
+type emailTemplate struct {
+	name    string             // Name of the template files
+	subject string             // Default subject
+	text    *template.Template // Default plain text body
+	sample  interface{}        // Template data used to render previews
+}
+
+// newEmailTemplate returns an email template with the provided defaults.  It
+// panics if the default body can not be parsed.
+func newEmailTemplate(name, subject, text string, sample interface{}) *emailTemplate {
+	return &emailTemplate{
+		name:    name,
+		subject: subject,
+		text:    template.Must(template.New(name).Parse(text)),
+		sample:  sample,
+	}
+}
+
+// renderedEmail is an email template that has been executed for a locale.
+type renderedEmail struct {
+	Subject string
+	Text    string
+	HTML    string // Optional HTML body
+}
+
+// emailTemplates contains the customized email templates that were loaded
+// from the template directory.
+type emailTemplates struct {
+	text map[string]map[string]*template.Template     // [locale][name]
+	html map[string]map[string]*htmltemplate.Template // [locale][name]
+}
+
+// loadEmailTemplates loads the customized email templates from the provided
+// directory.  Every subdirectory contains the templates of a locale, e.g.
+// "en" or "pt-BR".  A template consists of a plain text body named
+// <template>.txt and an optional HTML body named <template>.html.  The plain
+// text body may define a "subject" template that overrides the default
+// subject.  Templates that are not customized fall back to the built-in
+// English templates.
+func loadEmailTemplates(dir string) (*emailTemplates, error) {
+	t := emailTemplates{
+		text: make(map[string]map[string]*template.Template),
+		html: make(map[string]map[string]*htmltemplate.Template),
+	}
+
+	locales, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range locales {
+		if !l.IsDir() {
+			continue
+		}
+		locale := l.Name()
+		t.text[locale] = make(map[string]*template.Template)
+		t.html[locale] = make(map[string]*htmltemplate.Template)
+
+		for _, et := range emailTemplateList {
+			fn := filepath.Join(dir, locale, et.name+emailTemplateTextExt)
+			text, err := ioutil.ReadFile(fn)
+			if err == nil {
+				tpl, err := template.New(et.name).Parse(string(text))
+				if err != nil {
+					return nil, fmt.Errorf("%v: %v", fn, err)
+				}
+				t.text[locale][et.name] = tpl
+			} else if !os.IsNotExist(err) {
+				return nil, err
+			}
+
+			fn = filepath.Join(dir, locale, et.name+emailTemplateHTMLExt)
+			html, err := ioutil.ReadFile(fn)
+			if err == nil {
+				tpl, err := htmltemplate.New(et.name).Parse(string(html))
+				if err != nil {
+					return nil, fmt.Errorf("%v: %v", fn, err)
+				}
+				t.html[locale][et.name] = tpl
+			} else if !os.IsNotExist(err) {
+				return nil, err
+			}
+		}
+	}
+
+	return &t, nil
+}
+
+// locales returns the sorted locales for which templates are available.
+func (t *emailTemplates) locales() []string {
+	locales := []string{defaultLocale}
+	if t != nil {
+		for l := range t.text {
+			if l != defaultLocale {
+				locales = append(locales, l)
+			}
+		}
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// hasLocale returns whether templates are available for the provided locale
+// or, for a regional locale, for its language.
+func (t *emailTemplates) hasLocale(locale string) bool {
+	fallbacks := localeFallbacks(locale)
+	for _, l := range fallbacks[:len(fallbacks)-1] {
+		if l == defaultLocale {
+			return true
+		}
+		if t == nil {
+			continue
+		}
+		if _, ok := t.text[l]; ok {
+			return true
+		}
+	}
+	return locale == defaultLocale
+}
+
+// localeFallbacks returns the locales that are searched for a template, most
+// specific first.  A regional locale such as "pt-BR" falls back to its
+// language "pt" and all locales fall back to the default locale.
+func localeFallbacks(locale string) []string {
+	fallbacks := make([]string, 0, 3)
+	if locale != "" {
+		fallbacks = append(fallbacks, locale)
+		if i := strings.IndexAny(locale, "-_"); i > 0 {
+			fallbacks = append(fallbacks, locale[:i])
+		}
+	}
+	return append(fallbacks, defaultLocale)
+}
+
+// lookup returns the most specific customized templates for the provided
+// locale.  The returned templates are nil if the template has not been
+// customized.
+func (t *emailTemplates) lookup(et *emailTemplate, locale string) (*template.Template, *htmltemplate.Template) {
+	if t == nil {
+		return nil, nil
+	}
+
+	var (
+		text *template.Template
+		html *htmltemplate.Template
+	)
+	for _, l := range localeFallbacks(locale) {
+		if text == nil {
+			text = t.text[l][et.name]
+		}
+		if html == nil {
+			html = t.html[l][et.name]
+		}
+	}
+	return text, html
+}
+
+// render executes an email template for the provided locale.
+func (t *emailTemplates) render(et *emailTemplate, locale string, data interface{}) (*renderedEmail, error) {
+	text, html := t.lookup(et, locale)
+	if text == nil {
+		text = et.text
+	}
+
+	var (
+		e   renderedEmail
+		buf bytes.Buffer
+	)
+	err := text.Execute(&buf, data)
+	if err != nil {
+		return nil, err
+	}
+	e.Text = buf.String()
+
+	subject := text.Lookup(emailSubjectTemplate)
+	if subject != nil {
+		buf.Reset()
+		err := subject.Execute(&buf, data)
+		if err != nil {
+			return nil, err
+		}
+		e.Subject = strings.TrimSpace(buf.String())
+	}
+
+	if html != nil {
+		buf.Reset()
+		err := html.Execute(&buf, data)
+		if err != nil {
+			return nil, err
+		}
+		e.HTML = buf.String()
+	}
+
+	if e.Subject == "" {
+		e.Subject = et.subject
+	}
+
+	return &e, nil
+}
+
+// ProcessEmailTemplates renders a preview of every email template with sample
+// data in the requested locale.
+func (b *backend) ProcessEmailTemplates(et v1.EmailTemplates) (*v1.EmailTemplatesReply, error) {
+	locale := et.Locale
+	if locale == "" {
+		locale = defaultLocale
+	}
+	if !b.templates.hasLocale(locale) {
+		return nil, v1.UserError{
+			ErrorCode:    v1.ErrorStatusInvalidInput,
+			ErrorContext: []string{"unsupported locale"},
+		}
+	}
+
+	reply := v1.EmailTemplatesReply{
+		Locale:    locale,
+		Locales:   b.templates.locales(),
+		Templates: make([]v1.EmailTemplatePreview, 0, len(emailTemplateList)),
+	}
+	for _, tpl := range emailTemplateList {
+		e, err := b.templates.render(tpl, locale, tpl.sample)
+		if err != nil {
+			return nil, fmt.Errorf("render %v: %v", tpl.name, err)
+		}
+		reply.Templates = append(reply.Templates, v1.EmailTemplatePreview{
+			Name:    tpl.name,
+			Subject: e.Subject,
+			Text:    e.Text,
+			HTML:    e.HTML,
+		})
+	}
+
+	return &reply, nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/politeiawww/database"
+)
+
+// Tests that customized templates are loaded per locale, fall back to less
+// specific locales and are sent as multipart emails.
+func TestEmailTemplates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "politeiawww.templates")
+	assertSuccess(t, err)
+	defer os.RemoveAll(dir)
+
+	err = os.MkdirAll(filepath.Join(dir, "pt"), 0700)
+	assertSuccess(t, err)
+	err = ioutil.WriteFile(filepath.Join(dir, "pt", "proposal_vetted.txt"),
+		[]byte(`{{define "subject"}}Nova proposta: {{.Name}}{{end}}`+
+			`Nova proposta de {{.Username}}: {{.Name}}`), 0600)
+	assertSuccess(t, err)
+	err = ioutil.WriteFile(filepath.Join(dir, "pt", "proposal_vetted.html"),
+		[]byte(`<p>Nova proposta de <b>{{.Username}}</b>: {{.Name}}</p>`),
+		0600)
+	assertSuccess(t, err)
+
+	b := createBackend(t)
+	defer b.db.Close()
+	b.templates, err = loadEmailTemplates(dir)
+	assertSuccess(t, err)
+
+	tplData := proposalStatusChangeTemplateData{
+		Name:     "<Ação>",
+		Username: "user",
+	}
+	e, err := b.templates.render(templateProposalVetted, "pt-BR", &tplData)
+	assertSuccess(t, err)
+	if e.Subject != "Nova proposta: <Ação>" ||
+		e.Text != "Nova proposta de user: <Ação>" ||
+		e.HTML != "<p>Nova proposta de <b>user</b>: &lt;Ação&gt;</p>" {
+		t.Fatalf("unexpected localized email %v", e)
+	}
+
+	// Templates that have not been customized use the built-in ones.
+	e, err = b.templates.render(templateProposalVetted, "de", &tplData)
+	assertSuccess(t, err)
+	if e.Subject != templateProposalVetted.subject || e.HTML != "" {
+		t.Fatalf("unexpected default email %v", e)
+	}
+
+	// Emails with an HTML body include both bodies.
+	e, err = b.templates.render(templateProposalVetted, "pt", &tplData)
+	assertSuccess(t, err)
+	payload, err := createEmailMessage(database.Email{
+		Subject:  e.Subject,
+		Body:     e.Text,
+		HTMLBody: e.HTML,
+		To:       []string{"user@example.com"},
+	})
+	assertSuccess(t, err)
+	msg, err := mail.ReadMessage(bytes.NewReader(payload))
+	assertSuccess(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(
+		msg.Header.Get("Subject"))
+	assertSuccess(t, err)
+	if subject != e.Subject {
+		t.Fatalf("unexpected subject %v", subject)
+	}
+	mediaType, params, err := mime.ParseMediaType(
+		msg.Header.Get("Content-Type"))
+	assertSuccess(t, err)
+	if mediaType != "multipart/alternative" {
+		t.Fatalf("unexpected content type %v", mediaType)
+	}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for _, want := range []string{e.Text, e.HTML} {
+		part, err := mr.NextPart()
+		assertSuccess(t, err)
+		body, err := ioutil.ReadAll(part)
+		assertSuccess(t, err)
+		if string(body) != want {
+			t.Fatalf("unexpected part %q, want %q", body, want)
+		}
+	}
+
+	// Users can only select locales that are available.
+	nu, _, err := createNewUser(b)
+	assertSuccess(t, err)
+	user, err := b.db.UserGet(nu.Email)
+	assertSuccess(t, err)
+	locale := "de"
+	_, err = b.ProcessEditUser(&www.EditUser{Locale: &locale}, user)
+	assertErrorWithContext(t, err, www.ErrorStatusInvalidInput,
+		[]string{"unsupported locale"})
+	locale = "de-DE"
+	_, err = b.ProcessEditUser(&www.EditUser{Locale: &locale}, user)
+	assertErrorWithContext(t, err, www.ErrorStatusInvalidInput,
+		[]string{"unsupported locale"})
+	for _, v := range []string{"en-US", "pt-BR", "pt"} {
+		locale = v
+		_, err = b.ProcessEditUser(&www.EditUser{Locale: &locale}, user)
+		assertSuccess(t, err)
+	}
+
+	// Every template can be previewed.
+	etr, err := b.ProcessEmailTemplates(www.EmailTemplates{Locale: "pt"})
+	assertSuccess(t, err)
+	if len(etr.Templates) != len(emailTemplateList) ||
+		strings.Join(etr.Locales, ",") != "en,pt" {
+		t.Fatalf("unexpected previews %v", etr)
+	}
+	for _, v := range etr.Templates {
+		if v.Subject == "" || v.Text == "" {
+			t.Fatalf("empty preview %v", v)
+		}
+	}
+	_, err = b.ProcessEmailTemplates(www.EmailTemplates{Locale: "de"})
+	assertErrorWithContext(t, err, www.ErrorStatusInvalidInput,
+		[]string{"unsupported locale"})
+}
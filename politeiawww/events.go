@@ -27,6 +27,7 @@ const (
 	EventTypeProposalVoteFinished
 	EventTypeComment
 	EventTypeUserManage
+	EventTypeBallotCast
 )
 
 type EventDataProposalSubmitted struct {
@@ -59,6 +60,11 @@ type EventDataComment struct {
 	Comment *v1.Comment
 }
 
+// EventDataBallotCast contains the votes of a ballot that were accepted.
+type EventDataBallotCast struct {
+	Votes []v1.CastVote
+}
+
 type EventDataUserManage struct {
 	AdminUser  *database.User
 	User       *database.User
@@ -114,6 +120,18 @@ func (b *backend) getProposalAndAuthor(token string) (*v1.ProposalRecord, *datab
 	return &proposal, author, nil
 }
 
+// registerListener adds a listener channel for the given event types.
+//
+// This function must be called WITHOUT the mutex held.
+func (b *backend) registerListener(ch chan interface{}, eventTypes ...EventT) {
+	b.Lock()
+	defer b.Unlock()
+
+	for _, eventType := range eventTypes {
+		b.eventManager._register(eventType, ch)
+	}
+}
+
 // fireEvent is a convenience wrapper for EventManager._fireEvent which
 // holds the lock.
 //
@@ -134,8 +152,9 @@ func (b *backend) initEventManager() {
 	b._setupProposalStatusChangeLogging()
 	b._setupProposalVoteStartedLogging()
 	b._setupUserManageLogging()
+	b._setupWebhookNotifications()
 
-	if b.cfg.SMTP == nil {
+	if b.cfg.Mailer == nil {
 		return
 	}
 
@@ -427,7 +446,8 @@ func (b *backend) _setupUserManageLogging() {
 
 			// Log the action in the admin log.
 			err := b.logAdminUserAction(ue.AdminUser, ue.User,
-				ue.ManageUser.Action, ue.ManageUser.Reason)
+				ue.ManageUser.Action, ue.ManageUser.Role,
+				ue.ManageUser.Reason)
 			if err != nil {
 				log.Errorf("could not log action to file: %v", err)
 			}
@@ -436,6 +456,28 @@ func (b *backend) _setupUserManageLogging() {
 	b.eventManager._register(EventTypeUserManage, ch)
 }
 
+func (b *backend) _setupWebhookNotifications() {
+	ch := make(chan interface{})
+	go func() {
+		for data := range ch {
+			err := b.queueWebhookEvent(data)
+			if err != nil {
+				log.Errorf("queue webhook event: %v", err)
+			}
+		}
+	}()
+	for _, eventType := range []EventT{
+		EventTypeProposalSubmitted,
+		EventTypeProposalStatusChange,
+		EventTypeProposalEdited,
+		EventTypeProposalVoteAuthorized,
+		EventTypeProposalVoteStarted,
+		EventTypeComment,
+	} {
+		b.eventManager._register(eventType, ch)
+	}
+}
+
 // _register adds a listener channel for the given event type.
 //
 // This function must be called WITH the mutex held.
@@ -0,0 +1,642 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/decred/politeia/decredplugin"
+	pd "github.com/decred/politeia/politeiad/api/v1"
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/util"
+)
+
+const (
+	// feedAuthor is the author of the feeds.
+	feedAuthor = "Politeia"
+
+	// feedMaxAge is the number of seconds feed readers may cache a feed.
+	feedMaxAge = 60
+)
+
+// feed is a format independent Atom or RSS feed.
+type feed struct {
+	id      string      // Unique feed id
+	title   string      // Feed title
+	link    string      // Web page of the feed, may be empty
+	updated time.Time   // Last update of the feed entries
+	entries []feedEntry // Feed entries, newest first
+}
+
+// feedEntry is an entry of a feed.
+type feedEntry struct {
+	id        string    // Unique entry id
+	title     string    // Entry title
+	link      string    // Web page of the entry, may be empty
+	author    string    // Author username, may be empty
+	content   string    // Plain text content
+	published time.Time // Publication time
+	updated   time.Time // Last update
+}
+
+// atomFeed is the XML encoding of an Atom feed.
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  atomPerson  `xml:"author"`
+	Link    *atomLink   `xml:"link,omitempty"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Updated   string      `xml:"updated"`
+	Published string      `xml:"published"`
+	Author    *atomPerson `xml:"author,omitempty"`
+	Link      *atomLink   `xml:"link,omitempty"`
+	Content   atomText    `xml:"content"`
+}
+
+// rssFeed is the XML encoding of an RSS 2.0 feed.
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link,omitempty"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+	Description string  `xml:"description"`
+}
+
+// newFeed returns a feed of the newest FeedPageSize entries.
+func newFeed(id, title, link string, entries []feedEntry) *feed {
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].published.Equal(entries[j].published) {
+			return entries[i].published.After(entries[j].published)
+		}
+		return entries[i].id < entries[j].id
+	})
+	if len(entries) > www.FeedPageSize {
+		entries = entries[:www.FeedPageSize]
+	}
+
+	f := feed{
+		id:      id,
+		title:   title,
+		link:    link,
+		updated: time.Unix(0, 0),
+		entries: entries,
+	}
+	for _, v := range entries {
+		if v.updated.After(f.updated) {
+			f.updated = v.updated
+		}
+	}
+
+	return &f
+}
+
+// validateFeedFormat returns an error if the provided feed format is not
+// supported.
+func validateFeedFormat(format string) error {
+	switch format {
+	case "", www.FeedFormatAtom, www.FeedFormatRSS:
+		return nil
+	}
+	return www.UserError{
+		ErrorCode:    www.ErrorStatusInvalidInput,
+		ErrorContext: []string{"invalid format"},
+	}
+}
+
+// writeFeed writes a feed as Atom or, if the format is FeedFormatRSS, as
+// RSS.
+func writeFeed(w io.Writer, f *feed, format string) error {
+	var v interface{}
+	switch format {
+	case www.FeedFormatRSS:
+		rf := rssFeed{
+			Version: "2.0",
+			Channel: rssChannel{
+				Title:         f.title,
+				Link:          f.link,
+				Description:   f.title,
+				LastBuildDate: f.updated.UTC().Format(time.RFC1123Z),
+				Items:         make([]rssItem, 0, len(f.entries)),
+			},
+		}
+		for _, e := range f.entries {
+			rf.Channel.Items = append(rf.Channel.Items, rssItem{
+				Title: e.title,
+				Link:  e.link,
+				GUID: rssGUID{
+					Value: e.id,
+				},
+				PubDate:     e.published.UTC().Format(time.RFC1123Z),
+				Description: e.content,
+			})
+		}
+		v = rf
+	default:
+		af := atomFeed{
+			ID:      f.id,
+			Title:   f.title,
+			Updated: f.updated.UTC().Format(time.RFC3339),
+			Author: atomPerson{
+				Name: feedAuthor,
+			},
+			Entries: make([]atomEntry, 0, len(f.entries)),
+		}
+		if f.link != "" {
+			af.Link = &atomLink{Href: f.link}
+		}
+		for _, e := range f.entries {
+			ae := atomEntry{
+				ID:        e.id,
+				Title:     e.title,
+				Updated:   e.updated.UTC().Format(time.RFC3339),
+				Published: e.published.UTC().Format(time.RFC3339),
+				Content: atomText{
+					Type: "text",
+					Body: e.content,
+				},
+			}
+			if e.author != "" {
+				ae.Author = &atomPerson{Name: e.author}
+			}
+			if e.link != "" {
+				ae.Link = &atomLink{Href: e.link}
+			}
+			af.Entries = append(af.Entries, ae)
+		}
+		v = af
+	}
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	return e.Encode(v)
+}
+
+// respondWithFeed writes a feed in the provided format.  The ETag and
+// Last-Modified headers let feed readers skip fetching feeds that did not
+// change.
+func respondWithFeed(w http.ResponseWriter, r *http.Request, f *feed, format string) {
+	var buf bytes.Buffer
+	err := writeFeed(&buf, f, format)
+	if err != nil {
+		RespondWithError(w, r, 0, "respondWithFeed: writeFeed %v", err)
+		return
+	}
+
+	contentType := "application/atom+xml; charset=utf-8"
+	if format == www.FeedFormatRSS {
+		contentType = "application/rss+xml; charset=utf-8"
+	}
+	digest := sha256.Sum256(buf.Bytes())
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control",
+		fmt.Sprintf("public, max-age=%v", feedMaxAge))
+	w.Header().Set("ETag", `"`+hex.EncodeToString(digest[:16])+`"`)
+	http.ServeContent(w, r, "", f.updated, bytes.NewReader(buf.Bytes()))
+}
+
+// feedLink returns the link to a page of the politeia web server.  The link
+// is empty when the web server address is not configured.
+func (b *backend) feedLink(path string) string {
+	if b.cfg.WebServerAddress == "" {
+		return ""
+	}
+	return b.cfg.WebServerAddress + path
+}
+
+// getPluginBlockTimes fetches the timestamps of the blocks at the provided
+// heights from the decred plugin.
+//
+// This function must be called WITHOUT the mutex held.
+func (b *backend) getPluginBlockTimes(heights []uint32) ([]int64, error) {
+	challenge, err := util.Random(pd.ChallengeSize)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := decredplugin.EncodeBlockTimes(
+		decredplugin.BlockTimes{
+			Heights: heights,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	pc := pd.PluginCommand{
+		Challenge: hex.EncodeToString(challenge),
+		ID:        decredplugin.ID,
+		Command:   decredplugin.CmdBlockTimes,
+		CommandID: decredplugin.CmdBlockTimes,
+		Payload:   string(payload),
+	}
+
+	responseBody, err := b.makeRequest(http.MethodPost,
+		pd.PluginCommandRoute, pc)
+	if err != nil {
+		return nil, err
+	}
+
+	var reply pd.PluginCommandReply
+	err = json.Unmarshal(responseBody, &reply)
+	if err != nil {
+		return nil, fmt.Errorf("Could not unmarshal "+
+			"PluginCommandReply: %v", err)
+	}
+
+	// Verify the challenge.
+	err = util.VerifyChallenge(b.cfg.Identity, challenge, reply.Response)
+	if err != nil {
+		return nil, err
+	}
+
+	btr, err := decredplugin.DecodeBlockTimesReply([]byte(reply.Payload))
+	if err != nil {
+		return nil, err
+	}
+	if len(btr.Timestamps) != len(heights) {
+		return nil, fmt.Errorf("unexpected number of block times: "+
+			"got %v wanted %v", len(btr.Timestamps), len(heights))
+	}
+
+	return btr.Timestamps, nil
+}
+
+// getBlockTimes returns the timestamps of the blocks at the provided heights.
+// Mined blocks never change so the timestamps are cached and only the
+// unknown ones are fetched from politeiad.
+//
+// This function must be called WITHOUT the tally lock held.
+func (b *backend) getBlockTimes(heights []uint64) (map[uint64]time.Time, error) {
+	times := make(map[uint64]time.Time, len(heights))
+	missing := make([]uint32, 0, len(heights))
+	requested := make(map[uint64]struct{}, len(heights))
+	b.tallyMtx.Lock()
+	for _, v := range heights {
+		t, ok := b.blockTimes[v]
+		if ok {
+			times[v] = t
+			continue
+		}
+		if _, ok := requested[v]; !ok {
+			requested[v] = struct{}{}
+			missing = append(missing, uint32(v))
+		}
+	}
+	b.tallyMtx.Unlock()
+
+	if len(missing) == 0 {
+		return times, nil
+	}
+	timestamps, err := b.getPluginBlockTimes(missing)
+	if err != nil {
+		return nil, err
+	}
+
+	b.tallyMtx.Lock()
+	defer b.tallyMtx.Unlock()
+	for k, v := range missing {
+		t := time.Unix(timestamps[k], 0)
+		b.blockTimes[uint64(v)] = t
+		times[uint64(v)] = t
+	}
+	return times, nil
+}
+
+// proposalFeedContent returns the text of the index file of a proposal.
+func proposalFeedContent(pr www.ProposalRecord) string {
+	for _, f := range pr.Files {
+		if f.Name != indexFile {
+			continue
+		}
+		payload, err := base64.StdEncoding.DecodeString(f.Payload)
+		if err != nil {
+			log.Errorf("proposalFeedContent: %v %v",
+				pr.CensorshipRecord.Token, err)
+			return ""
+		}
+		return string(payload)
+	}
+	return ""
+}
+
+// votingFeedContent returns the description of a proposal vote.
+func votingFeedContent(ir inventoryRecord) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Voting started at block %v and ends at block %v.\n\n",
+		ir.voting.StartBlockHeight, ir.voting.EndHeight)
+	sb.WriteString("Vote options:\n")
+	for _, o := range ir.votebits.Vote.Options {
+		fmt.Fprintf(&sb, "- %v: %v\n", o.Id, o.Description)
+	}
+	fmt.Fprintf(&sb, "\nA quorum of %v%% of the %v eligible votes is "+
+		"required and %v%% of the votes are required to pass.",
+		ir.votebits.Vote.QuorumPercentage,
+		len(ir.voting.EligibleTickets),
+		ir.votebits.Vote.PassPercentage)
+	return sb.String()
+}
+
+// voteResultsFeedContent returns the description of the results of a
+// finished proposal vote.
+func voteResultsFeedContent(ir inventoryRecord, results []www.VoteOptionResult, total uint64) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Voting ended at block %v.\n\n", ir.voting.EndHeight)
+	for _, v := range results {
+		var percentage float64
+		if total > 0 {
+			percentage = float64(v.VotesReceived) * 100 /
+				float64(total)
+		}
+		fmt.Fprintf(&sb, "- %v: %v votes (%.2f%%)\n", v.Option.Id,
+			v.VotesReceived, percentage)
+	}
+	eligible := len(ir.voting.EligibleTickets)
+	quorum := uint64(eligible) *
+		uint64(ir.votebits.Vote.QuorumPercentage) / 100
+	reached := "was reached"
+	if total < quorum {
+		reached = "was not reached"
+	}
+	fmt.Fprintf(&sb, "\n%v of the %v eligible votes were cast.  The quorum "+
+		"of %v votes %v.", total, eligible, quorum, reached)
+	return sb.String()
+}
+
+// getVotedRecords returns the public proposals whose vote started.
+//
+// This function must be called WITHOUT the mutex held.
+func (b *backend) getVotedRecords() []inventoryRecord {
+	b.RLock()
+	defer b.RUnlock()
+
+	records := make([]inventoryRecord, 0)
+	for _, v := range b.inventory {
+		if v.record.Status != pd.RecordStatusPublic ||
+			v.voting.StartBlockHeight == "" {
+			continue
+		}
+		records = append(records, *v)
+	}
+	return records
+}
+
+// ProcessProposalsFeed returns the feed of the newest published proposals.
+func (b *backend) ProcessProposalsFeed(f www.Feed) (*feed, error) {
+	log.Tracef("ProcessProposalsFeed")
+
+	err := validateFeedFormat(f.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	b.RLock()
+	proposals := b._getAllProposals()
+	b.RUnlock()
+
+	entries := make([]feedEntry, 0, len(proposals))
+	for _, v := range proposals {
+		if v.Status != www.PropStatusPublic {
+			continue
+		}
+		token := v.CensorshipRecord.Token
+		published := time.Unix(v.PublishedAt, 0)
+		updated := time.Unix(v.Timestamp, 0)
+		if updated.Before(published) {
+			updated = published
+		}
+		entries = append(entries, feedEntry{
+			id:        "urn:politeia:proposal:" + token,
+			title:     v.Name,
+			link:      b.feedLink("/proposals/" + token),
+			author:    v.Username,
+			content:   proposalFeedContent(v),
+			published: published,
+			updated:   updated,
+		})
+	}
+
+	return newFeed("urn:politeia:feed:proposals", "Politeia proposals",
+		b.feedLink("/"), entries), nil
+}
+
+// ProcessVotingFeed returns the feed of the proposals whose vote started
+// most recently.
+func (b *backend) ProcessVotingFeed(f www.Feed) (*feed, error) {
+	log.Tracef("ProcessVotingFeed")
+
+	err := validateFeedFormat(f.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	records := b.getVotedRecords()
+	heights := make([]uint64, 0, len(records))
+	for _, v := range records {
+		height, err := strconv.ParseUint(v.voting.StartBlockHeight, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		heights = append(heights, height)
+	}
+	times, err := b.getBlockTimes(heights)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]feedEntry, 0, len(records))
+	for k, v := range records {
+		token := v.record.CensorshipRecord.Token
+		started := times[heights[k]]
+		entries = append(entries, feedEntry{
+			id:        "urn:politeia:vote:" + token,
+			title:     "Voting started: " + v.proposalMD.Name,
+			link:      b.feedLink("/proposals/" + token),
+			content:   votingFeedContent(v),
+			published: started,
+			updated:   started,
+		})
+	}
+
+	return newFeed("urn:politeia:feed:voting", "Politeia proposal votes",
+		b.feedLink("/"), entries), nil
+}
+
+// ProcessVoteResultsFeed returns the feed of the results of the most recently
+// finished proposal votes.
+func (b *backend) ProcessVoteResultsFeed(f www.Feed) (*feed, error) {
+	log.Tracef("ProcessVoteResultsFeed")
+
+	err := validateFeedFormat(f.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	bestBlock, err := b.cachedBestBlock()
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the results of the votes that make it into the feed are
+	// tallied.
+	type finishedVote struct {
+		ir        inventoryRecord
+		endHeight uint64
+	}
+	finished := make([]finishedVote, 0)
+	for _, v := range b.getVotedRecords() {
+		if getVoteStatus(v, bestBlock) != www.PropVoteStatusFinished {
+			continue
+		}
+		endHeight, err := strconv.ParseUint(v.voting.EndHeight, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		finished = append(finished, finishedVote{
+			ir:        v,
+			endHeight: endHeight,
+		})
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].endHeight > finished[j].endHeight
+	})
+	if len(finished) > www.FeedPageSize {
+		finished = finished[:www.FeedPageSize]
+	}
+
+	heights := make([]uint64, 0, len(finished))
+	for _, v := range finished {
+		heights = append(heights, v.endHeight)
+	}
+	times, err := b.getBlockTimes(heights)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]feedEntry, 0, len(finished))
+	for _, v := range finished {
+		results, total, err := b.voteResults(v.ir, bestBlock)
+		if err != nil {
+			return nil, err
+		}
+		token := v.ir.record.CensorshipRecord.Token
+		ended := times[v.endHeight]
+		entries = append(entries, feedEntry{
+			id:        "urn:politeia:voteresults:" + token,
+			title:     "Vote finished: " + v.ir.proposalMD.Name,
+			link:      b.feedLink("/proposals/" + token),
+			content:   voteResultsFeedContent(v.ir, results, total),
+			published: ended,
+			updated:   ended,
+		})
+	}
+
+	return newFeed("urn:politeia:feed:results", "Politeia vote results",
+		b.feedLink("/"), entries), nil
+}
+
+// ProcessCommentsFeed returns the feed of the newest comments of a public
+// proposal.  Censored comments are left out.
+func (b *backend) ProcessCommentsFeed(token string, f www.Feed) (*feed, error) {
+	log.Tracef("ProcessCommentsFeed: %v", token)
+
+	err := validateFeedFormat(f.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	ir, err := b.getInventoryRecord(token)
+	if err != nil {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusProposalNotFound,
+		}
+	}
+	if ir.record.Status != pd.RecordStatusPublic {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusWrongStatus,
+		}
+	}
+
+	gcr, err := b.getComments(token)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]feedEntry, 0, len(gcr.Comments))
+	for _, v := range gcr.Comments {
+		if v.Censored {
+			continue
+		}
+		title := "Comment by " + v.Username
+		if v.ParentID != "" && v.ParentID != "0" {
+			title = "Reply by " + v.Username
+		}
+		timestamp := time.Unix(v.Timestamp, 0)
+		entries = append(entries, feedEntry{
+			id: fmt.Sprintf("urn:politeia:comment:%v:%v", token,
+				v.CommentID),
+			title: title,
+			link: b.feedLink(fmt.Sprintf("/proposals/%v/comments/%v",
+				token, v.CommentID)),
+			author:    v.Username,
+			content:   v.Comment,
+			published: timestamp,
+			updated:   timestamp,
+		})
+	}
+
+	return newFeed("urn:politeia:feed:comments:"+token,
+		"Comments on "+ir.proposalMD.Name,
+		b.feedLink("/proposals/"+token), entries), nil
+}
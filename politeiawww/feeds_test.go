@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/decred/politeia/decredplugin"
+	pd "github.com/decred/politeia/politeiad/api/v1"
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/slog"
+)
+
+// Tests that the feeds contain the public proposal activity and that they
+// are served with caching headers.
+func TestFeeds(t *testing.T) {
+	defer log.SetLevel(log.Level())
+	log.SetLevel(slog.LevelOff)
+
+	b := createBackend(t)
+	defer b.db.Close()
+	b.cfg.WebServerAddress = "https://proposals.example.com"
+
+	now := time.Now().Truncate(time.Second)
+	b.bestBlock = 200
+	b.bestBlockFetched = now
+
+	// Block times are fetched from politeiad.
+	blockTime := func(height uint64) time.Time {
+		return time.Unix(1500000000+int64(height)*300, 0)
+	}
+	var blockTimesCalls int
+	server, _ := setupTestPoliteiad(t, b, func(pc pd.PluginCommand) (string, error) {
+		if pc.Command != decredplugin.CmdBlockTimes {
+			return "", fmt.Errorf("unexpected command %v", pc.Command)
+		}
+		blockTimesCalls++
+		bt, err := decredplugin.DecodeBlockTimes([]byte(pc.Payload))
+		if err != nil {
+			return "", err
+		}
+		var btr decredplugin.BlockTimesReply
+		for _, v := range bt.Heights {
+			btr.Timestamps = append(btr.Timestamps,
+				blockTime(uint64(v)).Unix())
+		}
+		reply, err := decredplugin.EncodeBlockTimesReply(btr)
+		return string(reply), err
+	})
+	defer server.Close()
+
+	newRecord := func(token string, status pd.RecordStatusT, publishedAt int64) *inventoryRecord {
+		return &inventoryRecord{
+			record: pd.Record{
+				Status:    status,
+				Timestamp: publishedAt,
+				CensorshipRecord: pd.CensorshipRecord{
+					Token: token,
+				},
+				Files: []pd.File{{
+					Name:    indexFile,
+					Payload: base64.StdEncoding.EncodeToString([]byte("Proposal " + token)),
+				}},
+			},
+			comments: make(map[string]www.Comment),
+			changes: []MDStreamChanges{{
+				NewStatus: status,
+				Timestamp: publishedAt,
+			}},
+		}
+	}
+	options := []www.VoteOption{
+		{Id: "no", Description: "Don't approve proposal", Bits: 1},
+		{Id: "yes", Description: "Approve proposal", Bits: 2},
+	}
+	startVote := func(ir *inventoryRecord, start, end string) {
+		ir.proposalMD.Name = "proposal " + ir.record.CensorshipRecord.Token
+		ir.votebits = www.StartVote{
+			Vote: www.Vote{
+				Token:            ir.record.CensorshipRecord.Token,
+				QuorumPercentage: 20,
+				PassPercentage:   60,
+				Options:          options,
+			},
+		}
+		ir.voting = www.StartVoteReply{
+			StartBlockHeight: start,
+			EndHeight:        end,
+			EligibleTickets:  make([]string, 10),
+		}
+	}
+
+	finished := strings.Repeat("1", 64)
+	voting := strings.Repeat("2", 64)
+	unvetted := strings.Repeat("3", 64)
+	b.inventory[finished] = newRecord(finished, pd.RecordStatusPublic,
+		now.Add(-2*time.Hour).Unix())
+	startVote(b.inventory[finished], "100", "150")
+	b.inventory[voting] = newRecord(voting, pd.RecordStatusPublic,
+		now.Add(-time.Hour).Unix())
+	startVote(b.inventory[voting], "180", "250")
+	b.inventory[unvetted] = newRecord(unvetted, pd.RecordStatusNotReviewed,
+		now.Unix())
+	b.voteTallies[finished] = &voteTally{
+		tickets: map[string]uint64{"a": 2, "b": 2, "c": 1},
+		votes:   map[uint64]uint64{1: 1, 2: 2},
+		final:   true,
+	}
+
+	b.inventory[voting].comments["1"] = www.Comment{
+		Token:     voting,
+		ParentID:  "0",
+		CommentID: "1",
+		Comment:   "first comment",
+		Timestamp: now.Add(-time.Minute).Unix(),
+	}
+	b.inventory[voting].comments["2"] = www.Comment{
+		Token:     voting,
+		ParentID:  "1",
+		CommentID: "2",
+		Timestamp: now.Unix(),
+		Censored:  true,
+	}
+
+	_, err := b.ProcessProposalsFeed(www.Feed{Format: "json"})
+	assertErrorWithContext(t, err, www.ErrorStatusInvalidInput,
+		[]string{"invalid format"})
+
+	// Only public proposals are published, newest first.
+	f, err := b.ProcessProposalsFeed(www.Feed{})
+	assertSuccess(t, err)
+	if len(f.entries) != 2 ||
+		f.entries[0].id != "urn:politeia:proposal:"+voting ||
+		f.entries[1].id != "urn:politeia:proposal:"+finished ||
+		f.entries[0].content != "Proposal "+voting ||
+		f.entries[0].link != b.cfg.WebServerAddress+"/proposals/"+voting ||
+		!f.updated.Equal(now.Add(-time.Hour)) {
+		t.Fatalf("unexpected proposals feed %v", f)
+	}
+
+	// Vote times are the timestamps of the start and end blocks and
+	// they are only fetched once.
+	for i := 0; i < 2; i++ {
+		f, err = b.ProcessVotingFeed(www.Feed{})
+		assertSuccess(t, err)
+		if len(f.entries) != 2 ||
+			f.entries[0].title != "Voting started: proposal "+voting ||
+			!f.entries[0].published.Equal(blockTime(180)) ||
+			!f.entries[1].published.Equal(blockTime(100)) {
+			t.Fatalf("unexpected voting feed %v", f)
+		}
+	}
+	if blockTimesCalls != 1 {
+		t.Fatalf("unexpected block times requests %v", blockTimesCalls)
+	}
+
+	f, err = b.ProcessVoteResultsFeed(www.Feed{})
+	assertSuccess(t, err)
+	if len(f.entries) != 1 ||
+		f.entries[0].title != "Vote finished: proposal "+finished ||
+		!strings.Contains(f.entries[0].content,
+			"- yes: 2 votes (66.67%)") ||
+		!strings.Contains(f.entries[0].content,
+			"The quorum of 2 votes was reached.") ||
+		!f.entries[0].published.Equal(blockTime(150)) {
+		t.Fatalf("unexpected vote results feed %v", f)
+	}
+
+	// Censored comments are left out and comments of unvetted proposals
+	// are not available.
+	_, err = b.ProcessCommentsFeed(unvetted, www.Feed{})
+	assertError(t, err, www.ErrorStatusWrongStatus)
+	f, err = b.ProcessCommentsFeed(voting, www.Feed{})
+	assertSuccess(t, err)
+	if len(f.entries) != 1 || f.entries[0].content != "first comment" ||
+		f.entries[0].title != "Comment by " {
+		t.Fatalf("unexpected comments feed %v", f)
+	}
+
+	// Feeds are served with caching headers and unchanged feeds are not
+	// sent again.
+	for _, format := range []string{www.FeedFormatAtom, www.FeedFormatRSS} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+		respondWithFeed(w, r, f, format)
+		if w.Code != http.StatusOK {
+			t.Fatalf("unexpected status %v", w.Code)
+		}
+		etag := w.Header().Get("ETag")
+		if etag == "" || w.Header().Get("Last-Modified") !=
+			f.updated.UTC().Format(http.TimeFormat) {
+			t.Fatalf("missing caching headers %v", w.Header())
+		}
+		var v struct {
+			XMLName xml.Name
+		}
+		err = xml.Unmarshal(w.Body.Bytes(), &v)
+		if err != nil {
+			t.Fatalf("invalid %v feed: %v", format, err)
+		}
+		if (format == www.FeedFormatAtom && v.XMLName.Local != "feed") ||
+			(format == www.FeedFormatRSS && v.XMLName.Local != "rss") {
+			t.Fatalf("unexpected %v feed %v", format, v.XMLName)
+		}
+
+		r = httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("If-None-Match", etag)
+		w = httptest.NewRecorder()
+		respondWithFeed(w, r, f, format)
+		if w.Code != http.StatusNotModified {
+			t.Fatalf("unexpected status %v", w.Code)
+		}
+	}
+}
@@ -43,12 +43,14 @@ var (
 
 	log        = backendLog.Logger("PWWW")
 	localdbLog = backendLog.Logger("LODB")
+	utilLog    = backendLog.Logger("UTIL")
 )
 
 // subsystemLoggers maps each subsystem identifier to its associated logger.
 var subsystemLoggers = map[string]slog.Logger{
 	"PWWW": log,
 	"LODB": localdbLog,
+	"UTIL": utilLog,
 }
 
 // initLogRotator initializes the logging rotater to write logs to logFile and
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"encoding/hex"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/politeiawww/database"
+	"github.com/decred/politeia/util"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxLoginChallenges is the maximum number of outstanding login
+	// challenges.  New challenges are refused until challenges are used
+	// or expire.
+	maxLoginChallenges = 10000
+
+	// maxLoginChallengesPerKey is the maximum number of outstanding login
+	// challenges of a public key.  The oldest challenge of the public key
+	// is discarded when another challenge is issued.
+	maxLoginChallengesPerKey = 5
+)
+
+// loginChallenge is a challenge that has been issued to the owner of a public
+// key and that has not been used yet.
+type loginChallenge struct {
+	publicKey string    // Public key the challenge was issued for
+	expiry    time.Time // Challenge expiry
+}
+
+// _removeLoginChallenge removes a login challenge.
+//
+// This function must be called WITH the mutex held.
+func (b *backend) _removeLoginChallenge(challenge string) {
+	c, ok := b.loginChallenges[challenge]
+	if !ok {
+		return
+	}
+	delete(b.loginChallenges, challenge)
+
+	challenges := b.loginChallengeKeys[c.publicKey]
+	for k, v := range challenges {
+		if v == challenge {
+			challenges = append(challenges[:k], challenges[k+1:]...)
+			break
+		}
+	}
+	if len(challenges) == 0 {
+		delete(b.loginChallengeKeys, c.publicKey)
+		return
+	}
+	b.loginChallengeKeys[c.publicKey] = challenges
+}
+
+// _pruneLoginChallenges removes all expired login challenges.  The challenges
+// of a public key expire in the order they were issued.
+//
+// This function must be called WITH the mutex held.
+func (b *backend) _pruneLoginChallenges(now time.Time) {
+	for publicKey, challenges := range b.loginChallengeKeys {
+		var expired int
+		for _, v := range challenges {
+			if !now.After(b.loginChallenges[v].expiry) {
+				break
+			}
+			delete(b.loginChallenges, v)
+			expired++
+		}
+		if expired == len(challenges) {
+			delete(b.loginChallengeKeys, publicKey)
+			continue
+		}
+		b.loginChallengeKeys[publicKey] = challenges[expired:]
+	}
+}
+
+// _addLoginChallenge adds a login challenge for the provided public key.  The
+// oldest challenge of the public key is discarded when it has reached
+// maxLoginChallengesPerKey challenges.  It returns false when the maximum
+// number of outstanding challenges has been reached.
+//
+// This function must be called WITH the mutex held.
+func (b *backend) _addLoginChallenge(challenge string, c loginChallenge) bool {
+	challenges := b.loginChallengeKeys[c.publicKey]
+	if len(challenges) >= maxLoginChallengesPerKey {
+		b._removeLoginChallenge(challenges[0])
+	} else if len(b.loginChallenges) >= maxLoginChallenges {
+		return false
+	}
+
+	b.loginChallenges[challenge] = c
+	b.loginChallengeKeys[c.publicKey] = append(
+		b.loginChallengeKeys[c.publicKey], challenge)
+	return true
+}
+
+// useLoginChallenge removes a login challenge and returns whether it was
+// issued for the provided public key and has not expired.  A challenge can
+// only be used once, regardless of the outcome of the login.
+//
+// This function must be called WITHOUT the mutex held.
+func (b *backend) useLoginChallenge(challenge, publicKey string) bool {
+	b.Lock()
+	defer b.Unlock()
+
+	c, ok := b.loginChallenges[challenge]
+	if !ok {
+		return false
+	}
+	b._removeLoginChallenge(challenge)
+
+	return c.publicKey == publicKey && !time.Now().After(c.expiry)
+}
+
+// getUserByActivePubkey returns the user whose active identity is the
+// provided public key.
+//
+// This function must be called WITHOUT the mutex held.
+func (b *backend) getUserByActivePubkey(publicKey string) (*database.User, error) {
+	b.RLock()
+	userID, ok := b.userPubkeys[publicKey]
+	b.RUnlock()
+	if !ok {
+		return nil, database.ErrUserNotFound
+	}
+
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, err
+	}
+	user, err := b.db.UserGetById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	activeIdentity, ok := database.ActiveIdentityString(user.Identities)
+	if !ok || activeIdentity != publicKey {
+		return nil, database.ErrUserNotFound
+	}
+
+	return user, nil
+}
+
+// loginSignature verifies the signature of a login challenge and logs in the
+// user whose active identity created it.
+func (b *backend) loginSignature(ls *v1.LoginSignature) loginReplyWithError {
+	if !b.useLoginChallenge(ls.Challenge, ls.PublicKey) {
+		log.Debugf("Login failure for %v: invalid login challenge",
+			ls.PublicKey)
+		return loginReplyWithError{
+			reply: nil,
+			err: v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidLoginChallenge,
+			},
+		}
+	}
+
+	// The public key is only looked up once the challenge has been
+	// verified.  Keys that don't belong to a user are indistinguishable
+	// from invalid signatures.
+	invalidSignature := loginReplyWithError{
+		reply: nil,
+		err: v1.UserError{
+			ErrorCode: v1.ErrorStatusInvalidSignature,
+		},
+	}
+	user, err := b.getUserByActivePubkey(ls.PublicKey)
+	if err == database.ErrUserNotFound {
+		log.Debugf("Login failure for %v: no user with active "+
+			"public key", ls.PublicKey)
+		return invalidSignature
+	} else if err != nil {
+		return loginReplyWithError{
+			reply: nil,
+			err:   err,
+		}
+	}
+
+	pk, err := hex.DecodeString(ls.PublicKey)
+	if err != nil {
+		return invalidSignature
+	}
+	err = checkSignature(pk, ls.Signature, v1.LoginChallengePrefix,
+		ls.Challenge)
+	if err != nil {
+		err := b.loginFailed(user)
+		if err != nil {
+			return loginReplyWithError{
+				reply: nil,
+				err:   err,
+			}
+		}
+
+		log.Debugf("Login failure for %v: invalid signature",
+			user.Email)
+		return invalidSignature
+	}
+
+	return b.completeLogin(user, ls.Code)
+}
+
+// ProcessLoginChallenge issues a single use challenge that must be signed
+// with the provided public key in order to login.  Challenges are issued for
+// any valid public key so that the reply does not reveal whether the key
+// belongs to a user.  The number of outstanding challenges is limited in
+// total and per public key.
+func (b *backend) ProcessLoginChallenge(lc *v1.LoginChallenge) (*v1.LoginChallengeReply, error) {
+	_, err := b.validatePubkey(lc.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := util.Random(v1.LoginChallengeSize)
+	if err != nil {
+		return nil, err
+	}
+	challenge := hex.EncodeToString(c)
+	now := time.Now()
+	expiry := now.Add(v1.LoginChallengeExpirySeconds * time.Second)
+
+	b.Lock()
+	b._pruneLoginChallenges(now)
+	ok := b._addLoginChallenge(challenge, loginChallenge{
+		publicKey: lc.PublicKey,
+		expiry:    expiry,
+	})
+	b.Unlock()
+	if !ok {
+		log.Debugf("Login challenge for %v refused: too many "+
+			"outstanding challenges", lc.PublicKey)
+		return nil, v1.UserError{
+			ErrorCode: v1.ErrorStatusTooManyLoginChallenges,
+		}
+	}
+
+	return &v1.LoginChallengeReply{
+		Challenge: challenge,
+		Expiry:    expiry.Unix(),
+	}, nil
+}
+
+// ProcessLoginSignature checks that the login challenge was signed by the
+// active identity of a user that is allowed to login.
+func (b *backend) ProcessLoginSignature(ls v1.LoginSignature) (*v1.LoginReply, error) {
+	return loginWithMinimumWait(func() loginReplyWithError {
+		return b.loginSignature(&ls)
+	})
+}
@@ -1,14 +1,71 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httputil"
 
 	v1 "github.com/decred/politeia/politeiawww/api/v1"
 	"github.com/decred/politeia/util"
+	"github.com/gorilla/csrf"
 )
 
+// contextKey is the type of the request context keys that are set by the
+// middleware.
+type contextKey int
+
+const (
+	// contextKeyAPIKeyUserID is the id of the user that owns the API key
+	// that authenticated the request.
+	contextKeyAPIKeyUserID contextKey = iota
+)
+
+// apiKey authenticates requests that carry an API key before calling the next
+// function.  The API key must grant the scope that the route requires.  The
+// owner of the key is stored in the request context where it takes the place
+// of the session user.  Requests without an API key are passed through.
+func (p *politeiawww) apiKey(method, route string, f http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get(v1.APIKeyHeader)
+		if token == "" {
+			f(w, r)
+			return
+		}
+
+		k, err := p.backend.authenticateAPIKey(token)
+		if err != nil {
+			RespondWithError(w, r, http.StatusUnauthorized,
+				"apiKey: authenticateAPIKey %v", err)
+			return
+		}
+		scope, ok := apiKeyScope(method, route)
+		if !ok || k.Scopes&uint64(scope) == 0 {
+			RespondWithError(w, r, http.StatusForbidden,
+				"apiKey: scope", v1.UserError{
+					ErrorCode: v1.ErrorStatusAPIKeyScopeNotAllowed,
+				})
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), contextKeyAPIKeyUserID,
+			k.UserID.String())
+		f(w, r.WithContext(ctx))
+	}
+}
+
+// skipCSRFForAPIKeys exempts requests that carry an API key from CSRF
+// protection.  Browsers can't attach custom headers to cross site requests
+// and API key requests don't rely on cookies so they can't be forged.
+func skipCSRFForAPIKeys(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(v1.APIKeyHeader) != "" {
+			r = csrf.UnsafeSkipCheck(r)
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
 // isLoggedIn ensures that a user is logged in before calling the next
 // function.
 func (p *politeiawww) isLoggedIn(f http.HandlerFunc) http.HandlerFunc {
@@ -39,23 +96,36 @@ func (p *politeiawww) isLoggedIn(f http.HandlerFunc) http.HandlerFunc {
 // isLoggedInAsAdmin ensures that a user is logged in as an admin user
 // before calling the next function.
 func (p *politeiawww) isLoggedInAsAdmin(f http.HandlerFunc) http.HandlerFunc {
+	return p.isLoggedInWithRole(v1.UserRoleSuperAdmin, f)
+}
+
+// isLoggedInWithRole ensures that a user is logged in and holds the provided
+// role before calling the next function.  Users that must enable two-factor
+// authentication are rejected until they have done so.
+func (p *politeiawww) isLoggedInWithRole(role v1.UserRoleT, f http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		log.Debugf("isLoggedInAsAdmin: %v %v %v %v", remoteAddr(r),
-			r.Method, r.URL, r.Proto)
+		log.Debugf("isLoggedInWithRole: %v %v %v %v %v", v1.UserRole[role],
+			remoteAddr(r), r.Method, r.URL, r.Proto)
 
-		// Check if user is admin
-		isAdmin, err := p.isAdmin(w, r)
+		// Check if user holds the role
+		user, err := p.getSessionUser(w, r)
 		if err != nil {
-			log.Errorf("isLoggedInAsAdmin: isAdmin %v", err)
+			log.Errorf("isLoggedInWithRole: getSessionUser %v", err)
 			util.RespondWithJSON(w, http.StatusUnauthorized, v1.ErrorReply{
 				ErrorCode: int64(v1.ErrorStatusNotLoggedIn),
 			})
 			return
 		}
-		if !isAdmin {
+		if !userHasRole(user, role) {
 			util.RespondWithJSON(w, http.StatusForbidden, v1.ErrorReply{})
 			return
 		}
+		if p.backend.totpRequired(user) {
+			util.RespondWithJSON(w, http.StatusForbidden, v1.ErrorReply{
+				ErrorCode: int64(v1.ErrorStatusTOTPRequired),
+			})
+			return
+		}
 
 		f(w, r)
 	}
@@ -0,0 +1,467 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/politeiawww/database"
+	"github.com/google/uuid"
+)
+
+const (
+	// emailOutboxInterval is the interval at which the outbox is checked
+	// for emails that are due for delivery.
+	emailOutboxInterval = 30 * time.Second
+
+	// emailRetryBackoff is the delay before the first retry of a failed
+	// delivery.  The delay doubles with every failed attempt up to
+	// emailRetryBackoffMax.
+	emailRetryBackoff    = time.Minute
+	emailRetryBackoffMax = 6 * time.Hour
+
+	// emailMaxAttempts is the number of failed delivery attempts after
+	// which an email is dead lettered.
+	emailMaxAttempts = 10
+
+	// smtpTimeout is the maximum duration of the delivery of a single
+	// email through the SMTP server, including the connection.
+	smtpTimeout = time.Minute
+)
+
+// emailTransport delivers email messages.
+type emailTransport interface {
+	// Send delivers an RFC 5322 message to the provided recipients.
+	Send(from string, recipients []string, msg []byte) error
+}
+
+// smtpTransport is an email transport that sends emails through an SMTP
+// server over an implicit TLS connection.  The server certificate is always
+// verified; servers with a self-signed certificate must be trusted by
+// providing their certificate.
+type smtpTransport struct {
+	server   string         // Server address including the port
+	host     string         // Server host name used to verify its certificate
+	hostname string         // Local host name announced to the server
+	auth     smtp.Auth      // Optional credentials
+	rootCAs  *x509.CertPool // Optional trusted server certificates
+	timeout  time.Duration  // Maximum duration of a delivery
+}
+
+// maildirTransport is an email transport that delivers emails to a local
+// maildir instead of sending them.  It is meant for development and testing.
+type maildirTransport struct {
+	dir string
+}
+
+var (
+	_ emailTransport = (*smtpTransport)(nil)
+	_ emailTransport = (*maildirTransport)(nil)
+
+	// errNoRecipients is returned by the transports when an email has no
+	// recipients.
+	errNoRecipients = errors.New("no recipients specified")
+
+	// maildirCounter makes the names of messages that are delivered
+	// within the same nanosecond unique.
+	maildirCounter uint64
+)
+
+// newSMTPTransport returns an smtpTransport for the provided server.  The
+// server port defaults to the SMTPS port.  cert is an optional file that
+// contains the server certificate; the system roots are used when it is not
+// provided.
+func newSMTPTransport(server, user, pass, cert string) (*smtpTransport, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+
+	host, _, err := net.SplitHostPort(server)
+	if err != nil {
+		host = server
+		server = net.JoinHostPort(server, "465")
+	}
+
+	t := smtpTransport{
+		server:   server,
+		host:     host,
+		hostname: hostname,
+		timeout:  smtpTimeout,
+	}
+	if user != "" {
+		t.auth = smtp.PlainAuth("", user, pass, host)
+	}
+	if cert != "" {
+		pem, err := ioutil.ReadFile(cert)
+		if err != nil {
+			return nil, fmt.Errorf("mail cert: %v", err)
+		}
+		t.rootCAs = x509.NewCertPool()
+		if !t.rootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("mail cert: invalid certificate")
+		}
+	}
+
+	return &t, nil
+}
+
+// Send sends the message through the SMTP server.
+//
+// Send satisfies the emailTransport interface.
+func (t *smtpTransport) Send(from string, recipients []string, msg []byte) error {
+	if len(recipients) == 0 {
+		return errNoRecipients
+	}
+
+	// A hung server must not block the outbox.
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: t.timeout}, "tcp",
+		t.server, &tls.Config{
+			ServerName: t.host,
+			RootCAs:    t.rootCAs,
+		})
+	if err != nil {
+		return err
+	}
+	err = conn.SetDeadline(time.Now().Add(t.timeout))
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	client, err := smtp.NewClient(conn, t.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	err = client.Hello(t.hostname)
+	if err != nil {
+		return err
+	}
+	if t.auth != nil {
+		err = client.Auth(t.auth)
+		if err != nil {
+			return err
+		}
+	}
+	err = client.Mail(from)
+	if err != nil {
+		return err
+	}
+	for _, v := range recipients {
+		err = client.Rcpt(v)
+		if err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(msg)
+	if err != nil {
+		return err
+	}
+	err = w.Close()
+	if err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// newMaildirTransport returns a maildirTransport that delivers to the given
+// directory.  The maildir is created if it does not exist.
+func newMaildirTransport(dir string) (*maildirTransport, error) {
+	for _, v := range []string{"tmp", "new", "cur"} {
+		err := os.MkdirAll(filepath.Join(dir, v), 0700)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &maildirTransport{
+		dir: dir,
+	}, nil
+}
+
+// Send writes the message to the new directory of the maildir.  The message
+// is written to the tmp directory first so that readers never see a partial
+// message.  Blind copy recipients are not part of the message headers so all
+// recipients are recorded in an X-Recipients header.
+//
+// Send satisfies the emailTransport interface.
+func (t *maildirTransport) Send(from string, recipients []string, msg []byte) error {
+	if len(recipients) == 0 {
+		return errNoRecipients
+	}
+
+	name := fmt.Sprintf("%v.%v_%v.politeiawww", time.Now().Unix(),
+		time.Now().UnixNano(), atomic.AddUint64(&maildirCounter, 1))
+	tmp := filepath.Join(t.dir, "tmp", name)
+	payload := append([]byte("X-Recipients: "+
+		strings.Join(recipients, ",")+"\r\n"), msg...)
+	err := ioutil.WriteFile(tmp, payload, 0600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, filepath.Join(t.dir, "new", name))
+}
+
+// writeEmailPart writes a quoted-printable encoded UTF-8 body.
+func writeEmailPart(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	_, err := qp.Write([]byte(body))
+	if err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// createEmailMessage returns the RFC 5322 message of an outbox email.  Emails
+// with an HTML body are sent as multipart/alternative messages that include
+// the plain text body for clients that do not render HTML.
+func createEmailMessage(e database.Email) ([]byte, error) {
+	var buf bytes.Buffer
+	from := mail.Address{
+		Name:    politeiaMailName,
+		Address: fromAddress,
+	}
+	to := "undisclosed-recipients:;"
+	if len(e.To) > 0 {
+		to = strings.Join(e.To, ", ")
+	}
+	fmt.Fprintf(&buf, "From: %v\r\n", from.String())
+	fmt.Fprintf(&buf, "Date: %v\r\n",
+		time.Unix(e.CreatedAt, 0).Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "To: %v\r\n", to)
+	fmt.Fprintf(&buf, "Subject: %v\r\n",
+		mime.QEncoding.Encode("utf-8", e.Subject))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if e.HTMLBody == "" {
+		buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+		buf.WriteString("\r\n")
+		err := writeEmailPart(&buf, e.Body)
+		if err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	mw := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%v\r\n",
+		mw.Boundary())
+	buf.WriteString("\r\n")
+	parts := []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=utf-8", e.Body},
+		{"text/html; charset=utf-8", e.HTMLBody},
+	}
+	for _, v := range parts {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {v.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		err = writeEmailPart(w, v.body)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := mw.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func convertWWWOutboxEmailFromDatabaseEmail(e database.Email) www.OutboxEmail {
+	return www.OutboxEmail{
+		ID:          e.ID,
+		Subject:     e.Subject,
+		Recipients:  len(e.To) + len(e.BCC),
+		CreatedAt:   e.CreatedAt,
+		Attempts:    e.Attempts,
+		NextAttempt: e.NextAttempt,
+		LastError:   e.LastError,
+		DeadLetter:  e.DeadLetter,
+	}
+}
+
+// retryDelay returns the delay before the next attempt of an operation that
+// failed the given number of times.  The delay starts at backoff and doubles
+// with every failed attempt up to max.
+func retryDelay(attempts uint64, backoff, max time.Duration) time.Duration {
+	delay := backoff
+	for i := uint64(1); i < attempts; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	return delay
+}
+
+// emailRetryDelay returns the delay before the next delivery attempt of an
+// email that failed to be delivered the given number of times.
+func emailRetryDelay(attempts uint64) time.Duration {
+	return retryDelay(attempts, emailRetryBackoff, emailRetryBackoffMax)
+}
+
+// queueEmail adds an email to the outbox.  The email is delivered in the
+// background by the outbox so that callers are never blocked by the
+// transport.
+func (b *backend) queueEmail(e *renderedEmail, to, bcc []string) error {
+	if len(to) == 0 && len(bcc) == 0 {
+		return nil
+	}
+
+	now := time.Now().Unix()
+	err := b.db.EmailSave(database.Email{
+		ID:          uuid.New().String(),
+		Subject:     e.Subject,
+		Body:        e.Text,
+		HTMLBody:    e.HTML,
+		To:          to,
+		BCC:         bcc,
+		CreatedAt:   now,
+		NextAttempt: now,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Wake up the outbox without waiting for it.
+	select {
+	case b.emailOutboxWake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// deliverEmail attempts to deliver an outbox email.  Delivered emails are
+// removed from the outbox.  Failed deliveries are retried with an
+// exponential backoff until the email is dead lettered.
+func (b *backend) deliverEmail(e database.Email, now time.Time) error {
+	msg, err := createEmailMessage(e)
+	if err != nil {
+		return err
+	}
+	recipients := append(append([]string{}, e.To...), e.BCC...)
+	err = b.cfg.Mailer.Send(fromAddress, recipients, msg)
+	if err == nil {
+		log.Debugf("Email delivered: %v %v", e.ID, e.Subject)
+		return b.db.EmailDelete(e.ID)
+	}
+
+	e.Attempts++
+	e.LastError = err.Error()
+	e.NextAttempt = now.Add(emailRetryDelay(e.Attempts)).Unix()
+	if e.Attempts >= emailMaxAttempts {
+		e.DeadLetter = true
+		log.Errorf("Email dead lettered after %v attempts: %v %v: %v",
+			e.Attempts, e.ID, e.Subject, err)
+	} else {
+		log.Infof("Email delivery failed, attempt %v: %v %v: %v",
+			e.Attempts, e.ID, e.Subject, err)
+	}
+
+	return b.db.EmailSave(e)
+}
+
+// deliverQueuedEmails attempts to deliver all outbox emails that are due,
+// oldest first.
+func (b *backend) deliverQueuedEmails(now time.Time) error {
+	due := make([]database.Email, 0)
+	err := b.db.AllEmails(func(e *database.Email) {
+		if !e.DeadLetter && e.NextAttempt <= now.Unix() {
+			due = append(due, *e)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].CreatedAt < due[j].CreatedAt
+	})
+
+	for _, e := range due {
+		err := b.deliverEmail(e, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runEmailOutbox delivers the outbox emails until the database is shut down.
+// It runs whenever an email is queued and periodically to retry failed
+// deliveries.
+func (b *backend) runEmailOutbox() {
+	for {
+		err := b.deliverQueuedEmails(time.Now())
+		if err == database.ErrShutdown {
+			return
+		} else if err != nil {
+			log.Errorf("deliverQueuedEmails: %v", err)
+		}
+
+		select {
+		case <-b.emailOutboxWake:
+		case <-time.After(emailOutboxInterval):
+		}
+	}
+}
+
+// ProcessEmailOutbox returns the emails that have not been delivered yet,
+// oldest first.
+func (b *backend) ProcessEmailOutbox(eo www.EmailOutbox) (*www.EmailOutboxReply, error) {
+	reply := www.EmailOutboxReply{
+		Emails: make([]www.OutboxEmail, 0),
+	}
+	err := b.db.AllEmails(func(e *database.Email) {
+		if eo.DeadLetter && !e.DeadLetter {
+			return
+		}
+		reply.Emails = append(reply.Emails,
+			convertWWWOutboxEmailFromDatabaseEmail(*e))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(reply.Emails, func(i, j int) bool {
+		return reply.Emails[i].CreatedAt < reply.Emails[j].CreatedAt
+	})
+
+	return &reply, nil
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"crypto/elliptic"
+	"crypto/tls"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/politeiawww/database"
+	"github.com/decred/politeia/util"
+	"github.com/decred/slog"
+)
+
+// testTransport is an email transport that records the delivered messages.
+type testTransport struct {
+	err  error
+	sent [][]byte
+}
+
+func (t *testTransport) Send(from string, recipients []string, msg []byte) error {
+	if t.err != nil {
+		return t.err
+	}
+	t.sent = append(t.sent, msg)
+	return nil
+}
+
+// Tests that failed deliveries are retried with a backoff until the email is
+// dead lettered and that delivered emails are removed from the outbox.
+func TestEmailOutbox(t *testing.T) {
+	defer log.SetLevel(log.Level())
+	log.SetLevel(slog.LevelOff)
+
+	b := createBackend(t)
+	transport := &testTransport{
+		err: errors.New("connection refused"),
+	}
+	b.cfg.Mailer = transport
+
+	tplData := userLockedResetPasswordEmailTemplateData{
+		Email: "user@example.com",
+	}
+	err := b.sendEmailTo(templateUserLockedResetPassword, &tplData, "",
+		"user@example.com")
+	assertSuccess(t, err)
+
+	now := time.Now()
+	for i := uint64(1); i <= emailMaxAttempts; i++ {
+		err = b.deliverQueuedEmails(now)
+		assertSuccess(t, err)
+		eor, err := b.ProcessEmailOutbox(www.EmailOutbox{})
+		assertSuccess(t, err)
+		if len(eor.Emails) != 1 || eor.Emails[0].Attempts != i ||
+			eor.Emails[0].LastError != "connection refused" {
+			t.Fatalf("unexpected outbox %v", eor.Emails)
+		}
+
+		// Emails are not retried before the backoff has passed.
+		err = b.deliverQueuedEmails(now)
+		assertSuccess(t, err)
+		eor, err = b.ProcessEmailOutbox(www.EmailOutbox{})
+		assertSuccess(t, err)
+		if eor.Emails[0].Attempts != i {
+			t.Fatalf("unexpected retry %v", eor.Emails[0])
+		}
+		now = now.Add(emailRetryDelay(i))
+	}
+
+	eor, err := b.ProcessEmailOutbox(www.EmailOutbox{DeadLetter: true})
+	assertSuccess(t, err)
+	if len(eor.Emails) != 1 || !eor.Emails[0].DeadLetter ||
+		eor.Emails[0].Recipients != 1 {
+		t.Fatalf("expected dead letter %v", eor.Emails)
+	}
+
+	// Dead letters are not retried.
+	transport.err = nil
+	err = b.deliverQueuedEmails(now)
+	assertSuccess(t, err)
+	if len(transport.sent) != 0 {
+		t.Fatalf("unexpected delivery of dead letter")
+	}
+
+	// Delivered emails are removed from the outbox.
+	err = b.sendEmailTo(templateUserLockedResetPassword, &tplData, "",
+		"user@example.com")
+	assertSuccess(t, err)
+	err = b.deliverQueuedEmails(now)
+	assertSuccess(t, err)
+	if len(transport.sent) != 1 {
+		t.Fatalf("expected email to be delivered")
+	}
+	eor, err = b.ProcessEmailOutbox(www.EmailOutbox{})
+	assertSuccess(t, err)
+	if len(eor.Emails) != 1 || !eor.Emails[0].DeadLetter {
+		t.Fatalf("unexpected outbox %v", eor.Emails)
+	}
+
+	b.db.Close()
+}
+
+// Tests that the maildir transport delivers messages to the new directory.
+func TestMaildirTransport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "politeiawww.maildir")
+	assertSuccess(t, err)
+	defer os.RemoveAll(dir)
+
+	transport, err := newMaildirTransport(dir)
+	assertSuccess(t, err)
+
+	msg, err := createEmailMessage(database.Email{
+		Subject:   "subject",
+		Body:      "body",
+		BCC:       []string{"user@example.com"},
+		CreatedAt: time.Now().Unix(),
+	})
+	assertSuccess(t, err)
+	err = transport.Send(fromAddress, []string{"user@example.com"}, msg)
+	assertSuccess(t, err)
+
+	files, err := ioutil.ReadDir(filepath.Join(dir, "new"))
+	assertSuccess(t, err)
+	if len(files) != 1 {
+		t.Fatalf("unexpected maildir messages %v", len(files))
+	}
+	payload, err := ioutil.ReadFile(filepath.Join(dir, "new",
+		files[0].Name()))
+	assertSuccess(t, err)
+	if !strings.Contains(string(payload), "X-Recipients: user@example.com") ||
+		!strings.Contains(string(payload), "Subject: subject") {
+		t.Fatalf("unexpected message %s", payload)
+	}
+
+	err = transport.Send(fromAddress, nil, msg)
+	if err != errNoRecipients {
+		t.Fatalf("expected no recipients error, got %v", err)
+	}
+}
+
+// serveTestSMTP answers a single SMTP session on the connection and sends the
+// received message to the messages channel.
+func serveTestSMTP(conn net.Conn, messages chan<- string) {
+	defer conn.Close()
+
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 localhost ready")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		switch strings.ToUpper(strings.SplitN(line, " ", 2)[0]) {
+		case "DATA":
+			tp.PrintfLine("354 go ahead")
+			msg, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			messages <- string(msg)
+			tp.PrintfLine("250 queued")
+		case "QUIT":
+			tp.PrintfLine("221 bye")
+			return
+		default:
+			tp.PrintfLine("250 ok")
+		}
+	}
+}
+
+// Tests that the SMTP transport verifies the server certificate and that a
+// self-signed server is trusted once its certificate is provided.
+func TestSMTPTransport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "politeiawww.smtp")
+	assertSuccess(t, err)
+	defer os.RemoveAll(dir)
+
+	certFile := filepath.Join(dir, "mail.cert")
+	keyFile := filepath.Join(dir, "mail.key")
+	err = util.GenCertPair(elliptic.P256(), "politeiawww test", certFile,
+		keyFile)
+	assertSuccess(t, err)
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	assertSuccess(t, err)
+	l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+	})
+	assertSuccess(t, err)
+	defer l.Close()
+
+	messages := make(chan string, 1)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serveTestSMTP(conn, messages)
+		}
+	}()
+	_, port, err := net.SplitHostPort(l.Addr().String())
+	assertSuccess(t, err)
+	server := net.JoinHostPort("127.0.0.1", port)
+	msg := []byte("Subject: subject\r\n\r\nbody\r\n")
+
+	transport, err := newSMTPTransport(server, "", "", "")
+	assertSuccess(t, err)
+	err = transport.Send(fromAddress, []string{"user@example.com"}, msg)
+	if err == nil {
+		t.Fatalf("expected self-signed certificate to be rejected")
+	}
+
+	transport, err = newSMTPTransport(server, "", "", certFile)
+	assertSuccess(t, err)
+	err = transport.Send(fromAddress, []string{"user@example.com"}, msg)
+	assertSuccess(t, err)
+	if m := <-messages; !strings.Contains(m, "Subject: subject") {
+		t.Fatalf("unexpected message %q", m)
+	}
+
+	_, err = newSMTPTransport(server, "", "", keyFile)
+	if err == nil {
+		t.Fatalf("expected invalid certificate")
+	}
+
+	// A server that stops responding after the TLS handshake does not
+	// block the delivery.
+	hung, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+	})
+	assertSuccess(t, err)
+	defer hung.Close()
+	go func() {
+		for {
+			conn, err := hung.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			conn.(*tls.Conn).Handshake()
+		}
+	}()
+	_, port, err = net.SplitHostPort(hung.Addr().String())
+	assertSuccess(t, err)
+	transport, err = newSMTPTransport(net.JoinHostPort("127.0.0.1", port),
+		"", "", certFile)
+	assertSuccess(t, err)
+	transport.timeout = 100 * time.Millisecond
+	err = transport.Send(fromAddress, []string{"user@example.com"}, msg)
+	if err == nil {
+		t.Fatalf("expected hung server to time out")
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	v1 "github.com/decred/politeia/politeiawww/api/v1"
@@ -141,8 +142,9 @@ func (b *backend) checkForUserPayments(pool map[uuid.UUID]paywallPoolMember) (bo
 			continue
 		}
 
-		tx, _, err := util.FetchTxWithBlockExplorers(poolMember.address, poolMember.amount,
-			poolMember.txNotBefore, b.cfg.MinConfirmationsRequired)
+		tx, _, err := util.FetchTx(b.txFetcher, poolMember.address,
+			poolMember.amount, poolMember.txNotBefore,
+			b.cfg.MinConfirmationsRequired)
 		if err != nil {
 			log.Errorf("cannot fetch tx: %v\n", err)
 			continue
@@ -331,7 +333,7 @@ func (b *backend) ProcessVerifyUserPayment(user *database.User, vupt v1.VerifyUs
 		return &reply, nil
 	}
 
-	tx, _, err := util.FetchTxWithBlockExplorers(user.NewUserPaywallAddress,
+	tx, _, err := util.FetchTx(b.txFetcher, user.NewUserPaywallAddress,
 		user.NewUserPaywallAmount, user.NewUserPaywallTxNotBefore,
 		b.cfg.MinConfirmationsRequired)
 	if err != nil {
@@ -405,6 +407,37 @@ func (b *backend) addUsersToPaywallPool() error {
 	return nil
 }
 
+// newTxFetcher returns the transaction fetcher that is used to check for
+// paywall payments.  The configured fetchers are tried in order.  The
+// fetcher settings are validated and defaulted by loadConfig.
+func newTxFetcher(cfg *config) (util.TxFetcher, error) {
+	fetchers := make([]util.TxFetcher, 0, 2)
+	for _, v := range strings.Split(cfg.TxFetchers, ",") {
+		switch strings.TrimSpace(v) {
+		case util.TxFetcherDcrdata:
+			fetchers = append(fetchers,
+				util.NewDcrdataTxFetcher(cfg.DcrdataURL))
+		case util.TxFetcherInsight:
+			fetchers = append(fetchers,
+				util.NewInsightTxFetcher(cfg.InsightURL))
+		case util.TxFetcherDcrd:
+			f, err := util.NewDcrdTxFetcher(cfg.DcrdHost, cfg.DcrdUser,
+				cfg.DcrdPass, cfg.DcrdCert)
+			if err != nil {
+				return nil, err
+			}
+			fetchers = append(fetchers, f)
+		case util.TxFetcherFixture:
+			fetchers = append(fetchers,
+				util.NewFixtureTxFetcher(cfg.TxFixture))
+		default:
+			return nil, fmt.Errorf("invalid transaction fetcher: %v", v)
+		}
+	}
+
+	return util.NewFailoverTxFetcher(fetchers...), nil
+}
+
 // initPaywallCheck is intended to be called
 func (b *backend) initPaywallChecker() error {
 	if b.cfg.PaywallAmount == 0 {
@@ -544,7 +577,7 @@ func (b *backend) verifyProposalPayment(user *database.User) (*util.TxDetails, e
 	}
 
 	// Fetch txs sent to paywall address
-	txs, err := util.FetchTxsForAddress(paywall.Address)
+	txs, err := b.txFetcher.FetchTxsForAddress(paywall.Address)
 	if err != nil {
 		return nil, fmt.Errorf("FetchTxsForAddress %v: %v",
 			paywall.Address, err)
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/politeiawww/database"
+	"github.com/google/uuid"
+	"github.com/gorilla/securecookie"
+	"github.com/gorilla/sessions"
+)
+
+const (
+	// sessionLastSeenInterval is the minimum interval between updates of
+	// the last seen timestamp of a session.  This prevents a database
+	// write on every request.
+	sessionLastSeenInterval = time.Minute
+)
+
+// hashSessionID returns the hex encoded SHA256 digest of a session id.  Only
+// the digests are stored in the database and returned to clients.
+func hashSessionID(id string) string {
+	h := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(h[:])
+}
+
+// dbSessionStore is a gorilla sessions store that keeps the session values in
+// the database instead of the filesystem.  This allows politeiawww instances
+// that share a database to share sessions.  It mirrors
+// sessions.FilesystemStore.
+type dbSessionStore struct {
+	db      database.Database
+	Codecs  []securecookie.Codec
+	Options *sessions.Options // default configuration
+}
+
+var (
+	_ sessions.Store = (*dbSessionStore)(nil)
+)
+
+// newDBSessionStore returns a new dbSessionStore.  The key pairs are used to
+// authenticate and optionally encrypt the cookies and the session values.
+func newDBSessionStore(db database.Database, keyPairs ...[]byte) *dbSessionStore {
+	return &dbSessionStore{
+		db:     db,
+		Codecs: securecookie.CodecsFromPairs(keyPairs...),
+		Options: &sessions.Options{
+			Path:   "/",
+			MaxAge: sessionMaxAge,
+		},
+	}
+}
+
+// Get returns a session for the given name after adding it to the registry.
+//
+// Get satisfies the sessions.Store interface.
+func (s *dbSessionStore) Get(r *http.Request, name string) (*sessions.Session, error) {
+	return sessions.GetRegistry(r).Get(s, name)
+}
+
+// New returns a session for the given name without adding it to the
+// registry.
+//
+// New satisfies the sessions.Store interface.
+func (s *dbSessionStore) New(r *http.Request, name string) (*sessions.Session, error) {
+	session := sessions.NewSession(s, name)
+	opts := *s.Options
+	session.Options = &opts
+	session.IsNew = true
+
+	c, err := r.Cookie(name)
+	if err != nil {
+		// No session cookie
+		return session, nil
+	}
+	err = securecookie.DecodeMulti(name, c.Value, &session.ID, s.Codecs...)
+	if err != nil {
+		return session, err
+	}
+	err = s.load(session)
+	if err != nil {
+		return session, err
+	}
+	session.IsNew = false
+
+	return session, nil
+}
+
+// Save adds a single session to the response.  Sessions with a MaxAge <= 0
+// are deleted from the database.
+//
+// Save satisfies the sessions.Store interface.
+func (s *dbSessionStore) Save(r *http.Request, w http.ResponseWriter, session *sessions.Session) error {
+	if session.Options.MaxAge <= 0 {
+		if session.ID != "" {
+			err := s.db.SessionDelete(hashSessionID(session.ID))
+			if err != nil && err != database.ErrSessionNotFound {
+				return err
+			}
+		}
+		http.SetCookie(w, sessions.NewCookie(session.Name(), "",
+			session.Options))
+		return nil
+	}
+
+	if session.ID == "" {
+		session.ID = strings.TrimRight(base32.StdEncoding.EncodeToString(
+			securecookie.GenerateRandomKey(32)), "=")
+	}
+	err := s.save(session)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := securecookie.EncodeMulti(session.Name(), session.ID,
+		s.Codecs...)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, sessions.NewCookie(session.Name(), encoded,
+		session.Options))
+
+	return nil
+}
+
+// save encodes the session values and stores them in the database.  The
+// session metadata of an existing record is preserved.
+func (s *dbSessionStore) save(session *sessions.Session) error {
+	values, err := securecookie.EncodeMulti(session.Name(), session.Values,
+		s.Codecs...)
+	if err != nil {
+		return err
+	}
+
+	id := hashSessionID(session.ID)
+	ds, err := s.db.SessionGet(id)
+	if err == database.ErrSessionNotFound {
+		ds = &database.Session{
+			ID: id,
+		}
+	} else if err != nil {
+		return err
+	}
+	ds.Values = values
+	ds.Expiry = time.Now().Add(time.Duration(session.Options.MaxAge) *
+		time.Second).Unix()
+
+	return s.db.SessionSave(*ds)
+}
+
+// load reads the session values from the database.
+func (s *dbSessionStore) load(session *sessions.Session) error {
+	ds, err := s.db.SessionGet(hashSessionID(session.ID))
+	if err != nil {
+		return err
+	}
+	return securecookie.DecodeMulti(session.Name(), ds.Values,
+		&session.Values, s.Codecs...)
+}
+
+func convertWWWSessionFromDatabaseSession(s database.Session, current string) v1.Session {
+	return v1.Session{
+		ID:        s.ID,
+		CreatedAt: s.CreatedAt,
+		LastSeen:  s.LastSeen,
+		IP:        s.IP,
+		UserAgent: s.UserAgent,
+		Current:   s.ID == current,
+	}
+}
+
+// newSession records that a user logged in using the provided session.  The
+// session values of an existing record are preserved.
+func (b *backend) newSession(id, userID, ip, userAgent string) error {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return err
+	}
+
+	s, err := b.db.SessionGet(id)
+	if err == database.ErrSessionNotFound {
+		s = &database.Session{
+			ID: id,
+		}
+	} else if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	s.UserID = uid
+	s.CreatedAt = now.Unix()
+	s.LastSeen = now.Unix()
+	s.Expiry = now.Add(sessionMaxAge * time.Second).Unix()
+	s.IP = ip
+	s.UserAgent = userAgent
+
+	return b.db.SessionSave(*s)
+}
+
+// touchSession returns whether the session belongs to the user and has been
+// neither revoked nor expired.  The last seen timestamp of the session is
+// updated.
+func (b *backend) touchSession(id, userID string) (bool, error) {
+	s, err := b.db.SessionGet(id)
+	if err == database.ErrSessionNotFound {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	if s.UserID.String() != userID || now.Unix() > s.Expiry {
+		return false, nil
+	}
+
+	if now.Sub(time.Unix(s.LastSeen, 0)) >= sessionLastSeenInterval {
+		s.LastSeen = now.Unix()
+		err = b.db.SessionSave(*s)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// revokeSessions logs out all sessions of the user except for the provided
+// ones.
+func (b *backend) revokeSessions(userID uuid.UUID, except ...string) error {
+	return b.db.SessionsDeleteByUserID(userID, except)
+}
+
+// ProcessSessions returns the active sessions of the user sorted by login
+// time.  Expired sessions are removed.
+func (b *backend) ProcessSessions(user *database.User, current string) (*v1.SessionsReply, error) {
+	sessions, err := b.db.SessionsGet(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt < sessions[j].CreatedAt
+	})
+
+	now := time.Now().Unix()
+	reply := v1.SessionsReply{
+		Sessions: make([]v1.Session, 0, len(sessions)),
+	}
+	for _, s := range sessions {
+		if now > s.Expiry {
+			err := b.db.SessionDelete(s.ID)
+			if err != nil && err != database.ErrSessionNotFound {
+				return nil, err
+			}
+			continue
+		}
+		reply.Sessions = append(reply.Sessions,
+			convertWWWSessionFromDatabaseSession(s, current))
+	}
+
+	return &reply, nil
+}
+
+// ProcessRevokeSessions logs out either the provided session or all sessions
+// of the user other than the current one.
+func (b *backend) ProcessRevokeSessions(rs *v1.RevokeSessions, user *database.User, current string) (*v1.RevokeSessionsReply, error) {
+	if rs.All {
+		err := b.revokeSessions(user.ID, current)
+		if err != nil {
+			return nil, err
+		}
+		return &v1.RevokeSessionsReply{}, nil
+	}
+
+	notFound := v1.UserError{
+		ErrorCode: v1.ErrorStatusSessionNotFound,
+	}
+	s, err := b.db.SessionGet(rs.ID)
+	if err == database.ErrSessionNotFound {
+		return nil, notFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Users can only revoke their own sessions.
+	if s.UserID != user.ID {
+		return nil, notFound
+	}
+
+	err = b.db.SessionDelete(s.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.RevokeSessionsReply{}, nil
+}
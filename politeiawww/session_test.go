@@ -0,0 +1,134 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	www "github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// Tests listing and revoking the sessions of a user.
+func TestProcessSessions(t *testing.T) {
+	b := createBackend(t)
+	nu, _ := createAndVerifyUser(t, b)
+	user, _ := b.db.UserGet(nu.Email)
+	nu, _ = createAndVerifyUser(t, b)
+	otherUser, _ := b.db.UserGet(nu.Email)
+
+	current := hashSessionID("current")
+	other := hashSessionID("other")
+	for _, id := range []string{current, other} {
+		err := b.newSession(id, user.ID.String(), "127.0.0.1", "cli")
+		assertSuccess(t, err)
+	}
+
+	// Sessions are only valid for the user that logged in
+	ok, err := b.touchSession(current, otherUser.ID.String())
+	assertSuccess(t, err)
+	if ok {
+		t.Fatalf("expected session of another user to be rejected")
+	}
+
+	sr, err := b.ProcessSessions(user, current)
+	assertSuccess(t, err)
+	if len(sr.Sessions) != 2 {
+		t.Fatalf("unexpected sessions %v", sr.Sessions)
+	}
+	for _, s := range sr.Sessions {
+		if s.Current != (s.ID == current) || s.IP != "127.0.0.1" {
+			t.Fatalf("unexpected session %v", s)
+		}
+	}
+
+	// Users can only revoke their own sessions
+	rs := www.RevokeSessions{ID: other}
+	_, err = b.ProcessRevokeSessions(&rs, otherUser, "")
+	assertError(t, err, www.ErrorStatusSessionNotFound)
+	_, err = b.ProcessRevokeSessions(&rs, user, current)
+	assertSuccess(t, err)
+	ok, err = b.touchSession(other, user.ID.String())
+	assertSuccess(t, err)
+	if ok {
+		t.Fatalf("expected revoked session to be rejected")
+	}
+
+	// Revoking all sessions keeps the current one
+	err = b.newSession(other, user.ID.String(), "127.0.0.1", "cli")
+	assertSuccess(t, err)
+	_, err = b.ProcessRevokeSessions(&www.RevokeSessions{All: true}, user,
+		current)
+	assertSuccess(t, err)
+	sr, err = b.ProcessSessions(user, current)
+	assertSuccess(t, err)
+	if len(sr.Sessions) != 1 || !sr.Sessions[0].Current {
+		t.Fatalf("unexpected sessions %v", sr.Sessions)
+	}
+
+	// Expired sessions are rejected and removed
+	s, err := b.db.SessionGet(current)
+	assertSuccess(t, err)
+	s.Expiry = time.Now().Add(-time.Second).Unix()
+	err = b.db.SessionSave(*s)
+	assertSuccess(t, err)
+	ok, err = b.touchSession(current, user.ID.String())
+	assertSuccess(t, err)
+	if ok {
+		t.Fatalf("expected expired session to be rejected")
+	}
+	sr, err = b.ProcessSessions(user, current)
+	assertSuccess(t, err)
+	if len(sr.Sessions) != 0 {
+		t.Fatalf("unexpected sessions %v", sr.Sessions)
+	}
+
+	b.db.Close()
+}
+
+// Tests that the database session store persists session values and that
+// deleting the session record logs the session out.
+func TestDBSessionStore(t *testing.T) {
+	b := createBackend(t)
+	store := newDBSessionStore(b.db, []byte("0123456789abcdef0123456789abcdef"))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	session, err := store.New(r, www.CookieSession)
+	assertSuccess(t, err)
+	if !session.IsNew {
+		t.Fatalf("expected new session")
+	}
+	session.Values["uuid"] = "user"
+	w := httptest.NewRecorder()
+	err = store.Save(r, w, session)
+	assertSuccess(t, err)
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("unexpected cookies %v", cookies)
+	}
+	load := func() (string, error) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.AddCookie(cookies[0])
+		session, err := store.New(r, www.CookieSession)
+		if err != nil {
+			return "", err
+		}
+		id, _ := session.Values["uuid"].(string)
+		return id, nil
+	}
+	id, err := load()
+	assertSuccess(t, err)
+	if id != "user" {
+		t.Fatalf("unexpected session value %v", id)
+	}
+
+	err = b.db.SessionDelete(hashSessionID(session.ID))
+	assertSuccess(t, err)
+	id, err = load()
+	if err == nil || id != "" {
+		t.Fatalf("expected deleted session to be rejected")
+	}
+
+	b.db.Close()
+}
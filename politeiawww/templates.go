@@ -72,6 +72,172 @@ type commentReplyOnCommentTemplateData struct {
 	CommentLink  string
 }
 
+type digestEventTemplateData struct {
+	ProposalName string
+	Username     string
+	Link         string
+}
+
+type emailDigestTemplateData struct {
+	Vetted      []digestEventTemplateData
+	Edited      []digestEventTemplateData
+	VoteStarted []digestEventTemplateData
+	Comments    []digestEventTemplateData
+}
+
+const (
+	sampleLink = "https://proposals.decred.org/proposals/" +
+		"27f87171d98b7923a1bd2bee6affed929fa2d2a8e2a8dcd2a5e7ab4e15d4fa17"
+	sampleName     = "Sample proposal"
+	sampleUsername = "sampleuser"
+	sampleEmail    = "sampleuser@example.com"
+)
+
+var (
+	sampleDigestEvents = []digestEventTemplateData{{
+		ProposalName: sampleName,
+		Username:     sampleUsername,
+		Link:         sampleLink,
+	}}
+
+	templateNewUserEmail = newEmailTemplate("new_user_email",
+		"Verify Your Email", templateNewUserEmailRaw,
+		newUserEmailTemplateData{
+			Username: sampleUsername,
+			Link:     sampleLink,
+			Email:    sampleEmail,
+		})
+	templateResetPasswordEmail = newEmailTemplate("reset_password_email",
+		"Reset Your Password", templateResetPasswordEmailRaw,
+		resetPasswordEmailTemplateData{
+			Link:  sampleLink,
+			Email: sampleEmail,
+		})
+	templateUpdateUserKeyEmail = newEmailTemplate("update_user_key_email",
+		"Verify Your New Identity", templateUpdateUserKeyEmailRaw,
+		updateUserKeyEmailTemplateData{
+			Link: sampleLink,
+			PublicKey: "5203ab0bb739f3fc267ad20c945b81bc" +
+				"b68ff22414510c000305f4f0afb90d1b",
+			Email: sampleEmail,
+		})
+	templateUserLockedResetPassword = newEmailTemplate(
+		"user_locked_reset_password",
+		"Locked Account - Reset Your Password",
+		templateUserLockedResetPasswordRaw,
+		userLockedResetPasswordEmailTemplateData{
+			Link:  sampleLink,
+			Email: sampleEmail,
+		})
+	templateNewProposalSubmitted = newEmailTemplate(
+		"new_proposal_submitted", "New Proposal Submitted",
+		templateNewProposalSubmittedRaw,
+		newProposalSubmittedTemplateData{
+			Link:     sampleLink,
+			Name:     sampleName,
+			Username: sampleUsername,
+			Email:    sampleEmail,
+		})
+	templateProposalVetted = newEmailTemplate("proposal_vetted",
+		"New Proposal Published", templateProposalVettedRaw,
+		proposalStatusChangeTemplateData{
+			Link:     sampleLink,
+			Name:     sampleName,
+			Username: sampleUsername,
+		})
+	templateProposalEdited = newEmailTemplate("proposal_edited",
+		"Proposal Edited", templateProposalEditedRaw,
+		proposalEditedTemplateData{
+			Link:     sampleLink,
+			Name:     sampleName,
+			Version:  "2",
+			Username: sampleUsername,
+		})
+	templateProposalVoteStarted = newEmailTemplate("proposal_vote_started",
+		"Voting Started for Proposal", templateProposalVoteStartedRaw,
+		proposalVoteStartedTemplateData{
+			Link:     sampleLink,
+			Name:     sampleName,
+			Username: sampleUsername,
+		})
+	templateProposalVoteAuthorized = newEmailTemplate(
+		"proposal_vote_authorized", "Proposal Authorized To Start Voting",
+		templateProposalVoteAuthorizedRaw,
+		proposalVoteAuthorizedTemplateData{
+			Link:     sampleLink,
+			Name:     sampleName,
+			Username: sampleUsername,
+			Email:    sampleEmail,
+		})
+	templateProposalVettedForAuthor = newEmailTemplate(
+		"proposal_vetted_for_author", "Your Proposal Has Been Published",
+		templateProposalVettedForAuthorRaw,
+		proposalStatusChangeTemplateData{
+			Link: sampleLink,
+			Name: sampleName,
+		})
+	templateProposalCensoredForAuthor = newEmailTemplate(
+		"proposal_censored_for_author", "Your Proposal Has Been Censored",
+		templateProposalCensoredForAuthorRaw,
+		proposalStatusChangeTemplateData{
+			Link:               sampleLink,
+			Name:               sampleName,
+			StatusChangeReason: "Spam",
+		})
+	templateProposalVoteStartedForAuthor = newEmailTemplate(
+		"proposal_vote_started_for_author",
+		"Your Proposal Has Started Voting",
+		templateProposalVoteStartedForAuthorRaw,
+		proposalVoteStartedTemplateData{
+			Link:     sampleLink,
+			Name:     sampleName,
+			Username: sampleUsername,
+		})
+	templateCommentReplyOnProposal = newEmailTemplate(
+		"comment_reply_on_proposal", "New Comment On Your Proposal",
+		templateCommentReplyOnProposalRaw,
+		commentReplyOnProposalTemplateData{
+			Commenter:    sampleUsername,
+			ProposalName: sampleName,
+			CommentLink:  sampleLink + "/comments/1",
+		})
+	templateCommentReplyOnComment = newEmailTemplate(
+		"comment_reply_on_comment", "New Comment On Your Comment",
+		templateCommentReplyOnCommentRaw,
+		commentReplyOnCommentTemplateData{
+			Commenter:    sampleUsername,
+			ProposalName: sampleName,
+			CommentLink:  sampleLink + "/comments/2",
+		})
+	templateEmailDigest = newEmailTemplate("email_digest",
+		"Your Politeia Digest", templateEmailDigestRaw,
+		emailDigestTemplateData{
+			Vetted:      sampleDigestEvents,
+			Edited:      sampleDigestEvents,
+			VoteStarted: sampleDigestEvents,
+			Comments:    sampleDigestEvents,
+		})
+
+	// emailTemplateList contains all email templates.
+	emailTemplateList = []*emailTemplate{
+		templateNewUserEmail,
+		templateResetPasswordEmail,
+		templateUpdateUserKeyEmail,
+		templateUserLockedResetPassword,
+		templateNewProposalSubmitted,
+		templateProposalVetted,
+		templateProposalEdited,
+		templateProposalVoteStarted,
+		templateProposalVoteAuthorized,
+		templateProposalVettedForAuthor,
+		templateProposalCensoredForAuthor,
+		templateProposalVoteStartedForAuthor,
+		templateCommentReplyOnProposal,
+		templateCommentReplyOnComment,
+		templateEmailDigest,
+	}
+)
+
 const templateNewUserEmailRaw = `
 Thanks for joining Politeia, {{.Username}}!
 
@@ -191,3 +357,29 @@ const templateCommentReplyOnCommentRaw = `
 Proposal: {{.ProposalName}}
 Comment: {{.CommentLink}}
 `
+
+const templateEmailDigestRaw = `
+Here is a summary of the recent activity on Politeia.
+{{if .Vetted}}
+New proposals:
+{{range .Vetted}}
+{{.ProposalName}} by {{.Username}}
+{{.Link}}
+{{end}}{{end}}{{if .Edited}}
+Edited proposals:
+{{range .Edited}}
+{{.ProposalName}} by {{.Username}}
+{{.Link}}
+{{end}}{{end}}{{if .VoteStarted}}
+Voting has started:
+{{range .VoteStarted}}
+{{.ProposalName}} by {{.Username}}
+{{.Link}}
+{{end}}{{end}}{{if .Comments}}
+New comments on your proposals and comments:
+{{range .Comments}}
+{{.Username}} on {{.ProposalName}}
+{{.Link}}
+{{end}}{{end}}
+You can change how often you receive these emails in your account settings.
+`
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/politeiawww/database"
+	"github.com/decred/politeia/util"
+)
+
+const (
+	// totpIssuer is the issuer that authenticator apps display next to
+	// the account.
+	totpIssuer = "politeia"
+
+	// totpSkew is the number of time steps before and after the current
+	// one whose codes are accepted in order to allow for clock drift.
+	totpSkew = 1
+
+	// totpRecoveryCodes is the number of recovery codes that are issued
+	// when two-factor authentication is enabled.
+	totpRecoveryCodes = 10
+
+	// totpRecoveryCodeSize is the size of a recovery code in bytes.
+	totpRecoveryCodeSize = 5
+)
+
+// normalizeRecoveryCode strips the separators and case from a recovery code.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.Replace(code, "-", "", -1)
+	return strings.Replace(code, " ", "", -1)
+}
+
+// hashRecoveryCode returns the hex encoded SHA256 digest of a recovery code.
+// Only the digests are stored in the database.
+func hashRecoveryCode(code string) string {
+	h := sha256.Sum256([]byte(normalizeRecoveryCode(code)))
+	return hex.EncodeToString(h[:])
+}
+
+// newRecoveryCodes returns a new set of recovery codes and their digests.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, totpRecoveryCodes)
+	digests := make([]string, 0, totpRecoveryCodes)
+	for i := 0; i < totpRecoveryCodes; i++ {
+		b, err := util.Random(totpRecoveryCodeSize)
+		if err != nil {
+			return nil, nil, err
+		}
+		code := hex.EncodeToString(b)
+		code = code[:len(code)/2] + "-" + code[len(code)/2:]
+		codes = append(codes, code)
+		digests = append(digests, hashRecoveryCode(code))
+	}
+	return codes, digests, nil
+}
+
+// checkTOTP verifies a TOTP code or a recovery code of a user that has
+// two-factor authentication enabled.  TOTP codes can only be used once and
+// recovery codes are removed once they have been used.  The user is only
+// modified in memory; it is up to the caller to persist it.
+func checkTOTP(user *database.User, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if len(code) == util.TOTPDigits {
+		step, ok, err := util.VerifyTOTP(user.TOTPSecret, code,
+			time.Now(), totpSkew)
+		if err != nil {
+			return false, err
+		}
+		if !ok || step <= user.TOTPLastStep {
+			return false, nil
+		}
+		user.TOTPLastStep = step
+		return true, nil
+	}
+
+	digest := hashRecoveryCode(code)
+	for k, v := range user.TOTPRecoveryCodes {
+		if subtle.ConstantTimeCompare([]byte(v), []byte(digest)) != 1 {
+			continue
+		}
+		user.TOTPRecoveryCodes = append(user.TOTPRecoveryCodes[:k],
+			user.TOTPRecoveryCodes[k+1:]...)
+		return true, nil
+	}
+	return false, nil
+}
+
+// resetTOTP disables two-factor authentication for the user and removes the
+// secret and the recovery codes.
+func resetTOTP(user *database.User) {
+	user.TOTPSecret = ""
+	user.TOTPEnabled = false
+	user.TOTPLastStep = 0
+	user.TOTPRecoveryCodes = nil
+}
+
+// totpRequired returns whether the user must enable two-factor
+// authentication before privileged routes can be used.  This is the case for
+// users that hold a role when two-factor authentication is mandatory for
+// admins.
+func (b *backend) totpRequired(user *database.User) bool {
+	return b.cfg.AdminTOTP && userRoles(user) != 0 && !user.TOTPEnabled
+}
+
+// ProcessSetTOTP generates a new TOTP secret for the user.  The secret
+// replaces any secret that has not been verified yet.
+func (b *backend) ProcessSetTOTP(user *database.User) (*v1.SetTOTPReply, error) {
+	if user.TOTPEnabled {
+		return nil, v1.UserError{
+			ErrorCode: v1.ErrorStatusTOTPAlreadyEnabled,
+		}
+	}
+
+	secret, err := util.NewTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	user.TOTPSecret = secret
+	user.TOTPLastStep = 0
+	err = b.db.UserUpdate(*user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.SetTOTPReply{
+		Secret: secret,
+		URI:    util.TOTPURI(totpIssuer, user.Email, secret),
+	}, nil
+}
+
+// ProcessVerifyTOTP verifies a code of the pending TOTP secret of the user,
+// enables two-factor authentication and issues a new set of recovery codes.
+func (b *backend) ProcessVerifyTOTP(vt *v1.VerifyTOTP, user *database.User) (*v1.VerifyTOTPReply, error) {
+	if user.TOTPEnabled {
+		return nil, v1.UserError{
+			ErrorCode: v1.ErrorStatusTOTPAlreadyEnabled,
+		}
+	}
+	if user.TOTPSecret == "" {
+		return nil, v1.UserError{
+			ErrorCode: v1.ErrorStatusTOTPNotSet,
+		}
+	}
+
+	step, ok, err := util.VerifyTOTP(user.TOTPSecret, vt.Code, time.Now(),
+		totpSkew)
+	if err != nil {
+		return nil, fmt.Errorf("VerifyTOTP: %v", err)
+	}
+	if !ok {
+		return nil, v1.UserError{
+			ErrorCode: v1.ErrorStatusInvalidTOTPCode,
+		}
+	}
+
+	codes, digests, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	user.TOTPEnabled = true
+	user.TOTPLastStep = step
+	user.TOTPRecoveryCodes = digests
+	err = b.db.UserUpdate(*user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.VerifyTOTPReply{
+		RecoveryCodes: codes,
+	}, nil
+}
@@ -12,6 +12,7 @@ func convertWWWUserFromDatabaseUser(user *database.User) v1.User {
 	return v1.User{
 		ID:                              user.ID.String(),
 		Admin:                           user.Admin,
+		Roles:                           uint64(userRoles(user)),
 		Email:                           user.Email,
 		Username:                        user.Username,
 		NewUserPaywallAddress:           user.NewUserPaywallAddress,
@@ -32,9 +33,40 @@ func convertWWWUserFromDatabaseUser(user *database.User) v1.User {
 		Identities:                      convertWWWIdentitiesFromDatabaseIdentities(user.Identities),
 		ProposalCredits:                 ProposalCreditBalance(user),
 		EmailNotifications:              user.EmailNotifications,
+		EmailDigestDaily:                user.EmailDigestDaily,
+		EmailDigestWeekly:               user.EmailDigestWeekly,
+		Locale:                          user.Locale,
+		TOTPEnabled:                     user.TOTPEnabled,
 	}
 }
 
+// userRoles returns the effective roles of the provided user.  The legacy
+// admin flag implies the super admin role and the super admin role implies
+// all other roles.
+func userRoles(user *database.User) v1.UserRoleT {
+	roles := v1.UserRoleT(user.Roles)
+	if user.Admin {
+		roles |= v1.UserRoleSuperAdmin
+	}
+	if roles&v1.UserRoleSuperAdmin != 0 {
+		for role := range v1.UserRole {
+			roles |= role
+		}
+	}
+	return roles
+}
+
+// userHasRole returns whether the provided user holds the provided role.
+func userHasRole(user *database.User, role v1.UserRoleT) bool {
+	return userRoles(user)&role == role
+}
+
+// validUserRole returns whether the provided role is a single known role.
+func validUserRole(role v1.UserRoleT) bool {
+	_, ok := v1.UserRole[role]
+	return ok
+}
+
 func convertWWWIdentitiesFromDatabaseIdentities(identities []database.Identity) []v1.UserIdentity {
 	userIdentities := make([]v1.UserIdentity, 0, len(identities))
 	for _, v := range identities {
@@ -75,6 +107,7 @@ func filterUserPublicFields(user v1.User) v1.User {
 	return v1.User{
 		ID:         user.ID,
 		Admin:      user.Admin,
+		Roles:      user.Roles,
 		Username:   user.Username,
 		Identities: user.Identities,
 	}
@@ -108,6 +141,37 @@ func (b *backend) ProcessEditUser(eu *v1.EditUser, user *database.User) (*v1.Edi
 	if eu.EmailNotifications != nil {
 		user.EmailNotifications = *eu.EmailNotifications
 	}
+	if eu.EmailDigestDaily != nil {
+		user.EmailDigestDaily = *eu.EmailDigestDaily
+	}
+	if eu.EmailDigestWeekly != nil {
+		user.EmailDigestWeekly = *eu.EmailDigestWeekly
+	}
+	if eu.Locale != nil {
+		// Only locales for which templates are available can be used.
+		if *eu.Locale != "" && !b.templates.hasLocale(*eu.Locale) {
+			return nil, v1.UserError{
+				ErrorCode:    v1.ErrorStatusInvalidInput,
+				ErrorContext: []string{"unsupported locale"},
+			}
+		}
+		user.Locale = *eu.Locale
+	}
+
+	// Validate the digest preferences.
+	digests := user.EmailDigestDaily | user.EmailDigestWeekly
+	if digests&^uint64(v1.NotificationEmailDigestable) != 0 {
+		return nil, v1.UserError{
+			ErrorCode:    v1.ErrorStatusInvalidInput,
+			ErrorContext: []string{"notification type can not be digested"},
+		}
+	}
+	if user.EmailDigestDaily&user.EmailDigestWeekly != 0 {
+		return nil, v1.UserError{
+			ErrorCode:    v1.ErrorStatusInvalidInput,
+			ErrorContext: []string{"notification type in both digests"},
+		}
+	}
 
 	// Update the user in the database.
 	err := b.db.UserUpdate(*user)
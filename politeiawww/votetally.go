@@ -0,0 +1,201 @@
+package main
+
+import (
+	"strconv"
+	"time"
+
+	www "github.com/decred/politeia/politeiawww/api/v1"
+)
+
+const (
+	// voteTallyResyncInterval is the interval after which the tally of an
+	// active vote is reloaded from politeiad.  This corrects the tally for
+	// votes that were cast through another politeiawww instance.
+	voteTallyResyncInterval = 10 * time.Minute
+
+	// bestBlockCacheTTL is the amount of time the best block height is
+	// cached for the vote status requests and the vote tally pushes.
+	bestBlockCacheTTL = 30 * time.Second
+)
+
+// voteTally is the number of votes that every option of a proposal vote
+// received.
+type voteTally struct {
+	tickets map[string]uint64 // [ticket]bits of the counted votes
+	votes   map[uint64]uint64 // [bits]number of votes
+	synced  time.Time         // Last time the tally was loaded from politeiad
+	final   bool              // Tally was loaded after the vote finished
+}
+
+// count adds the vote of the provided ticket to the tally.  It returns false
+// if the vote of the ticket was already counted or if the vote bit is invalid.
+func (vt *voteTally) count(ticket, voteBit string) bool {
+	if _, ok := vt.tickets[ticket]; ok {
+		return false
+	}
+	bits, err := strconv.ParseUint(voteBit, 16, 64)
+	if err != nil {
+		return false
+	}
+	vt.tickets[ticket] = bits
+	vt.votes[bits]++
+	return true
+}
+
+// results returns the number of votes that every option received and the
+// total number of votes.
+func (vt *voteTally) results(options []www.VoteOption) ([]www.VoteOptionResult, uint64) {
+	results := make([]www.VoteOptionResult, 0, len(options))
+	for _, o := range options {
+		results = append(results, www.VoteOptionResult{
+			Option:        o,
+			VotesReceived: vt.votes[o.Bits],
+		})
+	}
+	return results, uint64(len(vt.tickets))
+}
+
+// loadVoteTally fetches the cast votes of a proposal from politeiad and
+// tallies them.
+func (b *backend) loadVoteTally(token string) (*voteTally, error) {
+	vrr, err := b.getVoteResultsFromPlugin(token)
+	if err != nil {
+		return nil, err
+	}
+
+	vt := voteTally{
+		tickets: make(map[string]uint64, len(vrr.CastVotes)),
+		votes:   make(map[uint64]uint64),
+		synced:  time.Now(),
+	}
+	for _, v := range vrr.CastVotes {
+		if !vt.count(v.Ticket, v.VoteBit) {
+			log.Debugf("loadVoteTally: vote not counted %v %v",
+				token, v.Ticket)
+		}
+	}
+
+	return &vt, nil
+}
+
+// voteResults returns the number of votes that every option of the proposal
+// vote received and the total number of votes.  The tally is loaded from
+// politeiad the first time it is requested and it is reloaded periodically
+// while the vote is active.  It is reloaded once more when the vote has
+// finished so that the final results include all votes.
+//
+// This function must be called WITHOUT the tally lock held.
+func (b *backend) voteResults(ir inventoryRecord, bestBlock uint64) ([]www.VoteOptionResult, uint64, error) {
+	if ir.voting.StartBlockHeight == "" {
+		// Vote has not started
+		return nil, 0, nil
+	}
+	token := ir.record.CensorshipRecord.Token
+	endHeight, err := strconv.ParseUint(ir.voting.EndHeight, 10, 64)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	finished := bestBlock >= endHeight
+
+	b.tallyMtx.Lock()
+	vt, ok := b.voteTallies[token]
+	if ok && (vt.final || (!finished &&
+		time.Since(vt.synced) < voteTallyResyncInterval)) {
+		defer b.tallyMtx.Unlock()
+		results, total := vt.results(ir.votebits.Vote.Options)
+		return results, total, nil
+	}
+	b.tallyMtx.Unlock()
+
+	// This is expensive so do it outside of the lock.
+	nvt, err := b.loadVoteTally(token)
+	if err != nil {
+		return nil, 0, err
+	}
+	nvt.final = finished
+
+	b.tallyMtx.Lock()
+	defer b.tallyMtx.Unlock()
+
+	// Keep the votes that were counted while the tally was loading.
+	if vt, ok := b.voteTallies[token]; ok {
+		for ticket, bits := range vt.tickets {
+			if _, ok := nvt.tickets[ticket]; !ok {
+				nvt.tickets[ticket] = bits
+				nvt.votes[bits]++
+			}
+		}
+	}
+	b.voteTallies[token] = nvt
+
+	results, total := nvt.results(ir.votebits.Vote.Options)
+	return results, total, nil
+}
+
+// countBallot adds the accepted votes of a ballot to the loaded vote
+// tallies.  Tallies that were not loaded yet include the votes once they are
+// loaded from politeiad.
+//
+// This function must be called WITHOUT the tally lock held.
+func (b *backend) countBallot(votes []www.CastVote) {
+	b.tallyMtx.Lock()
+	defer b.tallyMtx.Unlock()
+
+	for _, v := range votes {
+		vt, ok := b.voteTallies[v.Token]
+		if !ok {
+			continue
+		}
+		if !vt.count(v.Ticket, v.VoteBit) {
+			log.Debugf("countBallot: vote not counted %v %v",
+				v.Token, v.Ticket)
+		}
+	}
+}
+
+// cachedBestBlock returns the best block height.  The height is fetched from
+// politeiad when the cached height is older than bestBlockCacheTTL.
+//
+// This function must be called WITHOUT the tally lock held.
+func (b *backend) cachedBestBlock() (uint64, error) {
+	b.tallyMtx.Lock()
+	if time.Since(b.bestBlockFetched) < bestBlockCacheTTL {
+		defer b.tallyMtx.Unlock()
+		return b.bestBlock, nil
+	}
+	b.tallyMtx.Unlock()
+
+	bestBlock, err := b.getBestBlock()
+	if err != nil {
+		return 0, err
+	}
+
+	b.tallyMtx.Lock()
+	b.bestBlock = bestBlock
+	b.bestBlockFetched = time.Now()
+	b.tallyMtx.Unlock()
+
+	return bestBlock, nil
+}
+
+// voteStatus returns the vote status of the provided proposal.
+//
+// This function must be called WITHOUT the tally lock held.
+func (b *backend) voteStatus(ir inventoryRecord, bestBlock uint64) (*www.VoteStatusReply, error) {
+	results, total, err := b.voteResults(ir, bestBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	return &www.VoteStatusReply{
+		Token:              ir.record.CensorshipRecord.Token,
+		Status:             getVoteStatus(ir, bestBlock),
+		TotalVotes:         total,
+		OptionsResult:      results,
+		EndHeight:          ir.voting.EndHeight,
+		NumOfEligibleVotes: len(ir.voting.EligibleTickets),
+		QuorumPercentage:   ir.votebits.Vote.QuorumPercentage,
+		PassPercentage:     ir.votebits.Vote.PassPercentage,
+	}, nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/decred/politeia/decredplugin"
+	pd "github.com/decred/politeia/politeiad/api/v1"
+	www "github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// Tests that accepted ballots are added to the loaded vote tallies and that
+// the vote status is served from the tallies.
+func TestVoteTally(t *testing.T) {
+	b := createBackend(t)
+	defer b.db.Close()
+
+	token := "0000000000000000000000000000000000000000000000000000000000000000"
+	b.inventory[token] = &inventoryRecord{
+		record: pd.Record{
+			Status: pd.RecordStatusPublic,
+			CensorshipRecord: pd.CensorshipRecord{
+				Token: token,
+			},
+		},
+		votebits: www.StartVote{
+			Vote: www.Vote{
+				Token:            token,
+				QuorumPercentage: 20,
+				PassPercentage:   60,
+				Options: []www.VoteOption{
+					{Id: "no", Bits: 1},
+					{Id: "yes", Bits: 2},
+				},
+			},
+		},
+		voting: www.StartVoteReply{
+			StartBlockHeight: "100",
+			EndHeight:        "120",
+			EligibleTickets:  make([]string, 10),
+		},
+	}
+	b.bestBlock = 110
+	b.bestBlockFetched = time.Now()
+	b.voteTallies[token] = &voteTally{
+		tickets: map[string]uint64{"a": 1},
+		votes:   map[uint64]uint64{1: 1},
+		synced:  time.Now(),
+	}
+
+	// Votes are counted once per ticket and votes with an invalid vote bit
+	// are ignored.
+	b.countBallot([]www.CastVote{
+		{Token: token, Ticket: "a", VoteBit: "2"},
+		{Token: token, Ticket: "b", VoteBit: "2"},
+		{Token: token, Ticket: "c", VoteBit: "x"},
+		{Token: "unknown", Ticket: "d", VoteBit: "1"},
+	})
+
+	vsr, err := b.ProcessVoteStatus(token)
+	assertSuccess(t, err)
+	if vsr.Status != www.PropVoteStatusStarted || vsr.TotalVotes != 2 ||
+		len(vsr.OptionsResult) != 2 ||
+		vsr.OptionsResult[0].VotesReceived != 1 ||
+		vsr.OptionsResult[1].VotesReceived != 1 ||
+		vsr.NumOfEligibleVotes != 10 || vsr.QuorumPercentage != 20 ||
+		vsr.PassPercentage != 60 {
+		t.Fatalf("unexpected vote status %v", vsr)
+	}
+	if _, ok := b.voteTallies["unknown"]; ok {
+		t.Fatalf("unexpected tally for unloaded proposal")
+	}
+
+	// The tally is reloaded once when the vote finishes even if it was
+	// synced recently.
+	var loads int
+	server, _ := setupTestPoliteiad(t, b, func(pc pd.PluginCommand) (string, error) {
+		if pc.Command != decredplugin.CmdProposalVotes {
+			return "", fmt.Errorf("unexpected command %v", pc.Command)
+		}
+		loads++
+		reply, err := decredplugin.EncodeVoteResultsReply(
+			decredplugin.VoteResultsReply{
+				CastVotes: []decredplugin.CastVote{
+					{Token: token, Ticket: "a", VoteBit: "1"},
+					{Token: token, Ticket: "b", VoteBit: "2"},
+					{Token: token, Ticket: "e", VoteBit: "2"},
+				},
+			})
+		return string(reply), err
+	})
+	defer server.Close()
+
+	b.bestBlock = 120
+	for i := 0; i < 2; i++ {
+		vsr, err = b.ProcessVoteStatus(token)
+		assertSuccess(t, err)
+		if vsr.Status != www.PropVoteStatusFinished ||
+			vsr.TotalVotes != 3 ||
+			vsr.OptionsResult[1].VotesReceived != 2 {
+			t.Fatalf("unexpected vote status %v", vsr)
+		}
+	}
+	if loads != 1 || !b.voteTallies[token].final {
+		t.Fatalf("unexpected tally loads %v", loads)
+	}
+}
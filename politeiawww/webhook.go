@@ -0,0 +1,541 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/politeiawww/database"
+	"github.com/decred/politeia/util"
+	"github.com/google/uuid"
+)
+
+const (
+	// webhookIDSize is the size of a webhook id in bytes.
+	webhookIDSize = 8
+
+	// webhookSecretSize is the size in bytes of the secrets that are
+	// generated for webhooks that were registered without one.
+	webhookSecretSize = 32
+
+	// webhookSecretMinLength is the minimum length of a webhook secret
+	// that is provided by an admin.
+	webhookSecretMinLength = 16
+
+	// webhookInterval is the interval at which the deliveries are checked
+	// for deliveries that are due.
+	webhookInterval = 30 * time.Second
+
+	// webhookTimeout is the timeout of a single delivery attempt.
+	webhookTimeout = 10 * time.Second
+
+	// webhookRetryBackoff is the delay before the first retry of a failed
+	// delivery.  The delay doubles with every failed attempt up to
+	// webhookRetryBackoffMax.
+	webhookRetryBackoff    = 30 * time.Second
+	webhookRetryBackoffMax = time.Hour
+
+	// webhookMaxAttempts is the number of failed delivery attempts after
+	// which a delivery is abandoned.
+	webhookMaxAttempts = 8
+
+	// webhookDeliveryRetention is the time that delivered and abandoned
+	// deliveries are kept in the delivery log.
+	webhookDeliveryRetention = 7 * 24 * time.Hour
+
+	// webhookResponseMaxSize is the maximum number of bytes that are read
+	// from the response of a webhook.
+	webhookResponseMaxSize = 64 * 1024
+)
+
+// newWebhookClient returns the HTTP client that delivers webhook requests.
+// Redirects are not followed so that signed requests are only sent to the
+// registered URL.
+func newWebhookClient() *http.Client {
+	return &http.Client{
+		Timeout: webhookTimeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// validWebhookEvents returns whether events is a non empty combination of
+// known webhook events.
+func validWebhookEvents(events uint64) bool {
+	var all uint64
+	for event := range v1.WebhookEvent {
+		all |= uint64(event)
+	}
+	return events != 0 && events&^all == 0
+}
+
+// validWebhookURL returns whether the URL is an absolute HTTP or HTTPS URL.
+func validWebhookURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// signWebhookPayload returns the hex encoded HMAC-SHA256 of a webhook request
+// body keyed with the webhook secret.
+func signWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func convertWWWWebhookFromDatabaseWebhook(w database.Webhook) v1.Webhook {
+	return v1.Webhook{
+		ID:        w.ID,
+		URL:       w.URL,
+		Events:    w.Events,
+		CreatedAt: w.CreatedAt,
+		CreatedBy: w.CreatedBy.String(),
+	}
+}
+
+func convertWWWWebhookDeliveryFromDatabaseWebhookDelivery(d database.WebhookDelivery) v1.WebhookDelivery {
+	return v1.WebhookDelivery{
+		ID:          d.ID,
+		Event:       d.Event,
+		CreatedAt:   d.CreatedAt,
+		Attempts:    d.Attempts,
+		NextAttempt: d.NextAttempt,
+		StatusCode:  d.StatusCode,
+		LastError:   d.LastError,
+		Delivered:   d.Delivered,
+		Abandoned:   d.Abandoned,
+	}
+}
+
+// webhookPayload returns the webhook event and the payload of the provided
+// event manager event.  False is returned if the event is not delivered to
+// webhooks.
+func (b *backend) webhookPayload(data interface{}) (v1.WebhookEventT, *v1.WebhookPayload, bool) {
+	switch d := data.(type) {
+	case EventDataProposalSubmitted:
+		// Unvetted proposals are not public so only the token is
+		// included.
+		return v1.WebhookEventProposalSubmitted, &v1.WebhookPayload{
+			Token: d.CensorshipRecord.Token,
+		}, true
+	case EventDataProposalStatusChange:
+		p := v1.WebhookPayload{
+			Token:  d.Proposal.CensorshipRecord.Token,
+			Status: d.SetProposalStatus.ProposalStatus,
+		}
+		if p.Status == v1.PropStatusPublic {
+			p.Name = d.Proposal.Name
+			p.Username = d.Proposal.Username
+			p.Version = d.Proposal.Version
+		}
+		return v1.WebhookEventProposalStatusChange, &p, true
+	case EventDataProposalEdited:
+		if d.Proposal.Status != v1.PropStatusPublic {
+			return 0, nil, false
+		}
+		return v1.WebhookEventProposalEdited, &v1.WebhookPayload{
+			Token:    d.Proposal.CensorshipRecord.Token,
+			Name:     d.Proposal.Name,
+			Username: d.Proposal.Username,
+			Version:  d.Proposal.Version,
+		}, true
+	case EventDataProposalVoteAuthorized:
+		return v1.WebhookEventVoteAuthorized, &v1.WebhookPayload{
+			Token:    d.AuthorizeVote.Token,
+			Username: d.User.Username,
+			Action:   d.AuthorizeVote.Action,
+		}, true
+	case EventDataProposalVoteStarted:
+		p := v1.WebhookPayload{
+			Token: d.StartVote.Vote.Token,
+		}
+		proposal, err := b.getProposal(p.Token)
+		if err == nil {
+			p.Name = proposal.Name
+			p.Username = proposal.Username
+			p.Version = proposal.Version
+		}
+		return v1.WebhookEventVoteStarted, &p, true
+	case EventDataComment:
+		return v1.WebhookEventComment, &v1.WebhookPayload{
+			Token:     d.Comment.Token,
+			Username:  d.Comment.Username,
+			CommentID: d.Comment.CommentID,
+			ParentID:  d.Comment.ParentID,
+		}, true
+	}
+	return 0, nil, false
+}
+
+// queueWebhookEvent queues a delivery of the provided event manager event
+// for every webhook that subscribes to it.
+func (b *backend) queueWebhookEvent(data interface{}) error {
+	event, payload, ok := b.webhookPayload(data)
+	if !ok {
+		return nil
+	}
+
+	webhooks := make([]database.Webhook, 0)
+	err := b.db.AllWebhooks(func(w *database.Webhook) {
+		if w.Events&uint64(event) != 0 {
+			webhooks = append(webhooks, *w)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	now := time.Now().Unix()
+	payload.Event = v1.WebhookEvent[event]
+	payload.Timestamp = now
+	for _, w := range webhooks {
+		payload.ID = uuid.New().String()
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		err = b.db.WebhookDeliverySave(database.WebhookDelivery{
+			ID:          payload.ID,
+			WebhookID:   w.ID,
+			Event:       payload.Event,
+			Payload:     body,
+			CreatedAt:   now,
+			NextAttempt: now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Wake up the delivery loop without waiting for it.
+	select {
+	case b.webhookWake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// postWebhook sends a signed delivery to a webhook.  It returns the HTTP
+// status code of the response and an error if the delivery failed.
+func (b *backend) postWebhook(w database.Webhook, d database.WebhookDelivery) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, w.URL,
+		bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(v1.WebhookEventHeader, d.Event)
+	req.Header.Set(v1.WebhookDeliveryHeader, d.ID)
+	req.Header.Set(v1.WebhookSignatureHeader,
+		"sha256="+signWebhookPayload(w.Secret, d.Payload))
+
+	resp, err := b.webhookClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, webhookResponseMaxSize))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status: %v",
+			resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// deliverWebhook attempts to deliver an event to a webhook and records the
+// outcome in the delivery log.  Failed deliveries are retried with an
+// exponential backoff until they are abandoned.  It returns whether the event
+// was delivered.
+func (b *backend) deliverWebhook(w database.Webhook, d database.WebhookDelivery, now time.Time) (bool, error) {
+	var err error
+	d.Attempts++
+	d.StatusCode, err = b.postWebhook(w, d)
+	if err == nil {
+		log.Debugf("Webhook delivered: %v %v %v", w.ID, d.ID, d.Event)
+		d.Delivered = true
+		d.NextAttempt = 0
+		d.LastError = ""
+		return true, b.db.WebhookDeliverySave(d)
+	}
+
+	d.LastError = err.Error()
+	if d.Attempts >= webhookMaxAttempts {
+		d.Abandoned = true
+		d.NextAttempt = 0
+		log.Errorf("Webhook delivery abandoned after %v attempts: %v %v: %v",
+			d.Attempts, w.ID, d.ID, err)
+	} else {
+		d.NextAttempt = now.Add(retryDelay(d.Attempts, webhookRetryBackoff,
+			webhookRetryBackoffMax)).Unix()
+		log.Infof("Webhook delivery failed, attempt %v: %v %v: %v",
+			d.Attempts, w.ID, d.ID, err)
+	}
+
+	return false, b.db.WebhookDeliverySave(d)
+}
+
+// deliverWebhooks attempts the due deliveries of a webhook in order.  The
+// remaining deliveries are left for the next pass once a delivery failed so
+// that an unreachable webhook is only attempted once per pass.
+func (b *backend) deliverWebhooks(w database.Webhook, due []database.WebhookDelivery, now time.Time) error {
+	for _, d := range due {
+		delivered, err := b.deliverWebhook(w, d, now)
+		if err != nil || !delivered {
+			return err
+		}
+	}
+	return nil
+}
+
+// deliverQueuedWebhooks attempts all webhook deliveries that are due and
+// prunes the delivery log.  The webhooks are delivered to concurrently so that
+// a slow webhook does not delay the others; the deliveries of a webhook are
+// attempted oldest first.
+func (b *backend) deliverQueuedWebhooks(now time.Time) error {
+	webhooks := make(map[string]database.Webhook)
+	err := b.db.AllWebhooks(func(w *database.Webhook) {
+		webhooks[w.ID] = *w
+	})
+	if err != nil {
+		return err
+	}
+
+	due := make([]database.WebhookDelivery, 0)
+	prune := make([]string, 0)
+	expired := now.Add(-webhookDeliveryRetention).Unix()
+	err = b.db.AllWebhookDeliveries(func(d *database.WebhookDelivery) {
+		_, ok := webhooks[d.WebhookID]
+		switch {
+		case !ok:
+			// The webhook was deleted while the event was queued.
+			prune = append(prune, d.ID)
+		case d.Delivered || d.Abandoned:
+			if d.CreatedAt < expired {
+				prune = append(prune, d.ID)
+			}
+		case d.NextAttempt <= now.Unix():
+			due = append(due, *d)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, id := range prune {
+		err := b.db.WebhookDeliveryDelete(id)
+		if err != nil {
+			return err
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].CreatedAt < due[j].CreatedAt
+	})
+	dueByWebhook := make(map[string][]database.WebhookDelivery)
+	for _, d := range due {
+		dueByWebhook[d.WebhookID] = append(dueByWebhook[d.WebhookID], d)
+	}
+
+	var wg sync.WaitGroup
+	errC := make(chan error, len(dueByWebhook))
+	for id, deliveries := range dueByWebhook {
+		wg.Add(1)
+		go func(w database.Webhook, deliveries []database.WebhookDelivery) {
+			defer wg.Done()
+			errC <- b.deliverWebhooks(w, deliveries, now)
+		}(webhooks[id], deliveries)
+	}
+	wg.Wait()
+	close(errC)
+	for err := range errC {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runWebhooks delivers the queued webhook events until the database is shut
+// down.  It runs whenever an event is queued and periodically to retry failed
+// deliveries.
+func (b *backend) runWebhooks() {
+	for {
+		err := b.deliverQueuedWebhooks(time.Now())
+		if err == database.ErrShutdown {
+			return
+		} else if err != nil {
+			log.Errorf("deliverQueuedWebhooks: %v", err)
+		}
+
+		select {
+		case <-b.webhookWake:
+		case <-time.After(webhookInterval):
+		}
+	}
+}
+
+// ProcessNewWebhook registers a new webhook.
+func (b *backend) ProcessNewWebhook(nw v1.NewWebhook, adminUser *database.User) (*v1.NewWebhookReply, error) {
+	if !validWebhookURL(nw.URL) {
+		return nil, v1.UserError{
+			ErrorCode:    v1.ErrorStatusInvalidInput,
+			ErrorContext: []string{"invalid webhook url"},
+		}
+	}
+	if !validWebhookEvents(nw.Events) {
+		return nil, v1.UserError{
+			ErrorCode:    v1.ErrorStatusInvalidInput,
+			ErrorContext: []string{"invalid webhook events"},
+		}
+	}
+
+	secret := nw.Secret
+	if secret == "" {
+		s, err := util.Random(webhookSecretSize)
+		if err != nil {
+			return nil, err
+		}
+		secret = hex.EncodeToString(s)
+	} else if len(secret) < webhookSecretMinLength {
+		return nil, v1.UserError{
+			ErrorCode:    v1.ErrorStatusInvalidInput,
+			ErrorContext: []string{"webhook secret too short"},
+		}
+	}
+
+	id, err := util.Random(webhookIDSize)
+	if err != nil {
+		return nil, err
+	}
+	w := database.Webhook{
+		ID:        hex.EncodeToString(id),
+		URL:       nw.URL,
+		Secret:    secret,
+		Events:    nw.Events,
+		CreatedAt: time.Now().Unix(),
+		CreatedBy: adminUser.ID,
+	}
+	err = b.db.WebhookNew(w)
+	if err != nil {
+		return nil, err
+	}
+
+	err = b.logAdminAction(adminUser, database.AdminLogEntry{
+		Action: fmt.Sprintf("add webhook %v %v", w.ID, w.URL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.NewWebhookReply{
+		Webhook: convertWWWWebhookFromDatabaseWebhook(w),
+		Secret:  secret,
+	}, nil
+}
+
+// ProcessWebhooks returns the registered webhooks sorted by creation time.
+func (b *backend) ProcessWebhooks() (*v1.WebhooksReply, error) {
+	webhooks := make([]database.Webhook, 0)
+	err := b.db.AllWebhooks(func(w *database.Webhook) {
+		webhooks = append(webhooks, *w)
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].CreatedAt < webhooks[j].CreatedAt
+	})
+
+	reply := v1.WebhooksReply{
+		Webhooks: make([]v1.Webhook, 0, len(webhooks)),
+	}
+	for _, w := range webhooks {
+		reply.Webhooks = append(reply.Webhooks,
+			convertWWWWebhookFromDatabaseWebhook(w))
+	}
+
+	return &reply, nil
+}
+
+// ProcessDeleteWebhook deletes a webhook and its delivery log.
+func (b *backend) ProcessDeleteWebhook(dw v1.DeleteWebhook, adminUser *database.User) (*v1.DeleteWebhookReply, error) {
+	w, err := b.db.WebhookGet(dw.ID)
+	if err == database.ErrWebhookNotFound {
+		return nil, v1.UserError{
+			ErrorCode: v1.ErrorStatusWebhookNotFound,
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	err = b.db.WebhookDelete(w.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = b.logAdminAction(adminUser, database.AdminLogEntry{
+		Action: fmt.Sprintf("delete webhook %v %v", w.ID, w.URL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.DeleteWebhookReply{}, nil
+}
+
+// ProcessWebhookDeliveries returns the delivery log of a webhook, newest
+// first.
+func (b *backend) ProcessWebhookDeliveries(wd v1.WebhookDeliveries) (*v1.WebhookDeliveriesReply, error) {
+	_, err := b.db.WebhookGet(wd.ID)
+	if err == database.ErrWebhookNotFound {
+		return nil, v1.UserError{
+			ErrorCode: v1.ErrorStatusWebhookNotFound,
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]database.WebhookDelivery, 0)
+	err = b.db.AllWebhookDeliveries(func(d *database.WebhookDelivery) {
+		if d.WebhookID == wd.ID {
+			deliveries = append(deliveries, *d)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt > deliveries[j].CreatedAt
+	})
+
+	reply := v1.WebhookDeliveriesReply{
+		Deliveries: make([]v1.WebhookDelivery, 0, len(deliveries)),
+	}
+	for _, d := range deliveries {
+		reply.Deliveries = append(reply.Deliveries,
+			convertWWWWebhookDeliveryFromDatabaseWebhookDelivery(d))
+	}
+
+	return &reply, nil
+}
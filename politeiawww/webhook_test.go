@@ -0,0 +1,257 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/slog"
+)
+
+// Tests that webhook events are delivered as signed requests, that failed
+// deliveries are retried and that the deliveries are recorded in the
+// delivery log.
+func TestWebhooks(t *testing.T) {
+	defer log.SetLevel(log.Level())
+	log.SetLevel(slog.LevelOff)
+
+	var (
+		mtx      sync.Mutex
+		status   = http.StatusInternalServerError
+		payloads []www.WebhookPayload
+	)
+	const secret = "0123456789abcdef"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mtx.Lock()
+		defer mtx.Unlock()
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("read body: %v", err)
+		}
+		if r.Header.Get(www.WebhookSignatureHeader) !=
+			"sha256="+signWebhookPayload(secret, body) {
+			t.Errorf("invalid signature %v",
+				r.Header.Get(www.WebhookSignatureHeader))
+		}
+		var p www.WebhookPayload
+		err = json.Unmarshal(body, &p)
+		if err != nil {
+			t.Errorf("unmarshal payload: %v", err)
+		}
+		if r.Header.Get(www.WebhookEventHeader) != p.Event ||
+			r.Header.Get(www.WebhookDeliveryHeader) != p.ID {
+			t.Errorf("unexpected headers %v", r.Header)
+		}
+		payloads = append(payloads, p)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	b := createBackend(t)
+	nu, _ := createAndVerifyUser(t, b)
+	admin, err := b.db.UserGet(nu.Email)
+	assertSuccess(t, err)
+
+	// Webhooks require a valid URL, known events and a strong secret.
+	events := uint64(www.WebhookEventComment | www.WebhookEventVoteStarted)
+	_, err = b.ProcessNewWebhook(www.NewWebhook{
+		URL:    "ftp://example.com",
+		Events: events,
+	}, admin)
+	assertErrorWithContext(t, err, www.ErrorStatusInvalidInput,
+		[]string{"invalid webhook url"})
+	_, err = b.ProcessNewWebhook(www.NewWebhook{
+		URL:    server.URL,
+		Events: 1 << 20,
+	}, admin)
+	assertErrorWithContext(t, err, www.ErrorStatusInvalidInput,
+		[]string{"invalid webhook events"})
+	_, err = b.ProcessNewWebhook(www.NewWebhook{
+		URL:    server.URL,
+		Secret: "secret",
+		Events: events,
+	}, admin)
+	assertErrorWithContext(t, err, www.ErrorStatusInvalidInput,
+		[]string{"webhook secret too short"})
+
+	nwr, err := b.ProcessNewWebhook(www.NewWebhook{
+		URL:    server.URL,
+		Secret: secret,
+		Events: events,
+	}, admin)
+	assertSuccess(t, err)
+	if nwr.Secret != secret {
+		t.Fatalf("unexpected secret %v", nwr.Secret)
+	}
+	wr, err := b.ProcessWebhooks()
+	assertSuccess(t, err)
+	if len(wr.Webhooks) != 1 || wr.Webhooks[0].URL != server.URL {
+		t.Fatalf("unexpected webhooks %v", wr.Webhooks)
+	}
+
+	// Only subscribed events are queued.
+	token := "0000000000000000000000000000000000000000000000000000000000000000"
+	err = b.queueWebhookEvent(EventDataProposalEdited{
+		Proposal: &www.ProposalRecord{
+			Status:           www.PropStatusPublic,
+			CensorshipRecord: www.CensorshipRecord{Token: token},
+		},
+	})
+	assertSuccess(t, err)
+	err = b.queueWebhookEvent(EventDataComment{
+		Comment: &www.Comment{
+			Token:     token,
+			ParentID:  "0",
+			CommentID: "1",
+			Username:  "commenter",
+		},
+	})
+	assertSuccess(t, err)
+
+	deliveries := func() []www.WebhookDelivery {
+		wdr, err := b.ProcessWebhookDeliveries(www.WebhookDeliveries{
+			ID: nwr.Webhook.ID,
+		})
+		assertSuccess(t, err)
+		return wdr.Deliveries
+	}
+	if d := deliveries(); len(d) != 1 || d[0].Event != "comment.new" {
+		t.Fatalf("unexpected deliveries %v", d)
+	}
+
+	// Failed deliveries are retried once the backoff has passed.
+	now := time.Now()
+	err = b.deliverQueuedWebhooks(now)
+	assertSuccess(t, err)
+	err = b.deliverQueuedWebhooks(now)
+	assertSuccess(t, err)
+	d := deliveries()
+	if len(payloads) != 1 || d[0].Attempts != 1 || d[0].Delivered ||
+		d[0].StatusCode != http.StatusInternalServerError {
+		t.Fatalf("unexpected failed delivery %v", d)
+	}
+
+	mtx.Lock()
+	status = http.StatusOK
+	mtx.Unlock()
+	err = b.deliverQueuedWebhooks(now.Add(webhookRetryBackoff))
+	assertSuccess(t, err)
+	d = deliveries()
+	if len(payloads) != 2 || d[0].Attempts != 2 || !d[0].Delivered ||
+		d[0].NextAttempt != 0 || d[0].LastError != "" {
+		t.Fatalf("unexpected delivery %v", d)
+	}
+	p := payloads[1]
+	if p.ID != d[0].ID || p.Token != token || p.CommentID != "1" ||
+		p.Username != "commenter" {
+		t.Fatalf("unexpected payload %v", p)
+	}
+
+	// Delivered events are kept in the log until the retention period
+	// has passed.
+	err = b.deliverQueuedWebhooks(now.Add(webhookDeliveryRetention +
+		time.Minute))
+	assertSuccess(t, err)
+	if d := deliveries(); len(d) != 0 {
+		t.Fatalf("expected delivery log to be pruned %v", d)
+	}
+
+	_, err = b.ProcessDeleteWebhook(www.DeleteWebhook{
+		ID: nwr.Webhook.ID,
+	}, admin)
+	assertSuccess(t, err)
+	_, err = b.ProcessWebhookDeliveries(www.WebhookDeliveries{
+		ID: nwr.Webhook.ID,
+	})
+	assertError(t, err, www.ErrorStatusWebhookNotFound)
+
+	b.db.Close()
+}
+
+// Tests that a slow or failing webhook neither delays the deliveries to other
+// webhooks nor is attempted more than once per pass.
+func TestWebhookDeliveryIsolation(t *testing.T) {
+	defer log.SetLevel(log.Level())
+	log.SetLevel(slog.LevelOff)
+
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer slow.Close()
+	received := make(chan struct{}, 2)
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+	}))
+	defer fast.Close()
+
+	b := createBackend(t)
+	nu, _ := createAndVerifyUser(t, b)
+	admin, err := b.db.UserGet(nu.Email)
+	assertSuccess(t, err)
+
+	ids := make(map[string]string) // [url]id
+	for _, url := range []string{slow.URL, fast.URL} {
+		nwr, err := b.ProcessNewWebhook(www.NewWebhook{
+			URL:    url,
+			Events: uint64(www.WebhookEventComment),
+		}, admin)
+		assertSuccess(t, err)
+		ids[url] = nwr.Webhook.ID
+	}
+	for _, id := range []string{"1", "2"} {
+		err = b.queueWebhookEvent(EventDataComment{
+			Comment: &www.Comment{
+				Token:     "0000000000000000000000000000000000000000000000000000000000000000",
+				ParentID:  "0",
+				CommentID: id,
+			},
+		})
+		assertSuccess(t, err)
+	}
+
+	// The fast webhook receives its deliveries while the slow webhook
+	// is still being delivered to.
+	done := make(chan error)
+	go func() {
+		done <- b.deliverQueuedWebhooks(time.Now())
+	}()
+	for i := 0; i < 2; i++ {
+		select {
+		case <-received:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("fast webhook delayed by slow webhook")
+		}
+	}
+	close(release)
+	assertSuccess(t, <-done)
+
+	attempts := func(url string) []int {
+		wdr, err := b.ProcessWebhookDeliveries(www.WebhookDeliveries{
+			ID: ids[url],
+		})
+		assertSuccess(t, err)
+		a := make([]int, 0, len(wdr.Deliveries))
+		for _, v := range wdr.Deliveries {
+			a = append(a, int(v.Attempts))
+		}
+		sort.Ints(a)
+		return a
+	}
+	if a := attempts(slow.URL); len(a) != 2 || a[0] != 0 || a[1] != 1 {
+		t.Fatalf("unexpected slow webhook attempts %v", a)
+	}
+	if a := attempts(fast.URL); len(a) != 2 || a[0] != 1 || a[1] != 1 {
+		t.Fatalf("unexpected fast webhook attempts %v", a)
+	}
+
+	b.db.Close()
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/util"
+)
+
+// wsEventBufferSize is the number of server side pushes that are buffered per
+// websocket connection.  Pushes to connections that are not keeping up are
+// dropped.
+const wsEventBufferSize = 64
+
+// wsEvent is a server side push to a websocket client.
+type wsEvent struct {
+	cmd     string
+	payload interface{}
+}
+
+// ballotTallies returns the vote tallies of the proposals that were voted on
+// in the provided votes.  Every tally contains the number of votes that every
+// option received in the provided votes as well as the vote totals and the
+// vote progress.  Votes for proposals that are not being voted on are
+// ignored.
+func (b *backend) ballotTallies(votes []v1.CastVote) []v1.WSVoteTally {
+	counts := make(map[string]map[uint64]uint64) // [token][bits]count
+	for _, v := range votes {
+		bits, err := strconv.ParseUint(v.VoteBit, 16, 64)
+		if err != nil {
+			continue
+		}
+		if _, ok := counts[v.Token]; !ok {
+			counts[v.Token] = make(map[uint64]uint64)
+		}
+		counts[v.Token][bits]++
+	}
+
+	bestBlock, err := b.cachedBestBlock()
+	if err != nil {
+		log.Errorf("ballotTallies: cachedBestBlock: %v", err)
+		return nil
+	}
+
+	tallies := make([]v1.WSVoteTally, 0, len(counts))
+	for token, c := range counts {
+		ir, err := b.getInventoryRecord(token)
+		if err != nil || len(ir.votebits.Vote.Options) == 0 {
+			continue
+		}
+		results, total, err := b.voteResults(ir, bestBlock)
+		if err != nil {
+			log.Errorf("ballotTallies: voteResults %v: %v", token, err)
+			continue
+		}
+		endHeight, err := strconv.ParseUint(ir.voting.EndHeight, 10, 64)
+		if err != nil {
+			log.Errorf("ballotTallies: invalid end height %v: %v",
+				token, err)
+			continue
+		}
+
+		eligible := len(ir.voting.EligibleTickets)
+		tally := v1.WSVoteTally{
+			Token: token,
+			Votes: make([]v1.VoteOptionResult, 0,
+				len(ir.votebits.Vote.Options)),
+			OptionsResult:      results,
+			TotalVotes:         total,
+			NumOfEligibleVotes: eligible,
+			QuorumVotes: uint64(eligible) *
+				uint64(ir.votebits.Vote.QuorumPercentage) / 100,
+			EndHeight: ir.voting.EndHeight,
+		}
+		if endHeight > bestBlock {
+			tally.BlocksRemaining = endHeight - bestBlock
+		}
+		for _, o := range ir.votebits.Vote.Options {
+			tally.Votes = append(tally.Votes, v1.VoteOptionResult{
+				Option:        o,
+				VotesReceived: c[o.Bits],
+			})
+		}
+		tallies = append(tallies, tally)
+	}
+	sort.Slice(tallies, func(i, j int) bool {
+		return tallies[i].Token < tallies[j].Token
+	})
+
+	return tallies
+}
+
+// websocketPush sends a server side push to all websocket clients that are
+// subscribed to the provided subscription.
+func (p *politeiawww) websocketPush(subscription, cmd string, payload interface{}) {
+	p.websocketPushTo(subscription, cmd, payload, nil)
+}
+
+// websocketPushTo sends a server side push to the websocket clients that are
+// subscribed to the provided subscription and that are allowed to receive it.
+// All subscribed clients are allowed when allowed is nil.  The push is dropped
+// for clients whose buffer is full so that a slow client can't block the
+// others.
+func (p *politeiawww) websocketPushTo(subscription, cmd string, payload interface{}, allowed func(*wsContext) bool) {
+	log.Tracef("websocketPush %v", subscription)
+
+	p.wsMtx.RLock()
+	defer p.wsMtx.RUnlock()
+
+	for _, contexts := range p.ws {
+		for _, v := range contexts {
+			if _, ok := v.subscriptions[subscription]; !ok {
+				continue
+			}
+			if allowed != nil && !allowed(v) {
+				continue
+			}
+
+			select {
+			case v.eventC <- wsEvent{cmd: cmd, payload: payload}:
+			default:
+				log.Debugf("websocketPush: dropped %v for %v",
+					cmd, v)
+			}
+		}
+	}
+}
+
+// websocketEvent pushes an event manager event to the subscribed websocket
+// clients.
+func (p *politeiawww) websocketEvent(data interface{}) {
+	switch d := data.(type) {
+	case EventDataProposalSubmitted:
+		// Unvetted proposals are only pushed to admins and to the
+		// author.
+		p.websocketPushTo(v1.WSCNewProposal, v1.WSCNewProposal,
+			v1.WSNewProposal{
+				Token: d.CensorshipRecord.Token,
+			}, func(wc *wsContext) bool {
+				return wc.admin || (d.User != nil &&
+					wc.uuid == d.User.ID.String())
+			})
+	case EventDataProposalStatusChange:
+		ps := v1.WSProposalStatus{
+			Token:  d.Proposal.CensorshipRecord.Token,
+			Status: d.SetProposalStatus.ProposalStatus,
+		}
+		switch ps.Status {
+		case v1.PropStatusPublic:
+			ps.Name = d.Proposal.Name
+			p.websocketPush(v1.WSCProposalStatus, v1.WSCProposalStatus,
+				ps)
+		case v1.PropStatusAbandoned:
+			p.websocketPush(v1.WSCProposalStatus, v1.WSCProposalStatus,
+				ps)
+		default:
+			// The status changes of unvetted proposals would reveal
+			// their tokens and are only pushed to admins and to the
+			// author.
+			p.websocketPushTo(v1.WSCProposalStatus,
+				v1.WSCProposalStatus, ps, func(wc *wsContext) bool {
+					return wc.admin ||
+						(wc.uuid != "" && wc.uuid == d.Proposal.UserId)
+				})
+		}
+	case EventDataProposalVoteStarted:
+		token := d.StartVote.Vote.Token
+		ir, err := p.backend.getInventoryRecord(token)
+		if err != nil {
+			log.Errorf("websocketEvent: %v", err)
+			return
+		}
+		p.websocketPush(v1.WSCVoteStarted, v1.WSCVoteStarted,
+			v1.WSVoteStarted{
+				Token:            token,
+				Name:             ir.proposalMD.Name,
+				StartBlockHeight: ir.voting.StartBlockHeight,
+				EndHeight:        ir.voting.EndHeight,
+				Options:          d.StartVote.Vote.Options,
+			})
+	case EventDataComment:
+		p.websocketPush(util.WSSubscription(v1.WSCNewComment,
+			d.Comment.Token), v1.WSCNewComment, v1.WSNewComment{
+			Comment: *d.Comment,
+		})
+	case EventDataBallotCast:
+		for _, tally := range p.backend.ballotTallies(d.Votes) {
+			p.websocketPush(util.WSSubscription(v1.WSCVoteTally,
+				tally.Token), v1.WSCVoteTally, tally)
+		}
+	}
+}
+
+// setupWebsocketEvents feeds the event manager events to the websocket
+// clients.
+func (p *politeiawww) setupWebsocketEvents() {
+	ch := make(chan interface{})
+	go func() {
+		for data := range ch {
+			p.websocketEvent(data)
+		}
+	}()
+	p.backend.registerListener(ch,
+		EventTypeProposalSubmitted,
+		EventTypeProposalStatusChange,
+		EventTypeProposalVoteStarted,
+		EventTypeComment,
+		EventTypeBallotCast,
+	)
+}
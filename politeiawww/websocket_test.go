@@ -0,0 +1,298 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/decred/politeia/decredplugin"
+	pd "github.com/decred/politeia/politeiad/api/v1"
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/politeiawww/database"
+	"github.com/decred/politeia/util"
+	"github.com/google/uuid"
+)
+
+// Tests that events are only pushed to the websocket clients that subscribed
+// to them and that ballots are pushed as per option tallies.
+func TestWebsocketEvents(t *testing.T) {
+	b := createBackend(t)
+	defer b.db.Close()
+
+	token := "0000000000000000000000000000000000000000000000000000000000000000"
+	options := []www.VoteOption{
+		{Id: "no", Bits: 1},
+		{Id: "yes", Bits: 2},
+	}
+	b.inventory[token] = &inventoryRecord{
+		record: pd.Record{
+			CensorshipRecord: pd.CensorshipRecord{
+				Token: token,
+			},
+		},
+		votebits: www.StartVote{
+			Vote: www.Vote{
+				Token:            token,
+				QuorumPercentage: 20,
+				Options:          options,
+			},
+		},
+		voting: www.StartVoteReply{
+			StartBlockHeight: "100",
+			EndHeight:        "120",
+			EligibleTickets:  make([]string, 10),
+		},
+	}
+	b.bestBlock = 110
+	b.bestBlockFetched = time.Now()
+	b.voteTallies[token] = &voteTally{
+		tickets: map[string]uint64{"a": 1},
+		votes:   map[uint64]uint64{1: 1},
+		synced:  time.Now(),
+	}
+
+	newContext := func(subscriptions ...string) *wsContext {
+		wc := wsContext{
+			subscriptions: make(map[string]struct{}),
+			eventC:        make(chan wsEvent, wsEventBufferSize),
+		}
+		for _, v := range subscriptions {
+			wc.subscriptions[v] = struct{}{}
+		}
+		return &wc
+	}
+	subscribed := newContext(util.WSSubscription(www.WSCNewComment, token),
+		util.WSSubscription(www.WSCVoteTally, token))
+	other := newContext(www.WSCPing, www.WSCProposalStatus)
+	p := &politeiawww{
+		ws: map[string]map[string]*wsContext{
+			"": {
+				"1": subscribed,
+				"2": other,
+			},
+		},
+		backend: b,
+	}
+
+	p.websocketEvent(EventDataComment{
+		Comment: &www.Comment{
+			Token:     token,
+			CommentID: "1",
+		},
+	})
+	votes := []www.CastVote{
+		{Token: token, Ticket: "b", VoteBit: "2"},
+		{Token: token, Ticket: "c", VoteBit: "2"},
+		{Token: token, Ticket: "d", VoteBit: "1"},
+	}
+	b.countBallot(votes)
+	p.websocketEvent(EventDataBallotCast{
+		Votes: votes,
+	})
+
+	e := <-subscribed.eventC
+	nc, ok := e.payload.(www.WSNewComment)
+	if e.cmd != www.WSCNewComment || !ok || nc.Comment.CommentID != "1" {
+		t.Fatalf("unexpected comment push %v", e)
+	}
+	e = <-subscribed.eventC
+	vt, ok := e.payload.(www.WSVoteTally)
+	if e.cmd != www.WSCVoteTally || !ok || len(vt.Votes) != 2 ||
+		vt.Votes[0].VotesReceived != 1 || vt.Votes[1].VotesReceived != 2 {
+		t.Fatalf("unexpected tally push %v", e)
+	}
+	if vt.TotalVotes != 4 || vt.OptionsResult[0].VotesReceived != 2 ||
+		vt.OptionsResult[1].VotesReceived != 2 || vt.QuorumVotes != 2 ||
+		vt.NumOfEligibleVotes != 10 || vt.BlocksRemaining != 10 {
+		t.Fatalf("unexpected tally totals %v", vt)
+	}
+	if len(other.eventC) != 0 {
+		t.Fatalf("unexpected push to unsubscribed client")
+	}
+}
+
+// Tests that new proposals and the status changes of unvetted proposals are
+// only pushed to admins and to the author of the proposal.
+func TestWebsocketProposalStatus(t *testing.T) {
+	newContext := func(id string, admin bool) *wsContext {
+		return &wsContext{
+			uuid:  id,
+			admin: admin,
+			subscriptions: map[string]struct{}{
+				www.WSCNewProposal:    {},
+				www.WSCProposalStatus: {},
+			},
+			eventC: make(chan wsEvent, wsEventBufferSize),
+		}
+	}
+	authorID := uuid.New()
+	anon := newContext("", false)
+	user := newContext("user", false)
+	author := newContext(authorID.String(), false)
+	admin := newContext("admin", true)
+	p := &politeiawww{
+		ws: map[string]map[string]*wsContext{
+			"":                {"1": anon},
+			"user":            {"2": user},
+			authorID.String(): {"3": author},
+			"admin":           {"4": admin},
+		},
+	}
+
+	token := "0000000000000000000000000000000000000000000000000000000000000000"
+	p.websocketEvent(EventDataProposalSubmitted{
+		CensorshipRecord: &www.CensorshipRecord{
+			Token: token,
+		},
+		User: &database.User{
+			ID: authorID,
+		},
+	})
+	for _, v := range []*wsContext{anon, user} {
+		if len(v.eventC) != 0 {
+			t.Fatalf("new proposal pushed to %v", v)
+		}
+	}
+	for _, v := range []*wsContext{author, admin} {
+		e := <-v.eventC
+		np, ok := e.payload.(www.WSNewProposal)
+		if !ok || np.Token != token {
+			t.Fatalf("unexpected new proposal push %v", e)
+		}
+	}
+
+	statusChange := func(status www.PropStatusT) {
+		p.websocketEvent(EventDataProposalStatusChange{
+			Proposal: &www.ProposalRecord{
+				Name:   "proposal",
+				UserId: authorID.String(),
+				CensorshipRecord: www.CensorshipRecord{
+					Token: token,
+				},
+			},
+			SetProposalStatus: &www.SetProposalStatus{
+				ProposalStatus: status,
+			},
+		})
+	}
+
+	statusChange(www.PropStatusCensored)
+	for _, v := range []*wsContext{anon, user} {
+		if len(v.eventC) != 0 {
+			t.Fatalf("unvetted status pushed to %v", v)
+		}
+	}
+	for _, v := range []*wsContext{author, admin} {
+		e := <-v.eventC
+		ps, ok := e.payload.(www.WSProposalStatus)
+		if !ok || ps.Token != token || ps.Status != www.PropStatusCensored ||
+			ps.Name != "" {
+			t.Fatalf("unexpected status push %v", e)
+		}
+	}
+
+	statusChange(www.PropStatusPublic)
+	for _, v := range []*wsContext{anon, user, author, admin} {
+		e := <-v.eventC
+		ps, ok := e.payload.(www.WSProposalStatus)
+		if !ok || ps.Status != www.PropStatusPublic ||
+			ps.Name != "proposal" {
+			t.Fatalf("unexpected status push %v", e)
+		}
+	}
+}
+
+// Tests that the votes of a ballot that politeiad accepted are pushed to the
+// clients that subscribed to the vote tally.
+func TestWebsocketBallotCast(t *testing.T) {
+	b := createBackend(t)
+	defer b.db.Close()
+
+	server, _ := setupTestPoliteiad(t, b, func(pc pd.PluginCommand) (string, error) {
+		if pc.Command != decredplugin.CmdBallot {
+			return "", fmt.Errorf("unexpected command %v", pc.Command)
+		}
+		ballot, err := decredplugin.DecodeBallot([]byte(pc.Payload))
+		if err != nil {
+			return "", err
+		}
+		// The last vote is rejected.
+		var br decredplugin.BallotReply
+		for k, v := range ballot.Votes {
+			r := decredplugin.CastVoteReply{
+				ClientSignature: v.Signature,
+			}
+			if k == len(ballot.Votes)-1 {
+				r.Error = "invalid ticket"
+			}
+			br.Receipts = append(br.Receipts, r)
+		}
+		reply, err := decredplugin.EncodeBallotReply(br)
+		return string(reply), err
+	})
+	defer server.Close()
+
+	token := "0000000000000000000000000000000000000000000000000000000000000000"
+	b.inventory[token] = &inventoryRecord{
+		record: pd.Record{
+			CensorshipRecord: pd.CensorshipRecord{
+				Token: token,
+			},
+		},
+		votebits: www.StartVote{
+			Vote: www.Vote{
+				Token: token,
+				Options: []www.VoteOption{
+					{Id: "no", Bits: 1},
+					{Id: "yes", Bits: 2},
+				},
+			},
+		},
+		voting: www.StartVoteReply{
+			StartBlockHeight: "100",
+			EndHeight:        "120",
+		},
+	}
+	b.bestBlock = 110
+	b.bestBlockFetched = time.Now()
+	b.voteTallies[token] = &voteTally{
+		tickets: make(map[string]uint64),
+		votes:   make(map[uint64]uint64),
+		synced:  time.Now(),
+	}
+
+	subscribed := &wsContext{
+		subscriptions: map[string]struct{}{
+			util.WSSubscription(www.WSCVoteTally, token): {},
+		},
+		eventC: make(chan wsEvent, wsEventBufferSize),
+	}
+	p := &politeiawww{
+		ws: map[string]map[string]*wsContext{
+			"": {"1": subscribed},
+		},
+		backend: b,
+	}
+	p.setupWebsocketEvents()
+
+	_, err := b.ProcessCastVotes(&www.Ballot{
+		Votes: []www.CastVote{
+			{Token: token, Ticket: "a", VoteBit: "2", Signature: "a"},
+			{Token: token, Ticket: "b", VoteBit: "2", Signature: "b"},
+			{Token: token, Ticket: "c", VoteBit: "1", Signature: "c"},
+		},
+	})
+	assertSuccess(t, err)
+
+	select {
+	case e := <-subscribed.eventC:
+		vt, ok := e.payload.(www.WSVoteTally)
+		if e.cmd != www.WSCVoteTally || !ok || vt.TotalVotes != 2 ||
+			vt.Votes[0].VotesReceived != 0 ||
+			vt.Votes[1].VotesReceived != 2 {
+			t.Fatalf("unexpected tally push %v", e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("ballot was not pushed")
+	}
+}
@@ -38,6 +38,9 @@ const (
 	permissionPublic permission = iota
 	permissionLogin
 	permissionAdmin
+	permissionModerator
+	permissionReviewer
+	permissionVoteAdmin
 
 	csrfKeyLength = 32
 	sessionMaxAge = 86400 //One day
@@ -53,6 +56,8 @@ type wsContext struct {
 	subscriptions map[string]struct{}
 	errorC        chan v1.WSError
 	pingC         chan struct{}
+	eventC        chan wsEvent  // Server side pushes
+	admin         bool          // Whether the user is an admin
 	done          chan struct{} // SHUT...DOWN...EVERYTHING...
 }
 
@@ -73,7 +78,8 @@ type politeiawww struct {
 	cfg    *config
 	router *mux.Router
 
-	store *sessions.FilesystemStore
+	store          sessions.Store
+	sessionOptions sessions.Options // Default session options
 
 	ws    map[string]map[string]*wsContext // [uuid][]*context
 	wsMtx sync.RWMutex
@@ -87,8 +93,13 @@ func (p *politeiawww) getSession(r *http.Request) (*sessions.Session, error) {
 }
 
 // getSessionUUID returns the uuid address of the currently logged in user from
-// the session store.
+// the session store.  Requests that were authenticated with an API key return
+// the owner of the key instead.
 func (p *politeiawww) getSessionUUID(r *http.Request) (string, error) {
+	if id, ok := r.Context().Value(contextKeyAPIKeyUserID).(string); ok {
+		return id, nil
+	}
+
 	session, err := p.getSession(r)
 	if err != nil {
 		return "", err
@@ -101,9 +112,29 @@ func (p *politeiawww) getSessionUUID(r *http.Request) (string, error) {
 	}
 	log.Tracef("getSessionUUID: %v", session.ID)
 
+	// Sessions that have been revoked or that have expired are treated
+	// as logged out.
+	ok, err = p.backend.touchSession(hashSessionID(session.ID), id)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", nil
+	}
+
 	return id, nil
 }
 
+// getSessionID returns the id of the active cookie session as it is stored in
+// the database.  An empty string is returned if there is no session.
+func (p *politeiawww) getSessionID(r *http.Request) string {
+	session, err := p.getSession(r)
+	if err != nil || session.ID == "" {
+		return ""
+	}
+	return hashSessionID(session.ID)
+}
+
 // getSessionUser retrieves the current session user from the database.
 func (p *politeiawww) getSessionUser(w http.ResponseWriter, r *http.Request) (*database.User, error) {
 	id, err := p.getSessionUUID(r)
@@ -140,7 +171,13 @@ func (p *politeiawww) setSessionUserID(w http.ResponseWriter, r *http.Request, i
 	}
 
 	session.Values["uuid"] = id
-	return session.Save(r, w)
+	err = session.Save(r, w)
+	if err != nil {
+		return err
+	}
+
+	return p.backend.newSession(hashSessionID(session.ID), id,
+		remoteAddr(r), r.UserAgent())
 }
 
 // removeSession deletes the session from the filesystem.
@@ -156,22 +193,17 @@ func (p *politeiawww) removeSession(w http.ResponseWriter, r *http.Request) erro
 		return nil
 	}
 
+	err = p.backend.db.SessionDelete(hashSessionID(session.ID))
+	if err != nil && err != database.ErrSessionNotFound {
+		return err
+	}
+
 	// Saving the session with a negative MaxAge will cause it to be deleted
-	// from the filesystem.
+	// from the store.
 	session.Options.MaxAge = -1
 	return session.Save(r, w)
 }
 
-// isAdmin returns true if the current session has admin privileges.
-func (p *politeiawww) isAdmin(w http.ResponseWriter, r *http.Request) (bool, error) {
-	user, err := p.getSessionUser(w, r)
-	if err != nil {
-		return false, err
-	}
-
-	return user.Admin, nil
-}
-
 // Fetch remote identity
 func (p *politeiawww) getIdentity() error {
 	id, err := util.RemoteIdentity(false, p.cfg.RPCHost, p.cfg.RPCCert)
@@ -250,13 +282,15 @@ func RespondWithError(w http.ResponseWriter, r *http.Request, userHttpCode int,
 	}
 
 	if pdError, ok := args[0].(v1.PDError); ok {
-		pdErrorCode := convertErrorStatusFromPD(pdError.ErrorReply.ErrorCode)
+		pdErrorCode := convertErrorStatusFromPD(pdError.ErrorReply)
 		if pdErrorCode == v1.ErrorStatusInvalid {
 			errorCode := time.Now().Unix()
 			log.Errorf("%v %v %v %v Internal error %v: error "+
-				"code from politeiad: %v", remoteAddr(r),
+				"code from politeiad: %v %v %v", remoteAddr(r),
 				r.Method, r.URL, r.Proto, errorCode,
-				pdError.ErrorReply.ErrorCode)
+				pdError.ErrorReply.ErrorCode,
+				pdError.ErrorReply.PluginID,
+				pdError.ErrorReply.PluginErrorCode)
 			util.RespondWithJSON(w, http.StatusInternalServerError,
 				v1.ErrorReply{
 					ErrorCode: errorCode,
@@ -305,7 +339,7 @@ func (p *politeiawww) handleVersion(w http.ResponseWriter, r *http.Request) {
 	if err != nil && session != nil {
 		// Create and save a new session for the user.
 		session := sessions.NewSession(p.store, v1.CookieSession)
-		opts := *p.store.Options
+		opts := p.sessionOptions
 		session.Options = &opts
 		session.IsNew = true
 		err = session.Save(r, w)
@@ -505,6 +539,70 @@ func (p *politeiawww) handleLogin(w http.ResponseWriter, r *http.Request) {
 	util.RespondWithJSON(w, http.StatusOK, reply)
 }
 
+// handleLoginChallenge issues a challenge that must be signed with the active
+// identity of the user in order to login without a password.
+func (p *politeiawww) handleLoginChallenge(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleLoginChallenge")
+
+	var lc v1.LoginChallenge
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&lc); err != nil {
+		RespondWithError(w, r, 0, "handleLoginChallenge: unmarshal",
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	reply, err := p.backend.ProcessLoginChallenge(&lc)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleLoginChallenge: ProcessLoginChallenge %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, reply)
+}
+
+// handleLoginSignature handles the incoming login signature command.  It
+// verifies that the login challenge was signed by the active identity of the
+// user.  On success a cookie is added to the gorilla sessions that must be
+// returned on subsequent calls.
+func (p *politeiawww) handleLoginSignature(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleLoginSignature")
+
+	var ls v1.LoginSignature
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&ls); err != nil {
+		RespondWithError(w, r, 0, "handleLoginSignature: unmarshal",
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	reply, err := p.backend.ProcessLoginSignature(ls)
+	if err != nil {
+		RespondWithError(w, r, http.StatusUnauthorized,
+			"handleLoginSignature: ProcessLoginSignature %v", err)
+		return
+	}
+
+	// Mark user as logged in if there's no error.
+	err = p.setSessionUserID(w, r, reply.UserID)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleLoginSignature: setSessionUser %v", err)
+		return
+	}
+
+	// Set session max age
+	reply.SessionMaxAge = sessionMaxAge
+
+	// Reply with the user information.
+	util.RespondWithJSON(w, http.StatusOK, reply)
+}
+
 // handleLogout logs the user out.
 func (p *politeiawww) handleLogout(w http.ResponseWriter, r *http.Request) {
 	log.Tracef("handleLogout")
@@ -618,6 +716,14 @@ func (p *politeiawww) handleChangePassword(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	// Log out all other sessions of the user.
+	err = p.backend.revokeSessions(user.ID, p.getSessionID(r))
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleChangePassword: revokeSessions %v", err)
+		return
+	}
+
 	// Reply with the error code.
 	util.RespondWithJSON(w, http.StatusOK, reply)
 }
@@ -807,6 +913,15 @@ func (p *politeiawww) handleWebsocketWrite(wc *wsContext) {
 			cmd = v1.WSCPing
 			id = ""
 			payload = v1.WSPing{Timestamp: time.Now().Unix()}
+		case e, ok := <-wc.eventC:
+			if !ok {
+				log.Tracef("handleWebsocketWrite event not ok"+
+					" %v", wc)
+				return
+			}
+			cmd = e.cmd
+			id = ""
+			payload = e.payload
 		}
 
 		err := util.WSWrite(wc.conn, cmd, id, payload)
@@ -817,16 +932,18 @@ func (p *politeiawww) handleWebsocketWrite(wc *wsContext) {
 	}
 }
 
-func (p *politeiawww) handleWebsocket(w http.ResponseWriter, r *http.Request, id string) {
+func (p *politeiawww) handleWebsocket(w http.ResponseWriter, r *http.Request, id string, admin bool) {
 	log.Tracef("handleWebsocket: %v", id)
 	defer log.Tracef("handleWebsocket exit: %v", id)
 
 	// Setup context
 	wc := wsContext{
 		uuid:          id,
+		admin:         admin,
 		subscriptions: make(map[string]struct{}),
 		pingC:         make(chan struct{}),
 		errorC:        make(chan v1.WSError),
+		eventC:        make(chan wsEvent, wsEventBufferSize),
 		done:          make(chan struct{}),
 	}
 
@@ -872,11 +989,6 @@ func (p *politeiawww) handleWebsocket(w http.ResponseWriter, r *http.Request, id
 	wc.wg.Add(1)
 	go p.handleWebsocketWrite(&wc)
 
-	// XXX Example of a server side notifcation. Remove once other commands
-	// can be used as examples.
-	// time.Sleep(2 * time.Second)
-	// p.websocketPing(id)
-
 	wc.wg.Wait()
 
 	// Remove session id
@@ -905,21 +1017,22 @@ func (p *politeiawww) handleUnauthenticatedWebsocket(w http.ResponseWriter, r *h
 	log.Tracef("handleUnauthenticatedWebsocket: %v", id)
 	defer log.Tracef("handleUnauthenticatedWebsocket exit: %v", id)
 
-	p.handleWebsocket(w, r, id)
+	p.handleWebsocket(w, r, id, false)
 }
 
 func (p *politeiawww) handleAuthenticatedWebsocket(w http.ResponseWriter, r *http.Request) {
-	id, err := p.getSessionUUID(r)
+	user, err := p.getSessionUser(w, r)
 	if err != nil {
-		http.Error(w, "Could not get session uuid",
+		http.Error(w, "Could not get session user",
 			http.StatusBadRequest)
 		return
 	}
+	id := user.ID.String()
 
 	log.Tracef("handleAuthenticatedWebsocket: %v", id)
 	defer log.Tracef("handleAuthenticatedWebsocket exit: %v", id)
 
-	p.handleWebsocket(w, r, id)
+	p.handleWebsocket(w, r, id, user.Admin)
 }
 
 // handleNewProposal handles the incoming new proposal command.
@@ -1026,6 +1139,181 @@ func (p *politeiawww) handleProposalDetails(w http.ResponseWriter, r *http.Reque
 	util.RespondWithJSON(w, http.StatusOK, reply)
 }
 
+// handleProposalBundle returns a public proposal together with its entire
+// discussion and all server receipts.
+func (p *politeiawww) handleProposalBundle(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleProposalBundle")
+
+	pathParams := mux.Vars(r)
+	token := pathParams["token"]
+
+	reply, err := p.backend.ProcessProposalBundle(token)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleProposalBundle: ProcessProposalBundle %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, reply)
+}
+
+// handleCensorshipReport returns the public record of all proposal and
+// comment censorship actions as either JSON or CSV.
+func (p *politeiawww) handleCensorshipReport(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleCensorshipReport")
+
+	var cr v1.CensorshipReport
+	err := util.ParseGetParams(r, &cr)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleCensorshipReport: ParseGetParams",
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	reply, err := p.backend.ProcessCensorshipReport(cr)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleCensorshipReport: ProcessCensorshipReport %v", err)
+		return
+	}
+
+	if cr.Format != v1.CensorshipReportFormatCSV {
+		util.RespondWithJSON(w, http.StatusOK, reply)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition",
+		"attachment; filename=censorship.csv")
+	w.WriteHeader(http.StatusOK)
+	err = writeCensorshipReportCSV(w, reply)
+	if err != nil {
+		log.Errorf("handleCensorshipReport: writeCensorshipReportCSV: %v",
+			err)
+	}
+}
+
+// handleProposalsFeed returns the feed of the newest published proposals.
+func (p *politeiawww) handleProposalsFeed(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleProposalsFeed")
+
+	var f v1.Feed
+	err := util.ParseGetParams(r, &f)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleProposalsFeed: ParseGetParams",
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	feed, err := p.backend.ProcessProposalsFeed(f)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleProposalsFeed: ProcessProposalsFeed %v", err)
+		return
+	}
+
+	respondWithFeed(w, r, feed, f.Format)
+}
+
+// handleVotingFeed returns the feed of the proposals whose vote started.
+func (p *politeiawww) handleVotingFeed(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleVotingFeed")
+
+	var f v1.Feed
+	err := util.ParseGetParams(r, &f)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleVotingFeed: ParseGetParams",
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	feed, err := p.backend.ProcessVotingFeed(f)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleVotingFeed: ProcessVotingFeed %v", err)
+		return
+	}
+
+	respondWithFeed(w, r, feed, f.Format)
+}
+
+// handleVoteResultsFeed returns the feed of the finished proposal votes and their results.
+func (p *politeiawww) handleVoteResultsFeed(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleVoteResultsFeed")
+
+	var f v1.Feed
+	err := util.ParseGetParams(r, &f)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleVoteResultsFeed: ParseGetParams",
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	feed, err := p.backend.ProcessVoteResultsFeed(f)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleVoteResultsFeed: ProcessVoteResultsFeed %v", err)
+		return
+	}
+
+	respondWithFeed(w, r, feed, f.Format)
+}
+
+// handleCommentsFeed returns the feed of the newest comments of a proposal.
+func (p *politeiawww) handleCommentsFeed(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleCommentsFeed")
+
+	pathParams := mux.Vars(r)
+	token := pathParams["token"]
+
+	var f v1.Feed
+	err := util.ParseGetParams(r, &f)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleCommentsFeed: ParseGetParams",
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	feed, err := p.backend.ProcessCommentsFeed(token, f)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleCommentsFeed: ProcessCommentsFeed %v", err)
+		return
+	}
+
+	respondWithFeed(w, r, feed, f.Format)
+}
+
+// handleVoteInclusionProof returns the proof that a ticket vote was included
+// in the politeiad repository and anchored in dcrtime.
+func (p *politeiawww) handleVoteInclusionProof(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleVoteInclusionProof")
+
+	pathParams := mux.Vars(r)
+	token := pathParams["token"]
+	ticket := pathParams["ticket"]
+
+	reply, err := p.backend.ProcessVoteInclusionProof(token, ticket)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleVoteInclusionProof: ProcessVoteInclusionProof %v",
+			err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, reply)
+}
+
 func (p *politeiawww) handlePolicy(w http.ResponseWriter, r *http.Request) {
 	// Get the policy command.
 	log.Tracef("handlePolicy")
@@ -1165,6 +1453,84 @@ func (p *politeiawww) handleCensorComment(w http.ResponseWriter, r *http.Request
 	util.RespondWithJSON(w, http.StatusOK, cr)
 }
 
+// handleReportComment handles the reporting of a comment by a user.
+func (p *politeiawww) handleReportComment(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleReportComment")
+
+	var rc v1.ReportComment
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&rc); err != nil {
+		RespondWithError(w, r, 0, "handleReportComment: unmarshal",
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	user, err := p.getSessionUser(w, r)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleReportComment: getSessionUser %v", err)
+		return
+	}
+
+	rcr, err := p.backend.ProcessReportComment(rc, user)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleReportComment: ProcessReportComment %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, rcr)
+}
+
+// handleReportedComments returns the comment moderation queue.
+func (p *politeiawww) handleReportedComments(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleReportedComments")
+
+	rcr, err := p.backend.ProcessReportedComments()
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleReportedComments: ProcessReportedComments %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, rcr)
+}
+
+// handleResolveCommentReport handles the resolution of comment reports by an
+// admin.
+func (p *politeiawww) handleResolveCommentReport(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleResolveCommentReport")
+
+	var rcr v1.ResolveCommentReport
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&rcr); err != nil {
+		RespondWithError(w, r, 0, "handleResolveCommentReport: unmarshal",
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	user, err := p.getSessionUser(w, r)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleResolveCommentReport: getSessionUser %v", err)
+		return
+	}
+
+	reply, err := p.backend.ProcessResolveCommentReport(rcr, user)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleResolveCommentReport: ProcessResolveCommentReport %v",
+			err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, reply)
+}
+
 // handleCommentsGet handles batched comments get.
 func (p *politeiawww) handleCommentsGet(w http.ResponseWriter, r *http.Request) {
 	log.Tracef("handleCommentsGet")
@@ -1304,7 +1670,7 @@ func (p *politeiawww) handleUserProposals(w http.ResponseWriter, r *http.Request
 	upr, err := p.backend.ProcessUserProposals(
 		&up,
 		user != nil && user.ID == userId,
-		user != nil && user.Admin)
+		user != nil && userHasRole(user, v1.UserRoleReviewer))
 	if err != nil {
 		RespondWithError(w, r, 0,
 			"handleUserProposals: ProcessUserProposals %v", err)
@@ -1416,9 +1782,9 @@ func (p *politeiawww) handleStartVote(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Sanity
-	if !user.Admin {
+	if !userHasRole(user, v1.UserRoleVoteAdmin) {
 		RespondWithError(w, r, 0,
-			"handleStartVote: admin %v", user.Admin)
+			"handleStartVote: roles %v", userRoles(user))
 		return
 	}
 
@@ -1457,7 +1823,7 @@ func (p *politeiawww) handleUserDetails(w http.ResponseWriter, r *http.Request)
 
 	udr, err := p.backend.ProcessUserDetails(&ud,
 		user != nil && user.ID == userID,
-		user != nil && user.Admin,
+		user != nil && userHasRole(user, v1.UserRoleSuperAdmin),
 	)
 
 	if err != nil {
@@ -1494,6 +1860,178 @@ func (p *politeiawww) handleUsers(w http.ResponseWriter, r *http.Request) {
 	util.RespondWithJSON(w, http.StatusOK, ur)
 }
 
+// handleAdminLog handles queries of the admin audit log.
+func (p *politeiawww) handleAdminLog(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleAdminLog")
+
+	var al v1.AdminLog
+	err := util.ParseGetParams(r, &al)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleAdminLog: ParseGetParams",
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	alr, err := p.backend.ProcessAdminLog(&al)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleAdminLog: ProcessAdminLog %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, alr)
+}
+
+// handleEmailOutbox returns the emails that have not been delivered yet.
+func (p *politeiawww) handleEmailOutbox(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleEmailOutbox")
+
+	var eo v1.EmailOutbox
+	err := util.ParseGetParams(r, &eo)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleEmailOutbox: ParseGetParams",
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	eor, err := p.backend.ProcessEmailOutbox(eo)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleEmailOutbox: ProcessEmailOutbox %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, eor)
+}
+
+// handleEmailTemplates renders a preview of every email template.
+func (p *politeiawww) handleEmailTemplates(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleEmailTemplates")
+
+	var et v1.EmailTemplates
+	err := util.ParseGetParams(r, &et)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleEmailTemplates: ParseGetParams",
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	etr, err := p.backend.ProcessEmailTemplates(et)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleEmailTemplates: ProcessEmailTemplates %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, etr)
+}
+
+// handleWebhooks returns the registered webhooks.
+func (p *politeiawww) handleWebhooks(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleWebhooks")
+
+	wr, err := p.backend.ProcessWebhooks()
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleWebhooks: ProcessWebhooks %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, wr)
+}
+
+// handleNewWebhook registers a new webhook.
+func (p *politeiawww) handleNewWebhook(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleNewWebhook")
+
+	var nw v1.NewWebhook
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&nw); err != nil {
+		RespondWithError(w, r, 0, "handleNewWebhook: unmarshal",
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	adminUser, err := p.getSessionUser(w, r)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleNewWebhook: getSessionUser %v",
+			err)
+		return
+	}
+
+	nwr, err := p.backend.ProcessNewWebhook(nw, adminUser)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleNewWebhook: ProcessNewWebhook %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, nwr)
+}
+
+// handleDeleteWebhook deletes a webhook and its delivery log.
+func (p *politeiawww) handleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleDeleteWebhook")
+
+	var dw v1.DeleteWebhook
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&dw); err != nil {
+		RespondWithError(w, r, 0, "handleDeleteWebhook: unmarshal",
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	adminUser, err := p.getSessionUser(w, r)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleDeleteWebhook: getSessionUser %v",
+			err)
+		return
+	}
+
+	dwr, err := p.backend.ProcessDeleteWebhook(dw, adminUser)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleDeleteWebhook: ProcessDeleteWebhook %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, dwr)
+}
+
+// handleWebhookDeliveries returns the delivery log of a webhook.
+func (p *politeiawww) handleWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleWebhookDeliveries")
+
+	var wd v1.WebhookDeliveries
+	err := util.ParseGetParams(r, &wd)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleWebhookDeliveries: ParseGetParams",
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	wdr, err := p.backend.ProcessWebhookDeliveries(wd)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleWebhookDeliveries: ProcessWebhookDeliveries %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, wdr)
+}
+
 // handleManageUser handles editing a user's details.
 func (p *politeiawww) handleManageUser(w http.ResponseWriter, r *http.Request) {
 	log.Tracef("handleManageUser")
@@ -1552,6 +2090,201 @@ func (p *politeiawww) handleEditUser(w http.ResponseWriter, r *http.Request) {
 	util.RespondWithJSON(w, http.StatusOK, eur)
 }
 
+// handleSetTOTP generates a new TOTP secret for the logged in user.
+func (p *politeiawww) handleSetTOTP(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleSetTOTP")
+
+	var st v1.SetTOTP
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&st); err != nil {
+		RespondWithError(w, r, 0, "handleSetTOTP: unmarshal", v1.UserError{
+			ErrorCode: v1.ErrorStatusInvalidInput,
+		})
+		return
+	}
+
+	user, err := p.getSessionUser(w, r)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleSetTOTP: getSessionUser %v", err)
+		return
+	}
+
+	str, err := p.backend.ProcessSetTOTP(user)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleSetTOTP: ProcessSetTOTP %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, str)
+}
+
+// handleVerifyTOTP enables two-factor authentication for the logged in user.
+func (p *politeiawww) handleVerifyTOTP(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleVerifyTOTP")
+
+	var vt v1.VerifyTOTP
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&vt); err != nil {
+		RespondWithError(w, r, 0, "handleVerifyTOTP: unmarshal",
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	user, err := p.getSessionUser(w, r)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleVerifyTOTP: getSessionUser %v",
+			err)
+		return
+	}
+
+	vtr, err := p.backend.ProcessVerifyTOTP(&vt, user)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleVerifyTOTP: ProcessVerifyTOTP %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, vtr)
+}
+
+// handleNewAPIKey creates a new API key for the logged in user.
+func (p *politeiawww) handleNewAPIKey(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleNewAPIKey")
+
+	var nak v1.NewAPIKey
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&nak); err != nil {
+		RespondWithError(w, r, 0, "handleNewAPIKey: unmarshal",
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	user, err := p.getSessionUser(w, r)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleNewAPIKey: getSessionUser %v",
+			err)
+		return
+	}
+
+	nakr, err := p.backend.ProcessNewAPIKey(&nak, user)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleNewAPIKey: ProcessNewAPIKey %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, nakr)
+}
+
+// handleAPIKeys returns the API keys of the logged in user.
+func (p *politeiawww) handleAPIKeys(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleAPIKeys")
+
+	user, err := p.getSessionUser(w, r)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleAPIKeys: getSessionUser %v", err)
+		return
+	}
+
+	akr, err := p.backend.ProcessAPIKeys(user)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleAPIKeys: ProcessAPIKeys %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, akr)
+}
+
+// handleSessions returns the active sessions of the logged in user.
+func (p *politeiawww) handleSessions(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleSessions")
+
+	user, err := p.getSessionUser(w, r)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleSessions: getSessionUser %v", err)
+		return
+	}
+
+	reply, err := p.backend.ProcessSessions(user, p.getSessionID(r))
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleSessions: ProcessSessions %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, reply)
+}
+
+// handleRevokeSessions logs out sessions of the logged in user.
+func (p *politeiawww) handleRevokeSessions(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleRevokeSessions")
+
+	var rs v1.RevokeSessions
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&rs); err != nil {
+		RespondWithError(w, r, 0, "handleRevokeSessions: unmarshal",
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	user, err := p.getSessionUser(w, r)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleRevokeSessions: getSessionUser %v", err)
+		return
+	}
+
+	reply, err := p.backend.ProcessRevokeSessions(&rs, user,
+		p.getSessionID(r))
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleRevokeSessions: ProcessRevokeSessions %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, reply)
+}
+
+// handleRevokeAPIKey deletes an API key of the logged in user.
+func (p *politeiawww) handleRevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleRevokeAPIKey")
+
+	var rak v1.RevokeAPIKey
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&rak); err != nil {
+		RespondWithError(w, r, 0, "handleRevokeAPIKey: unmarshal",
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	user, err := p.getSessionUser(w, r)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleRevokeAPIKey: getSessionUser %v",
+			err)
+		return
+	}
+
+	rakr, err := p.backend.ProcessRevokeAPIKey(&rak, user)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleRevokeAPIKey: ProcessRevokeAPIKey %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, rakr)
+}
+
 // handleGetAllVoteStatus returns the voting status of all public proposals.
 func (p *politeiawww) handleGetAllVoteStatus(w http.ResponseWriter, r *http.Request) {
 	gasvr, err := p.backend.ProcessGetAllVoteStatus()
@@ -1666,12 +2399,24 @@ func (p *politeiawww) addRoute(method string, route string, handler http.Handler
 	switch perm {
 	case permissionAdmin:
 		handler = logging(p.isLoggedInAsAdmin(handler))
+	case permissionModerator:
+		handler = logging(p.isLoggedInWithRole(v1.UserRoleModerator,
+			handler))
+	case permissionReviewer:
+		handler = logging(p.isLoggedInWithRole(v1.UserRoleReviewer,
+			handler))
+	case permissionVoteAdmin:
+		handler = logging(p.isLoggedInWithRole(v1.UserRoleVoteAdmin,
+			handler))
 	case permissionLogin:
 		handler = logging(p.isLoggedIn(handler))
 	default:
 		handler = logging(handler)
 	}
 
+	// API keys are authenticated before the permission checks
+	handler = p.apiKey(method, route, handler)
+
 	// All handlers need to close the body
 	handler = closeBody(handler)
 
@@ -1697,6 +2442,8 @@ func _main() error {
 		}
 	}()
 
+	util.UseLogger(utilLog)
+
 	log.Infof("Version : %v", version.String())
 	log.Infof("Network : %v", activeNetParams.Params.Name)
 	log.Infof("Home dir: %v", loadedCfg.HomeDir)
@@ -1749,6 +2496,7 @@ func _main() error {
 		return err
 	}
 	p.backend.params = activeNetParams.Params
+	log.Infof("Audit identity: %v", p.backend.auditIdentity.Public.String())
 
 	// Try to load inventory but do not fail.
 	log.Infof("Attempting to load proposal inventory")
@@ -1757,6 +2505,12 @@ func _main() error {
 		log.Errorf("LoadInventory: %v", err)
 	}
 
+	// Start delivering the queued webhook events.
+	go p.backend.runWebhooks()
+
+	// Push events to the websocket clients.
+	p.setupWebsocketEvents()
+
 	// Load or create new CSRF key
 	log.Infof("Load CSRF key")
 	csrfKeyFilename := filepath.Join(p.cfg.DataDir, "csrf.key")
@@ -1820,6 +2574,10 @@ func _main() error {
 		p.handleResendVerification, permissionPublic, false)
 	p.addRoute(http.MethodPost, v1.RouteLogin, p.handleLogin,
 		permissionPublic, false)
+	p.addRoute(http.MethodPost, v1.RouteLoginChallenge,
+		p.handleLoginChallenge, permissionPublic, false)
+	p.addRoute(http.MethodPost, v1.RouteLoginSignature,
+		p.handleLoginSignature, permissionPublic, false)
 	p.addRoute(http.MethodPost, v1.RouteLogout, p.handleLogout,
 		permissionPublic, false)
 	p.addRoute(http.MethodPost, v1.RouteResetPassword,
@@ -1844,6 +2602,20 @@ func _main() error {
 		p.handleGetAllVoteStatus, permissionPublic, true)
 	p.addRoute(http.MethodGet, v1.RouteVoteStatus,
 		p.handleVoteStatus, permissionPublic, true)
+	p.addRoute(http.MethodGet, v1.RouteProposalBundle,
+		p.handleProposalBundle, permissionPublic, true)
+	p.addRoute(http.MethodGet, v1.RouteVoteInclusionProof,
+		p.handleVoteInclusionProof, permissionPublic, true)
+	p.addRoute(http.MethodGet, v1.RouteCensorshipReport,
+		p.handleCensorshipReport, permissionPublic, true)
+	p.addRoute(http.MethodGet, v1.RouteProposalsFeed,
+		p.handleProposalsFeed, permissionPublic, true)
+	p.addRoute(http.MethodGet, v1.RouteVotingFeed,
+		p.handleVotingFeed, permissionPublic, true)
+	p.addRoute(http.MethodGet, v1.RouteVoteResultsFeed,
+		p.handleVoteResultsFeed, permissionPublic, true)
+	p.addRoute(http.MethodGet, v1.RouteCommentsFeed,
+		p.handleCommentsFeed, permissionPublic, true)
 	p.addRoute(http.MethodGet, v1.RouteUserDetails,
 		p.handleUserDetails, permissionPublic, true)
 	p.addRoute(http.MethodGet, v1.RoutePropsStats,
@@ -1870,6 +2642,8 @@ func _main() error {
 		p.handleNewComment, permissionLogin, true)
 	p.addRoute(http.MethodPost, v1.RouteLikeComment,
 		p.handleLikeComment, permissionLogin, true)
+	p.addRoute(http.MethodPost, v1.RouteReportComment,
+		p.handleReportComment, permissionLogin, true)
 	p.addRoute(http.MethodGet, v1.RouteVerifyUserPayment,
 		p.handleVerifyUserPayment, permissionLogin, false)
 	p.addRoute(http.MethodGet, v1.RouteUserCommentsLikes,
@@ -1884,6 +2658,20 @@ func _main() error {
 		p.handleProposalPaywallPayment, permissionLogin, false)
 	p.addRoute(http.MethodPost, v1.RouteEditUser,
 		p.handleEditUser, permissionLogin, false)
+	p.addRoute(http.MethodPost, v1.RouteSetTOTP,
+		p.handleSetTOTP, permissionLogin, false)
+	p.addRoute(http.MethodPost, v1.RouteVerifyTOTP,
+		p.handleVerifyTOTP, permissionLogin, false)
+	p.addRoute(http.MethodGet, v1.RouteAPIKeys,
+		p.handleAPIKeys, permissionLogin, false)
+	p.addRoute(http.MethodPost, v1.RouteNewAPIKey,
+		p.handleNewAPIKey, permissionLogin, false)
+	p.addRoute(http.MethodPost, v1.RouteRevokeAPIKey,
+		p.handleRevokeAPIKey, permissionLogin, false)
+	p.addRoute(http.MethodGet, v1.RouteSessions,
+		p.handleSessions, permissionLogin, false)
+	p.addRoute(http.MethodPost, v1.RouteRevokeSessions,
+		p.handleRevokeSessions, permissionLogin, false)
 
 	// Unauthenticated websocket
 	p.addRoute("", v1.RouteUnauthenticatedWebSocket,
@@ -1892,19 +2680,43 @@ func _main() error {
 	p.addRoute("", v1.RouteAuthenticatedWebSocket,
 		p.handleAuthenticatedWebsocket, permissionLogin, false)
 
-	// Routes that require being logged in as an admin user.
+	// Routes that require being logged in as a reviewer.
 	p.addRoute(http.MethodGet, v1.RouteAllUnvetted, p.handleAllUnvetted,
-		permissionAdmin, true)
+		permissionReviewer, true)
 	p.addRoute(http.MethodPost, v1.RouteSetProposalStatus,
-		p.handleSetProposalStatus, permissionAdmin, true)
+		p.handleSetProposalStatus, permissionReviewer, true)
+
+	// Routes that require being logged in as a vote administrator.
 	p.addRoute(http.MethodPost, v1.RouteStartVote,
-		p.handleStartVote, permissionAdmin, true)
+		p.handleStartVote, permissionVoteAdmin, true)
+
+	// Routes that require being logged in as a moderator.
+	p.addRoute(http.MethodPost, v1.RouteCensorComment,
+		p.handleCensorComment, permissionModerator, true)
+	p.addRoute(http.MethodGet, v1.RouteReportedComments,
+		p.handleReportedComments, permissionModerator, true)
+	p.addRoute(http.MethodPost, v1.RouteResolveCommentReport,
+		p.handleResolveCommentReport, permissionModerator, true)
+
+	// Routes that require being logged in as a super admin user.
 	p.addRoute(http.MethodPost, v1.RouteManageUser,
 		p.handleManageUser, permissionAdmin, true)
-	p.addRoute(http.MethodPost, v1.RouteCensorComment,
-		p.handleCensorComment, permissionAdmin, true)
 	p.addRoute(http.MethodGet, v1.RouteUsers,
 		p.handleUsers, permissionAdmin, false)
+	p.addRoute(http.MethodGet, v1.RouteAdminLog,
+		p.handleAdminLog, permissionAdmin, false)
+	p.addRoute(http.MethodGet, v1.RouteEmailOutbox,
+		p.handleEmailOutbox, permissionAdmin, false)
+	p.addRoute(http.MethodGet, v1.RouteEmailTemplates,
+		p.handleEmailTemplates, permissionAdmin, false)
+	p.addRoute(http.MethodGet, v1.RouteWebhooks,
+		p.handleWebhooks, permissionAdmin, false)
+	p.addRoute(http.MethodPost, v1.RouteNewWebhook,
+		p.handleNewWebhook, permissionAdmin, true)
+	p.addRoute(http.MethodPost, v1.RouteDeleteWebhook,
+		p.handleDeleteWebhook, permissionAdmin, true)
+	p.addRoute(http.MethodGet, v1.RouteWebhookDeliveries,
+		p.handleWebhookDeliveries, permissionAdmin, false)
 	p.addRoute(http.MethodPut, v1.RouteUserPaymentsRescan,
 		p.handleUserPaymentsRescan, permissionAdmin, false)
 
@@ -1927,14 +2739,24 @@ func _main() error {
 	if err != nil {
 		return err
 	}
-	p.store = sessions.NewFilesystemStore(sessionsDir, cookieKey)
-	p.store.Options = &sessions.Options{
+	p.sessionOptions = sessions.Options{
 		Path:     "/",
 		MaxAge:   sessionMaxAge,
 		Secure:   true,
 		HttpOnly: true,
 		SameSite: http.SameSiteStrictMode,
 	}
+	switch p.cfg.SessionStore {
+	case sessionStoreDatabase:
+		store := newDBSessionStore(p.backend.db, cookieKey)
+		store.Options = &p.sessionOptions
+		p.store = store
+	default:
+		store := sessions.NewFilesystemStore(sessionsDir, cookieKey)
+		store.Options = &p.sessionOptions
+		p.store = store
+	}
+	log.Infof("Session store: %v", p.cfg.SessionStore)
 
 	// Bind to a port and pass our router in
 	listenC := make(chan error)
@@ -1956,7 +2778,7 @@ func _main() error {
 				},
 			}
 			srv := &http.Server{
-				Handler:   csrfHandle(p.router),
+				Handler:   skipCSRFForAPIKeys(csrfHandle(p.router)),
 				Addr:      listen,
 				TLSConfig: cfg,
 				TLSNextProto: make(map[string]func(*http.Server,
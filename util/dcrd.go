@@ -0,0 +1,127 @@
+// Copyright (c) 2018 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package util
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DcrdRequest is a dcrd JSON-RPC request.
+type DcrdRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      int           `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+// DcrdError is a dcrd JSON-RPC error.
+type DcrdError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *DcrdError) Error() string {
+	return fmt.Sprintf("%v (%v)", e.Message, e.Code)
+}
+
+// DcrdReply is a dcrd JSON-RPC reply.
+type DcrdReply struct {
+	Result json.RawMessage `json:"result"`
+	Error  *DcrdError      `json:"error"`
+}
+
+// DcrdClient is a minimal dcrd JSON-RPC client.
+type DcrdClient struct {
+	host   string
+	user   string
+	pass   string
+	client *http.Client
+}
+
+// NewDcrdClient returns a client for the dcrd JSON-RPC server at the
+// provided host.  The host defaults to https when no scheme is provided and
+// cert is an optional file that contains the dcrd RPC certificate.
+func NewDcrdClient(host, user, pass, cert string, timeout time.Duration) (*DcrdClient, error) {
+	if host == "" {
+		return nil, fmt.Errorf("dcrd host not set")
+	}
+	if !strings.HasPrefix(host, "http://") &&
+		!strings.HasPrefix(host, "https://") {
+		host = "https://" + host
+	}
+
+	tlsConfig := &tls.Config{}
+	if cert != "" {
+		pem, err := ioutil.ReadFile(cert)
+		if err != nil {
+			return nil, fmt.Errorf("dcrd cert: %v", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("dcrd cert: invalid certificate")
+		}
+	}
+
+	return &DcrdClient{
+		host: host,
+		user: user,
+		pass: pass,
+		client: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				TLSClientConfig: tlsConfig,
+			},
+		},
+	}, nil
+}
+
+// Call executes a dcrd JSON-RPC command and decodes its result into v.
+// Errors that are returned by dcrd are of type *DcrdError.
+func (c *DcrdClient) Call(method string, v interface{}, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	b, err := json.Marshal(DcrdRequest{
+		JSONRPC: "1.0",
+		ID:      1,
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("dcrd %v %v", c.host, method)
+	req, err := http.NewRequest(http.MethodPost, c.host,
+		bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(c.user, c.pass)
+	r, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+
+	var reply DcrdReply
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&reply); err != nil {
+		return fmt.Errorf("%v %v: %v", method, r.Status, err)
+	}
+	if reply.Error != nil {
+		return reply.Error
+	}
+	return json.Unmarshal(reply.Result, v)
+}
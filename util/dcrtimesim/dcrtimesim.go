@@ -0,0 +1,371 @@
+// Copyright (c) 2018 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package dcrtimesim provides an in-process stand-in for a dcrtime server.
+// It implements the dcrtime v1 status, timestamp and verify routes and
+// simulates the anchoring of digests in a blockchain.  Digests are collected
+// until Flush is called, which anchors them in a fake transaction that is
+// mined in the next block.  Blocks are only mined when MineBlock or
+// MineBlocks is called which gives the caller full control over block times
+// and confirmations.
+package dcrtimesim
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrtime/api/v1"
+	"github.com/decred/dcrtime/merkle"
+	"github.com/decred/politeia/util"
+)
+
+const (
+	// DefaultConfirmations is the number of confirmations dcrtime waits
+	// for before it reports a digest as anchored.
+	DefaultConfirmations = 6
+
+	// DefaultBlockInterval is the time between blocks mined by
+	// MineBlocks.
+	DefaultBlockInterval = 5 * time.Minute
+)
+
+// collection is a set of digests that are anchored in a single transaction.
+type collection struct {
+	serverTimestamp int64                // Collection identifier
+	digests         []*[sha256.Size]byte // Digests in collection
+	merkle          *[sha256.Size]byte   // Merkle root, set on flush
+	transaction     string               // Anchor transaction, set on flush
+	height          int                  // Block height, -1 if not mined
+}
+
+// Server is an in-process dcrtime stand-in.  It satisfies the http.Handler
+// interface so that it can be served by any http server; Start serves it on
+// a local listener.
+type Server struct {
+	sync.Mutex
+
+	confirmations int                    // Required confirmations
+	blockInterval time.Duration          // Time between MineBlocks blocks
+	blocks        []int64                // Block timestamps by height
+	digests       map[string]*collection // [digest]collection
+	collections   []*collection          // All collections, oldest first
+	pending       *collection            // Collection that is not flushed
+
+	listener net.Listener
+	server   *http.Server
+	mux      *http.ServeMux
+}
+
+// New returns a new dcrtime stand-in that requires the provided number of
+// confirmations before digests are reported as anchored.
+func New(confirmations int) *Server {
+	s := &Server{
+		confirmations: confirmations,
+		blockInterval: DefaultBlockInterval,
+		blocks:        []int64{time.Now().Unix()}, // Genesis
+		digests:       make(map[string]*collection),
+		mux:           http.NewServeMux(),
+	}
+	s.mux.HandleFunc(v1.StatusRoute, s.status)
+	s.mux.HandleFunc(v1.TimestampRoute, s.timestamp)
+	s.mux.HandleFunc(v1.VerifyRoute, s.verify)
+	return s
+}
+
+// SetBlockInterval sets the time between the blocks that are mined by
+// MineBlocks.
+func (s *Server) SetBlockInterval(d time.Duration) {
+	s.Lock()
+	defer s.Unlock()
+	s.blockInterval = d
+}
+
+// Height returns the height of the best block.
+func (s *Server) Height() int {
+	s.Lock()
+	defer s.Unlock()
+	return len(s.blocks) - 1
+}
+
+// Flush anchors all digests that have been timestamped since the last flush
+// in a new transaction.  The transaction is mined in the next block.  Flush
+// returns the transaction id or an empty string if there was nothing to
+// anchor.
+func (s *Server) Flush() string {
+	s.Lock()
+	defer s.Unlock()
+
+	c := s.pending
+	if c == nil {
+		return ""
+	}
+	s.pending = nil
+
+	// merkle.Root sorts the digests which is required for the merkle
+	// paths that are handed out by verify.
+	c.merkle = merkle.Root(c.digests)
+
+	// Derive a unique fake transaction id from the merkle root and the
+	// collection.
+	h := sha256.New()
+	h.Write(c.merkle[:])
+	binary.Write(h, binary.LittleEndian, c.serverTimestamp)
+	binary.Write(h, binary.LittleEndian, int64(len(s.collections)))
+	c.transaction = hex.EncodeToString(h.Sum(nil))
+
+	return c.transaction
+}
+
+// MineBlock mines a block with the provided timestamp and returns its
+// height.  All flushed transactions that are not mined yet are included in
+// the block.
+func (s *Server) MineBlock(timestamp int64) int {
+	s.Lock()
+	defer s.Unlock()
+	return s._mineBlock(timestamp)
+}
+
+// _mineBlock mines a block.
+//
+// This function must be called WITH the mutex held.
+func (s *Server) _mineBlock(timestamp int64) int {
+	s.blocks = append(s.blocks, timestamp)
+	height := len(s.blocks) - 1
+	for _, c := range s.collections {
+		if c.transaction != "" && c.height < 0 {
+			c.height = height
+		}
+	}
+	return height
+}
+
+// MineBlocks mines count blocks that are spaced by the block interval and
+// returns the height of the last block.
+func (s *Server) MineBlocks(count int) int {
+	s.Lock()
+	defer s.Unlock()
+
+	height := len(s.blocks) - 1
+	for i := 0; i < count; i++ {
+		last := time.Unix(s.blocks[len(s.blocks)-1], 0)
+		height = s._mineBlock(last.Add(s.blockInterval).Unix())
+	}
+	return height
+}
+
+// Confirm flushes all pending digests and mines enough blocks to confirm
+// them.  It returns the anchor transaction or an empty string if there was
+// nothing to anchor.
+func (s *Server) Confirm() string {
+	tx := s.Flush()
+	blocks := s.confirmations
+	if blocks < 1 {
+		blocks = 1
+	}
+	s.MineBlocks(blocks)
+	return tx
+}
+
+// Start serves the stand-in on the provided address and returns the URL that
+// can be used as dcrtime host.  Use 127.0.0.1:0 to pick a random port.
+func (s *Server) Start(listen string) (string, error) {
+	l, err := net.Listen("tcp", listen)
+	if err != nil {
+		return "", err
+	}
+	s.listener = l
+	s.server = &http.Server{
+		Handler: s,
+	}
+	go s.server.Serve(l)
+
+	return "http://" + l.Addr().String(), nil
+}
+
+// Close stops serving the stand-in.
+func (s *Server) Close() error {
+	if s.server == nil {
+		return nil
+	}
+	return s.server.Close()
+}
+
+// ServeHTTP satisfies the http.Handler interface.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed),
+			http.StatusMethodNotAllowed)
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// respondWithError replies with a dcrtime style error.
+func respondWithError(w http.ResponseWriter, format string, args ...interface{}) {
+	util.RespondWithJSON(w, http.StatusBadRequest, map[string]string{
+		"error": fmt.Sprintf(format, args...),
+	})
+}
+
+// chainInformation returns the chain information of the provided digest.
+// The chain timestamp is 0 when the collection does not have enough
+// confirmations.
+//
+// This function must be called WITH the mutex held.
+func (s *Server) chainInformation(c *collection, digest *[sha256.Size]byte) v1.ChainInformation {
+	var ci v1.ChainInformation
+	if c.height < 0 ||
+		len(s.blocks)-c.height < s.confirmations {
+		return ci
+	}
+
+	ci.ChainTimestamp = s.blocks[c.height]
+	ci.Transaction = c.transaction
+	ci.MerkleRoot = hex.EncodeToString(c.merkle[:])
+	if digest != nil {
+		ci.MerklePath = *merkle.AuthPath(c.digests, digest)
+	}
+	return ci
+}
+
+func (s *Server) status(w http.ResponseWriter, r *http.Request) {
+	var st v1.Status
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&st); err != nil {
+		respondWithError(w, "Invalid request payload")
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, v1.StatusReply{ID: st.ID})
+}
+
+func (s *Server) timestamp(w http.ResponseWriter, r *http.Request) {
+	var ts v1.Timestamp
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&ts); err != nil {
+		respondWithError(w, "Invalid request payload")
+		return
+	}
+
+	digests := make([]*[sha256.Size]byte, 0, len(ts.Digests))
+	for _, v := range ts.Digests {
+		d, ok := util.ConvertDigest(v)
+		if !ok {
+			respondWithError(w, "Invalid digest: %v", v)
+			return
+		}
+		digests = append(digests, &d)
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if s.pending == nil {
+		// Collections are identified by their server timestamp so
+		// make sure it is unique.
+		st := time.Now().Unix()
+		if l := len(s.collections); l > 0 &&
+			s.collections[l-1].serverTimestamp >= st {
+			st = s.collections[l-1].serverTimestamp + 1
+		}
+		s.pending = &collection{
+			serverTimestamp: st,
+			height:          -1,
+		}
+		s.collections = append(s.collections, s.pending)
+	}
+
+	reply := v1.TimestampReply{
+		ID:              ts.ID,
+		ServerTimestamp: s.pending.serverTimestamp,
+		Digests:         ts.Digests,
+		Results:         make([]int, 0, len(digests)),
+	}
+	for k, d := range digests {
+		key := hex.EncodeToString(d[:])
+		if _, ok := s.digests[key]; ok {
+			reply.Results = append(reply.Results,
+				v1.ResultExistsError)
+			continue
+		}
+		s.digests[key] = s.pending
+		s.pending.digests = append(s.pending.digests, digests[k])
+		reply.Results = append(reply.Results, v1.ResultOK)
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, reply)
+}
+
+func (s *Server) verify(w http.ResponseWriter, r *http.Request) {
+	var vr v1.Verify
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&vr); err != nil {
+		respondWithError(w, "Invalid request payload")
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	reply := v1.VerifyReply{
+		ID:         vr.ID,
+		Digests:    make([]v1.VerifyDigest, 0, len(vr.Digests)),
+		Timestamps: make([]v1.VerifyTimestamp, 0, len(vr.Timestamps)),
+	}
+	for _, v := range vr.Digests {
+		d, ok := util.ConvertDigest(v)
+		if !ok {
+			respondWithError(w, "Invalid digest: %v", v)
+			return
+		}
+		vd := v1.VerifyDigest{
+			Digest: v,
+		}
+		c, ok := s.digests[hex.EncodeToString(d[:])]
+		if !ok {
+			vd.Result = v1.ResultDoesntExistError
+		} else {
+			vd.Result = v1.ResultOK
+			vd.ServerTimestamp = c.serverTimestamp
+			vd.ChainInformation = s.chainInformation(c, &d)
+		}
+		reply.Digests = append(reply.Digests, vd)
+	}
+
+	for _, v := range vr.Timestamps {
+		vt := v1.VerifyTimestamp{
+			ServerTimestamp: v,
+			Result:          v1.ResultDoesntExistError,
+		}
+		for _, c := range s.collections {
+			if c.serverTimestamp != v {
+				continue
+			}
+			ci := s.chainInformation(c, nil)
+			vt.Result = v1.ResultOK
+			vt.CollectionInformation = v1.CollectionInformation{
+				ChainTimestamp: ci.ChainTimestamp,
+				Transaction:    ci.Transaction,
+				MerkleRoot:     ci.MerkleRoot,
+				Digests:        make([]string, 0, len(c.digests)),
+			}
+			for _, d := range c.digests {
+				vt.CollectionInformation.Digests = append(
+					vt.CollectionInformation.Digests,
+					hex.EncodeToString(d[:]))
+			}
+			break
+		}
+		reply.Timestamps = append(reply.Timestamps, vt)
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, reply)
+}
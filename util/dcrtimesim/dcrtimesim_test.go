@@ -0,0 +1,115 @@
+// Copyright (c) 2018 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package dcrtimesim
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/decred/dcrtime/api/v1"
+	"github.com/decred/dcrtime/merkle"
+	"github.com/decred/politeia/util"
+)
+
+func TestTimestampVerify(t *testing.T) {
+	confirmations := 3
+	s := New(confirmations)
+	host, err := s.Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	digests := make([]*[sha256.Size]byte, 0, 3)
+	hexDigests := make([]string, 0, 3)
+	for i := 0; i < 3; i++ {
+		d := sha256.Sum256([]byte{byte(i)})
+		digests = append(digests, &d)
+		hexDigests = append(hexDigests, hex.EncodeToString(d[:]))
+	}
+	err = util.Timestamp(host, digests)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Digests that already exist are ignored by util.Timestamp.
+	err = util.Timestamp(host, digests[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Not anchored until flushed and confirmed
+	_, err = util.Verify(host, hexDigests)
+	if err == nil || !strings.Contains(err.Error(), "Not anchored") {
+		t.Fatalf("expected not anchored, got %v", err)
+	}
+	tx := s.Flush()
+	if tx == "" {
+		t.Fatalf("expected anchor transaction")
+	}
+	if s.Flush() != "" {
+		t.Fatalf("unexpected anchor transaction")
+	}
+	blockTime := int64(1500000000)
+	s.MineBlock(blockTime)
+	s.MineBlocks(confirmations - 2)
+	_, err = util.Verify(host, hexDigests)
+	if err == nil {
+		t.Fatalf("expected not anchored")
+	}
+
+	// Confirmed
+	s.MineBlocks(1)
+	vr, err := util.Verify(host, hexDigests)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vr.Digests) != len(digests) {
+		t.Fatalf("unexpected digests %v", len(vr.Digests))
+	}
+	root := merkle.Root(digests)
+	for _, v := range vr.Digests {
+		ci := v.ChainInformation
+		if v.Result != v1.ResultOK || ci.Transaction != tx ||
+			ci.ChainTimestamp != blockTime ||
+			ci.MerkleRoot != hex.EncodeToString(root[:]) {
+			t.Fatalf("unexpected verify digest %v", v)
+		}
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	s := New(DefaultConfirmations)
+	host, err := s.Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if s.Confirm() != "" {
+		t.Fatalf("unexpected anchor transaction")
+	}
+
+	d := sha256.Sum256([]byte("politeia"))
+	err = util.Timestamp(host, []*[sha256.Size]byte{&d})
+	if err != nil {
+		t.Fatal(err)
+	}
+	height := s.Height()
+	tx := s.Confirm()
+	if s.Height() != height+DefaultConfirmations {
+		t.Fatalf("unexpected height %v", s.Height())
+	}
+	vr, err := util.Verify(host, []string{hex.EncodeToString(d[:])})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vr.Digests[0].ChainInformation.Transaction != tx {
+		t.Fatalf("unexpected transaction %v",
+			vr.Digests[0].ChainInformation.Transaction)
+	}
+}
@@ -0,0 +1,284 @@
+// Copyright (c) 2018 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package util
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Following are the JSON schema types that are used by JSONSchema.  An empty
+// type accepts any value.
+const (
+	JSONSchemaObject  = "object"
+	JSONSchemaArray   = "array"
+	JSONSchemaString  = "string"
+	JSONSchemaInteger = "integer"
+	JSONSchemaNumber  = "number"
+	JSONSchemaBoolean = "boolean"
+)
+
+var (
+	typeJSONMarshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	typeTextMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// JSONSchema is the subset of JSON schema that is needed to describe the
+// encoding/json representation of Go types.  Since Go encodes nil slices, maps
+// and pointers as null, null is accepted for arrays and objects.
+type JSONSchema struct {
+	Type                 string                 `json:"type,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+	Minimum              *int64                 `json:"minimum,omitempty"`
+}
+
+// NewJSONSchema returns the schema of the JSON encoding of the provided value.
+// Struct fields are described according to their json tags.  Fields that are
+// tagged omitempty are optional, all other fields are required.
+func NewJSONSchema(v interface{}) *JSONSchema {
+	return newJSONSchema(reflect.TypeOf(v), make(map[reflect.Type]bool))
+}
+
+// newJSONSchema returns the schema of the provided type.  Types that are
+// currently being described are tracked in seen in order to terminate on
+// recursive types.
+func newJSONSchema(t reflect.Type, seen map[reflect.Type]bool) *JSONSchema {
+	if t == nil {
+		return &JSONSchema{}
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	// Types that encode themselves can not be described.
+	if t.Implements(typeJSONMarshaler) ||
+		reflect.PtrTo(t).Implements(typeJSONMarshaler) {
+		return &JSONSchema{}
+	}
+	if t.Implements(typeTextMarshaler) ||
+		reflect.PtrTo(t).Implements(typeTextMarshaler) {
+		return &JSONSchema{Type: JSONSchemaString}
+	}
+
+	var zero int64
+	switch t.Kind() {
+	case reflect.Bool:
+		return &JSONSchema{Type: JSONSchemaBoolean}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return &JSONSchema{Type: JSONSchemaInteger}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return &JSONSchema{Type: JSONSchemaInteger, Minimum: &zero}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: JSONSchemaNumber}
+	case reflect.String:
+		return &JSONSchema{Type: JSONSchemaString}
+	case reflect.Slice:
+		// Byte slices are base64 encoded.
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &JSONSchema{Type: JSONSchemaString}
+		}
+		fallthrough
+	case reflect.Array:
+		return &JSONSchema{
+			Type:  JSONSchemaArray,
+			Items: newJSONSchema(t.Elem(), seen),
+		}
+	case reflect.Map:
+		return &JSONSchema{
+			Type:                 JSONSchemaObject,
+			AdditionalProperties: newJSONSchema(t.Elem(), seen),
+		}
+	case reflect.Struct:
+		if seen[t] {
+			return &JSONSchema{Type: JSONSchemaObject}
+		}
+		seen[t] = true
+		defer delete(seen, t)
+
+		s := &JSONSchema{
+			Type:       JSONSchemaObject,
+			Properties: make(map[string]*JSONSchema),
+		}
+		addJSONSchemaFields(s, t, seen)
+		sort.Strings(s.Required)
+		return s
+	}
+
+	// Interfaces, channels and functions.
+	return &JSONSchema{}
+}
+
+// addJSONSchemaFields adds the fields of struct type t to s.  Fields of
+// embedded structs are promoted the same way encoding/json does.
+func addJSONSchemaFields(s *JSONSchema, t reflect.Type, seen map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if idx := strings.Index(tag, ","); idx != -1 {
+			name, opts = tag[:idx], tag[idx+1:]
+		}
+
+		// Promote untagged embedded structs.
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			addJSONSchemaFields(s, ft, seen)
+			continue
+		}
+		if f.PkgPath != "" {
+			// Unexported
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		s.Properties[name] = newJSONSchema(f.Type, seen)
+		required := true
+		for _, o := range strings.Split(opts, ",") {
+			switch o {
+			case "string":
+				s.Properties[name] = &JSONSchema{
+					Type: JSONSchemaString,
+				}
+			case "omitempty":
+				required = false
+			}
+		}
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// Validate verifies that the provided JSON payload matches the schema.
+func (s *JSONSchema) Validate(payload []byte) error {
+	d := json.NewDecoder(bytes.NewReader(payload))
+	d.UseNumber()
+	var v interface{}
+	err := d.Decode(&v)
+	if err != nil {
+		return fmt.Errorf("invalid JSON: %v", err)
+	}
+	if d.More() {
+		return fmt.Errorf("invalid JSON: trailing data")
+	}
+	return s.validate("", v)
+}
+
+// validate verifies that the decoded JSON value v matches the schema.  The
+// path is used to point at the offending value in errors.
+func (s *JSONSchema) validate(path string, v interface{}) error {
+	where := path
+	if where == "" {
+		where = "payload"
+	}
+
+	switch s.Type {
+	case "":
+		return nil
+	case JSONSchemaBoolean:
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("%v: expected boolean", where)
+		}
+	case JSONSchemaString:
+		if _, ok := v.(string); !ok {
+			return fmt.Errorf("%v: expected string", where)
+		}
+	case JSONSchemaNumber, JSONSchemaInteger:
+		n, ok := v.(json.Number)
+		if !ok {
+			return fmt.Errorf("%v: expected %v", where, s.Type)
+		}
+		if s.Type == JSONSchemaNumber {
+			if _, err := n.Float64(); err != nil {
+				return fmt.Errorf("%v: expected number", where)
+			}
+			return nil
+		}
+		if strings.ContainsAny(n.String(), ".eE") {
+			return fmt.Errorf("%v: expected integer", where)
+		}
+		if s.Minimum != nil && strings.HasPrefix(n.String(), "-") &&
+			n.String() != "-0" {
+			return fmt.Errorf("%v: must be at least %v", where,
+				*s.Minimum)
+		}
+	case JSONSchemaArray:
+		if v == nil {
+			return nil
+		}
+		a, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("%v: expected array", where)
+		}
+		if s.Items == nil {
+			return nil
+		}
+		for k, vv := range a {
+			err := s.Items.validate(fmt.Sprintf("%v[%v]", path, k), vv)
+			if err != nil {
+				return err
+			}
+		}
+	case JSONSchemaObject:
+		if v == nil {
+			return nil
+		}
+		o, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%v: expected object", where)
+		}
+		for _, r := range s.Required {
+			if _, ok := o[r]; !ok {
+				return fmt.Errorf("%v: missing %v", where, r)
+			}
+		}
+
+		// Validate in a stable order so that errors are reproducible.
+		keys := make([]string, 0, len(o))
+		for k := range o {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			ps, ok := s.Properties[k]
+			if !ok {
+				ps = s.AdditionalProperties
+			}
+			if ps == nil {
+				continue
+			}
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			err := ps.validate(p, o[k])
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%v: unsupported schema type %v", where, s.Type)
+	}
+
+	return nil
+}
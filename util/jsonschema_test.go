@@ -0,0 +1,151 @@
+// Copyright (c) 2018 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package util
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type testSchemaEmbedded struct {
+	Embedded string `json:"embedded"`
+}
+
+type testSchemaNode struct {
+	Name     string            `json:"name"`
+	Children []*testSchemaNode `json:"children,omitempty"`
+}
+
+type testSchema struct {
+	testSchemaEmbedded
+	Text     string            `json:"text"`
+	Count    uint32            `json:"count"`
+	Delta    int               `json:"delta,omitempty"`
+	Ratio    float64           `json:"ratio,omitempty"`
+	Flag     bool              `json:"flag,omitempty"`
+	Blob     []byte            `json:"blob,omitempty"`
+	Digest   [2]byte           `json:"digest,omitempty"`
+	Labels   map[string]int    `json:"labels,omitempty"`
+	Raw      json.RawMessage   `json:"raw,omitempty"`
+	Node     *testSchemaNode   `json:"node,omitempty"`
+	Quoted   int64             `json:"quoted,string,omitempty"`
+	Skipped  string            `json:"-"`
+	Untagged string            `json:",omitempty"`
+	Nested   map[string]string `json:"nested,omitempty"`
+	hidden   string
+}
+
+func TestNewJSONSchema(t *testing.T) {
+	s := NewJSONSchema(testSchema{})
+	if s.Type != JSONSchemaObject {
+		t.Fatalf("unexpected type %v", s.Type)
+	}
+	if !reflect.DeepEqual(s.Required, []string{"count", "embedded",
+		"text"}) {
+		t.Fatalf("unexpected required %v", s.Required)
+	}
+	expected := map[string]string{
+		"embedded": JSONSchemaString,
+		"text":     JSONSchemaString,
+		"count":    JSONSchemaInteger,
+		"delta":    JSONSchemaInteger,
+		"ratio":    JSONSchemaNumber,
+		"flag":     JSONSchemaBoolean,
+		"blob":     JSONSchemaString,
+		"digest":   JSONSchemaArray,
+		"labels":   JSONSchemaObject,
+		"raw":      "",
+		"node":     JSONSchemaObject,
+		"quoted":   JSONSchemaString,
+		"Untagged": JSONSchemaString,
+		"nested":   JSONSchemaObject,
+	}
+	if len(s.Properties) != len(expected) {
+		t.Fatalf("unexpected properties %v", s.Properties)
+	}
+	for k, v := range expected {
+		p, ok := s.Properties[k]
+		if !ok {
+			t.Fatalf("missing property %v", k)
+		}
+		if p.Type != v {
+			t.Fatalf("%v: got %v, want %v", k, p.Type, v)
+		}
+	}
+	if s.Properties["count"].Minimum == nil ||
+		s.Properties["delta"].Minimum != nil {
+		t.Fatalf("unexpected minimum")
+	}
+
+	// Recursive types terminate
+	node := s.Properties["node"].Properties["children"].Items
+	if node.Type != JSONSchemaObject || node.Properties != nil {
+		t.Fatalf("unexpected recursive schema %v", node)
+	}
+
+	// Schemas survive a JSON round trip
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ss JSONSchema
+	err = json.Unmarshal(b, &ss)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(s, &ss) {
+		t.Fatalf("round trip mismatch %s", b)
+	}
+}
+
+func TestJSONSchemaValidate(t *testing.T) {
+	s := NewJSONSchema(testSchema{})
+
+	valid := []string{
+		`{"embedded":"e","text":"t","count":1}`,
+		`{"embedded":"e","text":"t","count":0,"delta":-1,"ratio":0.5,` +
+			`"flag":true,"blob":"AAE=","digest":[1,2],` +
+			`"labels":{"a":1},"raw":[{}],"quoted":"12",` +
+			`"node":{"name":"n","children":[{"name":"c"}]},` +
+			`"unknown":1}`,
+		`{"embedded":"e","text":"t","count":1,"labels":null,` +
+			`"node":null}`,
+	}
+	for _, v := range valid {
+		err := s.Validate([]byte(v))
+		if err != nil {
+			t.Fatalf("%v: %v", v, err)
+		}
+	}
+
+	invalid := []string{
+		``,
+		`{"embedded":"e","text":"t","count":1} {}`,
+		`[]`,
+		`{"embedded":"e","text":"t"}`,
+		`{"embedded":"e","text":1,"count":1}`,
+		`{"embedded":"e","text":"t","count":-1}`,
+		`{"embedded":"e","text":"t","count":1.5}`,
+		`{"embedded":"e","text":"t","count":1,"ratio":"1"}`,
+		`{"embedded":"e","text":"t","count":1,"flag":1}`,
+		`{"embedded":"e","text":"t","count":1,"digest":["a"]}`,
+		`{"embedded":"e","text":"t","count":1,"labels":{"a":"b"}}`,
+		`{"embedded":"e","text":"t","count":1,"node":{}}`,
+	}
+	for _, v := range invalid {
+		if s.Validate([]byte(v)) == nil {
+			t.Fatalf("expected %v to be invalid", v)
+		}
+	}
+
+	// Non object schemas
+	if NewJSONSchema(uint32(0)).Validate([]byte("296710")) != nil {
+		t.Fatalf("expected integer to be valid")
+	}
+	if NewJSONSchema(uint32(0)).Validate([]byte(`"296710"`)) == nil {
+		t.Fatalf("expected string to be invalid")
+	}
+}
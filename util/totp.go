@@ -0,0 +1,101 @@
+// Copyright (c) 2018 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Following are the RFC 6238 parameters that are used by the TOTP functions.
+// These are the defaults of all common authenticator apps.
+const (
+	TOTPSecretSize = 20               // Size of the shared secret in bytes
+	TOTPDigits     = 6                // Number of digits of a code
+	TOTPPeriod     = 30 * time.Second // Time step of a code
+)
+
+// totpEncoding is the unpadded base32 encoding that authenticator apps expect
+// for shared secrets.
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewTOTPSecret returns a new random base32 encoded TOTP shared secret.
+func NewTOTPSecret() (string, error) {
+	b, err := Random(TOTPSecretSize)
+	if err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// TOTPURI returns the otpauth URI that authenticator apps use to enroll the
+// provided secret.  The URI is typically rendered as a QR code.
+func TOTPURI(issuer, account, secret string) string {
+	label := url.PathEscape(issuer + ":" + account)
+	v := url.Values{}
+	v.Set("secret", secret)
+	v.Set("issuer", issuer)
+	return fmt.Sprintf("otpauth://totp/%v?%v", label, v.Encode())
+}
+
+// TOTPStep returns the time step that t falls into.
+func TOTPStep(t time.Time) uint64 {
+	return uint64(t.Unix()) / uint64(TOTPPeriod/time.Second)
+}
+
+// TOTPCode returns the TOTP code of the provided base32 encoded secret for the
+// provided time step.
+func TOTPCode(secret string, step uint64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(
+		strings.TrimRight(secret, "=")))
+	if err != nil {
+		return "", fmt.Errorf("invalid TOTP secret: %v", err)
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], step)
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation as described in RFC 4226 section 5.3.
+	offset := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for i := 0; i < TOTPDigits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", TOTPDigits, code%mod), nil
+}
+
+// VerifyTOTP verifies that code is a valid TOTP code of the provided secret
+// at time t.  Codes of up to skew time steps before and after t are accepted
+// in order to allow for clock drift.  The matching time step is returned so
+// that callers can reject replayed codes.
+func VerifyTOTP(secret, code string, t time.Time, skew uint64) (uint64, bool, error) {
+	code = strings.TrimSpace(code)
+	if len(code) != TOTPDigits {
+		return 0, false, nil
+	}
+
+	now := TOTPStep(t)
+	for step := now - skew; step <= now+skew; step++ {
+		c, err := TOTPCode(secret, step)
+		if err != nil {
+			return 0, false, err
+		}
+		if subtle.ConstantTimeCompare([]byte(c), []byte(code)) == 1 {
+			return step, true, nil
+		}
+	}
+	return 0, false, nil
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2018 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package util
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 appendix B SHA1 test vectors truncated to six digits.
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, test := range tests {
+		code, err := TOTPCode(secret, TOTPStep(time.Unix(test.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != test.code {
+			t.Fatalf("%v: got %v, want %v", test.unix, code, test.code)
+		}
+	}
+
+	_, err := TOTPCode("not base32!", 0)
+	if err == nil {
+		t.Fatalf("expected invalid secret error")
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	secret, err := NewTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1530000000, 0)
+	step := TOTPStep(now)
+
+	// Codes within the skew are accepted and return their step.
+	for _, s := range []uint64{step - 1, step, step + 1} {
+		code, err := TOTPCode(secret, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok, err := VerifyTOTP(secret, code, now, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok || got != s {
+			t.Fatalf("expected code of step %v to verify, got %v %v",
+				s, got, ok)
+		}
+	}
+
+	// Codes outside of the skew and malformed codes are rejected.
+	code, err := TOTPCode(secret, step+2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []string{code, "", "12345", "1234567"} {
+		_, ok, err := VerifyTOTP(secret, c, now, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok {
+			t.Fatalf("expected %q to be rejected", c)
+		}
+	}
+
+	uri := TOTPURI("politeia", "user@example.com", secret)
+	if !strings.HasPrefix(uri, "otpauth://totp/politeia:user@example.com?") ||
+		!strings.Contains(uri, "secret="+secret) {
+		t.Fatalf("unexpected uri %v", uri)
+	}
+}
@@ -0,0 +1,450 @@
+// Copyright (c) 2018 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg"
+	"github.com/decred/dcrd/dcrutil"
+)
+
+const (
+	// Transaction fetcher names.
+	TxFetcherDcrdata = "dcrdata"
+	TxFetcherInsight = "insight"
+	TxFetcherDcrd    = "dcrd"
+	TxFetcherFixture = "fixture"
+
+	dcrdataMainnet = "https://explorer.dcrdata.org/api"
+	dcrdataTestnet = "https://testnet.dcrdata.org/api"
+	insightMainnet = "https://mainnet.decred.org/api"
+	insightTestnet = "https://testnet.decred.org/api"
+
+	requestTimeout = 3 * time.Second // Block explorer request timeout
+
+	// dcrdPageSize is the number of transactions that are requested per
+	// dcrd searchrawtransactions call.
+	dcrdPageSize = 100
+
+	// dcrdErrNoTxInfo is the dcrd RPC error code that is returned by
+	// searchrawtransactions when an address has no transactions.
+	dcrdErrNoTxInfo = -5
+)
+
+// TxFetcher looks up the transactions that have been sent to an address.
+// Implementations exist for the dcrdata and insight block explorers, for a
+// dcrd node and for a JSON fixture file.
+type TxFetcher interface {
+	// Name returns the name of the transaction source.
+	Name() string
+
+	// FetchTxsForAddress returns the transactions that have been sent to
+	// the provided address.
+	FetchTxsForAddress(address string) ([]TxDetails, error)
+
+	// FetchTxsForAddressNotBefore returns the transactions that have been
+	// sent to the provided address after the notBefore timestamp.
+	FetchTxsForAddressNotBefore(address string, notBefore int64) ([]TxDetails, error)
+}
+
+// DefaultDcrdataURL returns the public dcrdata API URL for the provided
+// network or an empty string if there is none.
+func DefaultDcrdataURL(params *chaincfg.Params) string {
+	switch params {
+	case &chaincfg.MainNetParams:
+		return dcrdataMainnet
+	case &chaincfg.TestNet3Params:
+		return dcrdataTestnet
+	}
+	return ""
+}
+
+// DefaultInsightURL returns the public insight API URL for the provided
+// network or an empty string if there is none.
+func DefaultInsightURL(params *chaincfg.Params) string {
+	switch params {
+	case &chaincfg.MainNetParams:
+		return insightMainnet
+	case &chaincfg.TestNet3Params:
+		return insightTestnet
+	}
+	return ""
+}
+
+// FetchTx uses the provided fetcher to look for a transaction for the given
+// address that equals or exceeds the given amount, occurs after the
+// txnotbefore time and has the minimum number of confirmations.  An empty
+// transaction id is returned when no such transaction exists.
+func FetchTx(f TxFetcher, address string, amount uint64, txnotbefore int64, minConfirmations uint64) (string, uint64, error) {
+	// pre-validate that the passed address is at least somewhat valid
+	// before querying the fetcher
+	_, err := dcrutil.DecodeAddress(address)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid address %v: %v", address, err)
+	}
+
+	txs, err := f.FetchTxsForAddress(address)
+	if err != nil {
+		return "", 0, err
+	}
+	for _, v := range txs {
+		switch {
+		case v.Timestamp < txnotbefore:
+			continue
+		case v.Confirmations < minConfirmations:
+			continue
+		case v.Amount < amount:
+			continue
+		}
+		return v.TxID, v.Amount, nil
+	}
+
+	return "", 0, nil
+}
+
+// filterTxsNotBefore returns the transactions that occurred after notBefore
+// in reverse chronological order.
+func filterTxsNotBefore(txs []TxDetails, notBefore int64) []TxDetails {
+	targetTxs := make([]TxDetails, 0, len(txs))
+	for _, tx := range txs {
+		if tx.Timestamp > notBefore {
+			targetTxs = append(targetTxs, tx)
+		}
+	}
+	sort.SliceStable(targetTxs, func(i, j int) bool {
+		return targetTxs[i].Timestamp > targetTxs[j].Timestamp
+	})
+	return targetTxs
+}
+
+func makeRequest(client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create request: %v", err)
+	}
+
+	response, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%v: %v", url, response.Status)
+	}
+
+	return ioutil.ReadAll(response.Body)
+}
+
+// failoverTxFetcher tries a list of fetchers in order until one of them
+// succeeds.
+type failoverTxFetcher struct {
+	fetchers []TxFetcher
+}
+
+// NewFailoverTxFetcher returns a fetcher that tries the provided fetchers in
+// order.  ErrCannotVerifyPayment is returned when all of them fail.
+func NewFailoverTxFetcher(fetchers ...TxFetcher) TxFetcher {
+	return &failoverTxFetcher{
+		fetchers: fetchers,
+	}
+}
+
+func (f *failoverTxFetcher) Name() string {
+	names := make([]string, 0, len(f.fetchers))
+	for _, v := range f.fetchers {
+		names = append(names, v.Name())
+	}
+	return strings.Join(names, ",")
+}
+
+func (f *failoverTxFetcher) FetchTxsForAddress(address string) ([]TxDetails, error) {
+	for _, v := range f.fetchers {
+		txs, err := v.FetchTxsForAddress(address)
+		if err != nil {
+			log.Errorf("failed to fetch from %v: %v", v.Name(), err)
+			continue
+		}
+		return txs, nil
+	}
+	return nil, ErrCannotVerifyPayment
+}
+
+func (f *failoverTxFetcher) FetchTxsForAddressNotBefore(address string, notBefore int64) ([]TxDetails, error) {
+	for _, v := range f.fetchers {
+		txs, err := v.FetchTxsForAddressNotBefore(address, notBefore)
+		if err != nil {
+			log.Errorf("failed to fetch from %v: %v", v.Name(), err)
+			continue
+		}
+		return txs, nil
+	}
+	return nil, ErrCannotVerifyPayment
+}
+
+// dcrdataTxFetcher fetches transactions from the dcrdata block explorer API.
+type dcrdataTxFetcher struct {
+	url    string
+	client *http.Client
+}
+
+// NewDcrdataTxFetcher returns a fetcher that uses the dcrdata API at the
+// provided URL, e.g. https://explorer.dcrdata.org/api.
+func NewDcrdataTxFetcher(url string) TxFetcher {
+	return &dcrdataTxFetcher{
+		url: strings.TrimSuffix(url, "/"),
+		client: &http.Client{
+			Timeout: requestTimeout,
+		},
+	}
+}
+
+func (d *dcrdataTxFetcher) Name() string {
+	return TxFetcherDcrdata
+}
+
+// fetch fetches and converts the dcrdata transactions for an address at the
+// provided dcrdata address route.
+func (d *dcrdataTxFetcher) fetch(address, route string) ([]TxDetails, error) {
+	if d.url == "" {
+		return nil, fmt.Errorf("dcrdata url not set")
+	}
+	responseBody, err := makeRequest(d.client,
+		d.url+"/address/"+address+route)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]BEPrimaryTransaction, 0)
+	err = json.Unmarshal(responseBody, &transactions)
+	if err != nil {
+		return nil, fmt.Errorf("Unmarshal []BEPrimaryTransaction: %v", err)
+	}
+
+	txs := make([]TxDetails, 0, len(transactions))
+	for _, tx := range transactions {
+		txDetail, err := convertBEPrimaryTransactionToTxDetails(address, tx)
+		if err != nil {
+			return nil, fmt.Errorf("convertBEPrimaryTransactionToTxDetails: %v",
+				tx.TxId)
+		}
+		txs = append(txs, *txDetail)
+	}
+
+	return txs, nil
+}
+
+func (d *dcrdataTxFetcher) FetchTxsForAddress(address string) ([]TxDetails, error) {
+	return d.fetch(address, "/raw")
+}
+
+func (d *dcrdataTxFetcher) FetchTxsForAddressNotBefore(address string, notBefore int64) ([]TxDetails, error) {
+	// Fetch all txs for the passed in wallet address
+	// that were sent after the notBefore timestamp
+	var (
+		targetTxs []TxDetails
+		count     = 10
+		skip      = 0
+	)
+	for {
+		// Fetch a page of user payment txs
+		txs, err := d.fetch(address, "/count/"+strconv.Itoa(count)+
+			"/skip/"+strconv.Itoa(skip)+"/raw")
+		if err != nil {
+			return nil, err
+		}
+
+		// Verify txs are within notBefore limit
+		page := filterTxsNotBefore(txs, notBefore)
+		targetTxs = append(targetTxs, page...)
+
+		// We have reached the notBefore limit or the
+		// last page; stop requesting txs
+		if len(page) < len(txs) || len(txs) < count {
+			break
+		}
+
+		skip += count
+	}
+
+	return targetTxs, nil
+}
+
+// insightTxFetcher fetches transactions from the insight block explorer API.
+type insightTxFetcher struct {
+	url    string
+	client *http.Client
+}
+
+// NewInsightTxFetcher returns a fetcher that uses the insight API at the
+// provided URL, e.g. https://mainnet.decred.org/api.
+func NewInsightTxFetcher(url string) TxFetcher {
+	return &insightTxFetcher{
+		url: strings.TrimSuffix(url, "/"),
+		client: &http.Client{
+			Timeout: requestTimeout,
+		},
+	}
+}
+
+func (i *insightTxFetcher) Name() string {
+	return TxFetcherInsight
+}
+
+func (i *insightTxFetcher) FetchTxsForAddress(address string) ([]TxDetails, error) {
+	if i.url == "" {
+		return nil, fmt.Errorf("insight url not set")
+	}
+	responseBody, err := makeRequest(i.client,
+		i.url+"/addr/"+address+"/utxo?noCache=1")
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]BEBackupTransaction, 0)
+	err = json.Unmarshal(responseBody, &transactions)
+	if err != nil {
+		return nil, fmt.Errorf("Unmarshal []BEBackupTransaction: %v", err)
+	}
+
+	txs := make([]TxDetails, 0, len(transactions))
+	for _, tx := range transactions {
+		txDetail, err := convertBEBackupTransactionToTxDetails(tx)
+		if err != nil {
+			return nil, fmt.Errorf("convertBEBackupTransactionToTxDetails: %v",
+				tx.TxId)
+		}
+		txs = append(txs, *txDetail)
+	}
+
+	return txs, nil
+}
+
+func (i *insightTxFetcher) FetchTxsForAddressNotBefore(address string, notBefore int64) ([]TxDetails, error) {
+	txs, err := i.FetchTxsForAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	return filterTxsNotBefore(txs, notBefore), nil
+}
+
+// dcrdTxFetcher fetches transactions from a dcrd node using the
+// searchrawtransactions JSON-RPC command.  The node must be running with
+// --addrindex.
+type dcrdTxFetcher struct {
+	client *DcrdClient
+}
+
+// NewDcrdTxFetcher returns a fetcher that uses the dcrd JSON-RPC server at
+// the provided host.  The host defaults to https when no scheme is provided
+// and cert is an optional file that contains the dcrd RPC certificate.
+func NewDcrdTxFetcher(host, user, pass, cert string) (TxFetcher, error) {
+	client, err := NewDcrdClient(host, user, pass, cert, requestTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return &dcrdTxFetcher{
+		client: client,
+	}, nil
+}
+
+func (d *dcrdTxFetcher) Name() string {
+	return TxFetcherDcrd
+}
+
+func (d *dcrdTxFetcher) FetchTxsForAddress(address string) ([]TxDetails, error) {
+	txs := make([]TxDetails, 0)
+	for skip := 0; ; skip += dcrdPageSize {
+		// The verbose searchrawtransactions reply uses the same
+		// format as the dcrdata raw transactions.
+		var transactions []BEPrimaryTransaction
+		err := d.client.Call("searchrawtransactions", &transactions,
+			address, 1, skip, dcrdPageSize)
+		if e, ok := err.(*DcrdError); ok && e.Code == dcrdErrNoTxInfo {
+			// No (more) transactions for this address
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("searchrawtransactions: %v", err)
+		}
+
+		for _, tx := range transactions {
+			txDetail, err := convertBEPrimaryTransactionToTxDetails(address,
+				tx)
+			if err != nil {
+				return nil, fmt.Errorf("convertBEPrimaryTransactionToTxDetails: %v",
+					tx.TxId)
+			}
+			txs = append(txs, *txDetail)
+		}
+		if len(transactions) < dcrdPageSize {
+			break
+		}
+	}
+
+	return txs, nil
+}
+
+func (d *dcrdTxFetcher) FetchTxsForAddressNotBefore(address string, notBefore int64) ([]TxDetails, error) {
+	txs, err := d.FetchTxsForAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	return filterTxsNotBefore(txs, notBefore), nil
+}
+
+// fixtureTxFetcher reads transactions from a JSON file that maps addresses
+// to transactions.  The file is read on every call so that it can be edited
+// while the fetcher is in use.
+type fixtureTxFetcher struct {
+	filename string
+}
+
+// NewFixtureTxFetcher returns a fetcher that reads transactions from the
+// provided JSON file.  The file contains an object that maps addresses to
+// arrays of TxDetails.
+func NewFixtureTxFetcher(filename string) TxFetcher {
+	return &fixtureTxFetcher{
+		filename: filename,
+	}
+}
+
+func (f *fixtureTxFetcher) Name() string {
+	return TxFetcherFixture
+}
+
+func (f *fixtureTxFetcher) FetchTxsForAddress(address string) ([]TxDetails, error) {
+	b, err := ioutil.ReadFile(f.filename)
+	if err != nil {
+		return nil, err
+	}
+	var fixture map[string][]TxDetails
+	err = json.Unmarshal(b, &fixture)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", f.filename, err)
+	}
+
+	txs := make([]TxDetails, 0, len(fixture[address]))
+	for _, tx := range fixture[address] {
+		tx.Address = address
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
+
+func (f *fixtureTxFetcher) FetchTxsForAddressNotBefore(address string, notBefore int64) ([]TxDetails, error) {
+	txs, err := f.FetchTxsForAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	return filterTxsNotBefore(txs, notBefore), nil
+}
@@ -0,0 +1,282 @@
+// Copyright (c) 2018 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package util
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg"
+)
+
+const testXpub = "tpubVobLtToNtTq6TZNw4raWQok35PRPZou53vegZqNubtBTJMMFmuMpWybFCfweJ52N8uZJPZZdHE5SRnBBuuRPfC5jdNstfKjiAs8JtbYG9jx"
+
+// testTxs returns the transactions that are served by all test fetchers in
+// reverse chronological order.
+func testTxs(address string) []TxDetails {
+	return []TxDetails{
+		{
+			Address:       address,
+			TxID:          "c3",
+			Amount:        2e8,
+			Timestamp:     1530000300,
+			Confirmations: 1,
+		},
+		{
+			Address:       address,
+			TxID:          "c2",
+			Amount:        1e8,
+			Timestamp:     1530000200,
+			Confirmations: 10,
+		},
+		{
+			Address:       address,
+			TxID:          "c1",
+			Amount:        5e7,
+			Timestamp:     1530000100,
+			Confirmations: 20,
+		},
+	}
+}
+
+func testPrimaryTxs(address string) []map[string]interface{} {
+	txs := make([]map[string]interface{}, 0, 3)
+	for _, v := range testTxs(address) {
+		txs = append(txs, map[string]interface{}{
+			"txid":          v.TxID,
+			"confirmations": v.Confirmations,
+			"time":          v.Timestamp,
+			"vout": []map[string]interface{}{
+				{
+					"value": float64(v.Amount) / 1e8,
+					"scriptPubKey": map[string]interface{}{
+						"addresses": []string{address},
+					},
+				},
+				{
+					"value": 42,
+					"scriptPubKey": map[string]interface{}{
+						"addresses": []string{"change"},
+					},
+				},
+			},
+		})
+	}
+	return txs
+}
+
+func testBackupTxs(address string) []map[string]interface{} {
+	txs := make([]map[string]interface{}, 0, 3)
+	for _, v := range testTxs(address) {
+		txs = append(txs, map[string]interface{}{
+			"address":       address,
+			"txid":          v.TxID,
+			"amount":        float64(v.Amount) / 1e8,
+			"confirmations": v.Confirmations,
+			"ts":            v.Timestamp,
+		})
+	}
+	return txs
+}
+
+func TestTxFetchers(t *testing.T) {
+	address, err := DerivePaywallAddress(&chaincfg.TestNet3Params, testXpub,
+		0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// dcrdata
+	dcrdata := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/address/" + address + "/raw":
+				RespondWithJSON(w, http.StatusOK,
+					testPrimaryTxs(address))
+			case "/api/address/" + address + "/count/10/skip/0/raw":
+				RespondWithJSON(w, http.StatusOK,
+					testPrimaryTxs(address))
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+	defer dcrdata.Close()
+
+	// insight
+	insight := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/addr/"+address+"/utxo" {
+				http.NotFound(w, r)
+				return
+			}
+			RespondWithJSON(w, http.StatusOK, testBackupTxs(address))
+		}))
+	defer insight.Close()
+
+	// dcrd
+	dcrd := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			var req DcrdRequest
+			err := json.NewDecoder(r.Body).Decode(&req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if req.Method != "searchrawtransactions" ||
+				len(req.Params) != 4 || req.Params[0] != address {
+				t.Fatalf("unexpected request %v", req)
+			}
+			if req.Params[2].(float64) > 0 {
+				RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+					"error": DcrdError{
+						Code:    dcrdErrNoTxInfo,
+						Message: "No information available",
+					},
+				})
+				return
+			}
+			RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+				"result": testPrimaryTxs(address),
+			})
+		}))
+	defer dcrd.Close()
+	dcrdFetcher, err := NewDcrdTxFetcher(dcrd.URL, "user", "pass", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// fixture
+	dir, err := ioutil.TempDir("", "txfetcher")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fixture := filepath.Join(dir, "fixture.json")
+	b, err := json.Marshal(map[string][]TxDetails{
+		address: testTxs(address),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(fixture, b, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fetchers := []TxFetcher{
+		NewDcrdataTxFetcher(dcrdata.URL + "/api/"),
+		NewInsightTxFetcher(insight.URL + "/api"),
+		dcrdFetcher,
+		NewFixtureTxFetcher(fixture),
+	}
+	expected := testTxs(address)
+	for _, f := range fetchers {
+		txs, err := f.FetchTxsForAddress(address)
+		if err != nil {
+			t.Fatalf("%v: %v", f.Name(), err)
+		}
+		if len(txs) != len(expected) {
+			t.Fatalf("%v: unexpected txs %v", f.Name(), txs)
+		}
+		for k, v := range txs {
+			if v != expected[k] {
+				t.Fatalf("%v: got %v, want %v", f.Name(), v,
+					expected[k])
+			}
+		}
+
+		// Only c3 and c2 are after the notBefore
+		txs, err = f.FetchTxsForAddressNotBefore(address,
+			expected[2].Timestamp)
+		if err != nil {
+			t.Fatalf("%v: %v", f.Name(), err)
+		}
+		if len(txs) != 2 || txs[0].TxID != "c3" || txs[1].TxID != "c2" {
+			t.Fatalf("%v: unexpected not before txs %v", f.Name(),
+				txs)
+		}
+
+		// c3 does not have enough confirmations and c1 is too small
+		tx, amount, err := FetchTx(f, address, 1e8, 0, 2)
+		if err != nil {
+			t.Fatalf("%v: %v", f.Name(), err)
+		}
+		if tx != "c2" || amount != 1e8 {
+			t.Fatalf("%v: unexpected tx %v %v", f.Name(), tx, amount)
+		}
+
+		// No payment
+		tx, _, err = FetchTx(f, address, 3e8, 0, 0)
+		if err != nil {
+			t.Fatalf("%v: %v", f.Name(), err)
+		}
+		if tx != "" {
+			t.Fatalf("%v: unexpected tx %v", f.Name(), tx)
+		}
+	}
+}
+
+func TestFailoverTxFetcher(t *testing.T) {
+	address, err := DerivePaywallAddress(&chaincfg.TestNet3Params, testXpub,
+		0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	down := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+		}))
+	defer down.Close()
+
+	dir, err := ioutil.TempDir("", "txfetcher")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fixture := filepath.Join(dir, "fixture.json")
+	b, err := json.Marshal(map[string][]TxDetails{
+		address: testTxs(address),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(fixture, b, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := NewFailoverTxFetcher(NewDcrdataTxFetcher(down.URL),
+		NewFixtureTxFetcher(fixture))
+	if f.Name() != "dcrdata,fixture" {
+		t.Fatalf("unexpected name %v", f.Name())
+	}
+	tx, _, err := FetchTx(f, address, 1, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx != "c3" {
+		t.Fatalf("unexpected tx %v", tx)
+	}
+
+	// All fetchers fail
+	f = NewFailoverTxFetcher(NewDcrdataTxFetcher(down.URL),
+		NewInsightTxFetcher(down.URL),
+		NewFixtureTxFetcher(filepath.Join(dir, "missing.json")))
+	_, _, err = FetchTx(f, address, 1, 0, 0)
+	if err != ErrCannotVerifyPayment {
+		t.Fatalf("expected ErrCannotVerifyPayment, got %v", err)
+	}
+
+	// Invalid address
+	_, _, err = FetchTx(f, "moo", 1, 0, 0)
+	if err == nil || err == ErrCannotVerifyPayment {
+		t.Fatalf("expected invalid address, got %v", err)
+	}
+}
@@ -1,8 +1,10 @@
 package util
 
 import (
+	"crypto/sha256"
 	"encoding/json"
 	"errors"
+	"strings"
 
 	v1 "github.com/decred/politeia/politeiawww/api/v1"
 	"github.com/gorilla/websocket"
@@ -17,24 +19,50 @@ func validCommand(cmd string) bool {
 	case v1.WSCError:
 	case v1.WSCPing:
 	case v1.WSCSubscribe:
+	case v1.WSCNewProposal:
+	case v1.WSCProposalStatus:
+	case v1.WSCNewComment:
+	case v1.WSCVoteStarted:
+	case v1.WSCVoteTally:
 	default:
 		return false
 	}
 	return true
 }
 
-func ValidSubscription(cmd string) bool {
+// ParseSubscription splits a subscription into the command and the censorship
+// token of the proposal.  The token is empty for commands that are not per
+// proposal.
+func ParseSubscription(subscription string) (string, string) {
+	s := strings.SplitN(subscription, v1.WSSubscriptionSeparator, 2)
+	if len(s) == 1 {
+		return s[0], ""
+	}
+	return s[0], s[1]
+}
+
+// WSSubscription returns the subscription to a per proposal command.
+func WSSubscription(cmd, token string) string {
+	return cmd + v1.WSSubscriptionSeparator + token
+}
+
+func ValidSubscription(subscription string) bool {
+	cmd, token := ParseSubscription(subscription)
 	switch cmd {
-	case v1.WSCPing:
-	default:
-		return false
+	case v1.WSCPing, v1.WSCNewProposal, v1.WSCProposalStatus,
+		v1.WSCVoteStarted:
+		return subscription == cmd
+	case v1.WSCNewComment, v1.WSCVoteTally:
+		return len(token) == sha256.Size*2 && IsDigest(token)
 	}
-	return true
+	return false
 }
 
-func SubsciptionReqAuth(cmd string) bool {
+func SubsciptionReqAuth(subscription string) bool {
+	cmd, _ := ParseSubscription(subscription)
 	switch cmd {
-	case v1.WSCPing:
+	case v1.WSCPing, v1.WSCProposalStatus, v1.WSCNewComment,
+		v1.WSCVoteStarted, v1.WSCVoteTally:
 	default:
 		return true
 	}
@@ -86,6 +114,30 @@ func WSRead(c *websocket.Conn) (string, string, interface{}, error) {
 		var ping v1.WSPing
 		err = c.ReadJSON(&ping)
 		payload = ping
+	case v1.WSCError:
+		var e v1.WSError
+		err = c.ReadJSON(&e)
+		payload = e
+	case v1.WSCNewProposal:
+		var np v1.WSNewProposal
+		err = c.ReadJSON(&np)
+		payload = np
+	case v1.WSCProposalStatus:
+		var ps v1.WSProposalStatus
+		err = c.ReadJSON(&ps)
+		payload = ps
+	case v1.WSCNewComment:
+		var nc v1.WSNewComment
+		err = c.ReadJSON(&nc)
+		payload = nc
+	case v1.WSCVoteStarted:
+		var vs v1.WSVoteStarted
+		err = c.ReadJSON(&vs)
+		payload = vs
+	case v1.WSCVoteTally:
+		var vt v1.WSVoteTally
+		err = c.ReadJSON(&vt)
+		payload = vt
 	default:
 		return "", "", nil, ErrInvalidWSCommand
 	}
@@ -0,0 +1,44 @@
+// Copyright (c) 2018 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package util
+
+import (
+	"strings"
+	"testing"
+
+	v1 "github.com/decred/politeia/politeiawww/api/v1"
+)
+
+func TestValidSubscription(t *testing.T) {
+	token := strings.Repeat("ab", 32)
+	tests := []struct {
+		subscription string
+		valid        bool
+		auth         bool
+	}{
+		{v1.WSCPing, true, false},
+		{v1.WSCNewProposal, true, true},
+		{v1.WSCProposalStatus, true, false},
+		{v1.WSCVoteStarted, true, false},
+		{WSSubscription(v1.WSCNewComment, token), true, false},
+		{WSSubscription(v1.WSCVoteTally, token), true, false},
+		{WSSubscription(v1.WSCPing, token), false, false},
+		{v1.WSCNewComment, false, false},
+		{WSSubscription(v1.WSCNewComment, token+"ab"), false, false},
+		{WSSubscription(v1.WSCVoteTally, "invalid"), false, false},
+		{v1.WSCSubscribe, false, true},
+		{"unknown", false, true},
+	}
+	for _, test := range tests {
+		if ValidSubscription(test.subscription) != test.valid {
+			t.Errorf("%v: expected valid %v", test.subscription,
+				test.valid)
+		}
+		if SubsciptionReqAuth(test.subscription) != test.auth {
+			t.Errorf("%v: expected requires auth %v",
+				test.subscription, test.auth)
+		}
+	}
+}